@@ -9,6 +9,7 @@ import (
 	"github.com/evergreen-ci/evergreen/model/distro"
 	"github.com/evergreen-ci/evergreen/plugin"
 	_ "github.com/evergreen-ci/evergreen/plugin/config"
+	"io"
 	"net/http"
 	"os"
 	"strconv"
@@ -104,6 +105,7 @@ type TaskCommunicator interface {
 	FetchExpansionVars() (*apimodels.ExpansionVars, error)
 	tryGet(path string) (*http.Response, error)
 	tryPostJSON(path string, data interface{}) (*http.Response, error)
+	tryPostStream(path string, data io.Reader) (*http.Response, error)
 }
 
 // SignalHandler is an implementation of TerminateHandler which runs the post-run
@@ -533,6 +535,22 @@ func (agt *Agent) RunCommands(commands []model.PluginCommandConf, returnOnError
 				continue
 			}
 
+			if commandInfo.If != "" {
+				shouldRun, err := agt.taskConfig.Expansions.EvalCondition(commandInfo.If)
+				if err != nil {
+					agt.logger.LogTask(slogger.ERROR, "Couldn't evaluate condition for command %v: %v", fullCommandName, err)
+					if returnOnError {
+						return err
+					}
+					continue
+				}
+				if !shouldRun {
+					agt.logger.LogTask(slogger.INFO, "Skipping command %v: condition '%v' is false (step %v of %v)",
+						fullCommandName, commandInfo.If, i+1, len(commands))
+					continue
+				}
+			}
+
 			if len(cmds) == 1 {
 				agt.logger.LogTask(slogger.INFO, "Running command %v (step %v of %v)", fullCommandName, i+1, len(commands))
 			} else {
@@ -566,6 +584,24 @@ func (agt *Agent) RunCommands(commands []model.PluginCommandConf, returnOnError
 				}
 			}
 
+			// commands within a function can have their own conditions, which
+			// may depend on the function's vars
+			if commandInfo.Function != "" && parsedCommand.If != "" {
+				shouldRun, err := agt.taskConfig.Expansions.EvalCondition(parsedCommand.If)
+				if err != nil {
+					agt.logger.LogTask(slogger.ERROR, "Couldn't evaluate condition for command %v: %v", fullCommandName, err)
+					if returnOnError {
+						return err
+					}
+					continue
+				}
+				if !shouldRun {
+					agt.logger.LogTask(slogger.INFO, "Skipping command %v: condition '%v' is false (step %v of %v)",
+						fullCommandName, parsedCommand.If, i+1, len(commands))
+					continue
+				}
+			}
+
 			pluginCom := &TaskJSONCommunicator{cmd.Plugin(), agt.TaskCommunicator}
 
 			agt.CheckIn(parsedCommand, timeoutPeriod)
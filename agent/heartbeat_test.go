@@ -7,6 +7,7 @@ import (
 	"github.com/evergreen-ci/evergreen/model"
 	"github.com/evergreen-ci/evergreen/model/distro"
 	. "github.com/smartystreets/goconvey/convey"
+	"io"
 	"net/http"
 	"testing"
 	"time"
@@ -28,6 +29,10 @@ func (mc *MockCommunicator) tryPostJSON(path string, data interface{}) (*http.Re
 	return nil, nil
 }
 
+func (mc *MockCommunicator) tryPostStream(path string, data io.Reader) (*http.Response, error) {
+	return nil, nil
+}
+
 func (mc *MockCommunicator) Start(pid string) error {
 	if mc.shouldFailStart {
 		return fmt.Errorf("failed to start!")
@@ -0,0 +1,88 @@
+package agent
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/apimodels"
+	"github.com/evergreen-ci/evergreen/util"
+	"net/http"
+	"time"
+)
+
+// HostCommunicator is used by a pull agent to ask the API server for the
+// tasks its host should run. It authenticates as the host, rather than as
+// a task.
+type HostCommunicator struct {
+	ServerURLRoot string
+	HostId        string
+	HostSecret    string
+	MaxAttempts   int
+	RetrySleep    time.Duration
+	httpClient    *http.Client
+}
+
+// NewHostCommunicator returns a HostCommunicator for the given host.
+func NewHostCommunicator(serverURL, hostId, hostSecret, cert string) (*HostCommunicator, error) {
+	hostCommunicator := &HostCommunicator{
+		ServerURLRoot: fmt.Sprintf("%v/api/%v", serverURL, APIVersion),
+		HostId:        hostId,
+		HostSecret:    hostSecret,
+		MaxAttempts:   10,
+		RetrySleep:    time.Second * 3,
+		httpClient:    &http.Client{},
+	}
+
+	if cert != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(cert)) {
+			return nil, errors.New("failed to append HttpsCert to new cert pool")
+		}
+		hostCommunicator.httpClient = &http.Client{
+			Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}},
+		}
+	}
+	return hostCommunicator, nil
+}
+
+// NextTask asks the API server for the next task to run on the host.
+func (h *HostCommunicator) NextTask() (*apimodels.NextTaskResponse, error) {
+	nextTask := &apimodels.NextTaskResponse{}
+	retriableGet := util.RetriableFunc(
+		func() error {
+			endpointUrl := fmt.Sprintf("%s/host/%s/next_task", h.ServerURLRoot, h.HostId)
+			req, err := http.NewRequest("GET", endpointUrl, nil)
+			if err != nil {
+				return err
+			}
+			req.Header.Add(evergreen.HostSecretHeader, h.HostSecret)
+
+			resp, err := h.httpClient.Do(req)
+			if err != nil {
+				// Some generic error trying to connect - try again
+				return util.RetriableError{err}
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode == http.StatusConflict {
+				// Something very wrong, fail now with no retry.
+				return fmt.Errorf("conflict - wrong host secret!")
+			}
+			if resp.StatusCode != http.StatusOK {
+				return util.RetriableError{fmt.Errorf("unexpected status code %v "+
+					"asking for next task", resp.StatusCode)}
+			}
+			if err = util.ReadJSONInto(resp.Body, nextTask); err != nil {
+				return util.RetriableError{err}
+			}
+			return nil
+		},
+	)
+
+	_, err := util.Retry(retriableGet, h.MaxAttempts, h.RetrySleep)
+	if err != nil {
+		return nil, fmt.Errorf("asking for next task failed: %v", err)
+	}
+	return nextTask, nil
+}
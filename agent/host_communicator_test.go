@@ -0,0 +1,53 @@
+package agent
+
+import (
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/apimodels"
+	"github.com/evergreen-ci/evergreen/util"
+	. "github.com/smartystreets/goconvey/convey"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHostCommunicatorNextTask(t *testing.T) {
+	Convey("With a host communicator and a live HTTP server", t, func() {
+		serveMux := http.NewServeMux()
+		ts := httptest.NewServer(serveMux)
+		Reset(func() {
+			ts.Close()
+		})
+
+		serveMux.HandleFunc("/api/2/host/mockhostid/next_task",
+			func(w http.ResponseWriter, req *http.Request) {
+				if req.Header.Get(evergreen.HostSecretHeader) != "mockhostsecret" {
+					http.Error(w, "wrong secret!", http.StatusConflict)
+					return
+				}
+				util.WriteJSON(&w, apimodels.NextTaskResponse{
+					TaskId:     "mocktaskid",
+					TaskSecret: "mocktasksecret",
+				}, http.StatusOK)
+			})
+
+		hostCommunicator, err := NewHostCommunicator(ts.URL, "mockhostid", "mockhostsecret", "")
+		So(err, ShouldBeNil)
+		hostCommunicator.RetrySleep = 10 * time.Millisecond
+
+		Convey("the next task should be returned", func() {
+			nextTask, err := hostCommunicator.NextTask()
+			So(err, ShouldBeNil)
+			So(nextTask.TaskId, ShouldEqual, "mocktaskid")
+			So(nextTask.TaskSecret, ShouldEqual, "mocktasksecret")
+			So(nextTask.ShouldExit, ShouldBeFalse)
+		})
+
+		Convey("a wrong secret should fail without retrying", func() {
+			hostCommunicator.HostSecret = "wrong"
+			hostCommunicator.MaxAttempts = 1000
+			_, err := hostCommunicator.NextTask()
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/evergreen-ci/evergreen/model/distro"
 	"github.com/evergreen-ci/evergreen/model/version"
 	"github.com/evergreen-ci/evergreen/util"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"time"
@@ -93,6 +94,11 @@ func (t *TaskJSONCommunicator) TaskGetJSON(endpoint string) (*http.Response, err
 	return t.tryGet(fmt.Sprintf("%s/%s", t.PluginName, endpoint))
 }
 
+// TaskPostStream does an HTTP POST of raw data for the communicator's plugin + task.
+func (t *TaskJSONCommunicator) TaskPostStream(endpoint string, data io.Reader) (*http.Response, error) {
+	return t.tryPostStream(fmt.Sprintf("%s/%s", t.PluginName, endpoint), data)
+}
+
 // TaskPostResults posts a set of test results for the communicator's task.
 func (t *TaskJSONCommunicator) TaskPostResults(results *model.TestResults) error {
 	retriableSendFile := util.RetriableFunc(
@@ -481,6 +487,18 @@ func (h *HTTPCommunicator) tryPostJSON(path string, data interface{}) (
 	return h.tryRequestWithClient(path, "POST", h.httpClient, &data)
 }
 
+// tryPostStream posts the reader's contents as an application/octet-stream body.
+func (h *HTTPCommunicator) tryPostStream(path string, data io.Reader) (*http.Response, error) {
+	endpointUrl := fmt.Sprintf("%s/task/%s/%s", h.ServerURLRoot, h.TaskId, path)
+	req, err := http.NewRequest("POST", endpointUrl, data)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add(evergreen.TaskSecretHeader, h.TaskSecret)
+	req.Header.Add("Content-Type", "application/octet-stream")
+	return h.httpClient.Do(req)
+}
+
 // tryRequestWithClient does the given task HTTP request using the provided client, allowing
 // requests to be done with multiple client configurations/timeouts.
 func (h *HTTPCommunicator) tryRequestWithClient(path string, method string, client *http.Client,
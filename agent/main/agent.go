@@ -7,13 +7,14 @@ import (
 	"github.com/evergreen-ci/evergreen/agent"
 	"io/ioutil"
 	"os"
+	"sync"
 	"time"
 )
 
 func init() {
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "%s pulls tasks from the API server and runs them.\n", os.Args[0])
-		fmt.Fprintf(os.Stderr, "This program is designed to be started by the Evergreen taskrunner, not manually.\n\n")
+		fmt.Fprintf(os.Stderr, "This program is designed to be started by the Evergreen taskrunner or hostinit, not manually.\n\n")
 		fmt.Fprintf(os.Stderr, "Usage:\n  %s [flags]\n\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "Supported flags are:\n")
 		flag.PrintDefaults()
@@ -24,6 +25,9 @@ func main() {
 	// Get the basic info needed to run the agent from command line flags.
 	taskId := flag.String("task_id", "", "id of task to run")
 	taskSecret := flag.String("task_secret", "", "secret of task to run")
+	hostId := flag.String("host_id", "", "id of the host to pull tasks for, instead of running a single task")
+	hostSecret := flag.String("host_secret", "", "secret of the host to pull tasks for")
+	pollInterval := flag.Duration("poll_interval", 15*time.Second, "how long to wait between asking for tasks when there are none")
 	apiServer := flag.String("api_server", "", "URL of API server")
 	httpsCertFile := flag.String("https_cert", "", "path to a self-signed private cert")
 	logPrefix := flag.String("log_prefix", "", "prefix for the agent's log filename")
@@ -37,37 +41,78 @@ func main() {
 
 	logFile := *logPrefix + logSuffix()
 
-	agt, err := agent.New(*apiServer, *taskId, *taskSecret, logFile, httpsCert)
+	if *hostId == "" {
+		if err = runTasks(*apiServer, *taskId, *taskSecret, logFile, httpsCert); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	// in pull mode, keep asking for tasks until the API server says to exit
+	hostComm, err := agent.NewHostCommunicator(*apiServer, *hostId, *hostSecret, httpsCert)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "could not create new agent: %v\n", err)
+		fmt.Fprintf(os.Stderr, "could not create host communicator: %v\n", err)
 		os.Exit(1)
 	}
+	for {
+		next, err := hostComm.NextTask()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error asking for next task: %v\n", err)
+			time.Sleep(*pollInterval)
+			continue
+		}
+		if next.ShouldExit {
+			fmt.Fprintf(os.Stderr, "exiting: %v\n", next.Message)
+			os.Exit(0)
+		}
+		if next.TaskId == "" {
+			time.Sleep(*pollInterval)
+			continue
+		}
+		if err = runTasks(*apiServer, next.TaskId, next.TaskSecret, logFile, httpsCert); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+		}
+	}
+}
+
+// the agent running the current task, for dumping debug traces
+var (
+	currentAgent   *agent.Agent
+	dumpStackStart sync.Once
+)
+
+// runTasks runs the given task, then any tasks the API server hands back
+// on finishing it, until a response has RunNext set to false.
+func runTasks(apiServer, taskId, taskSecret, logFile, httpsCert string) error {
+	agt, err := agent.New(apiServer, taskId, taskSecret, logFile, httpsCert)
+	if err != nil {
+		return fmt.Errorf("could not create new agent: %v", err)
+	}
+	currentAgent = agt
 
 	// enable debug traces on SIGQUIT signaling
-	go agent.DumpStackOnSIGQUIT(&agt)
+	dumpStackStart.Do(func() { go agent.DumpStackOnSIGQUIT(&currentAgent) })
 
-	// run all tasks until an API server's response has RunNext set to false
 	for {
 		resp, err := agt.RunTask()
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "error running task: %v\n", err)
-			os.Exit(1)
+			return fmt.Errorf("error running task: %v", err)
 		}
 
 		if resp == nil {
-			fmt.Fprintf(os.Stderr, "received nil response from API server\n")
-			os.Exit(1)
+			return fmt.Errorf("received nil response from API server")
 		}
 
 		if !resp.RunNext {
-			os.Exit(0)
+			return nil
 		}
 
-		agt, err = agent.New(*apiServer, resp.TaskId, resp.TaskSecret, logFile, httpsCert)
+		agt, err = agent.New(apiServer, resp.TaskId, resp.TaskSecret, logFile, httpsCert)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "could not create new agent for next task '%v': %v\n", resp.TaskId, err)
-			os.Exit(1)
+			return fmt.Errorf("could not create new agent for next task '%v': %v", resp.TaskId, err)
 		}
+		currentAgent = agt
 	}
 }
 
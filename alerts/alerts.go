@@ -168,7 +168,9 @@ func (qp *QueueProcessor) findProject(projectId string) (*model.ProjectRef, erro
 // getDeliverer returns the correct implementation of Deliverer according to the provider
 // specified in a project's alerts configuration.
 func (qp *QueueProcessor) getDeliverer(alertConf model.AlertConfig) (Deliverer, error) {
-	// TODO email is the only delivery mechanism supported currently.
+	if alertConf.Provider == "chat" {
+		return &ChatDeliverer{qp.config.Chat}, nil
+	}
 	if alertConf.Provider == "email" {
 		return &EmailDeliverer{
 			SMTPSettings{
@@ -190,13 +192,23 @@ func (qp *QueueProcessor) Deliver(req *alert.AlertRequest, ctx *AlertContext) er
 	if ctx.ProjectRef != nil {
 		// Project-specific alert - use alert configs defined on the project
 		// TODO(EVG-223) patch alerts should go to patch owner
-		alertConfigs = ctx.ProjectRef.Alerts[req.Trigger]
+		var err error
+		alertConfigs, err = ctx.ProjectRef.GetAlerts(req.Trigger)
+		if err != nil {
+			return err
+		}
 	} else if ctx.Host != nil {
 		// Host-specific alert - use superuser alert configs for now
 		// TODO(EVG-224) spawnhost alerts should go to spawnhost owner
 		alertConfigs = qp.superUsersConfigs
 	}
 
+	subscriptionConfigs, err := getSubscriptionConfigs(req, ctx)
+	if err != nil {
+		return err
+	}
+	alertConfigs = append(alertConfigs, subscriptionConfigs...)
+
 	for _, alertConfig := range alertConfigs {
 		deliverer, err := qp.getDeliverer(alertConfig)
 		if err != nil {
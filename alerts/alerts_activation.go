@@ -1,11 +1,13 @@
 package alerts
 
 import (
+	"github.com/10gen-labs/slogger/v1"
 	"github.com/evergreen-ci/evergreen"
 	"github.com/evergreen-ci/evergreen/db"
 	"github.com/evergreen-ci/evergreen/model"
 	"github.com/evergreen-ci/evergreen/model/alert"
 	"github.com/evergreen-ci/evergreen/model/host"
+	"github.com/evergreen-ci/evergreen/model/knownissue"
 	"github.com/evergreen-ci/evergreen/model/version"
 	"gopkg.in/mgo.v2/bson"
 	"time"
@@ -59,6 +61,18 @@ func RunLastRevisionNotFoundTrigger(proj *model.ProjectRef, v *version.Version)
 
 // RunTaskTriggers queues alerts for any active triggers on the tasks's state change.
 func RunTaskFailureTriggers(task *model.Task) error {
+	// repeats of failures already tracked by a ticket don't alert again
+	issue, err := knownissue.FindCovering(task.Project, task.BuildVariant, task.DisplayName,
+		task.FailedTests())
+	if err != nil {
+		return err
+	}
+	if issue != nil {
+		evergreen.Logger.Logf(slogger.INFO, "Not alerting for failure of task %v, known issue %v",
+			task.Id, issue.Ticket)
+		return nil
+	}
+
 	ctx, err := getTaskTriggerContext(task)
 	if err != nil {
 		return err
@@ -91,6 +105,36 @@ func RunTaskFailureTriggers(task *model.Task) error {
 	return nil
 }
 
+// RunPerfRegressionTriggers queues an alert for regressions in the task's
+// performance results. The display string describes the regressions.
+func RunPerfRegressionTriggers(task *model.Task, display string) error {
+	ctx := triggerContext{task: task}
+	trigger := PerfRegression{}
+	shouldExec, err := trigger.ShouldExecute(ctx)
+	if err != nil {
+		return err
+	}
+	if !shouldExec {
+		return nil
+	}
+
+	err = alert.EnqueueAlertRequest(&alert.AlertRequest{
+		Id:        bson.NewObjectId(),
+		Trigger:   trigger.Id(),
+		TaskId:    task.Id,
+		Execution: task.Execution,
+		BuildId:   task.BuildId,
+		VersionId: task.Version,
+		ProjectId: task.Project,
+		Display:   display,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	return storeTriggerBookkeeping(ctx, []Trigger{trigger})
+}
+
 func RunHostProvisionFailTriggers(h *host.Host) error {
 	ctx := triggerContext{host: h}
 	trigger := &ProvisionFailed{}
@@ -115,6 +159,30 @@ func RunHostProvisionFailTriggers(h *host.Host) error {
 	return storeTriggerBookkeeping(ctx, []Trigger{trigger})
 }
 
+// RunHostQuarantinedTriggers alerts the admins that the host was quarantined.
+func RunHostQuarantinedTriggers(h *host.Host) error {
+	ctx := triggerContext{host: h}
+	trigger := &HostQuarantined{}
+	shouldExec, err := trigger.ShouldExecute(ctx)
+	if err != nil {
+		return err
+	}
+	if !shouldExec {
+		return nil
+	}
+
+	err = alert.EnqueueAlertRequest(&alert.AlertRequest{
+		Id:        bson.NewObjectId(),
+		Trigger:   trigger.Id(),
+		HostId:    h.Id,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	return storeTriggerBookkeeping(ctx, []Trigger{trigger})
+}
+
 func RunSpawnWarningTriggers(host *host.Host) error {
 	ctx := triggerContext{host: host}
 	for _, trigger := range SpawnWarningTriggers {
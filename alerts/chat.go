@@ -0,0 +1,105 @@
+package alerts
+
+import (
+	"fmt"
+	"github.com/10gen-labs/slogger/v1"
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/alertrecord"
+	"github.com/evergreen-ci/evergreen/thirdparty"
+	"strings"
+)
+
+// maxChatFailedTests is the number of failed tests listed in a chat message
+const maxChatFailedTests = 10
+
+// ChatDeliverer is an implementation of Deliverer that posts notifications to
+// a Slack or Mattermost compatible incoming webhook. The alert config may set
+// the "channel" to post to.
+type ChatDeliverer struct {
+	evergreen.ChatConfig
+}
+
+func (cd *ChatDeliverer) Deliver(alertCtx AlertContext, alertConf model.AlertConfig) error {
+	if cd.WebhookURL == "" {
+		return fmt.Errorf("no chat webhook configured")
+	}
+	channel, _ := alertConf.Settings["channel"].(string)
+	evergreen.Logger.Logf(slogger.INFO, "Posting chat message to '%v'", channel)
+
+	message := getChatMessage(alertCtx)
+	message.Channel = channel
+	message.Username = cd.Username
+	message.IconURL = cd.IconURL
+	return thirdparty.PostChatMessage(cd.WebhookURL, message)
+}
+
+// getChatMessage generates the chat message for the given alert. Task alerts
+// get an attachment with the failed tests, the blamelist and links to the
+// task and its logs.
+func getChatMessage(alertCtx AlertContext) *thirdparty.ChatMessage {
+	subject := fmt.Sprintf("%v %v", EmailSubjectPrologue, getSubject(alertCtx))
+	message := &thirdparty.ChatMessage{Text: subject}
+	if alertCtx.Task == nil {
+		if alertCtx.Host != nil {
+			message.Text += "\n" + thirdparty.ChatLink(
+				fmt.Sprintf("%v/host/%v", alertCtx.Settings.Ui.Url, alertCtx.Host.Id), "view host")
+		}
+		return message
+	}
+
+	uiRoot := alertCtx.Settings.Ui.Url
+	task := alertCtx.Task
+	attachment := thirdparty.ChatAttachment{
+		Fallback:  subject,
+		Color:     thirdparty.ChatColorDanger,
+		Title:     fmt.Sprintf("%v on %v", task.DisplayName, task.BuildVariant),
+		TitleLink: fmt.Sprintf("%v/task/%v/%v", uiRoot, task.Id, task.Execution),
+	}
+	if alertCtx.AlertRequest.Trigger == alertrecord.PerfRegressionId {
+		attachment.Color = thirdparty.ChatColorWarning
+		attachment.Text = alertCtx.AlertRequest.Display
+	}
+
+	if len(alertCtx.FailedTests) != 0 {
+		tests := []string{}
+		for i, test := range alertCtx.FailedTests {
+			if i == maxChatFailedTests {
+				tests = append(tests, fmt.Sprintf("and %v more", len(alertCtx.FailedTests)-i))
+				break
+			}
+			tests = append(tests, test.TestFile)
+		}
+		attachment.Fields = append(attachment.Fields, thirdparty.ChatField{
+			Title: "Failed tests",
+			Value: strings.Join(tests, "\n"),
+		})
+	}
+
+	if alertCtx.Patch != nil {
+		attachment.Fields = append(attachment.Fields, thirdparty.ChatField{
+			Title: "Patch",
+			Value: fmt.Sprintf("%v by %v", alertCtx.Patch.Description, alertCtx.Patch.Author),
+		})
+	} else if alertCtx.Version != nil {
+		attachment.Fields = append(attachment.Fields, thirdparty.ChatField{
+			Title: "Blamelist",
+			Value: fmt.Sprintf("%v %v: %v", alertCtx.Version.Revision[0:5],
+				alertCtx.Version.Author, firstLine(alertCtx.Version.Message)),
+		})
+	}
+
+	attachment.Fields = append(attachment.Fields, thirdparty.ChatField{
+		Title: "Logs",
+		Value: thirdparty.ChatLink(fmt.Sprintf("%v/task_log_raw/%v/%v?type=ALL",
+			uiRoot, task.Id, task.Execution), "all logs"),
+		Short: true,
+	})
+	message.Attachments = []thirdparty.ChatAttachment{attachment}
+	return message
+}
+
+// firstLine returns the first line of a commit message.
+func firstLine(message string) string {
+	return strings.SplitN(message, "\n", 2)[0]
+}
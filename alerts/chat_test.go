@@ -0,0 +1,43 @@
+package alerts
+
+import (
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/alert"
+	"github.com/evergreen-ci/evergreen/model/alertrecord"
+	"github.com/evergreen-ci/evergreen/model/build"
+	"github.com/evergreen-ci/evergreen/model/version"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestChatMessage(t *testing.T) {
+	Convey("With a task failure alert", t, func() {
+		settings := &evergreen.Settings{}
+		settings.Ui.Url = "http://evergreen.example.com"
+		alertCtx := AlertContext{
+			AlertRequest: &alert.AlertRequest{Trigger: alertrecord.TaskFailedId},
+			ProjectRef:   &model.ProjectRef{DisplayName: "mongo"},
+			Task: &model.Task{Id: "t1", DisplayName: "compile", BuildVariant: "linux-64",
+				Execution: 1},
+			Build: &build.Build{DisplayName: "Linux 64"},
+			Version: &version.Version{Revision: "abcdef123", Author: "jane",
+				Message: "fix the build\n\nlong description"},
+			FailedTests: []model.TestResult{{TestFile: "a.js"}, {TestFile: "b.js"}},
+			Settings:    settings,
+		}
+
+		Convey("the message should describe the failure", func() {
+			message := getChatMessage(alertCtx)
+			So(message.Text, ShouldContainSubstring, "compile on Linux 64")
+			So(len(message.Attachments), ShouldEqual, 1)
+
+			attachment := message.Attachments[0]
+			So(attachment.TitleLink, ShouldEqual, "http://evergreen.example.com/task/t1/1")
+			So(len(attachment.Fields), ShouldEqual, 3)
+			So(attachment.Fields[0].Value, ShouldEqual, "a.js\nb.js")
+			So(attachment.Fields[1].Value, ShouldEqual, "abcde jane: fix the build")
+			So(attachment.Fields[2].Value, ShouldContainSubstring, "/task_log_raw/t1/1?type=ALL")
+		})
+	})
+}
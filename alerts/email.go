@@ -124,6 +124,10 @@ func getTemplate(alertCtx AlertContext) string {
 		fallthrough
 	case alertrecord.SpawnHostTwelveHourWarning:
 		return "email/host_spawn.html"
+	case alertrecord.HostQuarantinedId:
+		return "email/host_quarantined.html"
+	case alertrecord.PerfRegressionId:
+		return "email/perf_regression.html"
 	default:
 		return "email/task_fail.html"
 	}
@@ -169,12 +173,22 @@ func getSubject(alertCtx AlertContext) string {
 			alertCtx.ProjectRef.DisplayName,
 			alertCtx.Version.Revision[0:5],
 		)
+	case alertrecord.PerfRegressionId:
+		return fmt.Sprintf("Performance regression in '%s' on %s (%s @ %s)",
+			alertCtx.Task.DisplayName,
+			alertCtx.Build.DisplayName,
+			alertCtx.ProjectRef.DisplayName,
+			alertCtx.Version.Revision[0:5],
+		)
 	case alertrecord.SpawnHostTwoHourWarning:
 		return fmt.Sprintf("Your %s host (%s) will expire in two hours.",
 			alertCtx.Host.Distro, alertCtx.Host.Id)
 	case alertrecord.SpawnHostTwelveHourWarning:
 		return fmt.Sprintf("Your %s host (%s) will expire in twelve hours.",
 			alertCtx.Host.Distro, alertCtx.Host.Id)
+	case alertrecord.HostQuarantinedId:
+		return fmt.Sprintf("Host %s (%s) was quarantined after repeated system failures.",
+			alertCtx.Host.Id, alertCtx.Host.Distro.Id)
 		// TODO(EVG-224) alertrecord.SpawnHostExpired:
 	}
 
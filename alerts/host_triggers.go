@@ -104,3 +104,20 @@ func (pf *ProvisionFailed) ShouldExecute(ctx triggerContext) (bool, error) {
 	}
 	return true, nil
 }
+
+type HostQuarantined struct{}
+
+func (hq HostQuarantined) Id() string { return alertrecord.HostQuarantinedId }
+
+func (hq HostQuarantined) Display() string {
+	return "Host was quarantined after repeated system failures"
+}
+
+func (hq HostQuarantined) CreateAlertRecord(ctx triggerContext) *alertrecord.AlertRecord {
+	// No bookkeeping done for this trigger - the monitor only quarantines a host once.
+	return nil
+}
+
+func (hq *HostQuarantined) ShouldExecute(ctx triggerContext) (bool, error) {
+	return ctx.host != nil && ctx.host.Status == evergreen.HostQuarantined, nil
+}
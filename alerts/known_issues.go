@@ -0,0 +1,73 @@
+package alerts
+
+import (
+	"github.com/10gen-labs/slogger/v1"
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/knownissue"
+	"github.com/evergreen-ci/evergreen/thirdparty"
+	"time"
+)
+
+// KnownIssuePruner deletes the known issues whose JIRA tickets have been
+// resolved, so that the failures they covered alert again.
+type KnownIssuePruner struct{}
+
+const (
+	KnownIssuePrunerName        = "knownissues"
+	KnownIssuePrunerDescription = "delete known issues whose JIRA tickets have been resolved"
+)
+
+func (kip *KnownIssuePruner) Name() string {
+	return KnownIssuePrunerName
+}
+
+func (kip *KnownIssuePruner) Description() string {
+	return KnownIssuePrunerDescription
+}
+
+func (kip *KnownIssuePruner) Run(config *evergreen.Settings) error {
+	if config.Jira.Host == "" {
+		evergreen.Logger.Logf(slogger.INFO, "No JIRA host configured, not pruning known issues")
+		return nil
+	}
+
+	startTime := time.Now()
+	evergreen.Logger.Logf(slogger.INFO, "Starting known issue pruning at time %v", startTime)
+
+	issues, err := knownissue.Find(knownissue.All)
+	if err != nil {
+		return evergreen.Logger.Errorf(slogger.ERROR, "error finding known issues: %v", err)
+	}
+	jira := thirdparty.NewJiraHandler(config.Jira.Host, config.Jira.Username, config.Jira.Password)
+	checked := map[string]bool{}
+	for _, issue := range issues {
+		if checked[issue.Ticket] {
+			continue
+		}
+		checked[issue.Ticket] = true
+
+		// a ticket that can't be looked up is checked again on the next run
+		ticket, err := jira.GetJIRATicket(issue.Ticket)
+		if err != nil {
+			evergreen.Logger.Logf(slogger.ERROR, "Error getting JIRA ticket %v: %v", issue.Ticket, err)
+			continue
+		}
+		if ticket.Fields == nil || ticket.Fields.Resolution == nil {
+			continue
+		}
+		evergreen.Logger.Logf(slogger.INFO, "Ticket %v is resolved (%v), deleting its known issues",
+			issue.Ticket, ticket.Fields.Resolution.Name)
+		if err = knownissue.RemoveByTicket(issue.Ticket); err != nil {
+			return evergreen.Logger.Errorf(slogger.ERROR, "error deleting known issues of ticket %v: %v",
+				issue.Ticket, err)
+		}
+	}
+
+	runtime := time.Now().Sub(startTime)
+	if err = model.SetProcessRuntimeCompleted(KnownIssuePrunerName, runtime); err != nil {
+		evergreen.Logger.Errorf(slogger.ERROR, "error updating process status: %v", err)
+	}
+	evergreen.Logger.Logf(slogger.INFO, "Known issue pruning took %v to run", runtime)
+	return nil
+}
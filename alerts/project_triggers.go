@@ -158,3 +158,17 @@ func (lrnf LastRevisionNotFound) CreateAlertRecord(ctx triggerContext) *alertrec
 	rec := newAlertRecord(ctx, alertrecord.LastRevisionNotFound)
 	return rec
 }
+
+// PerfRegression is raised by the perf plugin when a task's benchmark results
+// are worse than those of the previous revisions.
+type PerfRegression struct{}
+
+func (pr PerfRegression) Id() string      { return alertrecord.PerfRegressionId }
+func (pr PerfRegression) Display() string { return "a performance metric regresses" }
+
+func (pr PerfRegression) ShouldExecute(ctx triggerContext) (bool, error) {
+	return ctx.task != nil, nil
+}
+
+// No bookkeeping is done - the perf plugin only checks a task's results once.
+func (pr PerfRegression) CreateAlertRecord(_ triggerContext) *alertrecord.AlertRecord { return nil }
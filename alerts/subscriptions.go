@@ -0,0 +1,76 @@
+package alerts
+
+import (
+	"github.com/10gen-labs/slogger/v1"
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/alert"
+	"github.com/evergreen-ci/evergreen/model/subscription"
+	"github.com/evergreen-ci/evergreen/model/user"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// getSubscriptionConfigs returns the alert configs of the users and teams
+// subscribed to the alert request's trigger on its project. Users subscribed
+// to patch alerts only get the alerts for their own patches.
+func getSubscriptionConfigs(req *alert.AlertRequest, ctx *AlertContext) ([]model.AlertConfig, error) {
+	if ctx.ProjectRef == nil {
+		return nil, nil
+	}
+	subscriptions, err := subscription.Find(
+		subscription.ByProjectAndTrigger(ctx.ProjectRef.Identifier, req.Trigger))
+	if err != nil {
+		return nil, err
+	}
+
+	requester := evergreen.RepotrackerVersionRequester
+	if ctx.Patch != nil {
+		requester = evergreen.PatchVersionRequester
+	}
+
+	alertConfigs := []model.AlertConfig{}
+	for _, s := range subscriptions {
+		if s.Requester != "" && s.Requester != requester {
+			continue
+		}
+		if ctx.Task != nil && !s.Matches(ctx.Task.BuildVariant, ctx.Task.DisplayName) {
+			continue
+		}
+
+		address := s.Address
+		if !s.IsTeam() {
+			if ctx.Patch != nil && ctx.Patch.Author != s.Owner {
+				continue
+			}
+			dbUser, err := user.FindOne(user.ById(s.Owner))
+			if err != nil {
+				return nil, err
+			}
+			if dbUser == nil {
+				evergreen.Logger.Logf(slogger.WARN, "User %v of subscription %v not found",
+					s.Owner, s.Id.Hex())
+				continue
+			}
+			address = dbUser.Email()
+			if s.IsChat() {
+				if dbUser.Settings.ChatHandle == "" {
+					evergreen.Logger.Logf(slogger.WARN, "User %v has no chat handle", s.Owner)
+					continue
+				}
+				address = "@" + dbUser.Settings.ChatHandle
+			}
+		}
+		if s.IsChat() {
+			alertConfigs = append(alertConfigs, model.AlertConfig{
+				Provider: "chat",
+				Settings: bson.M{"channel": address},
+			})
+			continue
+		}
+		alertConfigs = append(alertConfigs, model.AlertConfig{
+			Provider: "email",
+			Settings: bson.M{"recipient": address},
+		})
+	}
+	return alertConfigs, nil
+}
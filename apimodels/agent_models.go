@@ -35,5 +35,15 @@ type TaskEndResponse struct {
 	RunNext    bool   `json:"run_next,omitempty"`
 }
 
+// NextTaskResponse is what a pull agent receives when it asks for work. An
+// empty TaskId means there is nothing to run yet, and the agent should ask
+// again later; ShouldExit tells the agent to shut down.
+type NextTaskResponse struct {
+	TaskId     string `json:"task_id,omitempty"`
+	TaskSecret string `json:"task_secret,omitempty"`
+	Message    string `json:"message,omitempty"`
+	ShouldExit bool   `json:"should_exit,omitempty"`
+}
+
 // ExpansionVars is a map of expansion variables for a project.
 type ExpansionVars map[string]string
@@ -13,10 +13,12 @@ import (
 	"github.com/evergreen-ci/evergreen/bookkeeping"
 	"github.com/evergreen-ci/evergreen/cloud/providers"
 	"github.com/evergreen-ci/evergreen/db"
+	"github.com/evergreen-ci/evergreen/metrics"
 	"github.com/evergreen-ci/evergreen/model"
 	"github.com/evergreen-ci/evergreen/model/artifact"
 	"github.com/evergreen-ci/evergreen/model/event"
 	"github.com/evergreen-ci/evergreen/model/host"
+	"github.com/evergreen-ci/evergreen/model/logsearch"
 	"github.com/evergreen-ci/evergreen/model/user"
 	"github.com/evergreen-ci/evergreen/model/version"
 	"github.com/evergreen-ci/evergreen/notify"
@@ -44,6 +46,7 @@ type (
 	//  special types used as key types in the request context map to prevent key collisions.
 	userKey           int
 	taskKey           int
+	hostKey           int
 	projectContextKey int
 )
 
@@ -52,6 +55,7 @@ const (
 	// These are private custom types to avoid key collisions.
 	apiUserKey    userKey           = 0
 	apiTaskKey    taskKey           = 0
+	apiHostKey    hostKey           = 0
 	apiProjCtxKey projectContextKey = 0
 )
 
@@ -219,8 +223,11 @@ func (as *APIServer) checkTask(checkSecret bool, next http.HandlerFunc) http.Han
 		}
 
 		context.Set(r, apiTaskKey, task)
-		// also set the task in the context visible to plugins
+		// also set the task and user in the context visible to plugins
 		plugin.SetTask(r, task)
+		if u := GetUser(r); u != nil {
+			plugin.SetUser(r, u)
+		}
 		next(w, r)
 	}
 }
@@ -359,6 +366,22 @@ func (as *APIServer) EndTask(w http.ResponseWriter, r *http.Request) {
 	}
 	defer releaseGlobalLock(r.RemoteAddr, task.Id)
 
+	// tasks that hit a system failure are rescheduled on another host,
+	// if the project allows it, instead of being marked as failed
+	retried, err := task.TryRetrySystemFailure(APIServerLockTitle, details, projectRef.SystemFailureRetries)
+	if err != nil {
+		message := fmt.Errorf("Error retrying task %v after system failure: %v", task.Id, err)
+		as.LoggedError(w, r, http.StatusInternalServerError, message)
+		return
+	}
+	if retried {
+		evergreen.Logger.Logf(slogger.INFO, "Task %v hit a system failure on host %v "+
+			"and was rescheduled (retry %v of %v)", task.Id, task.HostId,
+			task.SystemFailureRetries, projectRef.SystemFailureRetries)
+		as.taskFinished(w, task, finishTime)
+		return
+	}
+
 	// mark task as finished
 	err = task.MarkEnd(APIServerLockTitle, finishTime, details, project, projectRef.DeactivatePrevious)
 	if err != nil {
@@ -452,6 +475,15 @@ func (as *APIServer) taskFinished(w http.ResponseWriter, task *model.Task, finis
 		return
 	}
 
+	// pull agents go back to asking for work, which is where their revision
+	// is checked and their next task dispatched
+	if host.Distro.PullAgent {
+		markHostRunningTaskFinished(host, task, "")
+		taskEndResponse.Message = "Task finished; agent will ask for its next task"
+		as.WriteJSON(w, http.StatusOK, taskEndResponse)
+		return
+	}
+
 	// b. check if the agent needs to be rebuilt
 	taskRunnerInstance := taskrunner.NewTaskRunner(&as.Settings)
 	agentRevision, err := taskRunnerInstance.HostGateway.GetAgentRevision()
@@ -523,7 +555,7 @@ func getNextDistroTask(currentTask *model.Task, host *host.Host) (
 }
 
 // AttachTestLog is the API Server hook for getting
-// the test logs and storing them in the test_logs collection.
+// the test logs and storing them in the log store.
 func (as *APIServer) AttachTestLog(w http.ResponseWriter, r *http.Request) {
 	task := MustHaveTask(r)
 	log := &model.TestLog{}
@@ -537,10 +569,13 @@ func (as *APIServer) AttachTestLog(w http.ResponseWriter, r *http.Request) {
 	log.Task = task.Id
 	log.TaskExecution = task.Execution
 
-	if err := log.Insert(); err != nil {
+	if err := model.InsertTestLog(log); err != nil {
 		as.LoggedError(w, r, http.StatusInternalServerError, err)
 		return
 	}
+	if err := logsearch.IndexTestLog(task, log); err != nil {
+		evergreen.Logger.Logf(slogger.ERROR, "Error indexing test log %v for search: %v", log.Id, err)
+	}
 	logReply := struct {
 		Id string `json:"_id"`
 	}{log.Id}
@@ -568,7 +603,7 @@ func (as *APIServer) AttachResults(w http.ResponseWriter, r *http.Request) {
 // associated with a task's project.
 func (as *APIServer) FetchProjectVars(w http.ResponseWriter, r *http.Request) {
 	task := MustHaveTask(r)
-	projectVars, err := model.FindOneProjectVars(task.Project)
+	projectVars, err := model.FindMergedProjectVars(task.Project)
 	if err != nil {
 		as.LoggedError(w, r, http.StatusInternalServerError, err)
 		return
@@ -623,10 +658,13 @@ func (as *APIServer) AppendTaskLog(w http.ResponseWriter, r *http.Request) {
 	taskLog.TaskId = task.Id
 	taskLog.Execution = task.Execution
 
-	if err := taskLog.Insert(); err != nil {
+	if err := model.InsertTaskLog(taskLog); err != nil {
 		as.LoggedError(w, r, http.StatusInternalServerError, err)
 		return
 	}
+	if err := logsearch.IndexTaskLog(task, taskLog); err != nil {
+		evergreen.Logger.Logf(slogger.ERROR, "Error indexing logs of task %v for search: %v", task.Id, err)
+	}
 
 	as.WriteJSON(w, http.StatusOK, "Logs added")
 }
@@ -742,15 +780,19 @@ func getHostFromRequest(r *http.Request) (*host.Host, error) {
 	if len(tag) == 0 {
 		return nil, fmt.Errorf("no host tag supplied")
 	}
-	// find the host
-	host, err := host.FindOne(host.ById(tag))
-	if host == nil {
+	// find the host. hosts that set themselves up from their user data only
+	// know their tag, which is not their id if they're spot instances
+	h, err := host.FindOne(host.ById(tag))
+	if err == nil && h == nil {
+		h, err = host.FindOne(host.ByTag(tag))
+	}
+	if h == nil {
 		return nil, fmt.Errorf("no host with tag: %v", tag)
 	}
 	if err != nil {
 		return nil, err
 	}
-	return host, nil
+	return h, nil
 }
 
 func (as *APIServer) hostReady(w http.ResponseWriter, r *http.Request) {
@@ -761,6 +803,13 @@ func (as *APIServer) hostReady(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// hosts given a secret to report back with have to send it
+	if hostObj.Secret != "" && r.Header.Get(evergreen.HostSecretHeader) != hostObj.Secret {
+		evergreen.Logger.Logf(slogger.ERROR, "Wrong secret sent for host %v", hostObj.Id)
+		http.Error(w, "wrong secret!", http.StatusConflict)
+		return
+	}
+
 	// if the host failed
 	setupSuccess := mux.Vars(r)["status"]
 	if setupSuccess == evergreen.HostStatusFailed {
@@ -813,6 +862,27 @@ func (as *APIServer) hostReady(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// hosts that set themselves up haven't been seen by hostinit, which
+	// otherwise records their DNS name and the revision of their pull agent
+	if hostObj.Host == "" && dns != "" {
+		if err := hostObj.SetDNSName(dns); err != nil {
+			as.LoggedError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+	}
+	if hostObj.Distro.ProvisionWithUserData && hostObj.Distro.PullAgent {
+		taskRunnerInstance := taskrunner.NewTaskRunner(&as.Settings)
+		agentRevision, err := taskRunnerInstance.HostGateway.GetAgentRevision()
+		if err != nil {
+			as.LoggedError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+		if err := hostObj.SetAgentRevision(agentRevision); err != nil {
+			as.LoggedError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
 	// mark host as provisioned
 	if err := hostObj.MarkAsProvisioned(); err != nil {
 		as.LoggedError(w, r, http.StatusInternalServerError, err)
@@ -963,6 +1033,9 @@ func (as *APIServer) Handler() (http.Handler, error) {
 	spawn.HandleFunc("/{instance_id:[\\w_\\-\\@]+}/", requireUser(as.modifyHost)).Methods("POST")
 	spawn.HandleFunc("/ready/{instance_id:[\\w_\\-\\@]+}/{status}", requireUser(as.spawnHostReady)).Methods("POST")
 
+	// Prometheus metrics
+	root.Handle("/metrics", metrics.Handler()).Methods("GET")
+
 	runtimes := apiRootOld.PathPrefix("/runtimes/").Subrouter()
 	runtimes.HandleFunc("/", as.listRuntimes).Methods("GET")
 	runtimes.HandleFunc("/timeout/{seconds:\\d*}", as.lateRuntimes).Methods("GET")
@@ -970,11 +1043,15 @@ func (as *APIServer) Handler() (http.Handler, error) {
 	// Hosts callback
 	host := r.PathPrefix("/host/{tag:[\\w_\\-\\@]+}/").Subrouter()
 	host.HandleFunc("/ready/{status}", as.hostReady).Methods("POST")
+	host.HandleFunc("/next_task", as.checkHost(as.NextTask)).Methods("GET")
+	host.HandleFunc("/agent", as.checkHost(as.AgentExecutable)).Methods("GET")
 
 	// Spawnhost routes - creating new hosts, listing existing hosts, listing distros
 	spawns := apiRootOld.PathPrefix("/spawns/").Subrouter()
 	spawns.HandleFunc("/", requireUser(as.requestHost)).Methods("PUT")
 	spawns.HandleFunc("/{user}/", requireUser(as.hostsInfoForUser)).Methods("GET")
+	spawns.HandleFunc("/{user}/volumes/", requireUser(as.volumesForUser)).Methods("GET")
+	spawns.HandleFunc("/volumes/{volume_id}/", requireUser(as.deleteVolume)).Methods("DELETE")
 	spawns.HandleFunc("/distros/list/", requireUser(as.listDistros)).Methods("GET")
 
 	taskRouter := r.PathPrefix("/task/{taskId:[\\w_\\.]+}").Subrouter()
@@ -1012,6 +1089,7 @@ func (as *APIServer) Handler() (http.Handler, error) {
 
 	n := negroni.New()
 	n.Use(negroni.NewLogger())
+	n.Use(metrics.NewRequestTimer("api"))
 	n.Use(negroni.HandlerFunc(UserMiddleware(as.UserManager)))
 	n.UseHandler(root)
 	return n, nil
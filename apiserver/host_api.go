@@ -0,0 +1,181 @@
+package apiserver
+
+import (
+	"fmt"
+	"github.com/10gen-labs/slogger/v1"
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/apimodels"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/host"
+	"github.com/evergreen-ci/evergreen/taskrunner"
+	"github.com/gorilla/context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// checkHost loads the host in the request's path and checks the secret its
+// agent sent before passing the request on.
+func (as *APIServer) checkHost(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		h, err := getHostFromRequest(r)
+		if err != nil {
+			as.LoggedError(w, r, http.StatusNotFound, err)
+			return
+		}
+
+		secret := r.Header.Get(evergreen.HostSecretHeader)
+		if h.Secret == "" || secret != h.Secret {
+			evergreen.Logger.Logf(slogger.ERROR, "Wrong secret sent for host %v", h.Id)
+			http.Error(w, "wrong secret!", http.StatusConflict)
+			return
+		}
+
+		context.Set(r, apiHostKey, h)
+		next(w, r)
+	}
+}
+
+// MustHaveHost gets the host from an HTTP Request.
+// Panics if the host is not in request context.
+func MustHaveHost(r *http.Request) *host.Host {
+	if rv := context.Get(r, apiHostKey); rv != nil {
+		return rv.(*host.Host)
+	}
+	panic("no host attached to request")
+}
+
+// NextTask is polled by pull agents for the next task their host should run.
+// The agent is told to exit if the host is being taken out of service or is
+// running a stale agent; otherwise the next task in the distro's queue is
+// dispatched to the host, if there is one.
+func (as *APIServer) NextTask(w http.ResponseWriter, r *http.Request) {
+	h := MustHaveHost(r)
+	response := &apimodels.NextTaskResponse{}
+
+	switch h.Status {
+	case evergreen.HostDecommissioned, evergreen.HostQuarantined, evergreen.HostTerminated:
+		response.ShouldExit = true
+		response.Message = fmt.Sprintf("Host %v is in state '%v'. Agent will terminate",
+			h.Id, h.Status)
+		as.WriteJSON(w, http.StatusOK, response)
+		return
+	case evergreen.HostRunning:
+	default:
+		response.Message = fmt.Sprintf("Host %v is in state '%v' and can't take tasks yet",
+			h.Id, h.Status)
+		as.WriteJSON(w, http.StatusOK, response)
+		return
+	}
+
+	// pull hosts aren't redeployed with a new agent; they're replaced
+	taskRunnerInstance := taskrunner.NewTaskRunner(&as.Settings)
+	agentRevision, err := taskRunnerInstance.HostGateway.GetAgentRevision()
+	if err != nil {
+		as.LoggedError(w, r, http.StatusInternalServerError,
+			fmt.Errorf("failed to get agent revision: %v", err))
+		return
+	}
+	if h.AgentRevision != agentRevision {
+		if err = h.SetDecommissioned(); err != nil {
+			as.LoggedError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+		response.ShouldExit = true
+		response.Message = fmt.Sprintf("Agent on host %v is out of date; host is decommissioned", h.Id)
+		evergreen.Logger.Logf(slogger.INFO, response.Message)
+		as.WriteJSON(w, http.StatusOK, response)
+		return
+	}
+
+	if !getGlobalLock(r.RemoteAddr, h.Id) {
+		as.LoggedError(w, r, http.StatusInternalServerError, ErrLockTimeout)
+		return
+	}
+	defer releaseGlobalLock(r.RemoteAddr, h.Id)
+
+	// reload the host now that no other request can dispatch to it
+	h, err = host.FindOne(host.ById(h.Id))
+	if err != nil {
+		as.LoggedError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	if h == nil {
+		as.LoggedError(w, r, http.StatusNotFound, fmt.Errorf("host not found"))
+		return
+	}
+
+	// if the agent lost the response that dispatched its current task, hand
+	// the same task back instead of dispatching another one
+	if h.RunningTask != "" {
+		t, err := model.FindTask(h.RunningTask)
+		if err != nil {
+			as.LoggedError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+		if t != nil && t.HostId == h.Id && t.Status == evergreen.TaskDispatched {
+			response.TaskId = t.Id
+			response.TaskSecret = t.Secret
+			response.Message = "Resume dispatched task"
+			as.WriteJSON(w, http.StatusOK, response)
+			return
+		}
+		response.Message = fmt.Sprintf("Host %v is already running task %v", h.Id, h.RunningTask)
+		as.WriteJSON(w, http.StatusOK, response)
+		return
+	}
+
+	taskQueue, err := model.FindTaskQueueForDistro(h.Distro.Id)
+	if err != nil {
+		as.LoggedError(w, r, http.StatusInternalServerError,
+			fmt.Errorf("error locating task queue for distro %v: %v", h.Distro.Id, err))
+		return
+	}
+	if taskQueue == nil {
+		response.Message = "No task queue for distro"
+		as.WriteJSON(w, http.StatusOK, response)
+		return
+	}
+
+	nextTask, err := taskrunner.DispatchTaskForHost(taskQueue, h)
+	if err != nil {
+		as.LoggedError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	if nextTask == nil {
+		response.Message = "No task on queue"
+		as.WriteJSON(w, http.StatusOK, response)
+		return
+	}
+
+	if err = h.SetRunningTask(nextTask.Id, h.AgentRevision, time.Now()); err != nil {
+		as.LoggedError(w, r, http.StatusInternalServerError,
+			fmt.Errorf("error updating running task %v on host %v: %v", nextTask.Id, h.Id, err))
+		return
+	}
+	evergreen.Logger.Logf(slogger.INFO, "Dispatched task %v to pull agent on host %v",
+		nextTask.Id, h.Id)
+
+	response.TaskId = nextTask.Id
+	response.TaskSecret = nextTask.Secret
+	response.Message = "Proceed with next task"
+	as.WriteJSON(w, http.StatusOK, response)
+}
+
+// AgentExecutable serves the agent built for the host's architecture, which
+// hosts provisioned with user data download to start their pull agent.
+func (as *APIServer) AgentExecutable(w http.ResponseWriter, r *http.Request) {
+	h := MustHaveHost(r)
+
+	gateway := &taskrunner.AgentBasedHostGateway{
+		ExecutablesDir: filepath.Join(evergreen.FindEvergreenHome(), as.Settings.AgentExecutablesDir),
+	}
+	agentPath := gateway.AgentExecutablePath(h.Distro.Arch)
+	if _, err := os.Stat(agentPath); err != nil {
+		as.LoggedError(w, r, http.StatusNotFound,
+			fmt.Errorf("no agent for architecture %v: %v", h.Distro.Arch, err))
+		return
+	}
+	http.ServeFile(w, r, agentPath)
+}
@@ -7,6 +7,10 @@ import (
 	"github.com/evergreen-ci/evergreen"
 	"github.com/evergreen-ci/evergreen/apiserver"
 	"github.com/evergreen-ci/evergreen/db"
+	"github.com/evergreen-ci/evergreen/githubstatus"
+	"github.com/evergreen-ci/evergreen/logstore"
+	"github.com/evergreen-ci/evergreen/metrics"
+	"github.com/evergreen-ci/evergreen/model"
 	"github.com/evergreen-ci/evergreen/plugin"
 	"github.com/evergreen-ci/evergreen/util"
 	"gopkg.in/tylerb/graceful.v1"
@@ -38,6 +42,15 @@ func main() {
 	}
 
 	db.SetGlobalSessionProvider(db.SessionFactoryFromConfig(settings))
+	githubstatus.Register(settings)
+
+	logStore, err := logstore.New(settings)
+	if err != nil {
+		evergreen.Logger.Logf(slogger.ERROR, "Failed to create log store: %v", err)
+		os.Exit(1)
+	}
+	model.SetLogStore(logStore)
+	metrics.RegisterServerMetrics()
 
 	tlsConfig, err := util.MakeTlsConfig(settings.Expansions["api_httpscert"], settings.Api.HttpsKey)
 	if err != nil {
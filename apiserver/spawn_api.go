@@ -6,6 +6,7 @@ import (
 	"github.com/evergreen-ci/evergreen"
 	"github.com/evergreen-ci/evergreen/alerts"
 	"github.com/evergreen-ci/evergreen/cloud/providers"
+	"github.com/evergreen-ci/evergreen/model"
 	"github.com/evergreen-ci/evergreen/model/distro"
 	"github.com/evergreen-ci/evergreen/model/event"
 	"github.com/evergreen-ci/evergreen/model/host"
@@ -15,6 +16,7 @@ import (
 	"github.com/gorilla/mux"
 	"io/ioutil"
 	"net/http"
+	"strconv"
 )
 
 type spawnRequest struct {
@@ -26,9 +28,10 @@ type spawnRequest struct {
 }
 
 type spawnResponse struct {
-	Hosts    []host.Host `json:"hosts,omitempty"`
-	HostInfo host.Host   `json:"host_info,omitempty"`
-	Distros  []string    `json:"distros,omitempty"`
+	Hosts    []host.Host   `json:"hosts,omitempty"`
+	HostInfo host.Host     `json:"host_info,omitempty"`
+	Distros  []string      `json:"distros,omitempty"`
+	Volumes  []host.Volume `json:"volumes,omitempty"`
 
 	// empty if the request succeeded
 	ErrorMessage string `json:"error_message,omitempty"`
@@ -50,9 +53,11 @@ func (as *APIServer) listDistros(w http.ResponseWriter, r *http.Request) {
 func (as *APIServer) requestHost(w http.ResponseWriter, r *http.Request) {
 	user := MustHaveUser(r)
 	hostRequest := struct {
-		Distro    string `json:"distro"`
-		PublicKey string `json:"public_key"`
-		UserData  string `json:"userdata"`
+		Distro     string `json:"distro"`
+		PublicKey  string `json:"public_key"`
+		UserData   string `json:"userdata"`
+		HomeVolume bool   `json:"home_volume"`
+		TaskId     string `json:"task_id"`
 	}{}
 	err := util.ReadJSONInto(r.Body, &hostRequest)
 	if err != nil {
@@ -60,6 +65,20 @@ func (as *APIServer) requestHost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// hosts for reproducing a task default to the task's distro
+	if hostRequest.Distro == "" && hostRequest.TaskId != "" {
+		t, err := model.FindTask(hostRequest.TaskId)
+		if err != nil {
+			as.LoggedError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+		if t == nil {
+			http.Error(w, fmt.Sprintf("task %v not found", hostRequest.TaskId), http.StatusNotFound)
+			return
+		}
+		hostRequest.Distro = t.DistroId
+	}
+
 	if hostRequest.Distro == "" {
 		http.Error(w, "distro may not be blank", http.StatusBadRequest)
 		return
@@ -70,10 +89,12 @@ func (as *APIServer) requestHost(w http.ResponseWriter, r *http.Request) {
 	}
 
 	opts := spawn.Options{
-		Distro:    hostRequest.Distro,
-		UserName:  user.Id,
-		PublicKey: hostRequest.PublicKey,
-		UserData:  hostRequest.UserData,
+		Distro:     hostRequest.Distro,
+		UserName:   user.Id,
+		PublicKey:  hostRequest.PublicKey,
+		UserData:   hostRequest.UserData,
+		HomeVolume: hostRequest.HomeVolume,
+		TaskId:     hostRequest.TaskId,
 	}
 
 	spawner := spawn.New(&as.Settings)
@@ -224,7 +245,7 @@ func (as *APIServer) modifyHost(w http.ResponseWriter, r *http.Request) {
 
 	user := GetUser(r)
 	if user == nil || user.Id != host.StartedBy {
-		message := fmt.Sprintf("Only %v is authorized to terminate this host", host.StartedBy)
+		message := fmt.Sprintf("Only %v is authorized to modify this host", host.StartedBy)
 		http.Error(w, message, http.StatusUnauthorized)
 		return
 	}
@@ -247,8 +268,90 @@ func (as *APIServer) modifyHost(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		as.WriteJSON(w, http.StatusOK, spawnResponse{HostInfo: *host})
+	case "stop":
+		if err = spawn.New(&as.Settings).StopHost(host); err != nil {
+			as.spawnError(w, r, err)
+			return
+		}
+		as.WriteJSON(w, http.StatusOK, spawnResponse{HostInfo: *host})
+	case "start":
+		if host.Status != evergreen.HostStopped {
+			http.Error(w, fmt.Sprintf("Host %v is not stopped", host.Id), http.StatusBadRequest)
+			return
+		}
+		// starting can take a few minutes, so it finishes in the background
+		go func() {
+			if err := spawn.New(&as.Settings).StartHost(host); err != nil {
+				evergreen.Logger.Logf(slogger.ERROR, "Failed to start spawn host %v: %v", host.Id, err)
+			}
+		}()
+		as.WriteJSON(w, http.StatusOK, spawnResponse{HostInfo: *host})
+	case "extend":
+		hours, err := strconv.Atoi(r.FormValue("hours"))
+		if err != nil {
+			http.Error(w, "bad hours param", http.StatusBadRequest)
+			return
+		}
+		if err = spawn.New(&as.Settings).ExtendHost(host, hours); err != nil {
+			as.spawnError(w, r, err)
+			return
+		}
+		as.WriteJSON(w, http.StatusOK, spawnResponse{HostInfo: *host})
 	default:
 		http.Error(w, fmt.Sprintf("Unrecognized action %v", hostAction), http.StatusBadRequest)
 	}
 
 }
+
+// returns the persistent volumes owned by a user
+func (as *APIServer) volumesForUser(w http.ResponseWriter, r *http.Request) {
+	user := GetUser(r)
+	if user == nil || user.Id != mux.Vars(r)["user"] {
+		http.Error(w, "Users may only list their own volumes", http.StatusUnauthorized)
+		return
+	}
+
+	volumes, err := host.FindVolumes(host.VolumesByUser(user.Id))
+	if err != nil {
+		as.LoggedError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	as.WriteJSON(w, http.StatusOK, spawnResponse{Volumes: volumes})
+}
+
+func (as *APIServer) deleteVolume(w http.ResponseWriter, r *http.Request) {
+	volumeId := mux.Vars(r)["volume_id"]
+	volume, err := host.FindOneVolume(host.VolumeById(volumeId))
+	if err != nil {
+		as.LoggedError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	if volume == nil {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+
+	user := GetUser(r)
+	if user == nil || user.Id != volume.CreatedBy {
+		message := fmt.Sprintf("Only %v is authorized to delete this volume", volume.CreatedBy)
+		http.Error(w, message, http.StatusUnauthorized)
+		return
+	}
+
+	if err = spawn.New(&as.Settings).DeleteVolume(volume); err != nil {
+		as.spawnError(w, r, err)
+		return
+	}
+	as.WriteJSON(w, http.StatusOK, spawnResponse{})
+}
+
+// spawnError writes an error from the spawn package, which is the client's
+// fault if it is about the request's options.
+func (as *APIServer) spawnError(w http.ResponseWriter, r *http.Request, err error) {
+	errCode := http.StatusInternalServerError
+	if _, ok := err.(spawn.BadOptionsErr); ok || err == spawn.ExpirationErr {
+		errCode = http.StatusBadRequest
+	}
+	as.LoggedError(w, r, errCode, err)
+}
@@ -0,0 +1,178 @@
+// Package artifactcleanup deletes the files attached to tasks once they
+// outlive the retention rules of the task's project.
+package artifactcleanup
+
+import (
+	"fmt"
+	"github.com/10gen-labs/slogger/v1"
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/artifact"
+	"github.com/evergreen-ci/evergreen/thirdparty"
+	"github.com/goamz/goamz/aws"
+	"github.com/goamz/goamz/s3"
+	"time"
+)
+
+// FileDeleter removes an uploaded file from the store that holds it.
+type FileDeleter interface {
+	Delete(bucket, key string) error
+}
+
+// S3Deleter deletes files from s3 buckets.
+type S3Deleter struct {
+	session *s3.S3
+}
+
+// NewS3Deleter returns an S3Deleter that talks to the s3 endpoint of the given region.
+func NewS3Deleter(auth *aws.Auth, region aws.Region) *S3Deleter {
+	return &S3Deleter{session: thirdparty.NewS3Session(auth, region)}
+}
+
+func (d *S3Deleter) Delete(bucket, key string) error {
+	return d.session.Bucket(bucket).Del(key)
+}
+
+// Cleaner finds attached files whose retention period has passed, deletes
+// them and marks them as expired so the UI stops linking to them.
+type Cleaner struct {
+	Deleter FileDeleter
+	// DryRun only logs the files that would be deleted
+	DryRun bool
+
+	projectsCache map[string]*model.ProjectRef
+}
+
+// NewCleaner returns a Cleaner that deletes files from s3 using the
+// credentials and endpoint in the settings.
+func NewCleaner(settings *evergreen.Settings) *Cleaner {
+	conf := settings.ArtifactCleanup
+	auth := &aws.Auth{
+		AccessKey: conf.AWS.Id,
+		SecretKey: conf.AWS.Secret,
+	}
+	if auth.AccessKey == "" {
+		auth.AccessKey = settings.Providers.AWS.Id
+		auth.SecretKey = settings.Providers.AWS.Secret
+	}
+	region := aws.USEast
+	if conf.S3Endpoint != "" {
+		region = aws.Region{
+			Name:                 "artifactcleanup",
+			S3Endpoint:           conf.S3Endpoint,
+			S3LocationConstraint: true,
+		}
+	}
+	return &Cleaner{
+		Deleter: NewS3Deleter(auth, region),
+		DryRun:  conf.DryRun,
+	}
+}
+
+// Run expires every file whose task finished longer ago than the
+// matching retention rule of its project allows.
+func (c *Cleaner) Run(now time.Time) error {
+	entries, err := artifact.FindAll(artifact.ByUnexpiredFiles())
+	if err != nil {
+		return fmt.Errorf("error finding artifact files: %v", err)
+	}
+	for _, entry := range entries {
+		if err := c.cleanEntry(entry, now); err != nil {
+			evergreen.Logger.Logf(slogger.ERROR, "error cleaning up files for task %v: %v",
+				entry.TaskId, err)
+		}
+	}
+	return nil
+}
+
+// cleanEntry expires the files in a single task's entry.
+func (c *Cleaner) cleanEntry(entry artifact.Entry, now time.Time) error {
+	task, err := model.FindTask(entry.TaskId)
+	if err != nil {
+		return fmt.Errorf("error finding task: %v", err)
+	}
+	if task == nil || !task.IsFinished() || !task.FinishTime.After(model.ZeroTime) {
+		return nil
+	}
+	projectRef, err := c.findProject(task.Project)
+	if err != nil {
+		return fmt.Errorf("error finding project %v: %v", task.Project, err)
+	}
+	if projectRef == nil {
+		return nil
+	}
+
+	for _, file := range entry.Files {
+		if file.Expired {
+			continue
+		}
+		maxAge, ok := retentionPeriod(projectRef.ArtifactRetention, task.Requester, file.Visibility)
+		if !ok || now.Sub(task.FinishTime) < maxAge {
+			continue
+		}
+
+		if c.DryRun {
+			evergreen.Logger.Logf(slogger.INFO, "[dry run] would expire file '%v' (%v) of task %v",
+				file.Name, file.Link, entry.TaskId)
+			continue
+		}
+
+		// files attached by link alone have no s3 location we can delete
+		if file.Bucket != "" && file.FileKey != "" {
+			if err := c.Deleter.Delete(file.Bucket, file.FileKey); err != nil {
+				evergreen.Logger.Logf(slogger.ERROR, "error deleting %v/%v for task %v: %v",
+					file.Bucket, file.FileKey, entry.TaskId, err)
+				continue
+			}
+		}
+		if err := artifact.MarkFileExpired(entry.TaskId, file.Link); err != nil {
+			return fmt.Errorf("error marking file '%v' expired: %v", file.Name, err)
+		}
+		evergreen.Logger.Logf(slogger.INFO, "Expired file '%v' (%v) of task %v",
+			file.Name, file.Link, entry.TaskId)
+	}
+	return nil
+}
+
+// retentionPeriod returns how long a file with the given visibility is kept
+// after a task with the given requester finishes. If several rules match, the
+// shortest period wins. The boolean is false if no rule matches, in which
+// case the file is kept forever.
+func retentionPeriod(rules []model.ArtifactRetentionRule, requester, visibility string) (time.Duration, bool) {
+	// files without a visibility setting are public
+	if visibility == "" {
+		visibility = artifact.Public
+	}
+	found := false
+	var shortest time.Duration
+	for _, rule := range rules {
+		if rule.Requester != "" && rule.Requester != requester {
+			continue
+		}
+		if rule.Visibility != "" && rule.Visibility != visibility {
+			continue
+		}
+		period := time.Duration(rule.MaxAgeDays) * 24 * time.Hour
+		if !found || period < shortest {
+			shortest = period
+			found = true
+		}
+	}
+	return shortest, found
+}
+
+// findProject is a wrapper around FindOneProjectRef that caches results by id.
+func (c *Cleaner) findProject(projectId string) (*model.ProjectRef, error) {
+	if c.projectsCache == nil {
+		c.projectsCache = map[string]*model.ProjectRef{}
+	}
+	if projectRef, ok := c.projectsCache[projectId]; ok {
+		return projectRef, nil
+	}
+	projectRef, err := model.FindOneProjectRef(projectId)
+	if err != nil {
+		return nil, err
+	}
+	c.projectsCache[projectId] = projectRef
+	return projectRef, nil
+}
@@ -0,0 +1,143 @@
+package artifactcleanup
+
+import (
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/artifact"
+	"github.com/evergreen-ci/evergreen/testutil"
+	"github.com/goamz/goamz/aws"
+	"github.com/goamz/goamz/s3"
+	"github.com/goamz/goamz/s3/s3test"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+	"time"
+)
+
+func init() {
+	db.SetGlobalSessionProvider(db.SessionFactoryFromConfig(evergreen.TestConfig()))
+}
+
+func TestRetentionPeriod(t *testing.T) {
+	Convey("With a set of artifact retention rules", t, func() {
+		day := 24 * time.Hour
+		rules := []model.ArtifactRetentionRule{
+			{Requester: evergreen.PatchVersionRequester, MaxAgeDays: 7},
+			{Requester: evergreen.PatchVersionRequester, Visibility: artifact.Private, MaxAgeDays: 2},
+			{Visibility: artifact.None, MaxAgeDays: 1},
+		}
+		Convey("the shortest matching rule should be used", func() {
+			period, ok := retentionPeriod(rules, evergreen.PatchVersionRequester, artifact.Public)
+			So(ok, ShouldBeTrue)
+			So(period, ShouldEqual, 7*day)
+			period, ok = retentionPeriod(rules, evergreen.PatchVersionRequester, artifact.Private)
+			So(ok, ShouldBeTrue)
+			So(period, ShouldEqual, 2*day)
+			period, ok = retentionPeriod(rules, evergreen.PatchVersionRequester, artifact.None)
+			So(ok, ShouldBeTrue)
+			So(period, ShouldEqual, day)
+		})
+		Convey("files without a visibility should be treated as public", func() {
+			period, ok := retentionPeriod(rules, evergreen.PatchVersionRequester, "")
+			So(ok, ShouldBeTrue)
+			So(period, ShouldEqual, 7*day)
+		})
+		Convey("files that match no rule should never expire", func() {
+			_, ok := retentionPeriod(rules, evergreen.RepotrackerVersionRequester, artifact.Public)
+			So(ok, ShouldBeFalse)
+		})
+	})
+}
+
+func TestCleanerExpiresFiles(t *testing.T) {
+	srv, err := s3test.NewServer(&s3test.Config{})
+	testutil.HandleTestingErr(err, t, "error starting s3 test server")
+	defer srv.Quit()
+
+	Convey("With a local s3 server and a project with retention rules", t, func() {
+		testutil.HandleTestingErr(db.ClearCollections(artifact.Collection,
+			model.TasksCollection, model.ProjectRefCollection), t, "error clearing collections")
+
+		region := aws.Region{
+			Name:                 "faux-region-1",
+			S3Endpoint:           srv.URL(),
+			S3LocationConstraint: true,
+		}
+		bucket := s3.New(aws.Auth{}, region).Bucket("artifacts")
+		So(bucket.PutBucket(s3.Private), ShouldBeNil)
+		So(bucket.Put("patch/old.tgz", []byte("old"), "application/x-gzip", s3.Private, s3.Options{}), ShouldBeNil)
+		So(bucket.Put("patch/new.tgz", []byte("new"), "application/x-gzip", s3.Private, s3.Options{}), ShouldBeNil)
+
+		projectRef := &model.ProjectRef{
+			Identifier: "proj",
+			ArtifactRetention: []model.ArtifactRetentionRule{
+				{Requester: evergreen.PatchVersionRequester, MaxAgeDays: 7},
+			},
+		}
+		So(projectRef.Insert(), ShouldBeNil)
+
+		now := time.Now()
+		oldTask := &model.Task{
+			Id:         "old",
+			Project:    "proj",
+			Requester:  evergreen.PatchVersionRequester,
+			Status:     evergreen.TaskSucceeded,
+			FinishTime: now.Add(-8 * 24 * time.Hour),
+		}
+		newTask := &model.Task{
+			Id:         "new",
+			Project:    "proj",
+			Requester:  evergreen.PatchVersionRequester,
+			Status:     evergreen.TaskSucceeded,
+			FinishTime: now.Add(-time.Hour),
+		}
+		So(oldTask.Insert(), ShouldBeNil)
+		So(newTask.Insert(), ShouldBeNil)
+
+		oldEntry := artifact.Entry{
+			TaskId: "old",
+			Files: []artifact.File{
+				{Name: "old", Link: "https://s3.amazonaws.com/artifacts/patch/old.tgz",
+					Bucket: "artifacts", FileKey: "patch/old.tgz"},
+			},
+		}
+		newEntry := artifact.Entry{
+			TaskId: "new",
+			Files: []artifact.File{
+				{Name: "new", Link: "https://s3.amazonaws.com/artifacts/patch/new.tgz",
+					Bucket: "artifacts", FileKey: "patch/new.tgz"},
+			},
+		}
+		So(oldEntry.Upsert(), ShouldBeNil)
+		So(newEntry.Upsert(), ShouldBeNil)
+
+		cleaner := &Cleaner{Deleter: &S3Deleter{session: s3.New(aws.Auth{}, region)}}
+
+		Convey("a dry run should leave every file in place", func() {
+			cleaner.DryRun = true
+			So(cleaner.Run(now), ShouldBeNil)
+
+			_, err := bucket.Get("patch/old.tgz")
+			So(err, ShouldBeNil)
+			entry, err := artifact.FindOne(artifact.ByTaskId("old"))
+			So(err, ShouldBeNil)
+			So(entry.Files[0].Expired, ShouldBeFalse)
+		})
+
+		Convey("only files older than the retention period should be deleted and expired", func() {
+			So(cleaner.Run(now), ShouldBeNil)
+
+			_, err := bucket.Get("patch/old.tgz")
+			So(err, ShouldNotBeNil)
+			entry, err := artifact.FindOne(artifact.ByTaskId("old"))
+			So(err, ShouldBeNil)
+			So(entry.Files[0].Expired, ShouldBeTrue)
+
+			_, err = bucket.Get("patch/new.tgz")
+			So(err, ShouldBeNil)
+			entry, err = artifact.FindOne(artifact.ByTaskId("new"))
+			So(err, ShouldBeNil)
+			So(entry.Files[0].Expired, ShouldBeFalse)
+		})
+	})
+}
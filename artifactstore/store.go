@@ -0,0 +1,112 @@
+// Package artifactstore provides content-addressed storage for task artifacts.
+// Files are stored under the hex-encoded SHA-256 digest of their contents, so
+// uploading the same file twice only stores it once.
+package artifactstore
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/evergreen-ci/evergreen/blobstore"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"regexp"
+)
+
+const (
+	LocalBackend = blobstore.LocalBackend
+	S3Backend    = blobstore.S3Backend
+)
+
+var digestRegex = regexp.MustCompile("^[0-9a-f]{64}$")
+
+// Store saves and retrieves artifacts by the digest of their contents.
+type Store interface {
+	// Put saves the contents of the reader and returns their digest.
+	Put(io.Reader) (string, error)
+	// Get returns the contents stored under the given digest.
+	// The caller must close the returned reader.
+	Get(digest string) (io.ReadCloser, error)
+}
+
+// Config selects and configures the blob store backend artifacts are kept in.
+type Config blobstore.Config
+
+// New returns the store described by the config.
+func New(conf Config) (Store, error) {
+	bucket, err := blobstore.New(blobstore.Config(conf))
+	if err != nil {
+		return nil, fmt.Errorf("error configuring artifact store: %v", err)
+	}
+	store := &BucketStore{Bucket: bucket}
+	if conf.Backend == LocalBackend {
+		// spread files over subdirectories so no one directory gets too big
+		store.Sharded = true
+	}
+	return store, nil
+}
+
+// BucketStore keeps artifacts in a blob store bucket, each under its digest,
+// or under <first two digest characters>/<digest> if the store is sharded.
+type BucketStore struct {
+	Bucket  blobstore.Bucket
+	Sharded bool
+}
+
+func (bs *BucketStore) key(digest string) string {
+	if bs.Sharded {
+		return path.Join(digest[:2], digest)
+	}
+	return digest
+}
+
+// Put spools the contents to a temporary file to compute their digest
+// and size before saving them.
+func (bs *BucketStore) Put(r io.Reader) (string, error) {
+	tmp, digest, size, err := spool(r, "")
+	if err != nil {
+		return "", fmt.Errorf("error buffering artifact: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	if err = bs.Bucket.PutReader(bs.key(digest), tmp, size); err != nil {
+		return "", fmt.Errorf("error storing artifact %v: %v", digest, err)
+	}
+	return digest, nil
+}
+
+func (bs *BucketStore) Get(digest string) (io.ReadCloser, error) {
+	if !ValidDigest(digest) {
+		return nil, fmt.Errorf("invalid digest '%v'", digest)
+	}
+	return bs.Bucket.GetReader(bs.key(digest))
+}
+
+// ValidDigest returns true if the string is a well-formed content digest.
+func ValidDigest(digest string) bool {
+	return digestRegex.MatchString(digest)
+}
+
+// spool copies the reader to a temporary file, returning the open file
+// positioned at its start along with the digest and size of its contents.
+// The caller is responsible for closing and removing the file.
+func spool(r io.Reader, dir string) (*os.File, string, int64, error) {
+	tmp, err := ioutil.TempFile(dir, "upload")
+	if err != nil {
+		return nil, "", 0, err
+	}
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hash), r)
+	if err == nil {
+		_, err = tmp.Seek(0, 0)
+	}
+	if err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return nil, "", 0, err
+	}
+	return tmp, hex.EncodeToString(hash.Sum(nil)), size, nil
+}
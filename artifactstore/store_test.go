@@ -0,0 +1,91 @@
+package artifactstore
+
+import (
+	"bytes"
+	"github.com/evergreen-ci/evergreen/testutil"
+	"github.com/goamz/goamz/aws"
+	"github.com/goamz/goamz/s3"
+	"github.com/goamz/goamz/s3/s3test"
+	. "github.com/smartystreets/goconvey/convey"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+// the sha256 digest of "hello world"
+const helloDigest = "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9"
+
+func testStore(store Store) {
+	Convey("putting a file should return the digest of its contents", func() {
+		digest, err := store.Put(bytes.NewBufferString("hello world"))
+		So(err, ShouldBeNil)
+		So(digest, ShouldEqual, helloDigest)
+
+		Convey("and getting it back should return the same contents", func() {
+			r, err := store.Get(digest)
+			So(err, ShouldBeNil)
+			defer r.Close()
+			data, err := ioutil.ReadAll(r)
+			So(err, ShouldBeNil)
+			So(string(data), ShouldEqual, "hello world")
+		})
+
+		Convey("and putting it again should be a no-op", func() {
+			digest, err := store.Put(bytes.NewBufferString("hello world"))
+			So(err, ShouldBeNil)
+			So(digest, ShouldEqual, helloDigest)
+		})
+	})
+
+	Convey("getting a malformed digest should fail", func() {
+		_, err := store.Get("../../etc/passwd")
+		So(err, ShouldNotBeNil)
+	})
+}
+
+func TestLocalStore(t *testing.T) {
+	root, err := ioutil.TempDir("", "artifactstore")
+	testutil.HandleTestingErr(err, t, "error creating temp dir")
+	defer os.RemoveAll(root)
+
+	Convey("With a local artifact store", t, func() {
+		store, err := New(Config{Backend: LocalBackend, LocalPath: root})
+		So(err, ShouldBeNil)
+
+		testStore(store)
+	})
+}
+
+func TestS3Store(t *testing.T) {
+	srv, err := s3test.NewServer(&s3test.Config{})
+	testutil.HandleTestingErr(err, t, "error starting s3 test server")
+	defer srv.Quit()
+
+	Convey("With an s3 artifact store backed by a local s3 server", t, func() {
+		region := aws.Region{
+			Name:                 "faux-region-1",
+			S3Endpoint:           srv.URL(),
+			S3LocationConstraint: true,
+		}
+		So(s3.New(aws.Auth{}, region).Bucket("artifacts").PutBucket(s3.Private), ShouldBeNil)
+		store, err := New(Config{Backend: S3Backend, S3Bucket: "artifacts", S3Prefix: "evg", S3Endpoint: srv.URL()})
+		So(err, ShouldBeNil)
+
+		testStore(store)
+	})
+}
+
+func TestNewValidatesConfig(t *testing.T) {
+	Convey("When creating an artifact store", t, func() {
+		Convey("an unknown backend should be rejected", func() {
+			_, err := New(Config{Backend: "ftp"})
+			So(err, ShouldNotBeNil)
+		})
+		Convey("a backend with missing settings should be rejected", func() {
+			_, err := New(Config{Backend: LocalBackend})
+			So(err, ShouldNotBeNil)
+			_, err = New(Config{Backend: S3Backend})
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
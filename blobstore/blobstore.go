@@ -0,0 +1,65 @@
+// Package blobstore keeps blobs, such as compressed logs and task artifacts,
+// by key in a directory on local disk or in an s3 bucket.
+package blobstore
+
+import (
+	"fmt"
+	"io"
+)
+
+const (
+	LocalBackend = "local"
+	S3Backend    = "s3"
+)
+
+// Bucket saves and retrieves blobs by key. Keys are slash-separated paths.
+type Bucket interface {
+	// Put saves the data under the key.
+	Put(key string, data []byte) error
+	// Get returns the data saved under the key.
+	Get(key string) ([]byte, error)
+	// PutReader saves the size bytes read from the reader under the key.
+	PutReader(key string, r io.Reader, size int64) error
+	// GetReader returns a reader of the data saved under the key. The caller
+	// must close it.
+	GetReader(key string) (io.ReadCloser, error)
+	// List returns the keys of the blobs directly under the directory,
+	// sorted by name.
+	List(dir string) ([]string, error)
+}
+
+// Config selects and configures a bucket backend.
+type Config struct {
+	// Backend is either "local" or "s3"
+	Backend string `mapstructure:"backend"`
+
+	// LocalPath is the directory the local backend stores blobs in. It may
+	// be an NFS mount shared by several servers.
+	LocalPath string `mapstructure:"local_path"`
+
+	S3Bucket  string `mapstructure:"s3_bucket"`
+	S3Prefix  string `mapstructure:"s3_prefix"`
+	AWSKey    string `mapstructure:"aws_key"`
+	AWSSecret string `mapstructure:"aws_secret"`
+	// S3Endpoint overrides the default s3 endpoint, e.g. to point at a
+	// local s3 stand-in
+	S3Endpoint string `mapstructure:"s3_endpoint"`
+}
+
+// New returns the bucket described by the config.
+func New(conf Config) (Bucket, error) {
+	switch conf.Backend {
+	case LocalBackend:
+		if conf.LocalPath == "" {
+			return nil, fmt.Errorf("local_path cannot be blank for the local backend")
+		}
+		return NewLocalBucket(conf.LocalPath)
+	case S3Backend:
+		if conf.S3Bucket == "" {
+			return nil, fmt.Errorf("s3_bucket cannot be blank for the s3 backend")
+		}
+		return NewS3Bucket(conf), nil
+	default:
+		return nil, fmt.Errorf("unknown blob store backend '%v'", conf.Backend)
+	}
+}
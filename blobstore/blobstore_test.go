@@ -0,0 +1,62 @@
+package blobstore
+
+import (
+	"bytes"
+	"github.com/evergreen-ci/evergreen/testutil"
+	. "github.com/smartystreets/goconvey/convey"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestLocalBucket(t *testing.T) {
+	root, err := ioutil.TempDir("", "blobstore")
+	testutil.HandleTestingErr(err, t, "error creating temp dir")
+	defer os.RemoveAll(root)
+
+	Convey("With a local bucket", t, func() {
+		bucket, err := New(Config{Backend: LocalBackend, LocalPath: root})
+		So(err, ShouldBeNil)
+
+		Convey("blobs put from data or readers should be listed and read back", func() {
+			So(bucket.Put("dir/b", []byte("bytes")), ShouldBeNil)
+			So(bucket.PutReader("dir/a", bytes.NewBufferString("reader"), 6), ShouldBeNil)
+
+			keys, err := bucket.List("dir")
+			So(err, ShouldBeNil)
+			So(keys, ShouldResemble, []string{"dir/a", "dir/b"})
+
+			data, err := bucket.Get("dir/a")
+			So(err, ShouldBeNil)
+			So(string(data), ShouldEqual, "reader")
+
+			r, err := bucket.GetReader("dir/b")
+			So(err, ShouldBeNil)
+			defer r.Close()
+			data, err = ioutil.ReadAll(r)
+			So(err, ShouldBeNil)
+			So(string(data), ShouldEqual, "bytes")
+		})
+
+		Convey("keys should not escape the root", func() {
+			So(bucket.Put("../../escaped", []byte("x")), ShouldBeNil)
+			_, err := os.Stat(root + "/escaped")
+			So(err, ShouldBeNil)
+		})
+	})
+}
+
+func TestNewValidatesConfig(t *testing.T) {
+	Convey("When creating a bucket", t, func() {
+		Convey("an unknown backend should be rejected", func() {
+			_, err := New(Config{Backend: "ftp"})
+			So(err, ShouldNotBeNil)
+		})
+		Convey("a backend with missing settings should be rejected", func() {
+			_, err := New(Config{Backend: LocalBackend})
+			So(err, ShouldNotBeNil)
+			_, err = New(Config{Backend: S3Backend})
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
@@ -0,0 +1,88 @@
+package blobstore
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+)
+
+// LocalBucket keeps blobs in a directory on local disk or an NFS mount,
+// each at <root>/<key>.
+type LocalBucket struct {
+	Root string
+}
+
+// NewLocalBucket returns a LocalBucket rooted at the given directory,
+// creating the directory if needed.
+func NewLocalBucket(root string) (*LocalBucket, error) {
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return nil, fmt.Errorf("error creating blob directory: %v", err)
+	}
+	return &LocalBucket{Root: root}, nil
+}
+
+func (lb *LocalBucket) path(key string) string {
+	return filepath.Join(lb.Root, filepath.FromSlash(path.Clean("/"+key)))
+}
+
+// Put writes the data to a temporary file, then moves it into place so
+// readers never see a partially written blob.
+func (lb *LocalBucket) Put(key string, data []byte) error {
+	return lb.PutReader(key, bytes.NewReader(data), int64(len(data)))
+}
+
+func (lb *LocalBucket) Get(key string) ([]byte, error) {
+	return ioutil.ReadFile(lb.path(key))
+}
+
+// PutReader copies the reader to a temporary file, then moves it into place
+// like Put.
+func (lb *LocalBucket) PutReader(key string, r io.Reader, size int64) error {
+	dest := lb.path(key)
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(dest), ".upload")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = io.CopyN(tmp, r, size)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("error writing blob %v: %v", key, err)
+	}
+	return os.Rename(tmp.Name(), dest)
+}
+
+func (lb *LocalBucket) GetReader(key string) (io.ReadCloser, error) {
+	return os.Open(lb.path(key))
+}
+
+// List returns the keys of the files in the directory, skipping
+// subdirectories and uploads in progress.
+func (lb *LocalBucket) List(dir string) ([]string, error) {
+	infos, err := ioutil.ReadDir(lb.path(dir))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	keys := []string{}
+	for _, info := range infos {
+		if info.IsDir() || info.Name()[0] == '.' {
+			continue
+		}
+		keys = append(keys, path.Join(dir, info.Name()))
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
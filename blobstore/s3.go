@@ -0,0 +1,94 @@
+package blobstore
+
+import (
+	"fmt"
+	"github.com/evergreen-ci/evergreen/thirdparty"
+	"github.com/goamz/goamz/aws"
+	"github.com/goamz/goamz/s3"
+	"io"
+	"path"
+	"sort"
+	"strings"
+)
+
+// the most keys s3 returns in one list request
+const s3ListMax = 1000
+
+// S3Bucket keeps blobs in an s3 bucket, under <prefix>/<key>.
+type S3Bucket struct {
+	bucket *s3.Bucket
+	prefix string
+}
+
+// NewS3Bucket returns an S3Bucket for the bucket in the config.
+func NewS3Bucket(conf Config) *S3Bucket {
+	auth := &aws.Auth{
+		AccessKey: conf.AWSKey,
+		SecretKey: conf.AWSSecret,
+	}
+	region := aws.USEast
+	if conf.S3Endpoint != "" {
+		region = aws.Region{
+			Name:                 "blobstore",
+			S3Endpoint:           conf.S3Endpoint,
+			S3LocationConstraint: true,
+		}
+	}
+	return &S3Bucket{
+		bucket: thirdparty.NewS3Session(auth, region).Bucket(conf.S3Bucket),
+		prefix: conf.S3Prefix,
+	}
+}
+
+func (sb *S3Bucket) key(key string) string {
+	return path.Join(sb.prefix, key)
+}
+
+func (sb *S3Bucket) Put(key string, data []byte) error {
+	err := sb.bucket.Put(sb.key(key), data, "application/octet-stream", s3.Private, s3.Options{})
+	if err != nil {
+		return fmt.Errorf("error uploading blob %v: %v", key, err)
+	}
+	return nil
+}
+
+func (sb *S3Bucket) Get(key string) ([]byte, error) {
+	return sb.bucket.Get(sb.key(key))
+}
+
+func (sb *S3Bucket) PutReader(key string, r io.Reader, size int64) error {
+	err := sb.bucket.PutReader(sb.key(key), r, size, "application/octet-stream", s3.Private, s3.Options{})
+	if err != nil {
+		return fmt.Errorf("error uploading blob %v: %v", key, err)
+	}
+	return nil
+}
+
+func (sb *S3Bucket) GetReader(key string) (io.ReadCloser, error) {
+	return sb.bucket.GetReader(sb.key(key))
+}
+
+// List pages through the keys under the directory.
+func (sb *S3Bucket) List(dir string) ([]string, error) {
+	prefix := sb.key(dir) + "/"
+	keys := []string{}
+	marker := ""
+	for {
+		resp, err := sb.bucket.List(prefix, "/", marker, s3ListMax)
+		if err != nil {
+			return nil, fmt.Errorf("error listing blobs in %v: %v", dir, err)
+		}
+		for _, k := range resp.Contents {
+			keys = append(keys, path.Join(dir, strings.TrimPrefix(k.Key, prefix)))
+			marker = k.Key
+		}
+		if !resp.IsTruncated {
+			break
+		}
+		if resp.NextMarker != "" {
+			marker = resp.NextMarker
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
@@ -122,6 +122,37 @@ func (ac *APIClient) ValidateLocalConfig(data []byte) ([]validator.ValidationErr
 	return nil, nil
 }
 
+// SpawnRequest holds the options for spawning a host.
+type SpawnRequest struct {
+	Distro     string `json:"distro"`
+	PublicKey  string `json:"public_key"`
+	HomeVolume bool   `json:"home_volume"`
+	// TaskId is the task to set the host up to reproduce. The host is of
+	// the task's distro unless another one is given.
+	TaskId string `json:"task_id"`
+}
+
+// SpawnHost asks the server to spawn a host. The host is set up in the
+// background, and the user is notified once it's ready.
+func (ac *APIClient) SpawnHost(spawnReq SpawnRequest) error {
+	rPipe, wPipe := io.Pipe()
+	encoder := json.NewEncoder(wPipe)
+	go func() {
+		encoder.Encode(spawnReq)
+		wPipe.Close()
+	}()
+	defer rPipe.Close()
+
+	resp, err := ac.put("spawns/", rPipe)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return NewAPIError(resp)
+	}
+	return nil
+}
+
 func (ac *APIClient) CancelPatch(patchId string) error {
 	return ac.modifyExisting(patchId, "cancel")
 }
@@ -23,6 +23,7 @@ func main() {
 	parser.AddCommand("finalize-patch", "finalize an existing patch", "", &cli.FinalizePatchCommand{GlobalOpts: opts})
 	parser.AddCommand("list-projects", "list all projects", "", &cli.ListProjectsCommand{GlobalOpts: opts})
 	parser.AddCommand("validate", "validate a config file", "", &cli.ValidateCommand{GlobalOpts: opts})
+	parser.AddCommand("spawn-task", "spawn a host set up to reproduce a task", "", &cli.SpawnTaskCommand{GlobalOpts: opts})
 	_, err := parser.Parse()
 	if err != nil {
 		os.Exit(1)
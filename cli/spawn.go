@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os/user"
+	"path/filepath"
+	"strings"
+)
+
+// SpawnTaskCommand is used to spawn a host set up to reproduce a task.
+type SpawnTaskCommand struct {
+	GlobalOpts Options `no-flag:"true"`
+	TaskId     string  `short:"t" long:"task" description:"id of the task to reproduce" required:"true"`
+	KeyFile    string  `short:"k" long:"key" description:"public key to add to the host (defaults to ~/.ssh/id_rsa.pub)"`
+	HomeVolume bool    `long:"home-volume" description:"attach your persistent home volume to the host"`
+}
+
+func (stc *SpawnTaskCommand) Execute(args []string) error {
+	ac, _, err := getAPIClient(stc.GlobalOpts)
+	if err != nil {
+		return err
+	}
+	notifyUserUpdate(ac)
+
+	keyFile := stc.KeyFile
+	if keyFile == "" {
+		u, err := user.Current()
+		if err != nil {
+			return err
+		}
+		keyFile = filepath.Join(u.HomeDir, ".ssh", "id_rsa.pub")
+	}
+	publicKey, err := ioutil.ReadFile(keyFile)
+	if err != nil {
+		return fmt.Errorf("Error reading public key: %v", err)
+	}
+
+	err = ac.SpawnHost(SpawnRequest{
+		TaskId:     stc.TaskId,
+		PublicKey:  strings.TrimSpace(string(publicKey)),
+		HomeVolume: stc.HomeVolume,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Spawning a host for task %v. You'll be emailed once it's ready.\n", stc.TaskId)
+	return nil
+}
@@ -1,6 +1,7 @@
 package cloud
 
 import (
+	"errors"
 	"github.com/evergreen-ci/evergreen"
 	"github.com/evergreen-ci/evergreen/model/distro"
 	"github.com/evergreen-ci/evergreen/model/host"
@@ -51,6 +52,10 @@ func (stat CloudStatus) String() string {
 	}
 }
 
+// ErrUnsupported is returned by the CloudManager methods that a provider
+// has no way of implementing, e.g. stopping a static host.
+var ErrUnsupported = errors.New("operation is not supported by this provider")
+
 // ProviderSettings exposes provider-specific configuration settings for a CloudManager.
 type ProviderSettings interface {
 	Validate() error
@@ -81,6 +86,25 @@ type CloudManager interface {
 	// TerminateInstances destroys the host in the underlying provider
 	TerminateInstance(*host.Host) error
 
+	// StopInstance shuts down the host without destroying it, so that it
+	// can be started again with StartInstance
+	StopInstance(*host.Host) error
+
+	// StartInstance boots a host that was shut down with StopInstance
+	StartInstance(*host.Host) error
+
+	// CreateVolume creates a volume of the given size in GiB that can be
+	// attached to the host. The volume is not stored in the database.
+	CreateVolume(h *host.Host, size int) (*host.Volume, error)
+
+	// AttachVolume attaches the volume to the host and returns the device name
+	// it was attached under. Providers that can only attach volumes to hosts in
+	// the same zone may move the volume, updating its provider id and zone.
+	AttachVolume(*host.Host, *host.Volume) (string, error)
+
+	// DeleteVolume destroys a volume that is not attached to any host
+	DeleteVolume(*host.Volume) error
+
 	//IsUp returns true if the underlying provider has not destroyed the
 	//host (in other words, if the host "should" be reachable. This does not
 	//necessarily mean that the host actually *is* reachable via SSH
@@ -106,6 +130,24 @@ type CloudManager interface {
 	TimeTilNextPayment(host *host.Host) time.Duration
 }
 
+// FallbackManager is implemented by CloudManagers that can replace a host
+// whose instance couldn't be started, e.g. an unfulfillable spot request,
+// with an instance from another source.
+type FallbackManager interface {
+	// SpawnFallback starts a replacement for the failed host. It returns a
+	// nil host if the host's distro isn't configured to fall back.
+	SpawnFallback(*host.Host) (*host.Host, error)
+}
+
+// InterruptibleManager is implemented by CloudManagers whose instances the
+// provider can take back at any time, like EC2 spot instances. The monitor
+// checks on their hosts even while they're running tasks.
+type InterruptibleManager interface {
+	// IsInterrupted returns true if the host's instance has been taken back,
+	// or the provider has given notice that it's about to be.
+	IsInterrupted(*host.Host) (bool, error)
+}
+
 //CloudHost is a provider-agnostic host object that delegates methods
 //like status checks, ssh options, DNS name checks, termination, etc. to the
 //underlying provider's implementation.
@@ -127,6 +169,14 @@ func (cloudHost *CloudHost) TerminateInstance() error {
 	return cloudHost.CloudMgr.TerminateInstance(cloudHost.Host)
 }
 
+func (cloudHost *CloudHost) StopInstance() error {
+	return cloudHost.CloudMgr.StopInstance(cloudHost.Host)
+}
+
+func (cloudHost *CloudHost) StartInstance() error {
+	return cloudHost.CloudMgr.StartInstance(cloudHost.Host)
+}
+
 func (cloudHost *CloudHost) GetInstanceStatus() (CloudStatus, error) {
 	return cloudHost.CloudMgr.GetInstanceStatus(cloudHost.Host)
 }
@@ -308,3 +308,28 @@ func (digoMgr *DigitalOceanManager) TimeTilNextPayment(host *host.Host) time.Dur
 
 	return nextPaymentTime.Sub(now)
 }
+
+// StopInstance is not supported for droplets.
+func (digoMgr *DigitalOceanManager) StopInstance(h *host.Host) error {
+	return cloud.ErrUnsupported
+}
+
+// StartInstance is not supported for droplets.
+func (digoMgr *DigitalOceanManager) StartInstance(h *host.Host) error {
+	return cloud.ErrUnsupported
+}
+
+// CreateVolume is not supported for droplets.
+func (digoMgr *DigitalOceanManager) CreateVolume(h *host.Host, size int) (*host.Volume, error) {
+	return nil, cloud.ErrUnsupported
+}
+
+// AttachVolume is not supported for droplets.
+func (digoMgr *DigitalOceanManager) AttachVolume(h *host.Host, volume *host.Volume) (string, error) {
+	return "", cloud.ErrUnsupported
+}
+
+// DeleteVolume is not supported for droplets.
+func (digoMgr *DigitalOceanManager) DeleteVolume(volume *host.Volume) error {
+	return cloud.ErrUnsupported
+}
@@ -439,3 +439,28 @@ func (dockerMgr *DockerManager) GetSSHOptions(host *host.Host, keyPath string) (
 func (dockerMgr *DockerManager) TimeTilNextPayment(host *host.Host) time.Duration {
 	return time.Duration(0)
 }
+
+// StopInstance is not supported for docker containers.
+func (dockerMgr *DockerManager) StopInstance(h *host.Host) error {
+	return cloud.ErrUnsupported
+}
+
+// StartInstance is not supported for docker containers.
+func (dockerMgr *DockerManager) StartInstance(h *host.Host) error {
+	return cloud.ErrUnsupported
+}
+
+// CreateVolume is not supported for docker containers.
+func (dockerMgr *DockerManager) CreateVolume(h *host.Host, size int) (*host.Volume, error) {
+	return nil, cloud.ErrUnsupported
+}
+
+// AttachVolume is not supported for docker containers.
+func (dockerMgr *DockerManager) AttachVolume(h *host.Host, volume *host.Volume) (string, error) {
+	return "", cloud.ErrUnsupported
+}
+
+// DeleteVolume is not supported for docker containers.
+func (dockerMgr *DockerManager) DeleteVolume(volume *host.Volume) error {
+	return cloud.ErrUnsupported
+}
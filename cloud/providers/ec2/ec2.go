@@ -18,6 +18,7 @@ import (
 // EC2Manager implements the CloudManager interface for Amazon EC2
 type EC2Manager struct {
 	awsCredentials *aws.Auth
+	settings       *evergreen.Settings
 }
 
 //Valid values for EC2 instance states:
@@ -37,6 +38,11 @@ type EC2ProviderSettings struct {
 	SecurityGroup string       `mapstructure:"security_group" json:"security_group,omitempty" bson:"security_group,omitempty"`
 	KeyName       string       `mapstructure:"key_name" json:"key_name,omitempty" bson:"key_name,omitempty"`
 	MountPoints   []MountPoint `mapstructure:"mount_points" json:"mount_points,omitempty" bson:"mount_points,omitempty"`
+
+	// InstanceTypes are tried in order when EC2 has no capacity for InstanceType
+	InstanceTypes []string `mapstructure:"instance_types" json:"instance_types,omitempty" bson:"instance_types,omitempty"`
+	// Placements are the availability zones and subnets to try, in order
+	Placements []Placement `mapstructure:"placements" json:"placements,omitempty" bson:"placements,omitempty"`
 }
 
 func (self *EC2ProviderSettings) Validate() error {
@@ -48,6 +54,12 @@ func (self *EC2ProviderSettings) Validate() error {
 		return fmt.Errorf("Instance size must not be blank")
 	}
 
+	for _, instanceType := range self.InstanceTypes {
+		if instanceType == "" {
+			return fmt.Errorf("Fallback instance sizes must not be blank")
+		}
+	}
+
 	if self.SecurityGroup == "" {
 		return fmt.Errorf("Security group must not be blank")
 	}
@@ -75,9 +87,16 @@ func (cloudManager *EC2Manager) Configure(settings *evergreen.Settings) error {
 		AccessKey: settings.Providers.AWS.Id,
 		SecretKey: settings.Providers.AWS.Secret,
 	}
+	cloudManager.settings = settings
 	return nil
 }
 
+// SupportsUserData is true because EC2 instances run the user data they're
+// started with when they boot.
+func (_ *EC2Manager) SupportsUserData() bool {
+	return true
+}
+
 func (cloudManager *EC2Manager) GetSSHOptions(h *host.Host, keyPath string) ([]string, error) {
 	return getEC2KeyOptions(h, keyPath)
 }
@@ -149,6 +168,11 @@ func (cloudManager *EC2Manager) SpawnInstance(d *distro.Distro, owner string, us
 		UserHost:         userHost,
 	}
 
+	userData, err := bootstrapUserData(cloudManager.settings, intentHost)
+	if err != nil {
+		return nil, fmt.Errorf("Error building user data for distro %v: %v", d.Id, err)
+	}
+
 	// record this 'intent host'
 	if err := intentHost.Insert(); err != nil {
 		return nil, evergreen.Logger.Errorf(slogger.ERROR, "Could not insert intent "+
@@ -167,12 +191,16 @@ func (cloudManager *EC2Manager) SpawnInstance(d *distro.Distro, owner string, us
 		InstanceType:   ec2Settings.InstanceType,
 		SecurityGroups: ec2.SecurityGroupNames(ec2Settings.SecurityGroup),
 		BlockDevices:   blockDevices,
+		UserData:       userData,
 	}
+	candidates := launchCandidates(ec2Settings.InstanceType, ec2Settings.InstanceTypes,
+		ec2Settings.Placements)
 
 	// start the instance - starting an instance does not mean you can connect
 	// to it immediately you have to use GetInstanceStatus below to ensure that
 	// it's actually running
-	newHost, resp, err := startEC2Instance(ec2Handle, &options, intentHost)
+	newHost, resp, err := startEC2Instance(ec2Handle, &options, ec2Settings.SecurityGroup,
+		candidates, intentHost)
 
 	if err != nil {
 		return nil, evergreen.Logger.Errorf(slogger.ERROR, "Could not start new "+
@@ -246,6 +274,19 @@ func (cloudManager *EC2Manager) StopInstance(host *host.Host) error {
 	return nil
 }
 
+func (cloudManager *EC2Manager) StartInstance(host *host.Host) error {
+	ec2Handle := getUSEast(*cloudManager.awsCredentials)
+	resp, err := ec2Handle.StartInstances(host.Id)
+	if err != nil {
+		return err
+	}
+
+	for _, stateChange := range resp.StateChanges {
+		evergreen.Logger.Logf(slogger.INFO, "Started %v", stateChange.InstanceId)
+	}
+	return nil
+}
+
 func (cloudManager *EC2Manager) TerminateInstance(host *host.Host) error {
 	// terminate the instance
 	if host.Status == evergreen.HostTerminated {
@@ -275,10 +316,30 @@ func (cloudManager *EC2Manager) TimeTilNextPayment(host *host.Host) time.Duratio
 	return timeTilNextEC2Payment(host)
 }
 
-func startEC2Instance(ec2Handle *ec2.EC2, options *ec2.RunInstancesOptions,
-	intentHost *host.Host) (*host.Host, *ec2.RunInstancesResp, error) {
+// runInstances starts an instance with the first launch candidate EC2 has
+// capacity for, leaving the options set to the candidate that was used.
+func runInstances(ec2Handle *ec2.EC2, options *ec2.RunInstancesOptions, securityGroup string,
+	candidates []launchCandidate) (resp *ec2.RunInstancesResp, err error) {
+	for _, candidate := range candidates {
+		options.InstanceType = candidate.InstanceType
+		options.AvailabilityZone = candidate.Placement.AvailabilityZone
+		options.SubnetId = candidate.Placement.SubnetId
+		options.SecurityGroups = securityGroups(securityGroup, candidate.Placement)
+		resp, err = ec2Handle.RunInstances(options)
+		if err == nil || !isCapacityError(err) {
+			return resp, err
+		}
+		evergreen.Logger.Logf(slogger.WARN, "Could not start %v instance (placement %+v), "+
+			"trying the next instance type or placement: %v", candidate.InstanceType,
+			candidate.Placement, err)
+	}
+	return resp, err
+}
+
+func startEC2Instance(ec2Handle *ec2.EC2, options *ec2.RunInstancesOptions, securityGroup string,
+	candidates []launchCandidate, intentHost *host.Host) (*host.Host, *ec2.RunInstancesResp, error) {
 	// start the instance
-	resp, err := ec2Handle.RunInstances(options)
+	resp, err := runInstances(ec2Handle, options, securityGroup, candidates)
 
 	if err != nil {
 		// remove the intent host document
@@ -312,6 +373,7 @@ func startEC2Instance(ec2Handle *ec2.EC2, options *ec2.RunInstancesOptions,
 
 	// we found the old document now we can insert the new one
 	host.Id = instance.InstanceId
+	host.InstanceType = options.InstanceType
 	err = host.Insert()
 	if err != nil {
 		return nil, nil, evergreen.Logger.Errorf(slogger.ERROR, "Could not insert "+
@@ -7,6 +7,7 @@ import (
 	"github.com/evergreen-ci/evergreen/cloud"
 	"github.com/evergreen-ci/evergreen/db/bsonutil"
 	"github.com/evergreen-ci/evergreen/model/host"
+	"github.com/evergreen-ci/evergreen/util"
 	"github.com/goamz/goamz/aws"
 	"github.com/goamz/goamz/ec2"
 	"math"
@@ -49,6 +50,69 @@ var (
 	SizeKey        = bsonutil.MustHaveTag(MountPoint{}, "Size")
 )
 
+// Placement is a location in which instances of a distro can be started.
+// When SubnetId is set, the distro's security group must be given by id.
+type Placement struct {
+	AvailabilityZone string `mapstructure:"availability_zone" json:"availability_zone,omitempty" bson:"availability_zone,omitempty"`
+	SubnetId         string `mapstructure:"subnet_id" json:"subnet_id,omitempty" bson:"subnet_id,omitempty"`
+}
+
+// launchCandidate is an instance type and placement to try starting an
+// instance with.
+type launchCandidate struct {
+	InstanceType string
+	Placement    Placement
+}
+
+// capacityErrorCodes are the EC2 error codes returned when an instance of a
+// type can't be started in a placement right now, but might be started with
+// a different instance type or placement.
+var capacityErrorCodes = map[string]bool{
+	"InsufficientInstanceCapacity":      true,
+	"InsufficientFreeAddressesInSubnet": true,
+	"InstanceLimitExceeded":             true,
+	"MaxSpotInstanceCountExceeded":      true,
+	"SpotMaxPriceTooLow":                true,
+	"Unsupported":                       true,
+}
+
+// launchCandidates returns the combinations of instance types and placements
+// to try, in order of preference. Each instance type is tried in every
+// placement before falling back to the next instance type.
+func launchCandidates(instanceType string, fallbackTypes []string, placements []Placement) []launchCandidate {
+	if len(placements) == 0 {
+		placements = []Placement{{}}
+	}
+	candidates := []launchCandidate{}
+	seen := map[string]bool{}
+	for _, t := range append([]string{instanceType}, fallbackTypes...) {
+		if t == "" || seen[t] {
+			continue
+		}
+		seen[t] = true
+		for _, p := range placements {
+			candidates = append(candidates, launchCandidate{InstanceType: t, Placement: p})
+		}
+	}
+	return candidates
+}
+
+// isCapacityError returns true if the error means the next launch candidate
+// should be tried.
+func isCapacityError(err error) bool {
+	ec2err, ok := err.(*ec2.Error)
+	return ok && capacityErrorCodes[ec2err.Code]
+}
+
+// securityGroups returns the distro's security group in the form EC2 expects
+// for the placement: instances started in a VPC subnet need the group's id.
+func securityGroups(group string, placement Placement) []ec2.SecurityGroup {
+	if placement.SubnetId != "" {
+		return ec2.SecurityGroupIds(group)
+	}
+	return ec2.SecurityGroupNames(group)
+}
+
 //Utility func to create a create a temporary instance name for a host
 func generateName(distroId string) string {
 	return "evg_" + distroId + "_" + time.Now().Format(NameTimeFormat) +
@@ -209,3 +273,18 @@ func timeTilNextEC2Payment(host *host.Host) time.Duration {
 	return nextPaymentTime.Sub(now)
 
 }
+
+// bootstrapUserData gives the host a secret and returns the user data that
+// provisions it, if its distro is provisioned with user data. Hosts spawned
+// by users are still set up over SSH, along with the user's key.
+func bootstrapUserData(settings *evergreen.Settings, h *host.Host) ([]byte, error) {
+	if !h.Distro.ProvisionWithUserData || h.UserHost {
+		return nil, nil
+	}
+	h.Secret = util.RandomString()
+	script, err := cloud.BootstrapScript(settings, h)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(script), nil
+}
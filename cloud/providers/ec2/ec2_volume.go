@@ -0,0 +1,177 @@
+package ec2
+
+import (
+	"fmt"
+	"github.com/10gen-labs/slogger/v1"
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model/host"
+	"github.com/evergreen-ci/evergreen/util"
+	"github.com/goamz/goamz/ec2"
+	"time"
+)
+
+const (
+	// the device home volumes are attached under. Linux kernels may
+	// rename it to /dev/xvdf.
+	homeVolumeDevice = "/dev/sdf"
+	homeVolumeType   = "gp2"
+
+	// EBS status values for volumes and snapshots
+	ebsVolumeAvailable = "available"
+	ebsSnapshotDone    = "completed"
+
+	ebsPollRetries  = 60
+	ebsPollInterval = 10 * time.Second
+)
+
+// CreateVolume creates an EBS volume in the host's availability zone.
+func (cloudManager *EC2Manager) CreateVolume(h *host.Host, size int) (*host.Volume, error) {
+	ec2Handle := getUSEast(*cloudManager.awsCredentials)
+	instance, err := getInstanceInfo(ec2Handle, h.Id)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := ec2Handle.CreateVolume(&ec2.CreateVolume{
+		AvailZone:  instance.AvailabilityZone,
+		Size:       int64(size),
+		VolumeType: homeVolumeType,
+	})
+	if err != nil {
+		return nil, evergreen.Logger.Errorf(slogger.ERROR, "Could not create volume "+
+			"for host %v: %v", h.Id, err)
+	}
+	evergreen.Logger.Logf(slogger.INFO, "Created volume %v in %v for host %v",
+		resp.VolumeId, resp.AvailZone, h.Id)
+
+	return &host.Volume{
+		ProviderId:       resp.VolumeId,
+		Provider:         OnDemandProviderName,
+		Size:             size,
+		AvailabilityZone: resp.AvailZone,
+	}, nil
+}
+
+// AttachVolume attaches the volume to the instance. EBS volumes can only be
+// attached to instances in their own availability zone, so a volume in
+// another zone is first copied over by way of a snapshot.
+func (cloudManager *EC2Manager) AttachVolume(h *host.Host, v *host.Volume) (string, error) {
+	ec2Handle := getUSEast(*cloudManager.awsCredentials)
+	instance, err := getInstanceInfo(ec2Handle, h.Id)
+	if err != nil {
+		return "", err
+	}
+	if v.AvailabilityZone != instance.AvailabilityZone {
+		if err = moveVolume(ec2Handle, v, instance.AvailabilityZone); err != nil {
+			return "", fmt.Errorf("error moving volume %v to %v: %v",
+				v.Id, instance.AvailabilityZone, err)
+		}
+	}
+	if err = waitForVolume(ec2Handle, v.ProviderId); err != nil {
+		return "", err
+	}
+	if _, err = ec2Handle.AttachVolume(v.ProviderId, h.Id, homeVolumeDevice); err != nil {
+		return "", evergreen.Logger.Errorf(slogger.ERROR, "Could not attach volume %v "+
+			"to %v: %v", v.ProviderId, h.Id, err)
+	}
+	evergreen.Logger.Logf(slogger.INFO, "Attached volume %v to %v", v.ProviderId, h.Id)
+	return homeVolumeDevice, nil
+}
+
+// DeleteVolume destroys the EBS volume.
+func (cloudManager *EC2Manager) DeleteVolume(v *host.Volume) error {
+	ec2Handle := getUSEast(*cloudManager.awsCredentials)
+	if _, err := ec2Handle.DeleteVolume(v.ProviderId); err != nil {
+		return evergreen.Logger.Errorf(slogger.ERROR, "Could not delete volume %v: %v",
+			v.ProviderId, err)
+	}
+	evergreen.Logger.Logf(slogger.INFO, "Deleted volume %v", v.ProviderId)
+	return nil
+}
+
+// moveVolume replaces the volume with a copy in the given availability
+// zone, updating its provider id and zone.
+func moveVolume(ec2Handle *ec2.EC2, v *host.Volume, zone string) error {
+	if err := waitForVolume(ec2Handle, v.ProviderId); err != nil {
+		return err
+	}
+	snapshot, err := ec2Handle.CreateSnapshot(v.ProviderId,
+		fmt.Sprintf("evergreen: moving volume %v to %v", v.Id, zone))
+	if err != nil {
+		return err
+	}
+	if err = waitForSnapshot(ec2Handle, snapshot.Id); err != nil {
+		return err
+	}
+	resp, err := ec2Handle.CreateVolume(&ec2.CreateVolume{
+		AvailZone:  zone,
+		SnapshotId: snapshot.Id,
+		VolumeType: homeVolumeType,
+	})
+	if err != nil {
+		return err
+	}
+	evergreen.Logger.Logf(slogger.INFO, "Copied volume %v to %v in %v",
+		v.ProviderId, resp.VolumeId, zone)
+
+	// the copy is in place, so failing to clean up is not fatal
+	if _, err = ec2Handle.DeleteVolume(v.ProviderId); err != nil {
+		evergreen.Logger.Logf(slogger.ERROR, "Could not delete moved volume %v: %v",
+			v.ProviderId, err)
+	}
+	if _, err = ec2Handle.DeleteSnapshots([]string{snapshot.Id}); err != nil {
+		evergreen.Logger.Logf(slogger.ERROR, "Could not delete snapshot %v: %v",
+			snapshot.Id, err)
+	}
+	v.ProviderId = resp.VolumeId
+	v.AvailabilityZone = zone
+	return nil
+}
+
+// waitForVolume waits until the volume can be attached.
+func waitForVolume(ec2Handle *ec2.EC2, volumeId string) error {
+	return pollEBS(fmt.Sprintf("volume %v", volumeId), func() (bool, error) {
+		resp, err := ec2Handle.Volumes([]string{volumeId}, nil)
+		if err != nil {
+			return false, err
+		}
+		if len(resp.Volumes) != 1 {
+			return false, fmt.Errorf("volume %v not found", volumeId)
+		}
+		return resp.Volumes[0].Status == ebsVolumeAvailable, nil
+	})
+}
+
+// waitForSnapshot waits until the snapshot is complete.
+func waitForSnapshot(ec2Handle *ec2.EC2, snapshotId string) error {
+	return pollEBS(fmt.Sprintf("snapshot %v", snapshotId), func() (bool, error) {
+		resp, err := ec2Handle.Snapshots([]string{snapshotId}, nil)
+		if err != nil {
+			return false, err
+		}
+		if len(resp.Snapshots) != 1 {
+			return false, fmt.Errorf("snapshot %v not found", snapshotId)
+		}
+		return resp.Snapshots[0].Status == ebsSnapshotDone, nil
+	})
+}
+
+// pollEBS calls isReady until it reports that the resource is ready.
+func pollEBS(resource string, isReady func() (bool, error)) error {
+	poll := util.RetriableFunc(
+		func() error {
+			ready, err := isReady()
+			if err != nil {
+				return err
+			}
+			if !ready {
+				return util.RetriableError{fmt.Errorf("%v is not ready", resource)}
+			}
+			return nil
+		},
+	)
+	retryFail, err := util.Retry(poll, ebsPollRetries, ebsPollInterval)
+	if retryFail {
+		return fmt.Errorf("timed out waiting for %v", resource)
+	}
+	return err
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/evergreen-ci/evergreen/model"
 	"github.com/evergreen-ci/evergreen/model/distro"
 	"github.com/evergreen-ci/evergreen/model/host"
+	"github.com/evergreen-ci/evergreen/util"
 	"github.com/goamz/goamz/aws"
 	"github.com/goamz/goamz/ec2"
 	"github.com/mitchellh/mapstructure"
@@ -23,11 +24,26 @@ const (
 	SpotStatusFailed    = "failed"
 
 	EC2ErrorSpotRequestNotFound = "InvalidSpotInstanceRequestID.NotFound"
+
+	// SpotFulfillmentTimeout is how long a spot request may stay open before
+	// it's treated as failed for lack of capacity
+	SpotFulfillmentTimeout = 15 * time.Minute
 )
 
+// spot request status codes meaning EC2 has taken back the request's
+// instance, or has given notice that it's about to
+var spotInterruptionCodes = []string{
+	"marked-for-termination",
+	"instance-terminated-by-price",
+	"instance-terminated-no-capacity",
+	"instance-terminated-capacity-oversubscribed",
+	"instance-terminated-launch-group-constraint",
+}
+
 // EC2SpotManager implements the CloudManager interface for Amazon EC2 Spot
 type EC2SpotManager struct {
 	awsCredentials *aws.Auth
+	settings       *evergreen.Settings
 }
 
 type EC2SpotSettings struct {
@@ -37,6 +53,14 @@ type EC2SpotSettings struct {
 	KeyName       string       `mapstructure:"key_name" json:"key_name,omitempty" bson:"key_name,omitempty"`
 	MountPoints   []MountPoint `mapstructure:"mount_points" json:"mount_points,omitempty" bson:"mount_points,omitempty"`
 	BidPrice      float64      `mapstructure:"bid_price" json:"bid_price,omitempty" bson:"bid_price,omitempty"`
+
+	// InstanceTypes are tried in order when EC2 has no capacity for InstanceType
+	InstanceTypes []string `mapstructure:"instance_types" json:"instance_types,omitempty" bson:"instance_types,omitempty"`
+	// Placements are the availability zones and subnets to try, in order
+	Placements []Placement `mapstructure:"placements" json:"placements,omitempty" bson:"placements,omitempty"`
+	// FallbackToOnDemand starts an on-demand instance with the same settings
+	// when no spot instance can be had
+	FallbackToOnDemand bool `mapstructure:"fallback_on_demand" json:"fallback_on_demand,omitempty" bson:"fallback_on_demand,omitempty"`
 }
 
 func (self *EC2SpotSettings) Validate() error {
@@ -52,6 +76,12 @@ func (self *EC2SpotSettings) Validate() error {
 		return fmt.Errorf("Instance size must not be blank")
 	}
 
+	for _, instanceType := range self.InstanceTypes {
+		if instanceType == "" {
+			return fmt.Errorf("Fallback instance sizes must not be blank")
+		}
+	}
+
 	if self.SecurityGroup == "" {
 		return fmt.Errorf("Security group must not be blank")
 	}
@@ -78,9 +108,16 @@ func (cloudManager *EC2SpotManager) Configure(settings *evergreen.Settings) erro
 		AccessKey: settings.Providers.AWS.Id,
 		SecretKey: settings.Providers.AWS.Secret,
 	}
+	cloudManager.settings = settings
 	return nil
 }
 
+// SupportsUserData is true because spot instances, like on-demand ones, run
+// the user data they're started with when they boot.
+func (_ *EC2SpotManager) SupportsUserData() bool {
+	return true
+}
+
 func (_ *EC2SpotManager) GetSettings() cloud.ProviderSettings {
 	return &EC2SpotSettings{}
 }
@@ -134,6 +171,7 @@ func (cloudManager *EC2SpotManager) IsSSHReachable(host *host.Host, keyPath stri
 //an ec2 spot-instance host. For unfulfilled spot requests, the behavior
 //is as follows:
 // Spot request open or active, but unfulfilled -> StatusPending
+// Spot request open past SpotFulfillmentTimeout -> StatusFailed
 // Spot request closed or cancelled             -> StatusTerminated
 // Spot request failed due to bidding/capacity  -> StatusFailed
 //
@@ -162,6 +200,9 @@ func (cloudManager *EC2SpotManager) GetInstanceStatus(host *host.Host) (cloud.Cl
 	//or still pending evaluation
 	switch spotDetails.State {
 	case SpotStatusOpen:
+		if spotRequestTimedOut(spotDetails) {
+			return cloud.StatusFailed, nil
+		}
 		return cloud.StatusPending, nil
 	case SpotStatusActive:
 		return cloud.StatusPending, nil
@@ -240,6 +281,11 @@ func (cloudManager *EC2SpotManager) SpawnInstance(d *distro.Distro, owner string
 		UserHost:         userHost,
 	}
 
+	userData, err := bootstrapUserData(cloudManager.settings, intentHost)
+	if err != nil {
+		return nil, fmt.Errorf("Error building user data for distro %v: %v", d.Id, err)
+	}
+
 	// record this 'intent host'
 	if err := intentHost.Insert(); err != nil {
 		return nil, evergreen.Logger.Errorf(slogger.ERROR, "Could not insert intent "+
@@ -258,17 +304,26 @@ func (cloudManager *EC2SpotManager) SpawnInstance(d *distro.Distro, owner string
 		InstanceType:   ec2Settings.InstanceType,
 		SecurityGroups: ec2.SecurityGroupNames(ec2Settings.SecurityGroup),
 		BlockDevices:   blockDevices,
+		UserData:       userData,
 	}
+	candidates := launchCandidates(ec2Settings.InstanceType, ec2Settings.InstanceTypes,
+		ec2Settings.Placements)
 
-	spotResp, err := ec2Handle.RequestSpotInstances(spotRequest)
+	spotResp, err := requestSpotInstances(ec2Handle, spotRequest, ec2Settings.SecurityGroup, candidates)
 	if err != nil {
 		//Remove the intent host if the API call failed
 		if err := intentHost.Remove(); err != nil {
 			evergreen.Logger.Logf(slogger.ERROR, "Failed to remove intent host %v: %v", intentHost.Id, err)
 		}
+		if ec2Settings.FallbackToOnDemand && isCapacityError(err) {
+			evergreen.Logger.Logf(slogger.WARN, "No spot instances available for distro '%v', "+
+				"falling back to on-demand: %v", d.Id, err)
+			return cloudManager.spawnOnDemand(d, owner, userHost)
+		}
 		return nil, evergreen.Logger.Errorf(slogger.ERROR, "Failed starting spot instance "+
 			" for distro '%v' on intent host %v: %v", d.Id, intentHost.Id, err)
 	}
+	intentHost.InstanceType = spotRequest.InstanceType
 
 	spotReqRes := spotResp.SpotRequestResults[0]
 	if spotReqRes.State != SpotStatusOpen && spotReqRes.State != SpotStatusActive {
@@ -319,6 +374,67 @@ func (cloudManager *EC2SpotManager) SpawnInstance(d *distro.Distro, owner string
 	return intentHost, nil
 }
 
+// requestSpotInstances requests a spot instance with the first launch
+// candidate EC2 accepts a request for, leaving the request set to the
+// candidate that was used.
+func requestSpotInstances(ec2Handle *ec2.EC2, spotRequest *ec2.RequestSpotInstances, securityGroup string,
+	candidates []launchCandidate) (resp *ec2.RequestSpotInstancesResp, err error) {
+	for _, candidate := range candidates {
+		spotRequest.InstanceType = candidate.InstanceType
+		spotRequest.AvailZone = candidate.Placement.AvailabilityZone
+		spotRequest.SubnetId = candidate.Placement.SubnetId
+		spotRequest.SecurityGroups = securityGroups(securityGroup, candidate.Placement)
+		resp, err = ec2Handle.RequestSpotInstances(spotRequest)
+		if err == nil || !isCapacityError(err) {
+			return resp, err
+		}
+		evergreen.Logger.Logf(slogger.WARN, "Could not request %v spot instance (placement %+v), "+
+			"trying the next instance type or placement: %v", candidate.InstanceType,
+			candidate.Placement, err)
+	}
+	return resp, err
+}
+
+// spotRequestTimedOut returns true if the open spot request has waited for
+// capacity for longer than SpotFulfillmentTimeout.
+func spotRequestTimedOut(spotDetails *ec2.SpotRequestResult) bool {
+	createTime, err := time.Parse(time.RFC3339, spotDetails.CreateTime)
+	if err != nil {
+		evergreen.Logger.Logf(slogger.WARN, "Could not parse creation time '%v' of spot request %v: %v",
+			spotDetails.CreateTime, spotDetails.SpotRequestId, err)
+		return false
+	}
+	return time.Since(createTime) > SpotFulfillmentTimeout
+}
+
+// SpawnFallback starts an on-demand instance to replace a host whose spot
+// request failed or couldn't be fulfilled, if the host's distro allows it.
+// Fulfills the FallbackManager interface.
+func (cloudManager *EC2SpotManager) SpawnFallback(h *host.Host) (*host.Host, error) {
+	ec2Settings := &EC2SpotSettings{}
+	if err := mapstructure.Decode(h.Distro.ProviderSettings, ec2Settings); err != nil {
+		return nil, fmt.Errorf("Error decoding params for distro %v: %v", h.Distro.Id, err)
+	}
+	if !ec2Settings.FallbackToOnDemand {
+		return nil, nil
+	}
+	evergreen.Logger.Logf(slogger.INFO, "Replacing failed spot request %v for distro '%v' "+
+		"with an on-demand instance", h.Id, h.Distro.Id)
+	return cloudManager.spawnOnDemand(&h.Distro, h.StartedBy, h.UserHost)
+}
+
+// spawnOnDemand starts an on-demand instance with the spot distro's settings.
+// The host records the on-demand provider, so that it's managed as one.
+func (cloudManager *EC2SpotManager) spawnOnDemand(d *distro.Distro, owner string, userHost bool) (*host.Host, error) {
+	onDemand := *d
+	onDemand.Provider = OnDemandProviderName
+	onDemandMgr := &EC2Manager{
+		awsCredentials: cloudManager.awsCredentials,
+		settings:       cloudManager.settings,
+	}
+	return onDemandMgr.SpawnInstance(&onDemand, owner, userHost)
+}
+
 func (cloudManager *EC2SpotManager) TerminateInstance(host *host.Host) error {
 	// terminate the instance
 	if host.Status == evergreen.HostTerminated {
@@ -372,6 +488,23 @@ func (cloudManager *EC2SpotManager) TerminateInstance(host *host.Host) error {
 	return host.Terminate()
 }
 
+// IsInterrupted returns true if the status of the host's spot request shows
+// that its instance was interrupted, or is about to be. Fulfills the
+// InterruptibleManager interface.
+func (cloudManager *EC2SpotManager) IsInterrupted(host *host.Host) (bool, error) {
+	status, err := describeSpotRequestStatus(*cloudManager.awsCredentials, aws.USEast, host.Id)
+	if err != nil {
+		ec2err, ok := err.(*ec2.Error)
+		if ok && ec2err.Code == EC2ErrorSpotRequestNotFound {
+			// as when terminating, assume amazon took back the instance
+			return true, nil
+		}
+		return false, evergreen.Logger.Errorf(slogger.ERROR,
+			"failed to get spot request info for %v: %v", host.Id, err)
+	}
+	return util.SliceContains(spotInterruptionCodes, status.Code), nil
+}
+
 // describeSpotRequest gets infomration about a spot request
 // Note that if the SpotRequestResult object returned has a non-blank InstanceId
 // field, this indicates that the spot request has been fulfilled.
@@ -391,3 +524,28 @@ func (cloudManager *EC2SpotManager) describeSpotRequest(spotReqId string) (*ec2.
 	}
 	return &resp.SpotRequestResults[0], nil
 }
+
+// StopInstance is not supported for spot instances.
+func (cloudManager *EC2SpotManager) StopInstance(h *host.Host) error {
+	return cloud.ErrUnsupported
+}
+
+// StartInstance is not supported for spot instances.
+func (cloudManager *EC2SpotManager) StartInstance(h *host.Host) error {
+	return cloud.ErrUnsupported
+}
+
+// CreateVolume is not supported for spot instances.
+func (cloudManager *EC2SpotManager) CreateVolume(h *host.Host, size int) (*host.Volume, error) {
+	return nil, cloud.ErrUnsupported
+}
+
+// AttachVolume is not supported for spot instances.
+func (cloudManager *EC2SpotManager) AttachVolume(h *host.Host, volume *host.Volume) (string, error) {
+	return "", cloud.ErrUnsupported
+}
+
+// DeleteVolume is not supported for spot instances.
+func (cloudManager *EC2SpotManager) DeleteVolume(volume *host.Volume) error {
+	return cloud.ErrUnsupported
+}
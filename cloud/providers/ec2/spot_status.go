@@ -0,0 +1,106 @@
+package ec2
+
+import (
+	"encoding/xml"
+	"fmt"
+	"github.com/goamz/goamz/aws"
+	"github.com/goamz/goamz/ec2"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// the EC2 API version spot request statuses are read with, the same as the
+// rest of the ec2 package's requests
+const spotStatusAPIVersion = "2014-02-01"
+
+// how long to wait for EC2 to describe a spot request
+const spotStatusTimeout = time.Minute
+
+// spotRequestStatus is the status of a spot request, which is more detailed
+// than its state, e.g. it reports when the instance is about to be
+// terminated. The ec2 package's SpotRequestResult doesn't include it, so
+// spot requests are described here to read it.
+//
+// See http://goo.gl/Rgj6mK for more details.
+type spotRequestStatus struct {
+	Code       string `xml:"code"`
+	UpdateTime string `xml:"updateTime"`
+	Message    string `xml:"message"`
+}
+
+type spotRequestStatusResp struct {
+	RequestId string `xml:"requestId"`
+	Results   []struct {
+		SpotRequestId string            `xml:"spotInstanceRequestId"`
+		Status        spotRequestStatus `xml:"status"`
+	} `xml:"spotInstanceRequestSet>item"`
+}
+
+type spotRequestErrorResp struct {
+	RequestId string      `xml:"RequestID"`
+	Errors    []ec2.Error `xml:"Errors>Error"`
+}
+
+// describeSpotRequestStatus returns the status of the spot request. Errors
+// returned by EC2 are returned as *ec2.Error, as by the ec2 package.
+func describeSpotRequestStatus(creds aws.Auth, region aws.Region,
+	spotReqId string) (*spotRequestStatus, error) {
+	endpoint, err := url.Parse(region.EC2Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if endpoint.Path == "" {
+		endpoint.Path = "/"
+	}
+	params := map[string]string{
+		"Action":                  "DescribeSpotInstanceRequests",
+		"SpotInstanceRequestId.1": spotReqId,
+		"Version":                 spotStatusAPIVersion,
+		"Timestamp":               time.Now().In(time.UTC).Format(time.RFC3339),
+	}
+	signer, err := aws.NewV2Signer(creds, aws.ServiceInfo{
+		Endpoint: region.EC2Endpoint,
+		Signer:   aws.V2Signature,
+	})
+	if err != nil {
+		return nil, err
+	}
+	signer.Sign("GET", endpoint.Path, params)
+	query := url.Values{}
+	for k, v := range params {
+		query.Set(k, v)
+	}
+	endpoint.RawQuery = query.Encode()
+
+	client := &http.Client{Timeout: spotStatusTimeout}
+	resp, err := client.Get(endpoint.String())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		errResp := spotRequestErrorResp{}
+		xml.NewDecoder(resp.Body).Decode(&errResp)
+		ec2Err := &ec2.Error{}
+		if len(errResp.Errors) > 0 {
+			ec2Err = &errResp.Errors[0]
+		}
+		ec2Err.RequestId = errResp.RequestId
+		ec2Err.StatusCode = resp.StatusCode
+		if ec2Err.Message == "" {
+			ec2Err.Message = resp.Status
+		}
+		return nil, ec2Err
+	}
+
+	statusResp := spotRequestStatusResp{}
+	if err = xml.NewDecoder(resp.Body).Decode(&statusResp); err != nil {
+		return nil, fmt.Errorf("error decoding spot request %v: %v", spotReqId, err)
+	}
+	if len(statusResp.Results) != 1 {
+		return nil, fmt.Errorf("expected one spot request, but got %v", len(statusResp.Results))
+	}
+	return &statusResp.Results[0].Status, nil
+}
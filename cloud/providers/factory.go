@@ -7,6 +7,7 @@ import (
 	"github.com/evergreen-ci/evergreen/cloud/providers/digitalocean"
 	"github.com/evergreen-ci/evergreen/cloud/providers/docker"
 	"github.com/evergreen-ci/evergreen/cloud/providers/ec2"
+	"github.com/evergreen-ci/evergreen/cloud/providers/kubernetes"
 	"github.com/evergreen-ci/evergreen/cloud/providers/mock"
 	"github.com/evergreen-ci/evergreen/cloud/providers/static"
 	"github.com/evergreen-ci/evergreen/model/host"
@@ -30,6 +31,8 @@ func GetCloudManager(providerName string, settings *evergreen.Settings) (cloud.C
 		provider = &ec2.EC2SpotManager{}
 	case docker.ProviderName:
 		provider = &docker.DockerManager{}
+	case kubernetes.ProviderName:
+		provider = &kubernetes.KubernetesManager{}
 	default:
 		return nil, fmt.Errorf("No known provider for '%v'", providerName)
 	}
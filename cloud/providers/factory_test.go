@@ -4,6 +4,7 @@ import (
 	"github.com/evergreen-ci/evergreen"
 	"github.com/evergreen-ci/evergreen/cloud/providers/digitalocean"
 	"github.com/evergreen-ci/evergreen/cloud/providers/ec2"
+	"github.com/evergreen-ci/evergreen/cloud/providers/kubernetes"
 	"github.com/evergreen-ci/evergreen/cloud/providers/mock"
 	"github.com/evergreen-ci/evergreen/cloud/providers/static"
 	"github.com/evergreen-ci/evergreen/model/host"
@@ -49,6 +50,13 @@ func TestGetCloudManager(t *testing.T) {
 			So(cloudMgr, ShouldHaveSameTypeAs, &digitalocean.DigitalOceanManager{})
 		})
 
+		Convey("Kubernetes should be returned for kubernetes provider name", func() {
+			cloudMgr, err := GetCloudManager("kubernetes", evergreen.TestConfig())
+			So(cloudMgr, ShouldNotBeNil)
+			So(err, ShouldBeNil)
+			So(cloudMgr, ShouldHaveSameTypeAs, &kubernetes.KubernetesManager{})
+		})
+
 		Convey("Invalid provider names should return nil with err", func() {
 			cloudMgr, err := GetCloudManager("bogus", evergreen.TestConfig())
 			So(cloudMgr, ShouldBeNil)
@@ -0,0 +1,162 @@
+package kubernetes
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"github.com/evergreen-ci/evergreen"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Pod phases reported by the API server.
+// See http://kubernetes.io/docs/user-guide/pod-states/
+const (
+	PodPhasePending   = "Pending"
+	PodPhaseRunning   = "Running"
+	PodPhaseSucceeded = "Succeeded"
+	PodPhaseFailed    = "Failed"
+	PodPhaseUnknown   = "Unknown"
+
+	apiTimeout = 30 * time.Second
+)
+
+// ErrPodNotFound is returned when the API server has no record of a pod,
+// which happens once a deleted pod is gone.
+var ErrPodNotFound = fmt.Errorf("pod not found")
+
+// pod holds the parts of a pod resource the provider reads.
+type pod struct {
+	Metadata podMetadata `json:"metadata"`
+	Status   podStatus   `json:"status"`
+}
+
+type podMetadata struct {
+	Name              string            `json:"name"`
+	Namespace         string            `json:"namespace"`
+	Labels            map[string]string `json:"labels,omitempty"`
+	CreationTimestamp time.Time         `json:"creationTimestamp"`
+	// DeletionTimestamp is set once the pod has been deleted, while its
+	// containers are shutting down
+	DeletionTimestamp *time.Time `json:"deletionTimestamp,omitempty"`
+}
+
+type podStatus struct {
+	Phase   string `json:"phase"`
+	PodIP   string `json:"podIP"`
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+}
+
+// apiStatus is the body of an error response from the API server.
+type apiStatus struct {
+	Message string `json:"message"`
+	Reason  string `json:"reason"`
+}
+
+// client makes requests to the pod endpoints of the Kubernetes API.
+type client struct {
+	apiServer  string
+	token      string
+	httpClient *http.Client
+}
+
+// newClient returns a client for the API server in the settings.
+func newClient(config evergreen.KubernetesConfig) (*client, error) {
+	if config.APIServer == "" {
+		return nil, fmt.Errorf("Kubernetes API server must not be blank")
+	}
+	transport := &http.Transport{Proxy: http.ProxyFromEnvironment}
+	if config.CACert != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(config.CACert)) {
+			return nil, fmt.Errorf("Kubernetes CA certificate is not valid PEM")
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	}
+	return &client{
+		apiServer:  strings.TrimRight(config.APIServer, "/"),
+		token:      config.Token,
+		httpClient: &http.Client{Transport: transport, Timeout: apiTimeout},
+	}, nil
+}
+
+func (c *client) podsURL(namespace string) string {
+	return fmt.Sprintf("%v/api/v1/namespaces/%v/pods", c.apiServer, namespace)
+}
+
+// do sends the request, decoding the response into result if it's non-nil.
+func (c *client) do(method, url string, body interface{}, result interface{}) error {
+	var reqBody []byte
+	if body != nil {
+		var err error
+		if reqBody, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequest(method, url, bytes.NewReader(reqBody))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrPodNotFound
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		status := apiStatus{}
+		if err := json.Unmarshal(respBody, &status); err != nil || status.Message == "" {
+			return fmt.Errorf("%v %v returned %v: %v", method, url, resp.Status, string(respBody))
+		}
+		return fmt.Errorf("%v %v returned %v: %v", method, url, resp.Status, status.Message)
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(respBody, result)
+}
+
+// createPod creates a pod from the spec in the namespace.
+func (c *client) createPod(namespace string, spec map[string]interface{}) (*pod, error) {
+	created := &pod{}
+	if err := c.do("POST", c.podsURL(namespace), spec, created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// getPod returns the pod, or ErrPodNotFound if it doesn't exist.
+func (c *client) getPod(namespace, name string) (*pod, error) {
+	p := &pod{}
+	if err := c.do("GET", c.podsURL(namespace)+"/"+name, nil, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// deletePod deletes the pod. Deleting a pod that doesn't exist is not an error.
+func (c *client) deletePod(namespace, name string) error {
+	err := c.do("DELETE", c.podsURL(namespace)+"/"+name, nil, nil)
+	if err == ErrPodNotFound {
+		return nil
+	}
+	return err
+}
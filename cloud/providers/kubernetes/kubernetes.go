@@ -0,0 +1,404 @@
+package kubernetes
+
+import (
+	"fmt"
+	"github.com/10gen-labs/slogger/v1"
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/cloud"
+	"github.com/evergreen-ci/evergreen/db/bsonutil"
+	"github.com/evergreen-ci/evergreen/hostutil"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/distro"
+	"github.com/evergreen-ci/evergreen/model/host"
+	"github.com/mitchellh/mapstructure"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/yaml.v2"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	ProviderName = "kubernetes"
+
+	DefaultNamespace = "default"
+	DefaultSSHPort   = 22
+
+	// SSHSidecarName is the name of the container added to pods to run the
+	// ssh server when the distro has an ssh sidecar image
+	SSHSidecarName = "evergreen-ssh"
+
+	// HostLabel marks the pods started by Evergreen
+	HostLabel = "evergreen.host"
+	// DistroAnnotation records the distro a pod was started for
+	DistroAnnotation = "evergreen.distro"
+
+	// pod names can be at most 253 characters, so long distro ids are cut short
+	maxDistroNameLength = 40
+)
+
+// KubernetesManager implements the CloudManager interface for pods started
+// through the Kubernetes API. The pods are reached over ssh, either from a
+// server running in the template's own container or from a sidecar.
+type KubernetesManager struct {
+	client *client
+}
+
+// Settings are the distro's provider settings.
+type Settings struct {
+	// Namespace the pods are created in. Defaults to "default".
+	Namespace string `mapstructure:"namespace" json:"namespace,omitempty" bson:"namespace,omitempty"`
+	// PodTemplate is the pod manifest, in YAML or JSON, the distro's pods are
+	// created from. Its name is replaced with a generated one.
+	PodTemplate string `mapstructure:"pod_template" json:"pod_template" bson:"pod_template"`
+	// SSHPort is the port the pod's ssh server listens on. Defaults to 22.
+	SSHPort int `mapstructure:"ssh_port" json:"ssh_port,omitempty" bson:"ssh_port,omitempty"`
+	// SSHSidecarImage, if set, is run in a container alongside the template's
+	// containers to serve ssh, for templates whose images don't run sshd
+	SSHSidecarImage string `mapstructure:"ssh_sidecar_image" json:"ssh_sidecar_image,omitempty" bson:"ssh_sidecar_image,omitempty"`
+}
+
+var (
+	// bson fields for the Settings struct
+	NamespaceKey       = bsonutil.MustHaveTag(Settings{}, "Namespace")
+	PodTemplateKey     = bsonutil.MustHaveTag(Settings{}, "PodTemplate")
+	SSHPortKey         = bsonutil.MustHaveTag(Settings{}, "SSHPort")
+	SSHSidecarImageKey = bsonutil.MustHaveTag(Settings{}, "SSHSidecarImage")
+
+	// characters that can't appear in pod names
+	invalidNameChars = regexp.MustCompile("[^a-z0-9-]+")
+)
+
+// Validate checks that the settings from the config file are sane.
+func (settings *Settings) Validate() error {
+	if settings.PodTemplate == "" {
+		return fmt.Errorf("Pod template must not be blank")
+	}
+	if settings.SSHPort < 0 || settings.SSHPort > 65535 {
+		return fmt.Errorf("SSH port must be between 0 and 65535")
+	}
+	if _, err := settings.podSpec("evg-validate", "validate"); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (settings *Settings) namespace() string {
+	if settings.Namespace == "" {
+		return DefaultNamespace
+	}
+	return settings.Namespace
+}
+
+func (settings *Settings) sshPort() int {
+	if settings.SSHPort == 0 {
+		return DefaultSSHPort
+	}
+	return settings.SSHPort
+}
+
+// podSpec builds the manifest of a pod from the template, named and labeled
+// so that it can be found again, that isn't restarted when its containers exit.
+func (settings *Settings) podSpec(name, distroId string) (map[string]interface{}, error) {
+	var template interface{}
+	if err := yaml.Unmarshal([]byte(settings.PodTemplate), &template); err != nil {
+		return nil, fmt.Errorf("Pod template is not valid YAML: %v", err)
+	}
+	spec, ok := jsonCompatible(template).(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("Pod template must be a pod manifest")
+	}
+	spec["apiVersion"] = "v1"
+	spec["kind"] = "Pod"
+
+	metadata := childMap(spec, "metadata")
+	metadata["name"] = name
+	metadata["namespace"] = settings.namespace()
+	delete(metadata, "generateName")
+	childMap(metadata, "labels")[HostLabel] = "true"
+	childMap(metadata, "annotations")[DistroAnnotation] = distroId
+
+	podSpec := childMap(spec, "spec")
+	containers, ok := podSpec["containers"].([]interface{})
+	if !ok || len(containers) == 0 {
+		return nil, fmt.Errorf("Pod template must have at least one container")
+	}
+	if _, ok := podSpec["restartPolicy"]; !ok {
+		podSpec["restartPolicy"] = "Never"
+	}
+	if settings.SSHSidecarImage != "" {
+		podSpec["containers"] = append(containers, map[string]interface{}{
+			"name":  SSHSidecarName,
+			"image": settings.SSHSidecarImage,
+			"ports": []interface{}{
+				map[string]interface{}{"containerPort": settings.sshPort()},
+			},
+		})
+	}
+	return spec, nil
+}
+
+// childMap returns the map stored under the key, adding an empty one if
+// there isn't one.
+func childMap(parent map[string]interface{}, key string) map[string]interface{} {
+	child, ok := parent[key].(map[string]interface{})
+	if !ok {
+		child = map[string]interface{}{}
+		parent[key] = child
+	}
+	return child
+}
+
+// jsonCompatible converts the maps decoded from YAML, which can have keys of
+// any type, into maps with string keys that can be encoded as JSON.
+func jsonCompatible(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		converted := map[string]interface{}{}
+		for key, child := range v {
+			converted[fmt.Sprintf("%v", key)] = jsonCompatible(child)
+		}
+		return converted
+	case []interface{}:
+		for i, child := range v {
+			v[i] = jsonCompatible(child)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// generateName returns a name for a new pod of the distro. Pod names may
+// only contain lowercase letters, numbers and dashes.
+func generateName(distroId string) string {
+	name := strings.Trim(invalidNameChars.ReplaceAllString(strings.ToLower(distroId), "-"), "-")
+	if len(name) > maxDistroNameLength {
+		name = strings.TrimRight(name[:maxDistroNameLength], "-")
+	}
+	return fmt.Sprintf("evg-%v-%v", name, bson.NewObjectId().Hex())
+}
+
+// decodeSettings decodes and validates the distro's provider settings.
+func decodeSettings(d *distro.Distro) (*Settings, error) {
+	settings := &Settings{}
+	if err := mapstructure.Decode(d.ProviderSettings, settings); err != nil {
+		return nil, fmt.Errorf("Error decoding params for distro %v: %v", d.Id, err)
+	}
+	if err := settings.Validate(); err != nil {
+		return nil, fmt.Errorf("Invalid Kubernetes settings in distro %v: %v", d.Id, err)
+	}
+	return settings, nil
+}
+
+func (_ *KubernetesManager) GetSettings() cloud.ProviderSettings {
+	return &Settings{}
+}
+
+// Configure loads the API server's address and credentials from the config
+// object.
+func (kubeMgr *KubernetesManager) Configure(settings *evergreen.Settings) error {
+	c, err := newClient(settings.Providers.Kubernetes)
+	if err != nil {
+		return err
+	}
+	kubeMgr.client = c
+	return nil
+}
+
+// SpawnInstance creates a pod from the distro's template.
+func (kubeMgr *KubernetesManager) SpawnInstance(d *distro.Distro, owner string, userHost bool) (*host.Host, error) {
+	if d.Provider != ProviderName {
+		return nil, fmt.Errorf("Can't spawn instance of %v for distro %v: provider is %v", ProviderName, d.Id, d.Provider)
+	}
+
+	settings, err := decodeSettings(d)
+	if err != nil {
+		return nil, err
+	}
+
+	name := generateName(d.Id)
+	spec, err := settings.podSpec(name, d.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	// record the host before creating the pod, so that a pod is never
+	// created without a host to clean it up
+	intentHost := &host.Host{
+		Id:               name,
+		User:             d.User,
+		Distro:           *d,
+		Tag:              name,
+		CreationTime:     time.Now(),
+		Status:           evergreen.HostUninitialized,
+		TerminationTime:  model.ZeroTime,
+		TaskDispatchTime: model.ZeroTime,
+		Provider:         ProviderName,
+		StartedBy:        owner,
+		UserHost:         userHost,
+	}
+	if err := intentHost.Insert(); err != nil {
+		return nil, evergreen.Logger.Errorf(slogger.ERROR, "Could not insert intent "+
+			"host '%v': %v", intentHost.Id, err)
+	}
+
+	if _, err := kubeMgr.client.createPod(settings.namespace(), spec); err != nil {
+		if rmErr := intentHost.Remove(); rmErr != nil {
+			evergreen.Logger.Logf(slogger.ERROR, "Failed to remove intent host %v: %v", intentHost.Id, rmErr)
+		}
+		return nil, evergreen.Logger.Errorf(slogger.ERROR, "Failed to create pod for distro '%v': %v",
+			d.Id, err)
+	}
+
+	evergreen.Logger.Logf(slogger.DEBUG, "Created pod '%v' in namespace '%v' for distro '%v'",
+		name, settings.namespace(), d.Id)
+	return intentHost, nil
+}
+
+// GetInstanceStatus returns a universal status code for the phase of the
+// host's pod. A pod that has been deleted is reported as terminated.
+func (kubeMgr *KubernetesManager) GetInstanceStatus(h *host.Host) (cloud.CloudStatus, error) {
+	settings, err := decodeSettings(&h.Distro)
+	if err != nil {
+		return cloud.StatusUnknown, err
+	}
+	p, err := kubeMgr.client.getPod(settings.namespace(), h.Id)
+	if err == ErrPodNotFound {
+		return cloud.StatusTerminated, nil
+	}
+	if err != nil {
+		return cloud.StatusUnknown, fmt.Errorf("Failed to get pod for host '%v': %v", h.Id, err)
+	}
+	return podStatusToEvergreenStatus(p), nil
+}
+
+func podStatusToEvergreenStatus(p *pod) cloud.CloudStatus {
+	if p.Metadata.DeletionTimestamp != nil {
+		return cloud.StatusTerminated
+	}
+	switch p.Status.Phase {
+	case PodPhasePending:
+		// the pod may still be waiting to be scheduled or pulling images
+		return cloud.StatusPending
+	case PodPhaseRunning:
+		return cloud.StatusRunning
+	case PodPhaseSucceeded:
+		return cloud.StatusTerminated
+	case PodPhaseFailed:
+		return cloud.StatusFailed
+	default:
+		return cloud.StatusUnknown
+	}
+}
+
+// GetDNSName returns the address of the pod's ssh server, which is only
+// known once the pod has been scheduled.
+func (kubeMgr *KubernetesManager) GetDNSName(h *host.Host) (string, error) {
+	settings, err := decodeSettings(&h.Distro)
+	if err != nil {
+		return "", err
+	}
+	p, err := kubeMgr.client.getPod(settings.namespace(), h.Id)
+	if err != nil {
+		return "", fmt.Errorf("Failed to get pod for host '%v': %v", h.Id, err)
+	}
+	if p.Status.PodIP == "" {
+		return "", nil
+	}
+	return fmt.Sprintf("%v:%v", p.Status.PodIP, settings.sshPort()), nil
+}
+
+// CanSpawn returns if a given cloud provider supports spawning a new host
+// dynamically. Always returns true for Kubernetes.
+func (kubeMgr *KubernetesManager) CanSpawn() (bool, error) {
+	return true, nil
+}
+
+// TerminateInstance deletes the host's pod.
+func (kubeMgr *KubernetesManager) TerminateInstance(h *host.Host) error {
+	if h.Status == evergreen.HostTerminated {
+		errMsg := fmt.Errorf("Can not terminate %v - already marked as "+
+			"terminated!", h.Id)
+		evergreen.Logger.Errorf(slogger.ERROR, errMsg.Error())
+		return errMsg
+	}
+
+	settings, err := decodeSettings(&h.Distro)
+	if err != nil {
+		return err
+	}
+	if err := kubeMgr.client.deletePod(settings.namespace(), h.Id); err != nil {
+		return evergreen.Logger.Errorf(slogger.ERROR, "Failed to delete pod '%v': %v", h.Id, err)
+	}
+
+	return h.Terminate()
+}
+
+// IsSSHReachable checks if a pod appears to be reachable via SSH by
+// attempting to contact the host directly.
+func (kubeMgr *KubernetesManager) IsSSHReachable(h *host.Host, keyPath string) (bool, error) {
+	sshOpts, err := kubeMgr.GetSSHOptions(h, keyPath)
+	if err != nil {
+		return false, err
+	}
+	return hostutil.CheckSSHResponse(h, sshOpts)
+}
+
+// IsUp returns true if the pod is running.
+func (kubeMgr *KubernetesManager) IsUp(h *host.Host) (bool, error) {
+	cloudStatus, err := kubeMgr.GetInstanceStatus(h)
+	if err != nil {
+		return false, err
+	}
+	return cloudStatus == cloud.StatusRunning, nil
+}
+
+func (kubeMgr *KubernetesManager) OnUp(h *host.Host) error {
+	return nil
+}
+
+// GetSSHOptions returns an array of default SSH options for connecting to a
+// pod.
+func (kubeMgr *KubernetesManager) GetSSHOptions(h *host.Host, keyPath string) ([]string, error) {
+	if keyPath == "" {
+		return []string{}, fmt.Errorf("No key specified for Kubernetes host")
+	}
+
+	opts := []string{"-i", keyPath}
+	for _, opt := range h.Distro.SSHOptions {
+		opts = append(opts, "-o", opt)
+	}
+	return opts, nil
+}
+
+// TimeTilNextPayment returns the amount of time until the next payment is due
+// for the host. Pods are not paid for individually.
+func (kubeMgr *KubernetesManager) TimeTilNextPayment(h *host.Host) time.Duration {
+	return time.Duration(0)
+}
+
+// StopInstance is not supported for pods.
+func (kubeMgr *KubernetesManager) StopInstance(h *host.Host) error {
+	return cloud.ErrUnsupported
+}
+
+// StartInstance is not supported for pods.
+func (kubeMgr *KubernetesManager) StartInstance(h *host.Host) error {
+	return cloud.ErrUnsupported
+}
+
+// CreateVolume is not supported for pods.
+func (kubeMgr *KubernetesManager) CreateVolume(h *host.Host, size int) (*host.Volume, error) {
+	return nil, cloud.ErrUnsupported
+}
+
+// AttachVolume is not supported for pods.
+func (kubeMgr *KubernetesManager) AttachVolume(h *host.Host, volume *host.Volume) (string, error) {
+	return "", cloud.ErrUnsupported
+}
+
+// DeleteVolume is not supported for pods.
+func (kubeMgr *KubernetesManager) DeleteVolume(volume *host.Volume) error {
+	return cloud.ErrUnsupported
+}
@@ -0,0 +1,200 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/cloud"
+	"github.com/evergreen-ci/evergreen/model/distro"
+	"github.com/evergreen-ci/evergreen/model/host"
+	. "github.com/smartystreets/goconvey/convey"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const testTemplate = `
+metadata:
+  labels:
+    team: build
+spec:
+  containers:
+  - name: task
+    image: evergreen/task:latest
+`
+
+// fakeAPIServer stores the pods created through it in memory.
+type fakeAPIServer struct {
+	sync.Mutex
+	pods map[string]*pod
+}
+
+func (f *fakeAPIServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.Lock()
+	defer f.Unlock()
+	if r.Header.Get("Authorization") != "Bearer test-token" {
+		http.Error(w, `{"message": "unauthorized"}`, http.StatusUnauthorized)
+		return
+	}
+	prefix := "/api/v1/namespaces/test/pods"
+	if !strings.HasPrefix(r.URL.Path, prefix) {
+		http.NotFound(w, r)
+		return
+	}
+	name := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, prefix), "/")
+
+	switch r.Method {
+	case "POST":
+		p := &pod{}
+		if err := json.NewDecoder(r.Body).Decode(p); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		p.Status.Phase = PodPhasePending
+		f.pods[p.Metadata.Name] = p
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(p)
+	case "GET":
+		p, ok := f.pods[name]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(p)
+	case "DELETE":
+		if _, ok := f.pods[name]; !ok {
+			http.NotFound(w, r)
+			return
+		}
+		delete(f.pods, name)
+		w.Write([]byte("{}"))
+	}
+}
+
+func TestPodSpec(t *testing.T) {
+	Convey("With settings holding a pod template", t, func() {
+		settings := &Settings{PodTemplate: testTemplate}
+
+		Convey("the pod should be named and labeled, keeping the template's labels", func() {
+			spec, err := settings.podSpec("evg-pod", "ubuntu")
+			So(err, ShouldBeNil)
+			metadata := spec["metadata"].(map[string]interface{})
+			So(metadata["name"], ShouldEqual, "evg-pod")
+			So(metadata["namespace"], ShouldEqual, DefaultNamespace)
+			labels := metadata["labels"].(map[string]interface{})
+			So(labels["team"], ShouldEqual, "build")
+			So(labels[HostLabel], ShouldEqual, "true")
+			annotations := metadata["annotations"].(map[string]interface{})
+			So(annotations[DistroAnnotation], ShouldEqual, "ubuntu")
+			So(spec["spec"].(map[string]interface{})["restartPolicy"], ShouldEqual, "Never")
+
+			// the spec must be encodable as JSON
+			_, err = json.Marshal(spec)
+			So(err, ShouldBeNil)
+		})
+
+		Convey("an ssh sidecar should be added if configured", func() {
+			settings.SSHSidecarImage = "evergreen/sshd"
+			settings.SSHPort = 2222
+			spec, err := settings.podSpec("evg-pod", "ubuntu")
+			So(err, ShouldBeNil)
+			containers := spec["spec"].(map[string]interface{})["containers"].([]interface{})
+			So(len(containers), ShouldEqual, 2)
+			sidecar := containers[1].(map[string]interface{})
+			So(sidecar["name"], ShouldEqual, SSHSidecarName)
+			So(sidecar["image"], ShouldEqual, "evergreen/sshd")
+		})
+
+		Convey("templates without containers should be rejected", func() {
+			settings.PodTemplate = "spec: {}"
+			So(settings.Validate(), ShouldNotBeNil)
+		})
+
+		Convey("templates that aren't manifests should be rejected", func() {
+			settings.PodTemplate = "- not a pod"
+			So(settings.Validate(), ShouldNotBeNil)
+		})
+	})
+}
+
+func TestGenerateName(t *testing.T) {
+	Convey("Generated pod names should only contain valid characters", t, func() {
+		name := generateName("Ubuntu_14.04 (large)")
+		So(name, ShouldStartWith, "evg-ubuntu-14-04-large-")
+		So(invalidNameChars.MatchString(name), ShouldBeFalse)
+		So(len(generateName(strings.Repeat("a", 300))), ShouldBeLessThan, 100)
+	})
+}
+
+func TestPodLifecycle(t *testing.T) {
+	Convey("With a manager talking to a fake API server", t, func() {
+		fake := &fakeAPIServer{pods: map[string]*pod{}}
+		server := httptest.NewServer(fake)
+		Reset(func() {
+			server.Close()
+		})
+
+		kubeMgr := &KubernetesManager{}
+		settings := &evergreen.Settings{}
+		settings.Providers.Kubernetes = evergreen.KubernetesConfig{
+			APIServer: server.URL,
+			Token:     "test-token",
+		}
+		So(kubeMgr.Configure(settings), ShouldBeNil)
+
+		providerSettings := map[string]interface{}{
+			"namespace":    "test",
+			"pod_template": testTemplate,
+			"ssh_port":     2222,
+		}
+		h := &host.Host{
+			Id:     "evg-pod",
+			Distro: distro.Distro{Id: "ubuntu", Provider: ProviderName, ProviderSettings: &providerSettings},
+		}
+		podSettings, err := decodeSettings(&h.Distro)
+		So(err, ShouldBeNil)
+		spec, err := podSettings.podSpec(h.Id, h.Distro.Id)
+		So(err, ShouldBeNil)
+		_, err = kubeMgr.client.createPod("test", spec)
+		So(err, ShouldBeNil)
+
+		Convey("the host's status should follow the pod's phase", func() {
+			status, err := kubeMgr.GetInstanceStatus(h)
+			So(err, ShouldBeNil)
+			So(status, ShouldEqual, cloud.StatusPending)
+
+			fake.pods[h.Id].Status.Phase = PodPhaseRunning
+			fake.pods[h.Id].Status.PodIP = "10.0.0.5"
+			status, err = kubeMgr.GetInstanceStatus(h)
+			So(err, ShouldBeNil)
+			So(status, ShouldEqual, cloud.StatusRunning)
+
+			dnsName, err := kubeMgr.GetDNSName(h)
+			So(err, ShouldBeNil)
+			So(dnsName, ShouldEqual, "10.0.0.5:2222")
+
+			fake.pods[h.Id].Status.Phase = PodPhaseFailed
+			status, err = kubeMgr.GetInstanceStatus(h)
+			So(err, ShouldBeNil)
+			So(status, ShouldEqual, cloud.StatusFailed)
+		})
+
+		Convey("a deleted pod should be reported as terminated", func() {
+			So(kubeMgr.client.deletePod("test", h.Id), ShouldBeNil)
+			status, err := kubeMgr.GetInstanceStatus(h)
+			So(err, ShouldBeNil)
+			So(status, ShouldEqual, cloud.StatusTerminated)
+
+			// deleting it again should be harmless
+			So(kubeMgr.client.deletePod("test", h.Id), ShouldBeNil)
+		})
+
+		Convey("API errors should be returned with the server's message", func() {
+			kubeMgr.client.token = "wrong"
+			_, err := kubeMgr.GetInstanceStatus(h)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "unauthorized")
+		})
+	})
+}
@@ -13,11 +13,18 @@ const ProviderName = "mock"
 
 type MockCloudManager struct{}
 
+// InterruptedHosts holds the ids of the hosts whose instances the mock
+// provider reports as interrupted.
+var InterruptedHosts = map[string]bool{}
+
 func (staticMgr *MockCloudManager) SpawnInstance(distro *distro.Distro, owner string, userHost bool) (*host.Host, error) {
 	return &host.Host{
 		Id:        util.RandomString(),
+		Host:      "localhost",
 		Distro:    *distro,
+		Provider:  ProviderName,
 		StartedBy: owner,
+		UserHost:  userHost,
 	}, nil
 }
 
@@ -26,6 +33,11 @@ func (staticMgr *MockCloudManager) GetInstanceStatus(host *host.Host) (cloud.Clo
 	return cloud.StatusRunning, nil
 }
 
+// IsInterrupted returns true if the host is in InterruptedHosts.
+func (staticMgr *MockCloudManager) IsInterrupted(host *host.Host) (bool, error) {
+	return InterruptedHosts[host.Id], nil
+}
+
 // get instance DNS
 func (staticMgr *MockCloudManager) GetDNSName(host *host.Host) (string, error) {
 	return host.Host, nil
@@ -48,6 +60,30 @@ func (staticMgr *MockCloudManager) TerminateInstance(host *host.Host) error {
 	return nil
 }
 
+func (staticMgr *MockCloudManager) StopInstance(host *host.Host) error {
+	return nil
+}
+
+func (staticMgr *MockCloudManager) StartInstance(host *host.Host) error {
+	return nil
+}
+
+func (staticMgr *MockCloudManager) CreateVolume(h *host.Host, size int) (*host.Volume, error) {
+	return &host.Volume{
+		ProviderId: util.RandomString(),
+		Provider:   ProviderName,
+		Size:       size,
+	}, nil
+}
+
+func (staticMgr *MockCloudManager) AttachVolume(h *host.Host, volume *host.Volume) (string, error) {
+	return "/dev/mock", nil
+}
+
+func (staticMgr *MockCloudManager) DeleteVolume(volume *host.Volume) error {
+	return nil
+}
+
 func (staticMgr *MockCloudManager) Configure(settings *evergreen.Settings) error {
 	//no-op. maybe will need to load something from settings in the future.
 	return nil
@@ -117,3 +117,28 @@ func (staticMgr *StaticManager) GetSSHOptions(h *host.Host, keyPath string) (opt
 func (staticMgr *StaticManager) TimeTilNextPayment(host *host.Host) time.Duration {
 	return time.Duration(0)
 }
+
+// StopInstance is not supported for static hosts.
+func (staticMgr *StaticManager) StopInstance(h *host.Host) error {
+	return cloud.ErrUnsupported
+}
+
+// StartInstance is not supported for static hosts.
+func (staticMgr *StaticManager) StartInstance(h *host.Host) error {
+	return cloud.ErrUnsupported
+}
+
+// CreateVolume is not supported for static hosts.
+func (staticMgr *StaticManager) CreateVolume(h *host.Host, size int) (*host.Volume, error) {
+	return nil, cloud.ErrUnsupported
+}
+
+// AttachVolume is not supported for static hosts.
+func (staticMgr *StaticManager) AttachVolume(h *host.Host, volume *host.Volume) (string, error) {
+	return "", cloud.ErrUnsupported
+}
+
+// DeleteVolume is not supported for static hosts.
+func (staticMgr *StaticManager) DeleteVolume(volume *host.Volume) error {
+	return cloud.ErrUnsupported
+}
@@ -0,0 +1,135 @@
+package cloud
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/command"
+	"github.com/evergreen-ci/evergreen/model/host"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// UserDataManager is implemented by CloudManagers that can give the
+// instances they start a script to run on boot, which is how hosts of
+// distros provisioned with user data are set up.
+type UserDataManager interface {
+	// SupportsUserData is true if the manager starts its hosts with the
+	// script returned by BootstrapScript.
+	SupportsUserData() bool
+}
+
+// the delimiter of the heredoc holding the distro's setup script
+const setupScriptDelimiter = "EVERGREEN_SETUP_SCRIPT"
+
+// the name of the agent's log files, as when it's started over SSH
+const agentLogPrefix = "agent"
+
+var bootstrapTemplate = template.Must(template.New("bootstrap").Parse(`#!/bin/sh
+# Sets up an Evergreen host of distro {{.DistroId}} and reports back to the API server.
+api_url={{.ApiUrl}}
+host_tag={{.Tag}}
+host_secret={{.Secret}}
+
+report() {
+  curl --silent --show-error --retry 5 -X POST -H "{{.SecretHeader}}: $host_secret" "$@"
+}
+
+fail() {
+  rm -f /tmp/evergreen_setup.sh
+  report --data-binary @/tmp/evergreen_setup.log "$api_url/api/2/host/$host_tag/ready/{{.Failed}}"
+  exit 1
+}
+
+cat > /tmp/evergreen_setup.sh <<'{{.Delimiter}}'
+{{.Setup}}
+{{.Delimiter}}
+chmod 755 /tmp/evergreen_setup.sh
+touch /tmp/evergreen_setup.log
+chmod 666 /tmp/evergreen_setup.log
+
+{{.RunSetup}} >> /tmp/evergreen_setup.log 2>&1 || fail
+rm -f /tmp/evergreen_setup.sh
+{{if .PullAgent}}
+mkdir -m 777 -p {{.WorkDir}}
+curl --silent --show-error --fail --retry 5 -H "{{.SecretHeader}}: $host_secret" \
+  -o {{.AgentPath}} "$api_url/api/2/host/$host_tag/agent" >> /tmp/evergreen_setup.log 2>&1 || fail
+chmod 755 {{.AgentPath}}
+{{.StartAgent}} >> /tmp/evergreen_setup.log 2>&1 || fail
+{{end}}
+report "$api_url/api/2/host/$host_tag/ready/{{.Success}}"
+`))
+
+// BootstrapScript returns the user data for hosts of distros provisioned with
+// user data. When the host boots it runs the distro's setup script, as the
+// distro's user unless the setup runs as sudo, then downloads and starts the
+// pull agent if the distro runs one, and finally reports to the API server
+// whether it succeeded. The host is identified to the API server by its tag,
+// which unlike its id is known before the instance is started, and it
+// authenticates with its secret.
+//
+// The user data, so the host's secret and the distro's setup script with its
+// expansions filled in, can be read from the instance metadata service by any
+// process on the host, including tasks. Distros whose setup needs expansions
+// that tasks mustn't see shouldn't be provisioned with user data. The setup
+// script written out of the user data is removed once it has run.
+func BootstrapScript(settings *evergreen.Settings, h *host.Host) (string, error) {
+	if h.Secret == "" {
+		return "", fmt.Errorf("host %v has no secret to report back with", h.Id)
+	}
+
+	exp := command.NewExpansions(settings.Expansions)
+	setup, err := exp.ExpandString(h.Distro.Setup)
+	if err != nil {
+		return "", fmt.Errorf("expansions error: %v", err)
+	}
+	if strings.Contains(setup, setupScriptDelimiter) {
+		return "", fmt.Errorf("setup script of distro %v can't contain '%v'",
+			h.Distro.Id, setupScriptDelimiter)
+	}
+
+	runSetup := "sh /tmp/evergreen_setup.sh"
+	if !h.Distro.SetupAsSudo {
+		runSetup = asUser(h.Distro.User, runSetup)
+	}
+
+	agentPath := filepath.Join(h.Distro.WorkDir, "main")
+	startAgent := asUser(h.Distro.User, fmt.Sprintf(
+		`nohup %v -api_server %v -host_id %v -host_secret %v -log_prefix %v -https_cert %v > /dev/null 2>&1 &`,
+		shellQuote(agentPath), shellQuote(settings.ApiUrl), shellQuote(h.Tag), shellQuote(h.Secret),
+		shellQuote(filepath.Join(h.Distro.WorkDir, agentLogPrefix)),
+		shellQuote(settings.Expansions["api_httpscert_path"])))
+
+	script := &bytes.Buffer{}
+	err = bootstrapTemplate.Execute(script, map[string]interface{}{
+		"DistroId":     h.Distro.Id,
+		"ApiUrl":       shellQuote(settings.ApiUrl),
+		"Tag":          shellQuote(h.Tag),
+		"Secret":       shellQuote(h.Secret),
+		"SecretHeader": evergreen.HostSecretHeader,
+		"Failed":       evergreen.HostStatusFailed,
+		"Success":      evergreen.HostStatusSuccess,
+		"Delimiter":    setupScriptDelimiter,
+		"Setup":        setup,
+		"RunSetup":     runSetup,
+		"PullAgent":    h.Distro.PullAgent,
+		"WorkDir":      shellQuote(h.Distro.WorkDir),
+		"AgentPath":    shellQuote(agentPath),
+		"StartAgent":   startAgent,
+	})
+	if err != nil {
+		return "", fmt.Errorf("error building user data for host %v: %v", h.Id, err)
+	}
+	return script.String(), nil
+}
+
+// asUser returns the command, run by the user through a login shell.
+func asUser(user, cmd string) string {
+	return fmt.Sprintf("su - %v -c %v", shellQuote(user), shellQuote(cmd))
+}
+
+// shellQuote quotes the string as a single word for sh.
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
@@ -0,0 +1,82 @@
+package cloud
+
+import (
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model/distro"
+	"github.com/evergreen-ci/evergreen/model/host"
+	. "github.com/smartystreets/goconvey/convey"
+	"strings"
+	"testing"
+)
+
+func TestBootstrapScript(t *testing.T) {
+	settings := &evergreen.Settings{
+		ApiUrl:     "https://evergreen.example.com",
+		Expansions: map[string]string{"greeting": "hello"},
+	}
+
+	Convey("With a host of a distro provisioned with user data", t, func() {
+		h := &host.Host{
+			Id:     "sir-1234",
+			Tag:    "evg_d1_1",
+			Secret: "s3cret",
+			Distro: distro.Distro{
+				Id:                    "d1",
+				User:                  "ubuntu",
+				WorkDir:               "/data/mci",
+				Setup:                 "echo ${greeting}",
+				ProvisionWithUserData: true,
+			},
+		}
+
+		Convey("the script should run the expanded setup as the distro's user and report back with the tag", func() {
+			script, err := BootstrapScript(settings, h)
+			So(err, ShouldBeNil)
+			So(script, ShouldStartWith, "#!/bin/sh\n")
+			So(script, ShouldContainSubstring, "\necho hello\n")
+			So(script, ShouldContainSubstring, `su - 'ubuntu' -c 'sh /tmp/evergreen_setup.sh'`)
+			So(script, ShouldContainSubstring, "host_tag='evg_d1_1'")
+			So(script, ShouldContainSubstring, "host_secret='s3cret'")
+			So(script, ShouldContainSubstring, "Host-Secret: $host_secret")
+			So(script, ShouldContainSubstring, "/ready/failed")
+			So(script, ShouldContainSubstring, "/ready/success")
+			So(script, ShouldNotContainSubstring, "/agent")
+			So(script, ShouldContainSubstring, "\nrm -f /tmp/evergreen_setup.sh\n")
+		})
+
+		Convey("a setup run as sudo should run as root", func() {
+			h.Distro.SetupAsSudo = true
+			script, err := BootstrapScript(settings, h)
+			So(err, ShouldBeNil)
+			So(script, ShouldContainSubstring, "\nsh /tmp/evergreen_setup.sh >>")
+		})
+
+		Convey("hosts running a pull agent should download and start it", func() {
+			h.Distro.PullAgent = true
+			script, err := BootstrapScript(settings, h)
+			So(err, ShouldBeNil)
+			So(script, ShouldContainSubstring, `-o '/data/mci/main' "$api_url/api/2/host/$host_tag/agent"`)
+			So(script, ShouldContainSubstring, `-host_id '\''evg_d1_1'\''`)
+			So(strings.Index(script, "/agent"), ShouldBeLessThan, strings.Index(script, "/ready/success"))
+		})
+
+		Convey("a host without a secret should be an error", func() {
+			h.Secret = ""
+			_, err := BootstrapScript(settings, h)
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("a setup script containing the heredoc delimiter should be an error", func() {
+			h.Distro.Setup = "echo " + setupScriptDelimiter
+			_, err := BootstrapScript(settings, h)
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestShellQuote(t *testing.T) {
+	Convey("Strings should be quoted as one word", t, func() {
+		So(shellQuote("a b"), ShouldEqual, `'a b'`)
+		So(shellQuote("it's"), ShouldEqual, `'it'\''s'`)
+	})
+}
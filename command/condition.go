@@ -0,0 +1,205 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Conditions decide whether a command runs. A condition is made up of
+// operands compared with == and !=, and combined with !, && and || and
+// parentheses. An operand is either the name of an expansion, which stands
+// for its value, or a quoted string, which is itself expanded:
+//
+//	is_patch && build_variant != 'windows'
+//	!(${branch_name} == "master" || skip_lint)
+//
+// An operand on its own is true if its value is neither empty nor "false".
+
+// EvalCondition evaluates the condition against the expansions.
+func (self *Expansions) EvalCondition(condition string) (bool, error) {
+	tokens, err := tokenizeCondition(condition)
+	if err != nil {
+		return false, err
+	}
+	parser := &conditionParser{tokens: tokens, expansions: self}
+	result, err := parser.parseOr()
+	if err != nil {
+		return false, fmt.Errorf("invalid condition \"%v\": %v", condition, err)
+	}
+	if parser.pos != len(tokens) {
+		return false, fmt.Errorf("invalid condition \"%v\": unexpected '%v'",
+			condition, tokens[parser.pos].text)
+	}
+	return result, nil
+}
+
+// ValidateCondition checks that the condition is well formed.
+func ValidateCondition(condition string) error {
+	_, err := NewExpansions(map[string]string{}).EvalCondition(condition)
+	return err
+}
+
+// isTrue returns the truth value of an operand on its own.
+func isTrue(value string) bool {
+	return value != "" && value != "false"
+}
+
+const (
+	tokenOperator = iota
+	tokenName
+	tokenString
+)
+
+type conditionToken struct {
+	kind int
+	text string
+}
+
+// tokenizeCondition splits a condition into operators, expansion names and
+// strings. Quoted strings are unquoted, and ${...} expansions are kept as
+// strings to be expanded.
+func tokenizeCondition(condition string) ([]conditionToken, error) {
+	tokens := []conditionToken{}
+	for i := 0; i < len(condition); {
+		c := condition[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case c == '(' || c == ')':
+			tokens = append(tokens, conditionToken{tokenOperator, string(c)})
+			i++
+		case strings.HasPrefix(condition[i:], "&&"), strings.HasPrefix(condition[i:], "||"),
+			strings.HasPrefix(condition[i:], "=="), strings.HasPrefix(condition[i:], "!="):
+			tokens = append(tokens, conditionToken{tokenOperator, condition[i : i+2]})
+			i += 2
+		case c == '!':
+			tokens = append(tokens, conditionToken{tokenOperator, "!"})
+			i++
+		case c == '\'' || c == '"':
+			end := strings.IndexByte(condition[i+1:], c)
+			if end == -1 {
+				return nil, fmt.Errorf("invalid condition \"%v\": unterminated string", condition)
+			}
+			tokens = append(tokens, conditionToken{tokenString, condition[i+1 : i+1+end]})
+			i += end + 2
+		case strings.HasPrefix(condition[i:], "${"):
+			end := strings.IndexByte(condition[i:], '}')
+			if end == -1 {
+				return nil, fmt.Errorf("invalid condition \"%v\": unclosed expansion", condition)
+			}
+			tokens = append(tokens, conditionToken{tokenString, condition[i : i+end+1]})
+			i += end + 1
+		case isNameChar(rune(c)):
+			start := i
+			for i < len(condition) && isNameChar(rune(condition[i])) {
+				i++
+			}
+			tokens = append(tokens, conditionToken{tokenName, condition[start:i]})
+		default:
+			return nil, fmt.Errorf("invalid condition \"%v\": unexpected '%c'", condition, c)
+		}
+	}
+	return tokens, nil
+}
+
+func isNameChar(c rune) bool {
+	return unicode.IsLetter(c) || unicode.IsDigit(c) || c == '_' || c == '.' || c == '-'
+}
+
+// conditionParser evaluates a tokenized condition by recursive descent.
+type conditionParser struct {
+	tokens     []conditionToken
+	pos        int
+	expansions *Expansions
+}
+
+// accept consumes the next token if it is the given operator.
+func (p *conditionParser) accept(operator string) bool {
+	if p.pos < len(p.tokens) && p.tokens[p.pos].kind == tokenOperator &&
+		p.tokens[p.pos].text == operator {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *conditionParser) parseOr() (bool, error) {
+	result, err := p.parseAnd()
+	if err != nil {
+		return false, err
+	}
+	for p.accept("||") {
+		next, err := p.parseAnd()
+		if err != nil {
+			return false, err
+		}
+		result = result || next
+	}
+	return result, nil
+}
+
+func (p *conditionParser) parseAnd() (bool, error) {
+	result, err := p.parseUnary()
+	if err != nil {
+		return false, err
+	}
+	for p.accept("&&") {
+		next, err := p.parseUnary()
+		if err != nil {
+			return false, err
+		}
+		result = result && next
+	}
+	return result, nil
+}
+
+func (p *conditionParser) parseUnary() (bool, error) {
+	if p.accept("!") {
+		result, err := p.parseUnary()
+		return !result, err
+	}
+	if p.accept("(") {
+		result, err := p.parseOr()
+		if err != nil {
+			return false, err
+		}
+		if !p.accept(")") {
+			return false, fmt.Errorf("missing ')'")
+		}
+		return result, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *conditionParser) parseComparison() (bool, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return false, err
+	}
+	switch {
+	case p.accept("=="):
+		right, err := p.parseOperand()
+		return left == right, err
+	case p.accept("!="):
+		right, err := p.parseOperand()
+		return left != right, err
+	}
+	return isTrue(left), nil
+}
+
+func (p *conditionParser) parseOperand() (string, error) {
+	if p.pos >= len(p.tokens) {
+		return "", fmt.Errorf("unexpected end of condition")
+	}
+	token := p.tokens[p.pos]
+	switch token.kind {
+	case tokenName:
+		p.pos++
+		return p.expansions.Get(token.text), nil
+	case tokenString:
+		p.pos++
+		return p.expansions.ExpandString(token.text)
+	}
+	return "", fmt.Errorf("unexpected '%v'", token.text)
+}
@@ -0,0 +1,73 @@
+package command
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestEvalCondition(t *testing.T) {
+
+	Convey("When evaluating conditions against expansions", t, func() {
+
+		expansions := NewExpansions(map[string]string{
+			"is_patch":      "true",
+			"build_variant": "linux-64",
+			"skip_lint":     "false",
+			"empty":         "",
+		})
+
+		Convey("a lone operand should be true if it is non-empty and not"+
+			" 'false'", func() {
+			for cond, expected := range map[string]bool{
+				"is_patch":       true,
+				"skip_lint":      false,
+				"empty":          false,
+				"missing":        false,
+				"${is_patch}":    true,
+				"'${missing}'":   false,
+				"'literal'":      true,
+				"${missing|yes}": true,
+			} {
+				result, err := expansions.EvalCondition(cond)
+				So(err, ShouldBeNil)
+				So(result, ShouldEqual, expected)
+			}
+		})
+
+		Convey("comparisons, boolean operators and parentheses should be"+
+			" evaluated", func() {
+			for cond, expected := range map[string]bool{
+				"build_variant == 'linux-64'":                     true,
+				"build_variant != \"linux-64\"":                   false,
+				"'${build_variant}-x' == 'linux-64-x'":            true,
+				"is_patch && build_variant == 'windows'":          false,
+				"is_patch || build_variant == 'windows'":          true,
+				"!is_patch":                                       false,
+				"!(skip_lint || empty)":                           true,
+				"is_patch && !(skip_lint || build_variant == '')": true,
+				"empty || skip_lint || build_variant":             true,
+			} {
+				result, err := expansions.EvalCondition(cond)
+				So(err, ShouldBeNil)
+				So(result, ShouldEqual, expected)
+			}
+		})
+
+		Convey("malformed conditions should cause an error", func() {
+			for _, cond := range []string{
+				"",
+				"is_patch &&",
+				"(is_patch",
+				"is_patch)",
+				"is_patch == 'true",
+				"is_patch = 'true'",
+				"is_patch build_variant",
+				"${is_patch",
+			} {
+				_, err := expansions.EvalCondition(cond)
+				So(err, ShouldNotBeNil)
+				So(ValidateCondition(cond), ShouldNotBeNil)
+			}
+		})
+	})
+}
@@ -66,11 +66,25 @@ func (self *Expansions) Exists(expansion string) bool {
 	return ok
 }
 
+// expansionTransforms are the functions that can be applied to an expanded
+// value, as in ${name:upper}.
+var expansionTransforms = map[string]func(string) string{
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"trim":  strings.TrimSpace,
+}
+
 // Apply the expansions to a single string.
+// Expansions are written as ${name}, and may be given a default value, as in
+// ${name|default}, or fall back to other expansions, as in ${name|*other},
+// which can be chained and end in a default: ${name|*other|*another|default}.
+// The value can be transformed with upper, lower and trim, as in
+// ${name:lower:trim|default}.
 // Return the expanded string, or an error if the input string is malformed.
 func (self *Expansions) ExpandString(toExpand string) (string, error) {
 	// replace all expandable parts of the string
 	malformedFound := false
+	var transformErr error
 	expanded := string(expansionRegex.ReplaceAllFunc([]byte(toExpand),
 		func(matchByte []byte) []byte {
 
@@ -91,18 +105,48 @@ func (self *Expansions) ExpandString(toExpand string) (string, error) {
 				match = match[0:idx]
 			}
 
-			// return the specified expansion, if it is present.
-			if self.Exists(match) {
-				return []byte(self.Get(match))
+			// split off any transforms from the name
+			transforms := strings.Split(match, ":")
+			value := self.lookup(transforms[0], defaultVal)
+			for _, name := range transforms[1:] {
+				transform, ok := expansionTransforms[name]
+				if !ok {
+					transformErr = fmt.Errorf("unknown expansion transform '%v' in "+
+						"\"${%v}\"", name, string(matchByte[2:len(matchByte)-1]))
+					continue
+				}
+				value = transform(value)
 			}
-
-			return []byte(defaultVal)
+			return []byte(value)
 		}))
 
 	if malformedFound || strings.Contains(expanded, "${") {
 		return expanded, fmt.Errorf("The line \"%v\" is badly formed - it"+
 			" contains an unclosed expansion", expanded)
 	}
+	if transformErr != nil {
+		return expanded, transformErr
+	}
 
 	return expanded, nil
 }
+
+// lookup returns the value of the expansion if it is present. Otherwise, it
+// works through the fallbacks: a fallback starting with * names another
+// expansion to use if that one is present, and anything else is a default.
+func (self *Expansions) lookup(name, fallback string) string {
+	if self.Exists(name) {
+		return self.Get(name)
+	}
+	for strings.HasPrefix(fallback, "*") {
+		other, rest := fallback[1:], ""
+		if idx := strings.Index(other, "|"); idx != -1 {
+			other, rest = other[:idx], other[idx+1:]
+		}
+		if self.Exists(other) {
+			return self.Get(other)
+		}
+		fallback = rest
+	}
+	return fallback
+}
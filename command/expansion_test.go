@@ -158,6 +158,32 @@ func TestExpandString(t *testing.T) {
 
 		})
 
+		Convey("fallbacks starting with * should use other expansions", func() {
+
+			toExpand := "${key3|*key2} ${key3|*key4|*key1} ${key3|*key4|blah}" +
+				" ${key3|*key4}"
+			expanded := "val2 val1 blah "
+
+			exp, err := expansions.ExpandString(toExpand)
+			So(err, ShouldBeNil)
+			So(exp, ShouldEqual, expanded)
+		})
+
+		Convey("transforms should be applied to the expanded value", func() {
+
+			expansions.Put("padded", "  Mixed Case ")
+			toExpand := "${key1:upper} ${padded:trim:lower}|" +
+				"${key3:upper|blah} ${key3:upper|*key2}"
+			expanded := "VAL1 mixed case|BLAH VAL2"
+
+			exp, err := expansions.ExpandString(toExpand)
+			So(err, ShouldBeNil)
+			So(exp, ShouldEqual, expanded)
+
+			_, err = expansions.ExpandString("${key1:reverse}")
+			So(err, ShouldNotBeNil)
+		})
+
 		Convey("badly formed command strings should cause an error", func() {
 
 			badStr1 := "hello ${key1|blah}${key3}hello${ ${key4} " +
@@ -58,6 +58,10 @@ type RepoTrackerConfig struct {
 	NumNewRepoRevisionsToFetch int
 	MaxRepoRevisionsToSearch   int
 	LogFile                    string
+
+	// MirrorDirectory holds the local mirrors of projects that aren't hosted
+	// on GitHub. It defaults to a directory under the system temp directory.
+	MirrorDirectory string
 }
 
 type ClientBinary struct {
@@ -101,6 +105,12 @@ type UIConfig struct {
 // MonitorConfig holds logging settings for the monitor process.
 type MonitorConfig struct {
 	LogFile string
+	// QuarantineThreshold is the number of consecutive tasks that must fail with
+	// system failures on a host for it to be quarantined. Zero disables quarantining.
+	QuarantineThreshold int `yaml:"quarantine_threshold"`
+	// QuarantineGraceMinutes is how long a quarantined host is kept around for
+	// inspection before it is decommissioned.
+	QuarantineGraceMinutes int `yaml:"quarantine_grace_minutes"`
 }
 
 // RunnerConfig holds logging and timing settings for the runner process.
@@ -115,6 +125,27 @@ type HostInitConfig struct {
 	SSHTimeoutSeconds int64
 }
 
+// SpawnConfig holds the limits for hosts spawned by users.
+type SpawnConfig struct {
+	// MaxExpirationHours is how far in the future a spawn host's expiration
+	// can be extended to. Defaults to a week.
+	MaxExpirationHours int `yaml:"max_expiration_hours"`
+	// HomeVolumeSize is the size in GiB of the persistent home volumes
+	// attached to spawn hosts. Defaults to 50.
+	HomeVolumeSize int `yaml:"home_volume_size"`
+}
+
+// ChatConfig holds the settings for posting notifications to a Slack or
+// Mattermost compatible incoming webhook.
+type ChatConfig struct {
+	// WebhookURL is the incoming webhook messages are posted to. Chat
+	// notifications are disabled when it's empty.
+	WebhookURL string `yaml:"webhook_url"`
+	// Username and IconURL override the sender configured for the webhook
+	Username string `yaml:"username"`
+	IconURL  string `yaml:"icon_url"`
+}
+
 // NotifyConfig hold logging and email settings for the notify package.
 type NotifyConfig struct {
 	LogFile string
@@ -143,10 +174,38 @@ type TaskRunnerConfig struct {
 	LogFile string
 }
 
+// ArtifactCleanupConfig holds settings for the process that deletes expired task artifacts.
+type ArtifactCleanupConfig struct {
+	LogFile string
+	// DryRun logs which files would be deleted without deleting or expiring anything
+	DryRun bool `yaml:"dry_run"`
+	// AWS credentials used to delete files; defaults to the AWS provider credentials
+	AWS AWSConfig `yaml:"aws"`
+	// S3Endpoint overrides the default s3 endpoint, e.g. to point at a local s3 stand-in
+	S3Endpoint string `yaml:"s3_endpoint"`
+}
+
+// LogStorageConfig selects where task and test logs are stored. Logs are kept
+// in the database unless the "local" or "s3" blob store backend is chosen.
+type LogStorageConfig struct {
+	// Backend is "mongo" (the default), "local" or "s3"
+	Backend string `yaml:"backend"`
+	// LocalPath is the directory the local backend stores logs in. It may be
+	// an NFS mount shared by the API and UI servers.
+	LocalPath string `yaml:"local_path"`
+	S3Bucket  string `yaml:"s3_bucket"`
+	S3Prefix  string `yaml:"s3_prefix"`
+	// AWS credentials used by the s3 backend; defaults to the AWS provider credentials
+	AWS AWSConfig `yaml:"aws"`
+	// S3Endpoint replaces the us-east-1 endpoint of the s3 backend when set
+	S3Endpoint string `yaml:"s3_endpoint"`
+}
+
 // CloudProviders stores configuration settings for the supported cloud host providers.
 type CloudProviders struct {
 	AWS          AWSConfig          `yaml:"aws"`
 	DigitalOcean DigitalOceanConfig `yaml:"digitalocean"`
+	Kubernetes   KubernetesConfig   `yaml:"kubernetes"`
 }
 
 // AWSConfig stores auth info for Amazon Web Services.
@@ -161,6 +220,17 @@ type DigitalOceanConfig struct {
 	Key      string `yaml:"key"`
 }
 
+// KubernetesConfig stores the address of and auth info for the Kubernetes
+// API server that task pods are started through.
+type KubernetesConfig struct {
+	APIServer string `yaml:"api_server"`
+	// Token is a bearer token for a service account allowed to manage pods
+	Token string `yaml:"token"`
+	// CACert is the PEM-encoded certificate authority of the API server. The
+	// system's certificate authorities are used if it is blank.
+	CACert string `yaml:"ca_cert"`
+}
+
 // JiraConfig stores auth info for interacting with Atlassian Jira.
 type JiraConfig struct {
 	Host     string
@@ -187,31 +257,35 @@ type AlertsConfig struct {
 
 // Settings contains all configuration settings for running Evergreen.
 type Settings struct {
-	DbUrl               string            `yaml:"dburl"`
-	Db                  string            `yaml:"db"`
-	WriteConcern        WriteConcern      `yaml:"write_concern"`
-	ConfigDir           string            `yaml:"configdir"`
-	ApiUrl              string            `yaml:"api_url"`
-	AgentExecutablesDir string            `yaml:"agentexecutablesdir"`
-	SuperUsers          []string          `yaml:"superusers"`
-	Jira                JiraConfig        `yaml:"jira"`
-	Providers           CloudProviders    `yaml:"providers"`
-	Keys                map[string]string `yaml:"keys"`
-	Credentials         map[string]string `yaml:"credentials"`
-	AuthConfig          AuthConfig        `yaml:"auth"`
-	RepoTracker         RepoTrackerConfig `yaml:"repotracker"`
-	Monitor             MonitorConfig     `yaml:"monitor"`
-	Api                 APIConfig         `yaml:"api"`
-	Alerts              AlertsConfig      `yaml:"alerts"`
-	Ui                  UIConfig          `yaml:"ui"`
-	HostInit            HostInitConfig    `yaml:"hostinit"`
-	Notify              NotifyConfig      `yaml:"notify"`
-	Runner              RunnerConfig      `yaml:"runner"`
-	Scheduler           SchedulerConfig   `yaml:"scheduler"`
-	TaskRunner          TaskRunnerConfig  `yaml:"taskrunner"`
-	Expansions          map[string]string `yaml:"expansions"`
-	Plugins             PluginConfig      `yaml:"plugins"`
-	IsProd              bool              `yaml:"isprod"`
+	DbUrl               string                `yaml:"dburl"`
+	Db                  string                `yaml:"db"`
+	WriteConcern        WriteConcern          `yaml:"write_concern"`
+	ConfigDir           string                `yaml:"configdir"`
+	ApiUrl              string                `yaml:"api_url"`
+	AgentExecutablesDir string                `yaml:"agentexecutablesdir"`
+	SuperUsers          []string              `yaml:"superusers"`
+	Jira                JiraConfig            `yaml:"jira"`
+	Providers           CloudProviders        `yaml:"providers"`
+	Keys                map[string]string     `yaml:"keys"`
+	Credentials         map[string]string     `yaml:"credentials"`
+	AuthConfig          AuthConfig            `yaml:"auth"`
+	RepoTracker         RepoTrackerConfig     `yaml:"repotracker"`
+	Monitor             MonitorConfig         `yaml:"monitor"`
+	Api                 APIConfig             `yaml:"api"`
+	Alerts              AlertsConfig          `yaml:"alerts"`
+	Ui                  UIConfig              `yaml:"ui"`
+	HostInit            HostInitConfig        `yaml:"hostinit"`
+	Spawn               SpawnConfig           `yaml:"spawn"`
+	Notify              NotifyConfig          `yaml:"notify"`
+	Chat                ChatConfig            `yaml:"chat"`
+	Runner              RunnerConfig          `yaml:"runner"`
+	Scheduler           SchedulerConfig       `yaml:"scheduler"`
+	TaskRunner          TaskRunnerConfig      `yaml:"taskrunner"`
+	ArtifactCleanup     ArtifactCleanupConfig `yaml:"artifactcleanup"`
+	LogStorage          LogStorageConfig      `yaml:"log_storage"`
+	Expansions          map[string]string     `yaml:"expansions"`
+	Plugins             PluginConfig          `yaml:"plugins"`
+	IsProd              bool                  `yaml:"isprod"`
 }
 
 // NewSettings builds an in-memory representation of the given settings file.
@@ -308,6 +382,23 @@ var ConfigValidationRules = []ConfigValidator{
 		return nil
 	},
 
+	func(settings *Settings) error {
+		switch settings.LogStorage.Backend {
+		case "", "mongo":
+		case "local":
+			if settings.LogStorage.LocalPath == "" {
+				return fmt.Errorf("log_storage local_path must not be empty for the local backend")
+			}
+		case "s3":
+			if settings.LogStorage.S3Bucket == "" {
+				return fmt.Errorf("log_storage s3_bucket must not be empty for the s3 backend")
+			}
+		default:
+			return fmt.Errorf("unknown log_storage backend '%v'", settings.LogStorage.Backend)
+		}
+		return nil
+	},
+
 	func(settings *Settings) error {
 		if settings.Ui.DefaultProject == "" {
 			return fmt.Errorf("You must specify a default project in UI")
@@ -0,0 +1,112 @@
+// Package githubstatus reports the results of mainline builds and versions
+// back to GitHub as commit statuses, for the projects whose project refs opt
+// in to it.
+package githubstatus
+
+import (
+	"fmt"
+	"github.com/10gen-labs/slogger/v1"
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/build"
+	"github.com/evergreen-ci/evergreen/model/version"
+	"github.com/evergreen-ci/evergreen/thirdparty"
+)
+
+const (
+	// SummaryContext is the context of the status summarizing a version, and
+	// the prefix of the contexts of the per-variant statuses.
+	SummaryContext = "evergreen"
+)
+
+// Register adds hooks that queue the commit statuses of finished builds and
+// versions to the model's build and version completion hooks. The hooks run
+// while tasks are being marked finished, so they only save the requests; the
+// Runner posts them to GitHub.
+func Register(settings *evergreen.Settings) {
+	if settings.Credentials["github"] == "" {
+		evergreen.Logger.Logf(slogger.WARN, "No GitHub credentials, commit statuses won't be reported")
+		return
+	}
+	model.AddBuildCompletionHook(QueueBuildStatus)
+	model.AddVersionCompletionHook(QueueVersionStatus)
+}
+
+// reportingProjectRef returns the project ref of the project if it reports
+// statuses in the given mode, and nil otherwise.
+func reportingProjectRef(identifier, mode string) (*model.ProjectRef, error) {
+	projectRef, err := model.FindOneProjectRef(identifier)
+	if err != nil {
+		return nil, err
+	}
+	if projectRef == nil || projectRef.GithubStatus != mode {
+		return nil, nil
+	}
+	if projectRef.RepoKind != "" && projectRef.RepoKind != evergreen.GithubRepoKind {
+		return nil, nil
+	}
+	return projectRef, nil
+}
+
+// BuildStatus returns the status of the finished build.
+func BuildStatus(b *build.Build, uiRoot string) thirdparty.GithubStatus {
+	status := thirdparty.GithubStatus{
+		State:       thirdparty.GithubStatusFailure,
+		TargetURL:   fmt.Sprintf("%v/build/%v", uiRoot, b.Id),
+		Description: fmt.Sprintf("%v failed", b.DisplayName),
+		Context:     fmt.Sprintf("%v/%v", SummaryContext, b.BuildVariant),
+	}
+	if b.Status == evergreen.BuildSucceeded {
+		status.State = thirdparty.GithubStatusSuccess
+		status.Description = fmt.Sprintf("%v succeeded", b.DisplayName)
+	}
+	return status
+}
+
+// VersionStatus returns the status summarizing the finished version and its
+// builds.
+func VersionStatus(v *version.Version, builds []build.Build, uiRoot string) thirdparty.GithubStatus {
+	failed := 0
+	for _, b := range builds {
+		if b.Status != evergreen.BuildSucceeded {
+			failed++
+		}
+	}
+	status := thirdparty.GithubStatus{
+		State:       thirdparty.GithubStatusFailure,
+		TargetURL:   fmt.Sprintf("%v/waterfall/%v", uiRoot, v.Identifier),
+		Description: fmt.Sprintf("%v of %v builds failed", failed, len(builds)),
+		Context:     SummaryContext,
+	}
+	if failed == 0 {
+		status.State = thirdparty.GithubStatusSuccess
+		status.Description = fmt.Sprintf("all %v builds succeeded", len(builds))
+	}
+	return status
+}
+
+// QueueBuildStatus queues the status of the build's variant, if its project
+// reports the status of each variant.
+func QueueBuildStatus(b *build.Build) error {
+	if b.Requester != evergreen.RepotrackerVersionRequester {
+		return nil
+	}
+	projectRef, err := reportingProjectRef(b.Project, model.GithubStatusVariants)
+	if err != nil || projectRef == nil {
+		return err
+	}
+	return EnqueueStatusRequest(&StatusRequest{BuildId: b.Id, ProjectId: b.Project})
+}
+
+// QueueVersionStatus queues the summary status of the version, if its
+// project reports a summary status.
+func QueueVersionStatus(v *version.Version) error {
+	if v.Requester != evergreen.RepotrackerVersionRequester {
+		return nil
+	}
+	projectRef, err := reportingProjectRef(v.Identifier, model.GithubStatusSummary)
+	if err != nil || projectRef == nil {
+		return err
+	}
+	return EnqueueStatusRequest(&StatusRequest{VersionId: v.Id, ProjectId: v.Identifier})
+}
@@ -0,0 +1,46 @@
+package githubstatus
+
+import (
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model/build"
+	"github.com/evergreen-ci/evergreen/model/version"
+	"github.com/evergreen-ci/evergreen/thirdparty"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestStatuses(t *testing.T) {
+	uiRoot := "http://evergreen.example.com"
+
+	Convey("Build statuses should reflect the build and link to it", t, func() {
+		b := &build.Build{Id: "b1", DisplayName: "Linux 64", BuildVariant: "linux-64",
+			Status: evergreen.BuildSucceeded}
+		status := BuildStatus(b, uiRoot)
+		So(status.State, ShouldEqual, thirdparty.GithubStatusSuccess)
+		So(status.Context, ShouldEqual, "evergreen/linux-64")
+		So(status.TargetURL, ShouldEqual, "http://evergreen.example.com/build/b1")
+
+		b.Status = evergreen.BuildFailed
+		status = BuildStatus(b, uiRoot)
+		So(status.State, ShouldEqual, thirdparty.GithubStatusFailure)
+		So(status.Description, ShouldEqual, "Linux 64 failed")
+	})
+
+	Convey("Version statuses should summarize the builds", t, func() {
+		v := &version.Version{Id: "v1", Identifier: "mongo"}
+		builds := []build.Build{
+			{Status: evergreen.BuildSucceeded},
+			{Status: evergreen.BuildSucceeded},
+		}
+		status := VersionStatus(v, builds, uiRoot)
+		So(status.State, ShouldEqual, thirdparty.GithubStatusSuccess)
+		So(status.Context, ShouldEqual, SummaryContext)
+		So(status.Description, ShouldEqual, "all 2 builds succeeded")
+		So(status.TargetURL, ShouldEqual, "http://evergreen.example.com/waterfall/mongo")
+
+		builds = append(builds, build.Build{Status: evergreen.BuildFailed})
+		status = VersionStatus(v, builds, uiRoot)
+		So(status.State, ShouldEqual, thirdparty.GithubStatusFailure)
+		So(status.Description, ShouldEqual, "1 of 3 builds failed")
+	})
+}
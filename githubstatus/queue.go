@@ -0,0 +1,94 @@
+package githubstatus
+
+import (
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/evergreen-ci/evergreen/db/bsonutil"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"time"
+)
+
+const (
+	// Collection is the name of the collection in MongoDB that stores the
+	// queued commit statuses.
+	Collection = "github_statuses"
+)
+
+// queue statuses of a StatusRequest
+const (
+	Pending    = "pending"
+	InProgress = "in-progress"
+	Delivered  = "delivered"
+	Failed     = "failed"
+)
+
+// StatusRequest is a commit status waiting to be posted to GitHub for a
+// finished build or version. Only the id of the build or version is queued;
+// the status itself is computed when the request is delivered.
+type StatusRequest struct {
+	Id          bson.ObjectId `bson:"_id"`
+	QueueStatus string        `bson:"queue_status"`
+	BuildId     string        `bson:"build_id,omitempty"`
+	VersionId   string        `bson:"version_id,omitempty"`
+	ProjectId   string        `bson:"project_id"`
+	Error       string        `bson:"error,omitempty"`
+	CreatedAt   time.Time     `bson:"created_at"`
+	ProcessedAt time.Time     `bson:"processed_at"`
+}
+
+var (
+	IdKey          = bsonutil.MustHaveTag(StatusRequest{}, "Id")
+	QueueStatusKey = bsonutil.MustHaveTag(StatusRequest{}, "QueueStatus")
+	ErrorKey       = bsonutil.MustHaveTag(StatusRequest{}, "Error")
+	CreatedAtKey   = bsonutil.MustHaveTag(StatusRequest{}, "CreatedAt")
+	ProcessedAtKey = bsonutil.MustHaveTag(StatusRequest{}, "ProcessedAt")
+)
+
+// EnqueueStatusRequest saves the request as pending.
+func EnqueueStatusRequest(r *StatusRequest) error {
+	r.Id = bson.NewObjectId()
+	r.QueueStatus = Pending
+	r.CreatedAt = time.Now()
+	return db.Insert(Collection, r)
+}
+
+// DequeueStatusRequest marks the oldest pending request as in progress and
+// returns it, or nil if there are no pending requests.
+func DequeueStatusRequest() (*StatusRequest, error) {
+	out := StatusRequest{}
+	_, err := db.FindAndModify(Collection,
+		bson.M{QueueStatusKey: Pending},
+		[]string{CreatedAtKey},
+		mgo.Change{
+			Update:    bson.M{"$set": bson.M{QueueStatusKey: InProgress}},
+			Upsert:    false,
+			Remove:    false,
+			ReturnNew: true,
+		}, &out)
+
+	if err == mgo.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// MarkProcessed records whether the request was delivered.
+func (r *StatusRequest) MarkProcessed(deliveryErr error) error {
+	r.QueueStatus = Delivered
+	r.Error = ""
+	if deliveryErr != nil {
+		r.QueueStatus = Failed
+		r.Error = deliveryErr.Error()
+	}
+	r.ProcessedAt = time.Now()
+	return db.Update(Collection,
+		bson.M{IdKey: r.Id},
+		bson.M{"$set": bson.M{
+			QueueStatusKey: r.QueueStatus,
+			ErrorKey:       r.Error,
+			ProcessedAtKey: r.ProcessedAt,
+		}})
+}
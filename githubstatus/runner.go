@@ -0,0 +1,103 @@
+package githubstatus
+
+import (
+	"fmt"
+	"github.com/10gen-labs/slogger/v1"
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/build"
+	"github.com/evergreen-ci/evergreen/model/version"
+	"github.com/evergreen-ci/evergreen/thirdparty"
+	"time"
+)
+
+// Runner posts the queued commit statuses to GitHub.
+type Runner struct{}
+
+const (
+	RunnerName  = "githubstatus"
+	Description = "post the commit statuses of finished builds and versions to GitHub"
+)
+
+func (r *Runner) Name() string {
+	return RunnerName
+}
+
+func (r *Runner) Description() string {
+	return Description
+}
+
+func (r *Runner) Run(config *evergreen.Settings) error {
+	oauthToken := config.Credentials["github"]
+	if oauthToken == "" {
+		evergreen.Logger.Logf(slogger.WARN, "No GitHub credentials, not posting commit statuses")
+		return nil
+	}
+
+	startTime := time.Now()
+	evergreen.Logger.Logf(slogger.INFO, "Starting GitHub status run at time %v", startTime)
+	for {
+		request, err := DequeueStatusRequest()
+		if err != nil {
+			return evergreen.Logger.Errorf(slogger.ERROR, "error dequeuing status request: %v", err)
+		}
+		if request == nil {
+			break
+		}
+
+		deliveryErr := deliver(request, oauthToken, config.Ui.Url)
+		if deliveryErr != nil {
+			evergreen.Logger.Logf(slogger.ERROR, "Error posting status request %v: %v",
+				request.Id.Hex(), deliveryErr)
+		}
+		if err = request.MarkProcessed(deliveryErr); err != nil {
+			return evergreen.Logger.Errorf(slogger.ERROR, "error updating status request %v: %v",
+				request.Id.Hex(), err)
+		}
+	}
+
+	runtime := time.Now().Sub(startTime)
+	if err := model.SetProcessRuntimeCompleted(RunnerName, runtime); err != nil {
+		evergreen.Logger.Errorf(slogger.ERROR, "error updating process status: %v", err)
+	}
+	evergreen.Logger.Logf(slogger.INFO, "GitHub status run took %v", runtime)
+	return nil
+}
+
+// deliver posts the status of the request's build or version to the
+// revision on GitHub.
+func deliver(request *StatusRequest, oauthToken, uiRoot string) error {
+	projectRef, err := model.FindOneProjectRef(request.ProjectId)
+	if err != nil {
+		return err
+	}
+	if projectRef == nil {
+		return fmt.Errorf("project %v not found", request.ProjectId)
+	}
+
+	if request.BuildId != "" {
+		b, err := build.FindOne(build.ById(request.BuildId))
+		if err != nil {
+			return err
+		}
+		if b == nil {
+			return fmt.Errorf("build %v not found", request.BuildId)
+		}
+		return thirdparty.SetGithubStatus(oauthToken, projectRef.Owner, projectRef.Repo,
+			b.Revision, BuildStatus(b, uiRoot))
+	}
+
+	v, err := version.FindOne(version.ById(request.VersionId).WithoutFields(version.ConfigKey))
+	if err != nil {
+		return err
+	}
+	if v == nil {
+		return fmt.Errorf("version %v not found", request.VersionId)
+	}
+	builds, err := build.Find(build.ByVersion(v.Id).WithFields(build.StatusKey))
+	if err != nil {
+		return err
+	}
+	return thirdparty.SetGithubStatus(oauthToken, projectRef.Owner, projectRef.Repo,
+		v.Revision, VersionStatus(v, builds, uiRoot))
+}
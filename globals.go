@@ -23,6 +23,7 @@ const (
 	HostUnreachable     = "unreachable"
 	HostQuarantined     = "quarantined"
 	HostDecommissioned  = "decommissioned"
+	HostStopped         = "stopped"
 
 	HostStatusSuccess = "success"
 	HostStatusFailed  = "failed"
@@ -67,6 +68,11 @@ const (
 	HostTypeStatic = "static"
 	HostTypeEC2    = "ec2"
 
+	// repository kinds, which decide how a project's repository is polled
+	GithubRepoKind = "github"
+	GitRepoKind    = "git"
+	GerritRepoKind = "gerrit"
+
 	CompileStage = "compile"
 	TestStage    = "single_test"
 	SanityStage  = "smokeCppUnitTests"
@@ -90,6 +96,7 @@ const (
 const (
 	AuthTokenCookie  = "mci-token"
 	TaskSecretHeader = "Task-Secret"
+	HostSecretHeader = "Host-Secret"
 )
 
 var (
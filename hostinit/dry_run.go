@@ -0,0 +1,185 @@
+package hostinit
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/10gen-labs/slogger/v1"
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/cloud/providers"
+	"github.com/evergreen-ci/evergreen/model/distro"
+	"github.com/evergreen-ci/evergreen/model/host"
+)
+
+// the steps of a dry run, in the order they run
+const (
+	DryRunSpawnStep    = "spawn"
+	DryRunWaitStep     = "wait"
+	DryRunSetupStep    = "setup"
+	DryRunCanaryStep   = "canary"
+	DryRunTeardownStep = "teardown"
+)
+
+// DefaultCanaryCommand is run on dry run hosts after their setup script,
+// unless the dry run asks for another command.
+const DefaultCanaryCommand = "uname -a"
+
+var (
+	// how long a dry run waits for its host to be ready for setup
+	DryRunReadyTimeout = 15 * time.Minute
+
+	// how often a dry run checks if its host is ready
+	dryRunPollInterval = 5 * time.Second
+)
+
+// DryRun tests the distro's configuration by spawning one host of the
+// distro, running the setup script and then the canary command on it, and
+// terminating it. The host is started on behalf of the run's user, so the
+// hostinit runner and the scheduler leave it alone. The result of each step
+// is saved to the run as it finishes; the returned error is the first step's
+// failure, if any.
+func (init *HostInit) DryRun(run *distro.DryRun, d *distro.Distro, canary string) error {
+	if canary == "" {
+		canary = DefaultCanaryCommand
+	}
+
+	var h *host.Host
+	err := init.dryRunStep(run, DryRunSpawnStep, func() (string, error) {
+		var err error
+		if h, err = init.spawnDryRunHost(run, d); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Spawned host %v with provider %v", h.Id, d.Provider), nil
+	})
+	if err == nil {
+		err = init.dryRunStep(run, DryRunWaitStep, func() (string, error) {
+			return init.waitForDryRunHost(h)
+		})
+	}
+	if err == nil {
+		err = init.dryRunStep(run, DryRunSetupStep, func() (string, error) {
+			output, err := init.setupHost(h)
+			return string(output), err
+		})
+	}
+	if err == nil {
+		err = init.dryRunStep(run, DryRunCanaryStep, func() (string, error) {
+			output, err := runRemoteScript(init, h, canary, false)
+			return string(output), err
+		})
+	}
+
+	// always clean up the host, if one was started
+	if h != nil {
+		teardownErr := init.dryRunStep(run, DryRunTeardownStep, func() (string, error) {
+			return init.terminateDryRunHost(h)
+		})
+		if err == nil {
+			err = teardownErr
+		}
+	}
+
+	run.Status = distro.DryRunSucceeded
+	if err != nil {
+		run.Status = distro.DryRunFailed
+	}
+	run.FinishTime = time.Now()
+	if updateErr := run.Update(); updateErr != nil {
+		evergreen.Logger.Logf(slogger.ERROR, "Error saving dry run %v: %v", run.Id, updateErr)
+	}
+	return err
+}
+
+// dryRunStep runs one step of the dry run and saves its result.
+func (init *HostInit) dryRunStep(run *distro.DryRun, name string, step func() (string, error)) error {
+	evergreen.Logger.Logf(slogger.INFO, "Running step '%v' of dry run %v of distro %v",
+		name, run.Id, run.DistroId)
+	result := distro.DryRunStep{Name: name, StartTime: time.Now()}
+	output, err := step()
+	result.Output = output
+	result.FinishTime = time.Now()
+	if err != nil {
+		result.Error = err.Error()
+	}
+	run.Steps = append(run.Steps, result)
+	if updateErr := run.Update(); updateErr != nil {
+		evergreen.Logger.Logf(slogger.ERROR, "Error saving dry run %v: %v", run.Id, updateErr)
+	}
+	return err
+}
+
+// spawnDryRunHost starts the dry run's host through the distro's provider,
+// and makes sure it's saved, since not every provider saves its hosts. Once
+// the instance is started the host is returned even on error, so that it can
+// still be torn down.
+func (init *HostInit) spawnDryRunHost(run *distro.DryRun, d *distro.Distro) (*host.Host, error) {
+	cloudMgr, err := providers.GetCloudManager(d.Provider, init.Settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cloud manager for provider %v: %v", d.Provider, err)
+	}
+	h, err := cloudMgr.SpawnInstance(d, run.User, true)
+	if err != nil {
+		return nil, fmt.Errorf("error spawning host: %v", err)
+	}
+	run.HostId = h.Id
+
+	existing, err := host.FindOne(host.ById(h.Id))
+	if err != nil {
+		return h, fmt.Errorf("error finding host %v: %v", h.Id, err)
+	}
+	if existing == nil {
+		if h.Status == "" {
+			h.Status = evergreen.HostUninitialized
+		}
+		if h.CreationTime.IsZero() {
+			h.CreationTime = time.Now()
+		}
+		if err := h.Insert(); err != nil {
+			return h, fmt.Errorf("error saving host %v: %v", h.Id, err)
+		}
+	}
+	return h, nil
+}
+
+// waitForDryRunHost polls the host until it's ready for its setup script.
+func (init *HostInit) waitForDryRunHost(h *host.Host) (string, error) {
+	startTime := time.Now()
+	for {
+		ready, err := init.IsHostReady(h)
+		if err != nil {
+			return "", err
+		}
+		if ready {
+			return fmt.Sprintf("Host %v was ready after %v", h.Host,
+				time.Now().Sub(startTime)), nil
+		}
+		if time.Now().Sub(startTime) > DryRunReadyTimeout {
+			return "", fmt.Errorf("host took longer than %v to come up", DryRunReadyTimeout)
+		}
+		time.Sleep(dryRunPollInterval)
+	}
+}
+
+// terminateDryRunHost terminates the host's instance and marks the host as
+// terminated.
+func (init *HostInit) terminateDryRunHost(h *host.Host) (string, error) {
+	cloudHost, err := providers.GetCloudHost(h, init.Settings)
+	if err != nil {
+		return "", fmt.Errorf("failed to get cloud host for %v: %v", h.Id, err)
+	}
+	if err := cloudHost.TerminateInstance(); err != nil {
+		return "", fmt.Errorf("error terminating host %v: %v", h.Id, err)
+	}
+
+	// some providers only terminate the instance, and leave the host as is
+	terminated, err := host.FindOne(host.ById(h.Id))
+	if err != nil {
+		return "", fmt.Errorf("error finding host %v: %v", h.Id, err)
+	}
+	if terminated != nil && terminated.Status != evergreen.HostTerminated {
+		if err := h.Terminate(); err != nil {
+			return "", fmt.Errorf("error marking host %v as terminated: %v", h.Id, err)
+		}
+	}
+	return fmt.Sprintf("Terminated host %v", h.Id), nil
+}
@@ -0,0 +1,199 @@
+package hostinit
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/cloud/providers/docker"
+	"github.com/evergreen-ci/evergreen/cloud/providers/mock"
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/evergreen-ci/evergreen/model/distro"
+	"github.com/evergreen-ci/evergreen/model/host"
+	"github.com/evergreen-ci/evergreen/testutil"
+	dockerclient "github.com/fsouza/go-dockerclient"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestDryRun(t *testing.T) {
+	testConfig := evergreen.TestConfig()
+	db.SetGlobalSessionProvider(db.SessionFactoryFromConfig(testConfig))
+
+	// record the scripts run on hosts instead of running them over ssh
+	scripts := []string{}
+	scriptErrs := map[string]error{}
+	runRemoteScript = func(_ *HostInit, _ *host.Host, script string, _ bool) ([]byte, error) {
+		scripts = append(scripts, script)
+		return []byte("ran " + script), scriptErrs[script]
+	}
+	defer func() { runRemoteScript = (*HostInit).copyAndRunScript }()
+
+	Convey("With a distro using the mock provider", t, func() {
+		testutil.HandleTestingErr(db.ClearCollections(host.Collection, distro.DryRunCollection),
+			t, "error clearing collections")
+		scripts = []string{}
+		scriptErrs = map[string]error{}
+
+		d := &distro.Distro{Id: "d1", Provider: mock.ProviderName, Setup: "echo setup"}
+		run := distro.NewDryRun(d.Id, "me")
+		So(run.Insert(), ShouldBeNil)
+		init := &HostInit{Settings: testConfig}
+
+		Convey("a successful dry run should set up the host, run the canary and terminate the host", func() {
+			So(init.DryRun(run, d, ""), ShouldBeNil)
+			So(scripts, ShouldResemble, []string{"echo setup", DefaultCanaryCommand})
+
+			saved, err := distro.FindOneDryRun(distro.DryRunById(run.Id))
+			So(err, ShouldBeNil)
+			So(saved.Status, ShouldEqual, distro.DryRunSucceeded)
+			So(len(saved.Steps), ShouldEqual, 5)
+			So(saved.Steps[2].Name, ShouldEqual, DryRunSetupStep)
+			So(saved.Steps[2].Output, ShouldEqual, "ran echo setup")
+			So(saved.Steps[3].Output, ShouldEqual, "ran "+DefaultCanaryCommand)
+
+			h, err := host.FindOne(host.ById(saved.HostId))
+			So(err, ShouldBeNil)
+			So(h.Status, ShouldEqual, evergreen.HostTerminated)
+			So(h.StartedBy, ShouldEqual, "me")
+		})
+
+		Convey("a failing setup script should fail the run, but still terminate the host", func() {
+			scriptErrs["echo setup"] = errors.New("exit code 1")
+			So(init.DryRun(run, d, "true"), ShouldNotBeNil)
+			So(scripts, ShouldResemble, []string{"echo setup"})
+
+			saved, err := distro.FindOneDryRun(distro.DryRunById(run.Id))
+			So(err, ShouldBeNil)
+			So(saved.Status, ShouldEqual, distro.DryRunFailed)
+			So(len(saved.Steps), ShouldEqual, 4)
+			So(saved.Steps[2].Error, ShouldEqual, "exit code 1")
+			So(saved.Steps[3].Name, ShouldEqual, DryRunTeardownStep)
+			So(saved.Steps[3].Error, ShouldEqual, "")
+
+			h, err := host.FindOne(host.ById(saved.HostId))
+			So(err, ShouldBeNil)
+			So(h.Status, ShouldEqual, evergreen.HostTerminated)
+		})
+
+		Convey("a distro with an unknown provider should fail without starting a host", func() {
+			d.Provider = "nonexistent"
+			So(init.DryRun(run, d, ""), ShouldNotBeNil)
+
+			saved, err := distro.FindOneDryRun(distro.DryRunById(run.Id))
+			So(err, ShouldBeNil)
+			So(saved.Status, ShouldEqual, distro.DryRunFailed)
+			So(len(saved.Steps), ShouldEqual, 1)
+			So(saved.HostId, ShouldEqual, "")
+		})
+	})
+}
+
+// dockerTestSettings returns the settings of a distro using the docker daemon
+// that DOCKER_HOST and DOCKER_CERT_PATH point to, with the image named by
+// EVG_DOCKER_IMAGE. It returns an error if the daemon isn't available.
+func dockerTestSettings() (*map[string]interface{}, error) {
+	endpoint := os.Getenv("DOCKER_HOST")
+	certPath := os.Getenv("DOCKER_CERT_PATH")
+	image := os.Getenv("EVG_DOCKER_IMAGE")
+	if endpoint == "" || certPath == "" || image == "" {
+		return nil, errors.New("DOCKER_HOST, DOCKER_CERT_PATH and EVG_DOCKER_IMAGE must be set")
+	}
+	hostUrl, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("bad DOCKER_HOST: %v", err)
+	}
+	hostIp, port, err := net.SplitHostPort(hostUrl.Host)
+	if err != nil {
+		return nil, fmt.Errorf("bad DOCKER_HOST: %v", err)
+	}
+	clientPort, err := strconv.Atoi(port)
+	if err != nil {
+		return nil, fmt.Errorf("bad port in DOCKER_HOST: %v", err)
+	}
+
+	auth := map[string]interface{}{}
+	for key, file := range map[string]string{"cert": "cert.pem", "key": "key.pem", "ca": "ca.pem"} {
+		contents, err := ioutil.ReadFile(filepath.Join(certPath, file))
+		if err != nil {
+			return nil, err
+		}
+		auth[key] = string(contents)
+	}
+	client, err := dockerclient.NewTLSClient(endpoint, filepath.Join(certPath, "cert.pem"),
+		filepath.Join(certPath, "key.pem"), filepath.Join(certPath, "ca.pem"))
+	if err != nil {
+		return nil, err
+	}
+	if err = client.Ping(); err != nil {
+		return nil, fmt.Errorf("docker daemon not responding: %v", err)
+	}
+
+	return &map[string]interface{}{
+		"host_ip":     hostIp,
+		"bind_ip":     hostIp,
+		"image_name":  image,
+		"client_port": clientPort,
+		"auth":        auth,
+	}, nil
+}
+
+func TestDryRunDocker(t *testing.T) {
+	testConfig := evergreen.TestConfig()
+	db.SetGlobalSessionProvider(db.SessionFactoryFromConfig(testConfig))
+
+	providerSettings, err := dockerTestSettings()
+	if err != nil {
+		t.Skipf("Skipping docker dry run test: %v", err)
+	}
+
+	// the container isn't necessarily reachable over ssh from here, so
+	// don't wait long for it and don't run the scripts
+	DryRunReadyTimeout = time.Minute
+	defer func() { DryRunReadyTimeout = 15 * time.Minute }()
+	runRemoteScript = func(_ *HostInit, _ *host.Host, script string, _ bool) ([]byte, error) {
+		return []byte("ran " + script), nil
+	}
+	defer func() { runRemoteScript = (*HostInit).copyAndRunScript }()
+
+	Convey("With a distro using the docker provider", t, func() {
+		testutil.HandleTestingErr(db.ClearCollections(host.Collection, distro.DryRunCollection),
+			t, "error clearing collections")
+
+		d := &distro.Distro{
+			Id:               "docker-dry-run",
+			Provider:         docker.ProviderName,
+			ProviderSettings: providerSettings,
+			User:             "root",
+			Setup:            "echo setup",
+		}
+		run := distro.NewDryRun(d.Id, "me")
+		So(run.Insert(), ShouldBeNil)
+		init := &HostInit{Settings: testConfig}
+
+		Convey("a dry run should start a container and always remove it", func() {
+			init.DryRun(run, d, "")
+
+			saved, err := distro.FindOneDryRun(distro.DryRunById(run.Id))
+			So(err, ShouldBeNil)
+			So(saved.HostId, ShouldNotEqual, "")
+			So(saved.Steps[0].Name, ShouldEqual, DryRunSpawnStep)
+			So(saved.Steps[0].Error, ShouldEqual, "")
+
+			last := saved.Steps[len(saved.Steps)-1]
+			So(last.Name, ShouldEqual, DryRunTeardownStep)
+			So(last.Error, ShouldEqual, "")
+
+			h, err := host.FindOne(host.ById(saved.HostId))
+			So(err, ShouldBeNil)
+			So(h.Status, ShouldEqual, evergreen.HostTerminated)
+		})
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"sync"
 	"time"
 
@@ -18,6 +19,7 @@ import (
 	"github.com/evergreen-ci/evergreen/model/event"
 	"github.com/evergreen-ci/evergreen/model/host"
 	"github.com/evergreen-ci/evergreen/notify"
+	"github.com/evergreen-ci/evergreen/taskrunner"
 	"github.com/evergreen-ci/evergreen/util"
 	"gopkg.in/mgo.v2"
 )
@@ -31,6 +33,12 @@ var (
 	ErrHostAlreadyInitializing = errors.New("Host already initializing")
 )
 
+// Error indicating the host's instance failed to start, e.g. because its
+// spot request couldn't be fulfilled.
+var (
+	ErrInstanceFailed = errors.New("Host's instance failed to start")
+)
+
 // Longest duration allowed for running setup script.
 var (
 	SSHTimeoutSeconds = int64(300) // 5 minutes
@@ -64,8 +72,25 @@ func (init *HostInit) setupReadyHosts() error {
 
 	for _, h := range uninitializedHosts {
 
+		// hosts provisioned with user data set themselves up when they boot,
+		// and report back to the API server when they're done
+		if h.Distro.ProvisionWithUserData {
+			if err := init.checkUserDataHost(&h); err != nil {
+				evergreen.Logger.Logf(slogger.ERROR, "Error checking host %v: %v", h.Id, err)
+			}
+			continue
+		}
+
 		// check whether or not the host is ready for its setup script to be run
 		ready, err := init.IsHostReady(&h)
+		if err == ErrInstanceFailed {
+			evergreen.Logger.Logf(slogger.WARN, "Instance for host %v failed to start", h.Id)
+			if err := init.replaceFailedHost(&h); err != nil {
+				evergreen.Logger.Logf(slogger.ERROR, "Error replacing failed host %v: %v",
+					h.Id, err)
+			}
+			continue
+		}
 		if err != nil {
 			evergreen.Logger.Logf(slogger.ERROR, "Error checking host %v for readiness: %v",
 				h.Id, err)
@@ -129,6 +154,10 @@ func (init *HostInit) IsHostReady(host *host.Host) (bool, error) {
 		return false, fmt.Errorf("error checking instance status of host %v: %v", host.Id, err)
 	}
 
+	if hostStatus == cloud.StatusFailed {
+		return false, ErrInstanceFailed
+	}
+
 	// if the host isn't up yet, we can't do anything
 	if hostStatus != cloud.StatusRunning {
 		return false, nil
@@ -170,6 +199,50 @@ func (init *HostInit) IsHostReady(host *host.Host) (bool, error) {
 	return reachable, nil
 }
 
+// checkUserDataHost replaces a host provisioned with user data if its
+// instance failed to start. Otherwise the host is left to set itself up.
+func (init *HostInit) checkUserDataHost(h *host.Host) error {
+	cloudMgr, err := providers.GetCloudManager(h.Provider, init.Settings)
+	if err != nil {
+		return fmt.Errorf("failed to get cloud manager for provider %v: %v", h.Provider, err)
+	}
+	hostStatus, err := cloudMgr.GetInstanceStatus(h)
+	if err != nil {
+		return fmt.Errorf("error checking instance status of host %v: %v", h.Id, err)
+	}
+	if hostStatus != cloud.StatusFailed {
+		return nil
+	}
+	evergreen.Logger.Logf(slogger.WARN, "Instance for host %v failed to start", h.Id)
+	return init.replaceFailedHost(h)
+}
+
+// replaceFailedHost terminates a host whose instance failed to start and,
+// if its provider can fall back to another source of instances, starts a
+// replacement. Spawn hosts are replaced by their user instead.
+func (init *HostInit) replaceFailedHost(h *host.Host) error {
+	cloudHost, err := providers.GetCloudHost(h, init.Settings)
+	if err != nil {
+		return fmt.Errorf("failed to get cloud host for %v: %v", h.Id, err)
+	}
+	if err := cloudHost.TerminateInstance(); err != nil {
+		return fmt.Errorf("error terminating host %v: %v", h.Id, err)
+	}
+	fallbackMgr, ok := cloudHost.CloudMgr.(cloud.FallbackManager)
+	if !ok || h.UserHost {
+		return nil
+	}
+	replacement, err := fallbackMgr.SpawnFallback(h)
+	if err != nil {
+		return fmt.Errorf("error starting replacement for host %v: %v", h.Id, err)
+	}
+	if replacement != nil {
+		evergreen.Logger.Logf(slogger.INFO, "Replaced failed host %v with %v",
+			h.Id, replacement.Id)
+	}
+	return nil
+}
+
 // setupHost runs the specified setup script for an individual host. Returns
 // the output from running the script remotely, as well as any error that
 // occurs. If the script exits with a non-zero exit code, the error will be non-nil.
@@ -199,9 +272,25 @@ func (init *HostInit) setupHost(targetHost *host.Host) ([]byte, error) {
 		evergreen.Logger.Logf(slogger.WARN, "OnUp callback failed for host '%v': '%v'", targetHost.Id, err)
 	}
 
-	// run the remote setup script as sudo, if appropriate
+	// build the setup script
+	setup, err := init.buildSetupScript(targetHost)
+	if err != nil {
+		return nil, fmt.Errorf("error building setup script for host %v: %v", targetHost.Id, err)
+	}
+
+	return runRemoteScript(init, targetHost, setup, targetHost.Distro.SetupAsSudo)
+}
+
+// runRemoteScript is how scripts are run on hosts; tests replace it to avoid
+// needing SSH access to the hosts they start.
+var runRemoteScript = (*HostInit).copyAndRunScript
+
+// copyAndRunScript copies the script over to the host and runs it there, as
+// sudo if asked to. Returns the script's combined output, as well as any error
+// that occurs; a non-zero exit code results in a non-nil error.
+func (init *HostInit) copyAndRunScript(targetHost *host.Host, script string, asSudo bool) ([]byte, error) {
 	sudoStr := ""
-	if targetHost.Distro.SetupAsSudo {
+	if asSudo {
 		sudoStr = "sudo "
 	}
 
@@ -226,14 +315,8 @@ func (init *HostInit) setupHost(targetHost *host.Host) ([]byte, error) {
 		os.Remove(file.Name())
 	}()
 
-	// build the setup script
-	setup, err := init.buildSetupScript(targetHost)
-	if err != nil {
-		return nil, fmt.Errorf("error building setup script for host %v: %v", targetHost.Id, err)
-	}
-
 	// write the setup script to the file
-	if _, err := file.Write([]byte(setup)); err != nil {
+	if _, err := file.Write([]byte(script)); err != nil {
 		return nil, fmt.Errorf("error writing remote setup script: %v", err)
 	}
 
@@ -285,7 +368,7 @@ func (init *HostInit) setupHost(targetHost *host.Host) ([]byte, error) {
 	}
 
 	// only force creation of a tty if sudo
-	if targetHost.Distro.SetupAsSudo {
+	if asSudo {
 		runSetupCmd.Options = []string{"-t", "-t", "-p", hostInfo.Port}
 	}
 	runSetupCmd.Options = append(runSetupCmd.Options, sshOptions...)
@@ -345,6 +428,18 @@ func (init *HostInit) ProvisionHost(h *host.Host) error {
 
 	}
 
+	// hosts running a pull agent get it started once, here; after that it
+	// asks the API server for tasks on its own
+	if h.Distro.PullAgent {
+		if err := init.startPullAgent(h); err != nil {
+			event.LogProvisionFailed(h.Id, err.Error())
+			if err := h.SetUnprovisioned(); err != nil {
+				evergreen.Logger.Logf(slogger.ERROR, "unprovisioning host %v failed: %v", h.Id, err)
+			}
+			return fmt.Errorf("error starting pull agent on host %v: %v", h.Id, err)
+		}
+	}
+
 	// the setup was successful. update the host accordingly in the database
 	if err := h.MarkAsProvisioned(); err != nil {
 		return fmt.Errorf("error marking host %v as provisioned: %v", err)
@@ -355,3 +450,19 @@ func (init *HostInit) ProvisionHost(h *host.Host) error {
 	return nil
 
 }
+
+// startPullAgent gives the host a secret to authenticate with, then starts
+// its long-lived agent and records the agent's revision.
+func (init *HostInit) startPullAgent(h *host.Host) error {
+	if err := h.CreateSecret(); err != nil {
+		return fmt.Errorf("error creating host secret: %v", err)
+	}
+	gateway := &taskrunner.AgentBasedHostGateway{
+		ExecutablesDir: filepath.Join(evergreen.FindEvergreenHome(), init.Settings.AgentExecutablesDir),
+	}
+	agentRevision, err := gateway.StartPullAgent(init.Settings, *h)
+	if err != nil {
+		return err
+	}
+	return h.SetAgentRevision(agentRevision)
+}
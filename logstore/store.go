@@ -0,0 +1,226 @@
+// Package logstore keeps task and test logs as gzip-compressed blobs on local
+// disk or in s3 instead of in the database.
+//
+// Each chunk of task log messages the agent sends is stored as its own blob
+// under task_logs/<task id>/<execution>/, named so that listing the directory
+// returns the chunks in the order they were written. Test log lines are stored
+// as a blob under test_logs/<log id>, while the test log's name, task and
+// execution stay in the database so the log can still be looked up by them.
+package logstore
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"github.com/10gen-labs/slogger/v1"
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/blobstore"
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/evergreen-ci/evergreen/model"
+	"gopkg.in/mgo.v2/bson"
+	"path"
+	"strconv"
+	"time"
+)
+
+const (
+	MongoBackend = "mongo"
+	LocalBackend = blobstore.LocalBackend
+	S3Backend    = blobstore.S3Backend
+
+	taskLogsDir = "task_logs"
+	testLogsDir = "test_logs"
+	blobSuffix  = ".json.gz"
+)
+
+// New returns the log store described by the settings.
+func New(settings *evergreen.Settings) (model.LogStore, error) {
+	conf := settings.LogStorage
+	if conf.Backend == "" || conf.Backend == MongoBackend {
+		return model.MongoLogStore{}, nil
+	}
+	if conf.AWS.Id == "" {
+		conf.AWS = settings.Providers.AWS
+	}
+	bucket, err := blobstore.New(blobstore.Config{
+		Backend:    conf.Backend,
+		LocalPath:  conf.LocalPath,
+		S3Bucket:   conf.S3Bucket,
+		S3Prefix:   conf.S3Prefix,
+		AWSKey:     conf.AWS.Id,
+		AWSSecret:  conf.AWS.Secret,
+		S3Endpoint: conf.S3Endpoint,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &BlobStore{Bucket: bucket}, nil
+}
+
+// BlobStore keeps compressed logs in a Bucket. Logs of tasks that ran before
+// the store was enabled are still read from the database.
+type BlobStore struct {
+	Bucket blobstore.Bucket
+}
+
+func taskLogDir(taskId string, execution int) string {
+	return path.Join(taskLogsDir, taskId, strconv.Itoa(execution))
+}
+
+// InsertTaskLog compresses the chunk of log messages into a new blob.
+func (bs *BlobStore) InsertTaskLog(taskLog *model.TaskLog) error {
+	if taskLog.Timestamp.IsZero() {
+		taskLog.Timestamp = time.Now()
+	}
+	data, err := compress(taskLog.Messages)
+	if err != nil {
+		return fmt.Errorf("error compressing task log: %v", err)
+	}
+	// chunks are named by their timestamp so they list in order, with an
+	// object id to keep chunks sent at the same time apart
+	name := fmt.Sprintf("%020d-%v%v", taskLog.Timestamp.UnixNano(), bson.NewObjectId().Hex(), blobSuffix)
+	return bs.Bucket.Put(path.Join(taskLogDir(taskLog.TaskId, taskLog.Execution), name), data)
+}
+
+// getTaskLogChunk returns the log messages stored in the blob.
+func (bs *BlobStore) getTaskLogChunk(key string) ([]model.LogMessage, error) {
+	data, err := bs.Bucket.Get(key)
+	if err != nil {
+		return nil, fmt.Errorf("error reading task log %v: %v", key, err)
+	}
+	messages := []model.LogMessage{}
+	if err := decompress(data, &messages); err != nil {
+		return nil, fmt.Errorf("error decompressing task log %v: %v", key, err)
+	}
+	return messages, nil
+}
+
+// GetRawTaskLogChannel streams the task's log messages, oldest first.
+func (bs *BlobStore) GetRawTaskLogChannel(taskId string, execution int, severities []string,
+	msgTypes []string) (chan model.LogMessage, error) {
+	keys, err := bs.Bucket.List(taskLogDir(taskId, execution))
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return model.MongoLogStore{}.GetRawTaskLogChannel(taskId, execution, severities, msgTypes)
+	}
+
+	channel := make(chan model.LogMessage, 100)
+	go func() {
+		defer close(channel)
+		for _, key := range keys {
+			messages, err := bs.getTaskLogChunk(key)
+			if err != nil {
+				evergreen.Logger.Logf(slogger.ERROR, "Error streaming logs for task %v: %v", taskId, err)
+				return
+			}
+			for _, logMsg := range messages {
+				if logMsg.MatchesFilter(severities, msgTypes) {
+					channel <- logMsg
+				}
+			}
+		}
+	}()
+	return channel, nil
+}
+
+// FindMostRecentLogMessages returns the task's most recent log messages,
+// newest first, reading chunks from the newest back until it has enough.
+func (bs *BlobStore) FindMostRecentLogMessages(taskId string, execution int, numMsgs int,
+	severities []string, msgTypes []string) ([]model.LogMessage, error) {
+	keys, err := bs.Bucket.List(taskLogDir(taskId, execution))
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return model.MongoLogStore{}.FindMostRecentLogMessages(taskId, execution, numMsgs,
+			severities, msgTypes)
+	}
+
+	logMsgs := []model.LogMessage{}
+	for i := len(keys) - 1; i >= 0 && len(logMsgs) < numMsgs; i-- {
+		messages, err := bs.getTaskLogChunk(keys[i])
+		if err != nil {
+			return nil, err
+		}
+		for j := len(messages) - 1; j >= 0 && len(logMsgs) < numMsgs; j-- {
+			if messages[j].MatchesFilter(severities, msgTypes) {
+				logMsgs = append(logMsgs, messages[j])
+			}
+		}
+	}
+	return logMsgs, nil
+}
+
+// InsertTestLog compresses the test log's lines into a blob and inserts the
+// rest of the test log into the database.
+func (bs *BlobStore) InsertTestLog(testLog *model.TestLog) error {
+	testLog.Id = bson.NewObjectId().Hex()
+	if err := testLog.Validate(); err != nil {
+		return fmt.Errorf("cannot insert invalid test log: %v", err)
+	}
+	data, err := compress(testLog.Lines)
+	if err != nil {
+		return fmt.Errorf("error compressing test log: %v", err)
+	}
+	key := path.Join(testLogsDir, testLog.Id+blobSuffix)
+	if err := bs.Bucket.Put(key, data); err != nil {
+		return err
+	}
+
+	meta := *testLog
+	meta.Lines = nil
+	meta.BlobKey = key
+	return db.Insert(model.TestLogCollection, &meta)
+}
+
+// withLines fills in the lines of a test log kept in the bucket.
+func (bs *BlobStore) withLines(testLog *model.TestLog, err error) (*model.TestLog, error) {
+	if err != nil || testLog == nil || testLog.BlobKey == "" {
+		return testLog, err
+	}
+	data, err := bs.Bucket.Get(testLog.BlobKey)
+	if err != nil {
+		return nil, fmt.Errorf("error reading test log %v: %v", testLog.Id, err)
+	}
+	if err := decompress(data, &testLog.Lines); err != nil {
+		return nil, fmt.Errorf("error decompressing test log %v: %v", testLog.Id, err)
+	}
+	return testLog, nil
+}
+
+// FindOneTestLogById returns the test log with the id, or nil if there is none.
+func (bs *BlobStore) FindOneTestLogById(id string) (*model.TestLog, error) {
+	return bs.withLines(model.MongoLogStore{}.FindOneTestLogById(id))
+}
+
+// FindOneTestLog returns the test log for the test's name, task id and
+// execution, or nil if there is none.
+func (bs *BlobStore) FindOneTestLog(name, task string, execution int) (*model.TestLog, error) {
+	return bs.withLines(model.MongoLogStore{}.FindOneTestLog(name, task, execution))
+}
+
+// compress returns the gzipped JSON encoding of the value.
+func compress(v interface{}) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	w := gzip.NewWriter(buf)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decompress decodes gzipped JSON into the value.
+func decompress(data []byte, v interface{}) error {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	return json.NewDecoder(r).Decode(v)
+}
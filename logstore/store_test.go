@@ -0,0 +1,155 @@
+package logstore
+
+import (
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/testutil"
+	"github.com/goamz/goamz/aws"
+	"github.com/goamz/goamz/s3"
+	"github.com/goamz/goamz/s3/s3test"
+	. "github.com/smartystreets/goconvey/convey"
+	"gopkg.in/mgo.v2/bson"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func logMessages(start time.Time, msgs ...string) []model.LogMessage {
+	messages := []model.LogMessage{}
+	for i, msg := range msgs {
+		messages = append(messages, model.LogMessage{
+			Type:      model.TaskLogPrefix,
+			Severity:  model.LogInfoPrefix,
+			Message:   msg,
+			Timestamp: start.Add(time.Duration(i) * time.Second),
+		})
+	}
+	return messages
+}
+
+func messageText(messages []model.LogMessage) []string {
+	text := []string{}
+	for _, msg := range messages {
+		text = append(text, msg.Message)
+	}
+	return text
+}
+
+func testStore(store model.LogStore) {
+	Convey("after inserting chunks of a task's log", func() {
+		start := time.Now()
+		So(store.InsertTaskLog(&model.TaskLog{
+			TaskId:    "t1",
+			Timestamp: start,
+			Messages:  logMessages(start, "one", "two"),
+		}), ShouldBeNil)
+		last := logMessages(start.Add(time.Minute), "three", "four")
+		last[1].Type = model.SystemLogPrefix
+		So(store.InsertTaskLog(&model.TaskLog{
+			TaskId:    "t1",
+			Timestamp: start.Add(time.Minute),
+			Messages:  last,
+		}), ShouldBeNil)
+		So(store.InsertTaskLog(&model.TaskLog{
+			TaskId:    "t1",
+			Execution: 1,
+			Timestamp: start,
+			Messages:  logMessages(start, "retried"),
+		}), ShouldBeNil)
+
+		Convey("streaming the log should return every message in order", func() {
+			channel, err := store.GetRawTaskLogChannel("t1", 0, []string{}, []string{})
+			So(err, ShouldBeNil)
+			messages := []model.LogMessage{}
+			for msg := range channel {
+				messages = append(messages, msg)
+			}
+			So(messageText(messages), ShouldResemble, []string{"one", "two", "three", "four"})
+		})
+
+		Convey("streaming the log should filter by type", func() {
+			channel, err := store.GetRawTaskLogChannel("t1", 0, []string{}, []string{model.SystemLogPrefix})
+			So(err, ShouldBeNil)
+			messages := []model.LogMessage{}
+			for msg := range channel {
+				messages = append(messages, msg)
+			}
+			So(messageText(messages), ShouldResemble, []string{"four"})
+		})
+
+		Convey("the most recent messages should come newest first", func() {
+			messages, err := store.FindMostRecentLogMessages("t1", 0, 3, []string{}, []string{})
+			So(err, ShouldBeNil)
+			So(messageText(messages), ShouldResemble, []string{"four", "three", "two"})
+
+			messages, err = store.FindMostRecentLogMessages("t1", 1, 3, []string{}, []string{model.TaskLogPrefix})
+			So(err, ShouldBeNil)
+			So(messageText(messages), ShouldResemble, []string{"retried"})
+		})
+	})
+}
+
+func TestLocalStore(t *testing.T) {
+	root, err := ioutil.TempDir("", "logstore")
+	testutil.HandleTestingErr(err, t, "error creating temp dir")
+	defer os.RemoveAll(root)
+
+	Convey("With a local log store", t, func() {
+		So(os.RemoveAll(root), ShouldBeNil)
+		store, err := New(&evergreen.Settings{LogStorage: evergreen.LogStorageConfig{
+			Backend:   LocalBackend,
+			LocalPath: root,
+		}})
+		So(err, ShouldBeNil)
+
+		testStore(store)
+	})
+}
+
+func TestS3Store(t *testing.T) {
+	srv, err := s3test.NewServer(&s3test.Config{})
+	testutil.HandleTestingErr(err, t, "error starting s3 test server")
+	defer srv.Quit()
+
+	region := aws.Region{
+		Name:                 "faux-region-1",
+		S3Endpoint:           srv.URL(),
+		S3LocationConstraint: true,
+	}
+	err = s3.New(aws.Auth{}, region).Bucket("logs").PutBucket(s3.Private)
+	testutil.HandleTestingErr(err, t, "error creating s3 bucket")
+
+	Convey("With an s3 log store backed by a local s3 server", t, func() {
+		// each run gets its own prefix so it starts with no logs
+		store, err := New(&evergreen.Settings{LogStorage: evergreen.LogStorageConfig{
+			Backend:    S3Backend,
+			S3Bucket:   "logs",
+			S3Prefix:   bson.NewObjectId().Hex(),
+			S3Endpoint: srv.URL(),
+		}})
+		So(err, ShouldBeNil)
+
+		testStore(store)
+	})
+}
+
+func TestNewValidatesConfig(t *testing.T) {
+	Convey("When creating a log store", t, func() {
+		Convey("the database should be the default", func() {
+			store, err := New(&evergreen.Settings{})
+			So(err, ShouldBeNil)
+			So(store, ShouldResemble, model.MongoLogStore{})
+		})
+		Convey("an unknown backend should be rejected", func() {
+			_, err := New(&evergreen.Settings{LogStorage: evergreen.LogStorageConfig{Backend: "ftp"}})
+			So(err, ShouldNotBeNil)
+		})
+		Convey("a backend with missing settings should be rejected", func() {
+			_, err := New(&evergreen.Settings{LogStorage: evergreen.LogStorageConfig{Backend: LocalBackend}})
+			So(err, ShouldNotBeNil)
+			_, err = New(&evergreen.Settings{LogStorage: evergreen.LogStorageConfig{Backend: S3Backend}})
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
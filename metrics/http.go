@@ -0,0 +1,69 @@
+package metrics
+
+import (
+	"github.com/codegangsta/negroni"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// the most path segments kept in a request's route label
+const maxRouteSegments = 5
+
+// HTTPRequestDuration records how long the servers take to handle requests.
+var HTTPRequestDuration = NewHistogram("evergreen_http_request_duration_seconds",
+	"Time taken to handle HTTP requests, by server, method, route and response code.",
+	[]float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+	"server", "method", "route", "code")
+
+func init() {
+	Default.Register(HTTPRequestDuration)
+}
+
+// RequestTimer is negroni middleware that records the duration of each
+// request to the server in HTTPRequestDuration.
+type RequestTimer struct {
+	server string
+}
+
+// NewRequestTimer returns middleware timing the requests to the named server.
+func NewRequestTimer(server string) *RequestTimer {
+	return &RequestTimer{server}
+}
+
+func (rt *RequestTimer) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	start := time.Now()
+	next(rw, r)
+	code := http.StatusOK
+	if res, ok := rw.(negroni.ResponseWriter); ok && res.Status() != 0 {
+		code = res.Status()
+	}
+	HTTPRequestDuration.Observe(time.Since(start).Seconds(),
+		rt.server, r.Method, routeLabel(r.URL.Path), strconv.Itoa(code))
+}
+
+var literalSegment = regexp.MustCompile("^([a-z_]+|v?[0-9])$")
+
+// routeLabel returns the route of the request path, with the segments that
+// look like ids (anything but lowercase letters and underscores, or an API
+// version such as "2" or "v1") replaced by ":id", so that requests for
+// different tasks, hosts and so on are counted together.
+func routeLabel(path string) string {
+	segments := []string{}
+	for _, segment := range strings.Split(path, "/") {
+		if segment == "" {
+			continue
+		}
+		if len(segments) == maxRouteSegments {
+			segments = append(segments, "...")
+			break
+		}
+		if !literalSegment.MatchString(segment) {
+			segment = ":id"
+		}
+		segments = append(segments, segment)
+	}
+	return "/" + strings.Join(segments, "/")
+}
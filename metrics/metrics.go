@@ -0,0 +1,302 @@
+// Package metrics exports Evergreen's operational metrics in the Prometheus
+// text exposition format, for scraping from the /metrics endpoint of the API
+// and UI servers.
+//
+// Most metrics are read from the database when they're scraped, so every
+// server reports the same values for them; request latencies are recorded by
+// each server as it handles requests.
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/10gen-labs/slogger/v1"
+	"github.com/evergreen-ci/evergreen"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// the content type of the Prometheus text format
+const contentType = "text/plain; version=0.0.4"
+
+// Metric is a named set of samples that can be written in the text format.
+type Metric interface {
+	Name() string
+	// Write writes the metric's samples, with their HELP and TYPE lines.
+	Write(w io.Writer) error
+}
+
+// Sample is one value of a metric, along with the values of its labels.
+type Sample struct {
+	LabelValues []string
+	Value       float64
+}
+
+// Registry is the set of metrics served together.
+type Registry struct {
+	mu      sync.Mutex
+	metrics []Metric
+}
+
+// Default is the registry served by Handler.
+var Default = &Registry{}
+
+// Register adds the metric to the registry.
+func (reg *Registry) Register(m Metric) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	reg.metrics = append(reg.metrics, m)
+}
+
+// ServeHTTP writes every metric in the registry, sorted by name. Metrics that
+// fail to collect are logged and left out, rather than failing the scrape.
+func (reg *Registry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	reg.mu.Lock()
+	metrics := make([]Metric, len(reg.metrics))
+	copy(metrics, reg.metrics)
+	reg.mu.Unlock()
+
+	sort.Sort(byName(metrics))
+	out := &bytes.Buffer{}
+	for _, m := range metrics {
+		buf := &bytes.Buffer{}
+		if err := m.Write(buf); err != nil {
+			evergreen.Logger.Logf(slogger.ERROR, "Error collecting metric %v: %v", m.Name(), err)
+			continue
+		}
+		out.Write(buf.Bytes())
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(out.Bytes())
+}
+
+// Handler returns the handler serving the default registry.
+func Handler() http.Handler {
+	return Default
+}
+
+type byName []Metric
+
+func (m byName) Len() int           { return len(m) }
+func (m byName) Less(i, j int) bool { return m[i].Name() < m[j].Name() }
+func (m byName) Swap(i, j int)      { m[i], m[j] = m[j], m[i] }
+
+// GaugeFunc is a gauge whose samples are collected each time it's written.
+type GaugeFunc struct {
+	name       string
+	help       string
+	labelNames []string
+	collect    func() ([]Sample, error)
+}
+
+// NewGaugeFunc returns a gauge whose samples are returned by collect, with
+// values for each of the label names.
+func NewGaugeFunc(name, help string, labelNames []string, collect func() ([]Sample, error)) *GaugeFunc {
+	return &GaugeFunc{name, help, labelNames, collect}
+}
+
+func (g *GaugeFunc) Name() string {
+	return g.name
+}
+
+func (g *GaugeFunc) Write(w io.Writer) error {
+	samples, err := g.collect()
+	if err != nil {
+		return err
+	}
+	writeHeader(w, g.name, g.help, "gauge")
+	for _, s := range samples {
+		writeSample(w, g.name, g.labelNames, s.LabelValues, nil, s.Value)
+	}
+	return nil
+}
+
+// SummaryFunc is a summary whose observations are collected each time it's
+// written, for values such as latencies that are read from the database.
+type SummaryFunc struct {
+	name       string
+	help       string
+	labelNames []string
+	collect    func() ([]Observations, error)
+}
+
+// Observations are the values observed for one set of label values.
+type Observations struct {
+	LabelValues []string
+	Values      []float64
+}
+
+// the quantiles reported by summaries
+var summaryQuantiles = []float64{0.5, 0.9, 0.99}
+
+// NewSummaryFunc returns a summary of the observations returned by collect,
+// with values for each of the label names.
+func NewSummaryFunc(name, help string, labelNames []string, collect func() ([]Observations, error)) *SummaryFunc {
+	return &SummaryFunc{name, help, labelNames, collect}
+}
+
+func (s *SummaryFunc) Name() string {
+	return s.name
+}
+
+func (s *SummaryFunc) Write(w io.Writer) error {
+	observations, err := s.collect()
+	if err != nil {
+		return err
+	}
+	writeHeader(w, s.name, s.help, "summary")
+	for _, obs := range observations {
+		values := make([]float64, len(obs.Values))
+		copy(values, obs.Values)
+		sort.Float64s(values)
+		sum := 0.0
+		for _, v := range values {
+			sum += v
+		}
+		for _, q := range summaryQuantiles {
+			writeSample(w, s.name, s.labelNames, obs.LabelValues,
+				[]string{"quantile", formatFloat(q)}, quantile(values, q))
+		}
+		writeSample(w, s.name+"_sum", s.labelNames, obs.LabelValues, nil, sum)
+		writeSample(w, s.name+"_count", s.labelNames, obs.LabelValues, nil, float64(len(values)))
+	}
+	return nil
+}
+
+// quantile returns the q-quantile of the sorted values, or NaN if there are none.
+func quantile(sorted []float64, q float64) float64 {
+	if len(sorted) == 0 {
+		return math.NaN()
+	}
+	return sorted[int(q*float64(len(sorted)-1)+0.5)]
+}
+
+// Histogram counts observations in buckets, for values recorded as they happen.
+type Histogram struct {
+	name       string
+	help       string
+	labelNames []string
+	buckets    []float64
+
+	mu     sync.Mutex
+	series map[string]*histogramSeries
+}
+
+type histogramSeries struct {
+	labelValues []string
+	counts      []uint64
+	sum         float64
+	count       uint64
+}
+
+// NewHistogram returns a histogram with the given upper bounds for its
+// buckets, in increasing order, and values for each of the label names.
+func NewHistogram(name, help string, buckets []float64, labelNames ...string) *Histogram {
+	return &Histogram{
+		name:       name,
+		help:       help,
+		labelNames: labelNames,
+		buckets:    buckets,
+		series:     map[string]*histogramSeries{},
+	}
+}
+
+// Observe records the value for the label values.
+func (h *Histogram) Observe(value float64, labelValues ...string) {
+	key := strings.Join(labelValues, "\xff")
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s, ok := h.series[key]
+	if !ok {
+		s = &histogramSeries{
+			labelValues: labelValues,
+			counts:      make([]uint64, len(h.buckets)),
+		}
+		h.series[key] = s
+	}
+	for i, bound := range h.buckets {
+		if value <= bound {
+			s.counts[i]++
+		}
+	}
+	s.sum += value
+	s.count++
+}
+
+func (h *Histogram) Name() string {
+	return h.name
+}
+
+func (h *Histogram) Write(w io.Writer) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	keys := []string{}
+	for key := range h.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	writeHeader(w, h.name, h.help, "histogram")
+	for _, key := range keys {
+		s := h.series[key]
+		for i, bound := range h.buckets {
+			writeSample(w, h.name+"_bucket", h.labelNames, s.labelValues,
+				[]string{"le", formatFloat(bound)}, float64(s.counts[i]))
+		}
+		writeSample(w, h.name+"_bucket", h.labelNames, s.labelValues,
+			[]string{"le", "+Inf"}, float64(s.count))
+		writeSample(w, h.name+"_sum", h.labelNames, s.labelValues, nil, s.sum)
+		writeSample(w, h.name+"_count", h.labelNames, s.labelValues, nil, float64(s.count))
+	}
+	return nil
+}
+
+func writeHeader(w io.Writer, name, help, metricType string) {
+	fmt.Fprintf(w, "# HELP %v %v\n", name, strings.Replace(help, "\n", " ", -1))
+	fmt.Fprintf(w, "# TYPE %v %v\n", name, metricType)
+}
+
+// writeSample writes one sample line, with an extra label pair (such as a
+// histogram bucket's "le") if given.
+func writeSample(w io.Writer, name string, labelNames, labelValues []string, extra []string, value float64) {
+	pairs := []string{}
+	for i, labelName := range labelNames {
+		labelValue := ""
+		if i < len(labelValues) {
+			labelValue = labelValues[i]
+		}
+		pairs = append(pairs, fmt.Sprintf("%v=\"%v\"", labelName, escapeLabelValue(labelValue)))
+	}
+	if extra != nil {
+		pairs = append(pairs, fmt.Sprintf("%v=\"%v\"", extra[0], extra[1]))
+	}
+	if len(pairs) == 0 {
+		fmt.Fprintf(w, "%v %v\n", name, formatFloat(value))
+		return
+	}
+	fmt.Fprintf(w, "%v{%v} %v\n", name, strings.Join(pairs, ","), formatFloat(value))
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+func escapeLabelValue(value string) string {
+	return labelValueEscaper.Replace(value)
+}
+
+func formatFloat(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
@@ -0,0 +1,79 @@
+package metrics
+
+import (
+	"bytes"
+	. "github.com/smartystreets/goconvey/convey"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTextFormat(t *testing.T) {
+	Convey("With a registry of metrics", t, func() {
+		reg := &Registry{}
+		hist := NewHistogram("test_duration_seconds", "Test durations.", []float64{0.1, 1}, "route")
+		hist.Observe(0.05, "/a")
+		hist.Observe(0.5, "/a")
+		hist.Observe(5, "/a")
+		reg.Register(hist)
+		reg.Register(NewGaugeFunc("test_queue_length", "Queue length.", []string{"distro"},
+			func() ([]Sample, error) {
+				return []Sample{{[]string{`ubuntu "1404"`}, 3}}, nil
+			}))
+		reg.Register(NewSummaryFunc("test_latency_seconds", "Latencies.", []string{"distro"},
+			func() ([]Observations, error) {
+				return []Observations{{[]string{"rhel"}, []float64{4, 1, 3, 2, 5}}}, nil
+			}))
+
+		Convey("the metrics should be written sorted by name in the text format", func() {
+			w := httptest.NewRecorder()
+			r, err := http.NewRequest("GET", "/metrics", nil)
+			So(err, ShouldBeNil)
+			reg.ServeHTTP(w, r)
+			So(w.Code, ShouldEqual, http.StatusOK)
+			So(w.Header().Get("Content-Type"), ShouldEqual, contentType)
+			So(w.Body.String(), ShouldEqual, `# HELP test_duration_seconds Test durations.
+# TYPE test_duration_seconds histogram
+test_duration_seconds_bucket{route="/a",le="0.1"} 1
+test_duration_seconds_bucket{route="/a",le="1"} 2
+test_duration_seconds_bucket{route="/a",le="+Inf"} 3
+test_duration_seconds_sum{route="/a"} 5.55
+test_duration_seconds_count{route="/a"} 3
+# HELP test_latency_seconds Latencies.
+# TYPE test_latency_seconds summary
+test_latency_seconds{distro="rhel",quantile="0.5"} 3
+test_latency_seconds{distro="rhel",quantile="0.9"} 5
+test_latency_seconds{distro="rhel",quantile="0.99"} 5
+test_latency_seconds_sum{distro="rhel"} 15
+test_latency_seconds_count{distro="rhel"} 5
+# HELP test_queue_length Queue length.
+# TYPE test_queue_length gauge
+test_queue_length{distro="ubuntu \"1404\""} 3
+`)
+		})
+
+		Convey("metrics that fail to collect should be left out", func() {
+			reg.Register(NewGaugeFunc("test_broken", "Broken.", nil, func() ([]Sample, error) {
+				return nil, http.ErrHandlerTimeout
+			}))
+			w := httptest.NewRecorder()
+			r, err := http.NewRequest("GET", "/metrics", nil)
+			So(err, ShouldBeNil)
+			reg.ServeHTTP(w, r)
+			So(w.Code, ShouldEqual, http.StatusOK)
+			So(bytes.Contains(w.Body.Bytes(), []byte("test_broken")), ShouldBeFalse)
+			So(bytes.Contains(w.Body.Bytes(), []byte("test_queue_length")), ShouldBeTrue)
+		})
+	})
+}
+
+func TestRouteLabel(t *testing.T) {
+	Convey("Request paths should be labeled by their route", t, func() {
+		So(routeLabel("/api/2/task/mongodb_mongo_master_linux_64_jsCore_abc123/log"), ShouldEqual,
+			"/api/2/task/:id/log")
+		So(routeLabel("/rest/v1/projects/mongodb-mongo-master/waterfall"), ShouldEqual,
+			"/rest/v1/projects/:id/waterfall")
+		So(routeLabel("/"), ShouldEqual, "/")
+		So(routeLabel("/a/b/c/d/e/f/g"), ShouldEqual, "/a/b/c/d/e/...")
+	})
+}
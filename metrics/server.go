@@ -0,0 +1,208 @@
+package metrics
+
+import (
+	"fmt"
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/distro"
+	"github.com/evergreen-ci/evergreen/model/host"
+	"gopkg.in/mgo.v2/bson"
+	"sync"
+	"time"
+)
+
+// dispatch latencies are summarized over the tasks dispatched this recently
+const dispatchLatencyWindow = 15 * time.Minute
+
+var registerServerMetrics sync.Once
+
+// RegisterServerMetrics adds the metrics read from the database to the
+// default registry: task queues, hosts, task dispatch latency, runner
+// durations and agent heartbeat lag.
+func RegisterServerMetrics() {
+	registerServerMetrics.Do(func() {
+		Default.Register(NewGaugeFunc("evergreen_task_queue_length",
+			"Number of tasks in the distro's task queue.",
+			[]string{"distro"}, collectQueueLengths))
+		Default.Register(NewGaugeFunc("evergreen_task_queue_oldest_age_seconds",
+			"Time since the longest-waiting task in the distro's queue was scheduled.",
+			[]string{"distro"}, collectQueueAges))
+		Default.Register(NewGaugeFunc("evergreen_hosts",
+			"Number of hosts that aren't terminated, by distro and status.",
+			[]string{"distro", "status"}, collectHosts))
+		Default.Register(NewSummaryFunc("evergreen_task_dispatch_latency_seconds",
+			fmt.Sprintf("Time from scheduling to dispatch of the tasks dispatched in the last %v.",
+				dispatchLatencyWindow),
+			[]string{"distro"}, collectDispatchLatencies))
+		Default.Register(NewGaugeFunc("evergreen_runner_last_duration_seconds",
+			"How long the runner's last completed run took.",
+			[]string{"runner"}, collectRunnerDurations))
+		Default.Register(NewGaugeFunc("evergreen_runner_last_finished_timestamp_seconds",
+			"Unix time the runner last completed a run.",
+			[]string{"runner"}, collectRunnerFinishTimes))
+		Default.Register(NewGaugeFunc("evergreen_task_heartbeat_lag_max_seconds",
+			"Longest time since a running task on the distro sent a heartbeat.",
+			[]string{"distro"}, collectHeartbeatLags))
+	})
+}
+
+func collectQueueLengths() ([]Sample, error) {
+	queues, err := model.FindAllTaskQueues()
+	if err != nil {
+		return nil, err
+	}
+	samples := []Sample{}
+	for _, queue := range queues {
+		samples = append(samples, Sample{[]string{queue.Distro}, float64(len(queue.Queue))})
+	}
+	return samples, nil
+}
+
+func collectQueueAges() ([]Sample, error) {
+	queues, err := model.FindAllTaskQueues()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	samples := []Sample{}
+	for _, queue := range queues {
+		age := 0.0
+		if len(queue.Queue) != 0 {
+			ids := []string{}
+			for _, item := range queue.Queue {
+				ids = append(ids, item.Id)
+			}
+			oldest, err := model.FindAllTasks(
+				bson.M{
+					model.TaskIdKey:            bson.M{"$in": ids},
+					model.TaskScheduledTimeKey: bson.M{"$gt": model.ZeroTime},
+				},
+				bson.M{model.TaskScheduledTimeKey: 1},
+				[]string{model.TaskScheduledTimeKey},
+				db.NoSkip,
+				1,
+			)
+			if err != nil {
+				return nil, err
+			}
+			if len(oldest) != 0 {
+				age = now.Sub(oldest[0].ScheduledTime).Seconds()
+			}
+		}
+		samples = append(samples, Sample{[]string{queue.Distro}, age})
+	}
+	return samples, nil
+}
+
+func collectHosts() ([]Sample, error) {
+	counts := []struct {
+		Id struct {
+			Distro string `bson:"distro"`
+			Status string `bson:"status"`
+		} `bson:"_id"`
+		Count int `bson:"count"`
+	}{}
+	pipeline := []bson.M{
+		{"$match": bson.M{host.StatusKey: bson.M{"$ne": evergreen.HostTerminated}}},
+		{"$group": bson.M{
+			"_id": bson.M{
+				"distro": "$" + host.DistroKey + "." + distro.IdKey,
+				"status": "$" + host.StatusKey,
+			},
+			"count": bson.M{"$sum": 1},
+		}},
+	}
+	if err := db.Aggregate(host.Collection, pipeline, &counts); err != nil {
+		return nil, err
+	}
+	samples := []Sample{}
+	for _, c := range counts {
+		samples = append(samples, Sample{[]string{c.Id.Distro, c.Id.Status}, float64(c.Count)})
+	}
+	return samples, nil
+}
+
+func collectDispatchLatencies() ([]Observations, error) {
+	tasks, err := model.FindAllTasks(
+		bson.M{
+			model.TaskDispatchTimeKey:  bson.M{"$gte": time.Now().Add(-dispatchLatencyWindow)},
+			model.TaskScheduledTimeKey: bson.M{"$gt": model.ZeroTime},
+		},
+		bson.M{
+			model.TaskDistroIdKey:      1,
+			model.TaskScheduledTimeKey: 1,
+			model.TaskDispatchTimeKey:  1,
+		},
+		db.NoSort,
+		db.NoSkip,
+		db.NoLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	byDistro := map[string][]float64{}
+	for _, task := range tasks {
+		byDistro[task.DistroId] = append(byDistro[task.DistroId],
+			task.DispatchTime.Sub(task.ScheduledTime).Seconds())
+	}
+	observations := []Observations{}
+	for distroId, latencies := range byDistro {
+		observations = append(observations, Observations{[]string{distroId}, latencies})
+	}
+	return observations, nil
+}
+
+func collectRunnerDurations() ([]Sample, error) {
+	runtimes, err := model.FindEveryProcessRuntime()
+	if err != nil {
+		return nil, err
+	}
+	samples := []Sample{}
+	for _, runtime := range runtimes {
+		samples = append(samples, Sample{[]string{runtime.Id}, runtime.Runtime.Seconds()})
+	}
+	return samples, nil
+}
+
+func collectRunnerFinishTimes() ([]Sample, error) {
+	runtimes, err := model.FindEveryProcessRuntime()
+	if err != nil {
+		return nil, err
+	}
+	samples := []Sample{}
+	for _, runtime := range runtimes {
+		samples = append(samples, Sample{[]string{runtime.Id},
+			float64(runtime.FinishedAt.UnixNano()) / float64(time.Second)})
+	}
+	return samples, nil
+}
+
+func collectHeartbeatLags() ([]Sample, error) {
+	tasks, err := model.FindAllTasks(
+		bson.M{model.TaskStatusKey: model.SelectorTaskInProgress},
+		bson.M{
+			model.TaskDistroIdKey:      1,
+			model.TaskLastHeartbeatKey: 1,
+		},
+		db.NoSort,
+		db.NoSkip,
+		db.NoLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	lags := map[string]float64{}
+	for _, task := range tasks {
+		lag := now.Sub(task.LastHeartbeat).Seconds()
+		if lag > lags[task.DistroId] {
+			lags[task.DistroId] = lag
+		}
+	}
+	samples := []Sample{}
+	for distroId, lag := range lags {
+		samples = append(samples, Sample{[]string{distroId}, lag})
+	}
+	return samples, nil
+}
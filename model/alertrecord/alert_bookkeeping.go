@@ -19,6 +19,7 @@ var (
 	FirstTaskTypeFailureId = "first_tasktype_failure"
 	TaskFailTransitionId   = "task_transition_failure"
 	LastRevisionNotFound   = "last_revision_not_found"
+	PerfRegressionId       = "perf_regression"
 )
 
 // Host triggers
@@ -27,6 +28,7 @@ var (
 	SpawnHostTwelveHourWarning = "spawn_twelvehour"
 	SlowProvisionWarning       = "slow_provision"
 	ProvisionFailed            = "provision_failed"
+	HostQuarantinedId          = "host_quarantined"
 )
 
 type AlertRecord struct {
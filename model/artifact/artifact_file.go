@@ -35,6 +35,15 @@ type File struct {
 	Link string `json:"link" bson:"link"`
 	// Visibility determines who can see the file in the UI
 	Visibility string `json:"visibility" bson:"visibility"`
+	// Bucket and FileKey locate the file in s3, if it was uploaded there,
+	// so that it can be deleted once it expires
+	Bucket  string `json:"bucket,omitempty" bson:"bucket,omitempty"`
+	FileKey string `json:"filekey,omitempty" bson:"filekey,omitempty"`
+	// Digest is the content digest of files kept in the artifact store
+	Digest string `json:"digest,omitempty" bson:"digest,omitempty"`
+	// Expired is set once the file has been removed by the artifact cleanup
+	// process; the link is kept around for display purposes only
+	Expired bool `json:"expired,omitempty" bson:"expired,omitempty"`
 }
 
 // Array turns the parameter map into an array of File structs.
@@ -42,7 +51,7 @@ type File struct {
 func (params Params) Array() []File {
 	var files []File
 	for name, link := range params {
-		files = append(files, File{name, link, ""})
+		files = append(files, File{Name: name, Link: link})
 	}
 	return files
 }
@@ -15,6 +15,7 @@ var (
 	FilesKey    = bsonutil.MustHaveTag(Entry{}, "Files")
 	NameKey     = bsonutil.MustHaveTag(File{}, "Name")
 	LinkKey     = bsonutil.MustHaveTag(File{}, "Link")
+	ExpiredKey  = bsonutil.MustHaveTag(File{}, "Expired")
 )
 
 // === Queries ===
@@ -29,6 +30,16 @@ func ByBuildId(id string) db.Q {
 	return db.Query(bson.D{{BuildIdKey, id}}).Sort([]string{TaskNameKey})
 }
 
+// ByUnexpiredFiles returns all entries that have at least one file which
+// has not yet been expired by the artifact cleanup process.
+func ByUnexpiredFiles() db.Q {
+	return db.Query(bson.M{
+		FilesKey: bson.M{
+			"$elemMatch": bson.M{ExpiredKey: bson.M{"$ne": true}},
+		},
+	})
+}
+
 // === DB Logic ===
 
 // Upsert updates the files entry in the db if an entry already exists,
@@ -71,3 +82,20 @@ func FindAll(query db.Q) ([]Entry, error) {
 	err := db.FindAllQ(Collection, query, &entries)
 	return entries, err
 }
+
+// MarkFileExpired flags the file with the given link in the task's entry
+// as expired, so that the UI no longer links to it.
+func MarkFileExpired(taskId, link string) error {
+	return db.Update(
+		Collection,
+		bson.M{
+			TaskIdKey:                taskId,
+			FilesKey + "." + LinkKey: link,
+		},
+		bson.M{
+			"$set": bson.M{
+				FilesKey + ".$." + ExpiredKey: true,
+			},
+		},
+	)
+}
@@ -0,0 +1,76 @@
+package model
+
+import (
+	"github.com/10gen-labs/slogger/v1"
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model/build"
+	"github.com/evergreen-ci/evergreen/model/version"
+	"time"
+)
+
+// BuildCompletionHook is run after a build finishes.
+type BuildCompletionHook func(b *build.Build) error
+
+// VersionCompletionHook is run after all of a version's builds have finished.
+type VersionCompletionHook func(v *version.Version) error
+
+var (
+	buildCompletionHooks   []BuildCompletionHook
+	versionCompletionHooks []VersionCompletionHook
+)
+
+// AddBuildCompletionHook registers a hook to run after each build finishes.
+// Hooks should be registered when the process starts.
+func AddBuildCompletionHook(hook BuildCompletionHook) {
+	buildCompletionHooks = append(buildCompletionHooks, hook)
+}
+
+// AddVersionCompletionHook registers a hook to run after each version
+// finishes. Hooks should be registered when the process starts.
+func AddVersionCompletionHook(hook VersionCompletionHook) {
+	versionCompletionHooks = append(versionCompletionHooks, hook)
+}
+
+// markBuildFinished marks the build as finished and runs the build
+// completion hooks.
+func markBuildFinished(b *build.Build, status string, finishTime time.Time) error {
+	if err := b.MarkFinished(status, finishTime); err != nil {
+		return err
+	}
+	runBuildCompletionHooks(b)
+	return nil
+}
+
+// runBuildCompletionHooks runs the hooks for the finished build. Errors are
+// logged rather than returned, since the build is finished regardless.
+func runBuildCompletionHooks(b *build.Build) {
+	for _, hook := range buildCompletionHooks {
+		if err := hook(b); err != nil {
+			evergreen.Logger.Logf(slogger.ERROR, "Error running completion hook for build %v: %v",
+				b.Id, err)
+		}
+	}
+}
+
+// runVersionCompletionHooks runs the hooks for the finished version. Errors
+// are logged rather than returned, since the version is finished regardless.
+func runVersionCompletionHooks(versionId string) {
+	if len(versionCompletionHooks) == 0 {
+		return
+	}
+	v, err := version.FindOne(version.ById(versionId).WithoutFields(version.ConfigKey))
+	if err != nil {
+		evergreen.Logger.Logf(slogger.ERROR, "Error finding version %v: %v", versionId, err)
+		return
+	}
+	if v == nil {
+		evergreen.Logger.Logf(slogger.ERROR, "Finished version %v not found", versionId)
+		return
+	}
+	for _, hook := range versionCompletionHooks {
+		if err := hook(v); err != nil {
+			evergreen.Logger.Logf(slogger.ERROR, "Error running completion hook for version %v: %v",
+				versionId, err)
+		}
+	}
+}
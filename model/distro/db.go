@@ -22,8 +22,9 @@ var (
 
 	UserDataKey = bsonutil.MustHaveTag(Distro{}, "UserData")
 
-	SpawnAllowedKey = bsonutil.MustHaveTag(Distro{}, "SpawnAllowed")
-	ExpansionsKey   = bsonutil.MustHaveTag(Distro{}, "Expansions")
+	SpawnAllowedKey          = bsonutil.MustHaveTag(Distro{}, "SpawnAllowed")
+	ExpansionsKey            = bsonutil.MustHaveTag(Distro{}, "Expansions")
+	ProvisionWithUserDataKey = bsonutil.MustHaveTag(Distro{}, "ProvisionWithUserData")
 
 	// bson fields for the UserData struct
 	UserDataFileKey     = bsonutil.MustHaveTag(UserData{}, "File")
@@ -24,6 +24,16 @@ type Distro struct {
 
 	SpawnAllowed bool        `bson:"spawn_allowed" json:"spawn_allowed,omitempty" mapstructure:"spawn_allowed,omitempty"`
 	Expansions   []Expansion `bson:"expansions,omitempty" json:"expansions,omitempty" mapstructure:"expansions,omitempty"`
+
+	// PullAgent hosts run a long-lived agent that asks the API server for
+	// its next task, instead of having the task runner start one over SSH
+	PullAgent bool `bson:"pull_agent,omitempty" json:"pull_agent,omitempty" mapstructure:"pull_agent,omitempty"`
+
+	// ProvisionWithUserData hosts are given their setup script, and their
+	// agent if they run a pull agent, as user data to run when they boot.
+	// They report back to the API server when they're done, rather than
+	// waiting for hostinit to set them up over SSH.
+	ProvisionWithUserData bool `bson:"provision_with_user_data,omitempty" json:"provision_with_user_data,omitempty" mapstructure:"provision_with_user_data,omitempty"`
 }
 
 type ValidateFormat string
@@ -0,0 +1,85 @@
+package distro
+
+import (
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/evergreen-ci/evergreen/db/bsonutil"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"time"
+)
+
+const DryRunCollection = "distro_dry_runs"
+
+// statuses of a dry run
+const (
+	DryRunRunning   = "running"
+	DryRunSucceeded = "succeeded"
+	DryRunFailed    = "failed"
+)
+
+// DryRun is a test of a distro's configuration, which provisions one host
+// of the distro, runs a command on it and then terminates it.
+type DryRun struct {
+	Id         string       `bson:"_id" json:"id"`
+	DistroId   string       `bson:"distro_id" json:"distro_id"`
+	HostId     string       `bson:"host_id,omitempty" json:"host_id,omitempty"`
+	User       string       `bson:"user" json:"user"`
+	Status     string       `bson:"status" json:"status"`
+	CreateTime time.Time    `bson:"create_time" json:"create_time"`
+	FinishTime time.Time    `bson:"finish_time" json:"finish_time"`
+	Steps      []DryRunStep `bson:"steps" json:"steps"`
+}
+
+// DryRunStep is the result of one step of a dry run.
+type DryRunStep struct {
+	Name       string    `bson:"name" json:"name"`
+	Output     string    `bson:"output" json:"output"`
+	Error      string    `bson:"error,omitempty" json:"error,omitempty"`
+	StartTime  time.Time `bson:"start_time" json:"start_time"`
+	FinishTime time.Time `bson:"finish_time" json:"finish_time"`
+}
+
+var (
+	// bson fields for the DryRun struct
+	DryRunIdKey       = bsonutil.MustHaveTag(DryRun{}, "Id")
+	DryRunDistroIdKey = bsonutil.MustHaveTag(DryRun{}, "DistroId")
+	DryRunStatusKey   = bsonutil.MustHaveTag(DryRun{}, "Status")
+)
+
+// NewDryRun returns a dry run of the distro started by the user, which
+// hasn't been saved yet.
+func NewDryRun(distroId, user string) *DryRun {
+	return &DryRun{
+		Id:         bson.NewObjectId().Hex(),
+		DistroId:   distroId,
+		User:       user,
+		Status:     DryRunRunning,
+		CreateTime: time.Now(),
+		Steps:      []DryRunStep{},
+	}
+}
+
+// FindOneDryRun gets one DryRun for the given query.
+func FindOneDryRun(query db.Q) (*DryRun, error) {
+	run := &DryRun{}
+	err := db.FindOneQ(DryRunCollection, query, run)
+	if err == mgo.ErrNotFound {
+		return nil, nil
+	}
+	return run, err
+}
+
+// DryRunById returns a query that contains an Id selector on the string, id.
+func DryRunById(id string) db.Q {
+	return db.Query(bson.D{{DryRunIdKey, id}})
+}
+
+// Insert writes the dry run to the database.
+func (run *DryRun) Insert() error {
+	return db.Insert(DryRunCollection, run)
+}
+
+// Update saves the dry run's current status and steps.
+func (run *DryRun) Update() error {
+	return db.UpdateId(DryRunCollection, run.Id, run)
+}
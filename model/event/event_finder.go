@@ -3,6 +3,7 @@ package event
 import (
 	"github.com/evergreen-ci/evergreen/db"
 	"gopkg.in/mgo.v2/bson"
+	"time"
 )
 
 // === DB Logic ===
@@ -31,6 +32,17 @@ func HostEventsInOrder(id string) db.Q {
 	return HostEventsForId(id).Sort([]string{TimestampKey})
 }
 
+// RecentHostProvisionFailures returns the up to n most recent provisioning
+// failures of the host after the given time, most recent first.
+func RecentHostProvisionFailures(id string, since time.Time, n int) db.Q {
+	return db.Query(bson.D{
+		{DataKey + "." + ResourceTypeKey, ResourceTypeHost},
+		{ResourceIdKey, id},
+		{TypeKey, EventHostProvisionFailed},
+		{TimestampKey, bson.M{"$gt": since}},
+	}).Sort([]string{"-" + TimestampKey}).Limit(n)
+}
+
 // Task Events
 func TaskEventsForId(id string) db.Q {
 	return db.Query(bson.D{
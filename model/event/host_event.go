@@ -19,6 +19,7 @@ const (
 	EventHostRunningTaskSet     = "HOST_RUNNING_TASK_SET"
 	EventHostRunningTaskCleared = "HOST_RUNNING_TASK_CLEARED"
 	EventHostTaskPidSet         = "HOST_TASK_PID_SET"
+	EventHostQuarantined        = "HOST_QUARANTINED"
 )
 
 // implements EventData
@@ -90,3 +91,9 @@ func LogHostTaskPidSet(hostId string, taskPid string) {
 func LogProvisionFailed(hostId string, setupLog string) {
 	LogHostEvent(hostId, EventHostProvisionFailed, HostEventData{SetupLog: setupLog})
 }
+
+// LogHostQuarantined logs that the host was quarantined after repeated
+// system failures, the last of which was in the given task.
+func LogHostQuarantined(hostId string, taskId string) {
+	LogHostEvent(hostId, EventHostQuarantined, HostEventData{TaskId: taskId})
+}
@@ -21,6 +21,8 @@ const (
 	TaskDeactivated  = "TASK_DEACTIVATED"
 	TaskAbortRequest = "TASK_ABORT_REQUEST"
 	TaskScheduled    = "TASK_SCHEDULED"
+
+	TaskSystemFailureRetried = "TASK_SYSTEM_FAILURE_RETRIED"
 )
 
 // implements Data
@@ -76,6 +78,12 @@ func LogTaskRestarted(taskId string, userId string) {
 	LogTaskEvent(taskId, TaskRestarted, TaskEventData{UserId: userId})
 }
 
+// LogTaskSystemFailureRetried logs that a task was automatically rescheduled
+// after failing with a system failure on the given host.
+func LogTaskSystemFailureRetried(taskId, hostId string) {
+	LogTaskEvent(taskId, TaskSystemFailureRetried, TaskEventData{HostId: hostId})
+}
+
 func LogTaskActivated(taskId string, userId string) {
 	LogTaskEvent(taskId, TaskActivated, TaskEventData{UserId: userId})
 }
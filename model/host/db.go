@@ -44,6 +44,9 @@ var (
 	NotificationsKey         = bsonutil.MustHaveTag(Host{}, "Notifications")
 	UserDataKey              = bsonutil.MustHaveTag(Host{}, "UserData")
 	LastReachabilityCheckKey = bsonutil.MustHaveTag(Host{}, "LastReachabilityCheck")
+	QuarantineTimeKey        = bsonutil.MustHaveTag(Host{}, "QuarantineTime")
+	HomeVolumeIdKey          = bsonutil.MustHaveTag(Host{}, "HomeVolumeId")
+	SecretKey                = bsonutil.MustHaveTag(Host{}, "Secret")
 )
 
 // === Queries ===
@@ -178,6 +181,11 @@ func ById(id string) db.Q {
 	return db.Query(bson.D{{IdKey, id}})
 }
 
+// ByTag produces a query that returns a host with the given tag.
+func ByTag(tag string) db.Q {
+	return db.Query(bson.D{{TagKey, tag}})
+}
+
 // ByIds produces a query that returns all hosts in the given list of ids.
 func ByIds(ids []string) db.Q {
 	return db.Query(bson.D{
@@ -229,6 +237,23 @@ func ByNotMonitoredSince(threshold time.Time) db.Q {
 	})
 }
 
+// ByRunningTaskNotMonitoredSince produces a query that returns all hosts
+// running tasks whose last reachability check was before the specified
+// threshold, filtering out user-spawned hosts.
+func ByRunningTaskNotMonitoredSince(threshold time.Time) db.Q {
+	return db.Query(bson.M{
+		RunningTaskKey: bson.M{"$exists": true, "$ne": ""},
+		StatusKey: bson.M{
+			"$in": []string{evergreen.HostRunning, evergreen.HostUnreachable},
+		},
+		StartedByKey: evergreen.User,
+		"$or": []bson.M{
+			bson.M{LastReachabilityCheckKey: bson.M{"$lte": threshold}},
+			bson.M{LastReachabilityCheckKey: bson.M{"$exists": false}},
+		},
+	})
+}
+
 // ByExpiringBetween produces a query that returns  any user-spawned hosts
 // that will expire between the specified times.
 func ByExpiringBetween(lowerBound time.Time, upperBound time.Time) db.Q {
@@ -253,6 +278,26 @@ func ByExpiredSince(time time.Time) db.Q {
 	})
 }
 
+// ByQuarantinedBefore produces a query that returns all hosts that were
+// quarantined for repeated system failures before the given time. Hosts
+// quarantined by hand have no quarantine time and are left alone.
+func ByQuarantinedBefore(threshold time.Time) db.Q {
+	return db.Query(bson.M{
+		StatusKey:         evergreen.HostQuarantined,
+		QuarantineTimeKey: bson.M{"$exists": true, "$lte": threshold},
+	})
+}
+
+// ByTerminatedWithRunningTaskSince produces a query that returns all hosts
+// terminated after the given time that were still running a task.
+func ByTerminatedWithRunningTaskSince(threshold time.Time) db.Q {
+	return db.Query(bson.M{
+		StatusKey:          evergreen.HostTerminated,
+		TerminationTimeKey: bson.M{"$gt": threshold},
+		RunningTaskKey:     bson.M{"$exists": true, "$ne": ""},
+	})
+}
+
 // IsProvisioningFailure is a query that returns all hosts that
 // failed to provision.
 var IsProvisioningFailure = db.Query(bson.D{{StatusKey, evergreen.HostProvisionFailed}})
@@ -7,6 +7,7 @@ import (
 	"github.com/evergreen-ci/evergreen/db"
 	"github.com/evergreen-ci/evergreen/model/distro"
 	"github.com/evergreen-ci/evergreen/model/event"
+	"github.com/evergreen-ci/evergreen/util"
 	"gopkg.in/mgo.v2"
 	"gopkg.in/mgo.v2/bson"
 	"time"
@@ -52,6 +53,15 @@ type Host struct {
 
 	// the last time that the host's reachability was checked
 	LastReachabilityCheck time.Time `bson:"last_reachability_check" json:"last_reachability_check"`
+
+	// the time the host was quarantined because of repeated system failures
+	QuarantineTime time.Time `bson:"quarantine_time,omitempty" json:"quarantine_time,omitempty"`
+
+	// the persistent volume attached to a spawn host as its home volume
+	HomeVolumeId string `bson:"home_volume_id,omitempty" json:"home_volume_id,omitempty"`
+
+	// the secret a pull agent authenticates with when asking for tasks
+	Secret string `bson:"secret,omitempty" json:"-"`
 }
 
 // IdleTime returns how long has this host been idle
@@ -131,6 +141,25 @@ func (self *Host) SetUnreachable() error {
 	return self.SetStatus(evergreen.HostUnreachable)
 }
 
+// SetStopped marks a spawn host as stopped. Providers hand out a new
+// DNS name when a stopped host is started again, so the old one is cleared.
+func (self *Host) SetStopped() error {
+	if err := self.SetStatus(evergreen.HostStopped); err != nil {
+		return err
+	}
+	self.Host = ""
+	return UpdateOne(
+		bson.M{
+			IdKey: self.Id,
+		},
+		bson.M{
+			"$set": bson.M{
+				DNSKey: "",
+			},
+		},
+	)
+}
+
 func (self *Host) SetUnprovisioned() error {
 	return UpdateOne(
 		bson.M{
@@ -149,13 +178,34 @@ func (self *Host) SetQuarantined(status string) error {
 	return self.SetStatus(evergreen.HostQuarantined)
 }
 
+// QuarantineForSystemFailures quarantines the host after tasks repeatedly hit
+// system failures on it, recording the time so that the monitor can
+// decommission it once the grace period for inspecting it is over.
+func (self *Host) QuarantineForSystemFailures(lastTaskId string) error {
+	if err := self.SetQuarantined(evergreen.HostQuarantined); err != nil {
+		return err
+	}
+	event.LogHostQuarantined(self.Id, lastTaskId)
+	self.QuarantineTime = time.Now()
+	return UpdateOne(
+		bson.M{
+			IdKey: self.Id,
+		},
+		bson.M{
+			"$set": bson.M{
+				QuarantineTimeKey: self.QuarantineTime,
+			},
+		},
+	)
+}
+
 func (self *Host) Terminate() error {
 	err := self.SetTerminated()
 	if err != nil {
 		return err
 	}
 	self.TerminationTime = time.Now()
-	return UpdateOne(
+	err = UpdateOne(
 		bson.M{
 			IdKey: self.Id,
 		},
@@ -165,6 +215,11 @@ func (self *Host) Terminate() error {
 			},
 		},
 	)
+	if err != nil {
+		return err
+	}
+	// volumes outlive the host they're attached to
+	return DetachVolumes(self.Id)
 }
 
 // SetDNSName updates the DNS name for a given host once
@@ -276,6 +331,57 @@ func (self *Host) SetExpirationTime(expirationTime time.Time) error {
 	)
 }
 
+// SetHomeVolume records the volume attached to a spawn host as its home volume.
+func (self *Host) SetHomeVolume(volumeId string) error {
+	self.HomeVolumeId = volumeId
+	return UpdateOne(
+		bson.M{
+			IdKey: self.Id,
+		},
+		bson.M{
+			"$set": bson.M{
+				HomeVolumeIdKey: volumeId,
+			},
+		},
+	)
+}
+
+// CreateSecret generates a new secret for the host's agent to authenticate
+// with, and saves it.
+func (self *Host) CreateSecret() error {
+	secret := util.RandomString()
+	err := UpdateOne(
+		bson.M{
+			IdKey: self.Id,
+		},
+		bson.M{
+			"$set": bson.M{
+				SecretKey: secret,
+			},
+		},
+	)
+	if err != nil {
+		return err
+	}
+	self.Secret = secret
+	return nil
+}
+
+// SetAgentRevision records the revision of the agent running on the host.
+func (self *Host) SetAgentRevision(agentRevision string) error {
+	self.AgentRevision = agentRevision
+	return UpdateOne(
+		bson.M{
+			IdKey: self.Id,
+		},
+		bson.M{
+			"$set": bson.M{
+				AgentRevisionKey: agentRevision,
+			},
+		},
+	)
+}
+
 // SetUserData updates the userdata field of a spawn host
 func (self *Host) SetUserData(userData string) error {
 	// update the in-memory host, then the database
@@ -344,6 +450,22 @@ func (self *Host) SetTaskPid(pid string) error {
 	)
 }
 
+// SetReachabilityChecked records that the host was checked on, without
+// changing its status.
+func (self *Host) SetReachabilityChecked() error {
+	self.LastReachabilityCheck = time.Now()
+	return UpdateOne(
+		bson.M{
+			IdKey: self.Id,
+		},
+		bson.M{
+			"$set": bson.M{
+				LastReachabilityCheckKey: self.LastReachabilityCheck,
+			},
+		},
+	)
+}
+
 // UpdateReachability sets a host as either running or unreachable, depending on the bool passed
 // in. also update the last reachability check for the host
 func (self *Host) UpdateReachability(reachable bool) error {
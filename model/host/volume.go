@@ -0,0 +1,115 @@
+package host
+
+import (
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/evergreen-ci/evergreen/db/bsonutil"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"time"
+)
+
+const (
+	// VolumeCollection is the name of the MongoDB collection that stores
+	// the volumes attached to spawn hosts.
+	VolumeCollection = "volumes"
+)
+
+// Volume is a persistent disk owned by a user. It is attached to the user's
+// spawn hosts as their home volume and outlives them, so that work isn't
+// lost when a host is terminated.
+type Volume struct {
+	Id string `bson:"_id" json:"id"`
+	// ProviderId is the id of the volume in the cloud provider. It can
+	// change if the provider has to copy the volume to attach it to a host.
+	ProviderId string `bson:"provider_id" json:"provider_id"`
+	Provider   string `bson:"provider" json:"provider"`
+	CreatedBy  string `bson:"created_by" json:"created_by"`
+	// Size is the size of the volume in GiB
+	Size             int       `bson:"size" json:"size"`
+	AvailabilityZone string    `bson:"availability_zone,omitempty" json:"availability_zone,omitempty"`
+	CreationTime     time.Time `bson:"creation_time" json:"creation_time"`
+
+	// HostId and DeviceName are set while the volume is attached to a host
+	HostId     string `bson:"host_id,omitempty" json:"host_id,omitempty"`
+	DeviceName string `bson:"device_name,omitempty" json:"device_name,omitempty"`
+}
+
+var (
+	VolumeIdKey               = bsonutil.MustHaveTag(Volume{}, "Id")
+	VolumeProviderIdKey       = bsonutil.MustHaveTag(Volume{}, "ProviderId")
+	VolumeCreatedByKey        = bsonutil.MustHaveTag(Volume{}, "CreatedBy")
+	VolumeAvailabilityZoneKey = bsonutil.MustHaveTag(Volume{}, "AvailabilityZone")
+	VolumeHostIdKey           = bsonutil.MustHaveTag(Volume{}, "HostId")
+	VolumeDeviceNameKey       = bsonutil.MustHaveTag(Volume{}, "DeviceName")
+)
+
+// VolumeById produces a query that returns the volume with the given id.
+func VolumeById(id string) db.Q {
+	return db.Query(bson.D{{VolumeIdKey, id}})
+}
+
+// VolumesByUser produces a query that returns all volumes owned by the user.
+func VolumesByUser(user string) db.Q {
+	return db.Query(bson.M{VolumeCreatedByKey: user}).Sort([]string{VolumeIdKey})
+}
+
+// FindOneVolume gets one Volume for the given query.
+func FindOneVolume(query db.Q) (*Volume, error) {
+	volume := &Volume{}
+	err := db.FindOneQ(VolumeCollection, query, volume)
+	if err == mgo.ErrNotFound {
+		return nil, nil
+	}
+	return volume, err
+}
+
+// FindVolumes gets all Volumes for the given query.
+func FindVolumes(query db.Q) ([]Volume, error) {
+	volumes := []Volume{}
+	err := db.FindAllQ(VolumeCollection, query, &volumes)
+	return volumes, err
+}
+
+// Insert stores a new volume.
+func (v *Volume) Insert() error {
+	return db.Insert(VolumeCollection, v)
+}
+
+// Remove deletes the volume's record.
+func (v *Volume) Remove() error {
+	return db.Remove(VolumeCollection, bson.M{VolumeIdKey: v.Id})
+}
+
+// SetAttached records that the volume was attached to the host, along with
+// the provider id and zone of the volume, which attaching may have changed.
+func (v *Volume) SetAttached(hostId, deviceName string) error {
+	v.HostId = hostId
+	v.DeviceName = deviceName
+	return db.Update(
+		VolumeCollection,
+		bson.M{VolumeIdKey: v.Id},
+		bson.M{
+			"$set": bson.M{
+				VolumeProviderIdKey:       v.ProviderId,
+				VolumeAvailabilityZoneKey: v.AvailabilityZone,
+				VolumeHostIdKey:           hostId,
+				VolumeDeviceNameKey:       deviceName,
+			},
+		},
+	)
+}
+
+// DetachVolumes records that all volumes attached to the host were detached.
+func DetachVolumes(hostId string) error {
+	_, err := db.UpdateAll(
+		VolumeCollection,
+		bson.M{VolumeHostIdKey: hostId},
+		bson.M{
+			"$unset": bson.M{
+				VolumeHostIdKey:     1,
+				VolumeDeviceNameKey: 1,
+			},
+		},
+	)
+	return err
+}
@@ -0,0 +1,129 @@
+package knownissue
+
+import (
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/evergreen-ci/evergreen/db/bsonutil"
+	"github.com/evergreen-ci/evergreen/util"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"time"
+)
+
+const Collection = "known_issues"
+
+// KnownIssue marks the failures of a task as tracked by a JIRA ticket, so
+// that repeats of them don't alert again. An empty BuildVariant covers the
+// task on every variant, and empty Tests cover failures of the task in which
+// no tests failed. Known issues are deleted once their ticket is resolved.
+type KnownIssue struct {
+	Id           bson.ObjectId `bson:"_id" json:"id"`
+	Project      string        `bson:"project" json:"project"`
+	BuildVariant string        `bson:"build_variant,omitempty" json:"build_variant"`
+	TaskName     string        `bson:"task_name" json:"task_name"`
+	Tests        []string      `bson:"tests,omitempty" json:"tests"`
+	Ticket       string        `bson:"ticket" json:"ticket"`
+	// TaskId is the task the failure was marked on
+	TaskId    string    `bson:"task_id" json:"task_id"`
+	CreatedBy string    `bson:"created_by" json:"created_by"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+}
+
+var (
+	IdKey           = bsonutil.MustHaveTag(KnownIssue{}, "Id")
+	ProjectKey      = bsonutil.MustHaveTag(KnownIssue{}, "Project")
+	BuildVariantKey = bsonutil.MustHaveTag(KnownIssue{}, "BuildVariant")
+	TaskNameKey     = bsonutil.MustHaveTag(KnownIssue{}, "TaskName")
+	TestsKey        = bsonutil.MustHaveTag(KnownIssue{}, "Tests")
+	TicketKey       = bsonutil.MustHaveTag(KnownIssue{}, "Ticket")
+	TaskIdKey       = bsonutil.MustHaveTag(KnownIssue{}, "TaskId")
+	CreatedByKey    = bsonutil.MustHaveTag(KnownIssue{}, "CreatedBy")
+	CreatedAtKey    = bsonutil.MustHaveTag(KnownIssue{}, "CreatedAt")
+)
+
+// All is a query for every known issue.
+var All = db.Query(bson.M{})
+
+// ById returns a query for the known issue with the given id.
+func ById(id bson.ObjectId) db.Q {
+	return db.Query(bson.M{IdKey: id})
+}
+
+// ByTask returns a query for the known issues of the task on the project,
+// on any variant.
+func ByTask(project, taskName string) db.Q {
+	return db.Query(bson.M{
+		ProjectKey:  project,
+		TaskNameKey: taskName,
+	})
+}
+
+// ByTicket returns a query for the known issues tracked by the ticket.
+func ByTicket(ticket string) db.Q {
+	return db.Query(bson.M{TicketKey: ticket})
+}
+
+// FindOne gets one KnownIssue for the given query.
+func FindOne(query db.Q) (*KnownIssue, error) {
+	issue := &KnownIssue{}
+	err := db.FindOneQ(Collection, query, issue)
+	if err == mgo.ErrNotFound {
+		return nil, nil
+	}
+	return issue, err
+}
+
+// Find gets all KnownIssues for the given query.
+func Find(query db.Q) ([]KnownIssue, error) {
+	issues := []KnownIssue{}
+	err := db.FindAllQ(Collection, query, &issues)
+	return issues, err
+}
+
+// FindCovering returns a known issue covering the failure of the task on the
+// variant, with the given failed tests, or nil if there is none.
+func FindCovering(project, buildVariant, taskName string, failedTests []string) (*KnownIssue, error) {
+	issues, err := Find(ByTask(project, taskName))
+	if err != nil {
+		return nil, err
+	}
+	for _, issue := range issues {
+		if issue.Covers(buildVariant, failedTests) {
+			return &issue, nil
+		}
+	}
+	return nil, nil
+}
+
+// Remove deletes the known issue with the given id.
+func Remove(id bson.ObjectId) error {
+	return db.Remove(Collection, bson.M{IdKey: id})
+}
+
+// RemoveByTicket deletes the known issues tracked by the ticket.
+func RemoveByTicket(ticket string) error {
+	return db.RemoveAll(Collection, bson.M{TicketKey: ticket})
+}
+
+// Insert writes the known issue to the database.
+func (ki *KnownIssue) Insert() error {
+	return db.Insert(Collection, ki)
+}
+
+// Covers returns true if the failure of the issue's task on the variant, with
+// the given failed tests, is a repeat of the issue. A failure with tests the
+// issue doesn't list is a new failure, as is a failure with failed tests of
+// an issue that lists none.
+func (ki *KnownIssue) Covers(buildVariant string, failedTests []string) bool {
+	if ki.BuildVariant != "" && ki.BuildVariant != buildVariant {
+		return false
+	}
+	if len(ki.Tests) == 0 || len(failedTests) == 0 {
+		return len(ki.Tests) == 0 && len(failedTests) == 0
+	}
+	for _, test := range failedTests {
+		if !util.SliceContains(ki.Tests, test) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,31 @@
+package knownissue
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestKnownIssueCovers(t *testing.T) {
+	Convey("With a known issue for some tests on a variant", t, func() {
+		issue := &KnownIssue{
+			BuildVariant: "linux-64",
+			TaskName:     "core",
+			Tests:        []string{"jstests/core/a.js", "jstests/core/b.js"},
+		}
+		Convey("failures of the listed tests should be covered", func() {
+			So(issue.Covers("linux-64", []string{"jstests/core/a.js"}), ShouldBeTrue)
+			So(issue.Covers("linux-64", []string{"jstests/core/b.js", "jstests/core/a.js"}), ShouldBeTrue)
+		})
+		Convey("failures of other tests or on other variants should not", func() {
+			So(issue.Covers("linux-64", []string{"jstests/core/a.js", "jstests/core/c.js"}), ShouldBeFalse)
+			So(issue.Covers("linux-64", nil), ShouldBeFalse)
+			So(issue.Covers("windows", []string{"jstests/core/a.js"}), ShouldBeFalse)
+		})
+	})
+	Convey("A known issue without a variant or tests should cover failures of the task without failed tests", t, func() {
+		issue := &KnownIssue{TaskName: "core"}
+		So(issue.Covers("linux-64", nil), ShouldBeTrue)
+		So(issue.Covers("windows", []string{}), ShouldBeTrue)
+		So(issue.Covers("windows", []string{"jstests/core/c.js"}), ShouldBeFalse)
+	})
+}
@@ -134,7 +134,7 @@ func MarkVersionStarted(versionId string, startTime time.Time) error {
 }
 
 // MarkVersionCompleted updates the status of a completed version to reflect its correct state by
-// checking the status of its individual builds.
+// checking the status of its individual builds, and runs the version completion hooks.
 func MarkVersionCompleted(versionId string, finishTime time.Time) error {
 	status := evergreen.VersionSucceeded
 
@@ -154,13 +154,18 @@ func MarkVersionCompleted(versionId string, finishTime time.Time) error {
 			status = evergreen.VersionFailed
 		}
 	}
-	return version.UpdateOne(
+	err = version.UpdateOne(
 		bson.M{version.IdKey: versionId},
 		bson.M{"$set": bson.M{
 			version.FinishTimeKey: finishTime,
 			version.StatusKey:     status,
 		}},
 	)
+	if err != nil {
+		return err
+	}
+	runVersionCompletionHooks(versionId)
+	return nil
 }
 
 // SetBuildPriority updates the priority field of all tasks associated with the given build id.
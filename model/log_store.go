@@ -0,0 +1,81 @@
+package model
+
+// LogStore saves and retrieves task and test logs. The database is the
+// default store; the logstore package provides compressed blob storage on
+// local disk or s3.
+type LogStore interface {
+	// InsertTaskLog saves a chunk of a task's log messages.
+	InsertTaskLog(taskLog *TaskLog) error
+	// GetRawTaskLogChannel streams the task's log messages, oldest first.
+	GetRawTaskLogChannel(taskId string, execution int, severities []string,
+		msgTypes []string) (chan LogMessage, error)
+	// FindMostRecentLogMessages returns up to numMsgs of the task's most
+	// recent log messages, newest first.
+	FindMostRecentLogMessages(taskId string, execution int, numMsgs int,
+		severities []string, msgTypes []string) ([]LogMessage, error)
+
+	// InsertTestLog saves the test log and sets its id.
+	InsertTestLog(testLog *TestLog) error
+	// FindOneTestLogById returns the test log with the id, or nil if there is none.
+	FindOneTestLogById(id string) (*TestLog, error)
+	// FindOneTestLog returns the test log for the test's name, task id and
+	// execution, or nil if there is none.
+	FindOneTestLog(name, task string, execution int) (*TestLog, error)
+}
+
+// MongoLogStore keeps logs in the database.
+type MongoLogStore struct{}
+
+// InsertTaskLog inserts the chunk of log messages as its own document.
+func (MongoLogStore) InsertTaskLog(taskLog *TaskLog) error {
+	return taskLog.Insert()
+}
+
+// InsertTestLog inserts the test log, lines and all, into the database.
+func (MongoLogStore) InsertTestLog(testLog *TestLog) error {
+	return testLog.Insert()
+}
+
+// logStore is where the package-level log functions save and retrieve logs.
+var logStore LogStore = MongoLogStore{}
+
+// SetLogStore changes where task and test logs are saved and retrieved.
+func SetLogStore(store LogStore) {
+	logStore = store
+}
+
+// InsertTaskLog saves a chunk of a task's log messages to the log store.
+func InsertTaskLog(taskLog *TaskLog) error {
+	return logStore.InsertTaskLog(taskLog)
+}
+
+// GetRawTaskLogChannel streams the task's log messages from the log store,
+// oldest first. To ignore severity or type filtering, pass in empty slices.
+func GetRawTaskLogChannel(taskId string, execution int, severities []string,
+	msgTypes []string) (chan LogMessage, error) {
+	return logStore.GetRawTaskLogChannel(taskId, execution, severities, msgTypes)
+}
+
+// FindMostRecentLogMessages returns the task's most recent log messages from
+// the log store, newest first. To ignore severity or type filtering, pass in
+// empty slices.
+func FindMostRecentLogMessages(taskId string, execution int, numMsgs int,
+	severities []string, msgTypes []string) ([]LogMessage, error) {
+	return logStore.FindMostRecentLogMessages(taskId, execution, numMsgs, severities, msgTypes)
+}
+
+// InsertTestLog saves the test log to the log store and sets its id.
+func InsertTestLog(testLog *TestLog) error {
+	return logStore.InsertTestLog(testLog)
+}
+
+// FindOneTestLogById returns the test log with the id from the log store.
+func FindOneTestLogById(id string) (*TestLog, error) {
+	return logStore.FindOneTestLogById(id)
+}
+
+// FindOneTestLog returns a TestLog from the log store, given the test's
+// name, task id, and execution.
+func FindOneTestLog(name, task string, execution int) (*TestLog, error) {
+	return logStore.FindOneTestLog(name, task, execution)
+}
@@ -0,0 +1,402 @@
+// Package logsearch indexes task and test logs as they are appended so they
+// can be searched by text across a project.
+//
+// The lines themselves stay in the log store. Each chunk of a log gets a
+// document in the log_search collection holding the distinct words of its
+// lines and where the lines are in the log: a test log's line offsets, or the
+// time range of a task log chunk's messages. Searches find the chunks with
+// all the words of the text through the index on the words (see
+// scripts/indexes.js), then read their lines back from the log store to find
+// the lines to link to.
+package logsearch
+
+import (
+	"fmt"
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/evergreen-ci/evergreen/db/bsonutil"
+	"github.com/evergreen-ci/evergreen/model"
+	"gopkg.in/mgo.v2/bson"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	Collection = "log_search"
+
+	// the most test log lines indexed by one chunk
+	testLogChunkLines = 1000
+	// words longer than this, like hashes, aren't indexed
+	maxTokenLength = 64
+	// the most matches returned by a search
+	MaxLimit = 500
+)
+
+// Chunk indexes a run of lines from one task log or test log, along with the
+// task they belong to.
+type Chunk struct {
+	Id           bson.ObjectId `bson:"_id"`
+	Project      string        `bson:"project"`
+	BuildVariant string        `bson:"build_variant"`
+	TaskName     string        `bson:"task_name"`
+	TaskId       string        `bson:"task_id"`
+	Execution    int           `bson:"execution"`
+	TestLogId    string        `bson:"test_log_id,omitempty"`
+	TestName     string        `bson:"test_name,omitempty"`
+	// Timestamp is when the chunk's first line was logged, and EndTime when a
+	// task log chunk's last line was
+	Timestamp time.Time `bson:"ts"`
+	EndTime   time.Time `bson:"end_ts,omitempty"`
+	// FirstLine and NumLines locate a test log chunk's lines in the test log
+	FirstLine int `bson:"first_line"`
+	NumLines  int `bson:"num_lines"`
+	// Tokens are the distinct lowercase words of the chunk's lines
+	Tokens []string `bson:"tokens"`
+}
+
+var (
+	IdKey           = bsonutil.MustHaveTag(Chunk{}, "Id")
+	ProjectKey      = bsonutil.MustHaveTag(Chunk{}, "Project")
+	BuildVariantKey = bsonutil.MustHaveTag(Chunk{}, "BuildVariant")
+	TaskNameKey     = bsonutil.MustHaveTag(Chunk{}, "TaskName")
+	TaskIdKey       = bsonutil.MustHaveTag(Chunk{}, "TaskId")
+	ExecutionKey    = bsonutil.MustHaveTag(Chunk{}, "Execution")
+	TestLogIdKey    = bsonutil.MustHaveTag(Chunk{}, "TestLogId")
+	TestNameKey     = bsonutil.MustHaveTag(Chunk{}, "TestName")
+	TimestampKey    = bsonutil.MustHaveTag(Chunk{}, "Timestamp")
+	EndTimeKey      = bsonutil.MustHaveTag(Chunk{}, "EndTime")
+	FirstLineKey    = bsonutil.MustHaveTag(Chunk{}, "FirstLine")
+	NumLinesKey     = bsonutil.MustHaveTag(Chunk{}, "NumLines")
+	TokensKey       = bsonutil.MustHaveTag(Chunk{}, "Tokens")
+)
+
+// Line is a log line read back from the log store for a chunk.
+type Line struct {
+	Message string
+	// Type and Timestamp are only set for task log lines
+	Type      string
+	Timestamp time.Time
+}
+
+// Find gets all Chunks for the given query.
+func Find(query db.Q) ([]Chunk, error) {
+	chunks := []Chunk{}
+	err := db.FindAllQ(Collection, query, &chunks)
+	return chunks, err
+}
+
+// Insert writes the chunk to the database.
+func (c *Chunk) Insert() error {
+	return db.Insert(Collection, c)
+}
+
+// newChunk returns an empty chunk for the task's logs.
+func newChunk(task *model.Task) *Chunk {
+	return &Chunk{
+		Id:           bson.NewObjectId(),
+		Project:      task.Project,
+		BuildVariant: task.BuildVariant,
+		TaskName:     task.DisplayName,
+		TaskId:       task.Id,
+		Execution:    task.Execution,
+	}
+}
+
+// tokenize returns the lowercase words of the text: its runs of letters and
+// digits.
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// tokenSet collects the distinct words of lines.
+type tokenSet map[string]bool
+
+func (ts tokenSet) add(line string) {
+	for _, token := range tokenize(line) {
+		if len(token) <= maxTokenLength {
+			ts[token] = true
+		}
+	}
+}
+
+// sorted returns the words in order, so that chunks are stored the same way
+// every time.
+func (ts tokenSet) sorted() []string {
+	tokens := make([]string, 0, len(ts))
+	for token := range ts {
+		tokens = append(tokens, token)
+	}
+	sort.Strings(tokens)
+	return tokens
+}
+
+// IndexTaskLog indexes the messages of a chunk of the task's log.
+func IndexTaskLog(task *model.Task, taskLog *model.TaskLog) error {
+	chunk := newChunk(task)
+	tokens := tokenSet{}
+	for _, msg := range taskLog.Messages {
+		if strings.TrimSpace(msg.Message) == "" {
+			continue
+		}
+		tokens.add(msg.Message)
+		chunk.NumLines++
+		if msg.Timestamp.IsZero() {
+			continue
+		}
+		if chunk.Timestamp.IsZero() || msg.Timestamp.Before(chunk.Timestamp) {
+			chunk.Timestamp = msg.Timestamp
+		}
+		if msg.Timestamp.After(chunk.EndTime) {
+			chunk.EndTime = msg.Timestamp
+		}
+	}
+	if len(tokens) == 0 {
+		return nil
+	}
+	if chunk.Timestamp.IsZero() {
+		chunk.Timestamp = taskLog.Timestamp
+	}
+	if chunk.Timestamp.IsZero() {
+		chunk.Timestamp = time.Now()
+	}
+	if chunk.EndTime.IsZero() {
+		chunk.EndTime = chunk.Timestamp
+	}
+	chunk.Tokens = tokens.sorted()
+	return chunk.Insert()
+}
+
+// IndexTestLog indexes the lines of the task's test log, which must already
+// have been saved so that its lines can be read back by its id.
+func IndexTestLog(task *model.Task, testLog *model.TestLog) error {
+	now := time.Now()
+	for first := 0; first < len(testLog.Lines); first += testLogChunkLines {
+		chunk := newChunk(task)
+		chunk.TestLogId = testLog.Id
+		chunk.TestName = testLog.Name
+		chunk.Timestamp = now
+		chunk.FirstLine = first
+		last := first + testLogChunkLines
+		if last > len(testLog.Lines) {
+			last = len(testLog.Lines)
+		}
+		chunk.NumLines = last - first
+		tokens := tokenSet{}
+		for _, line := range testLog.Lines[first:last] {
+			tokens.add(line)
+		}
+		if len(tokens) == 0 {
+			continue
+		}
+		chunk.Tokens = tokens.sorted()
+		if err := chunk.Insert(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Query describes a search of a project's logs.
+type Query struct {
+	Project string
+	// Text is matched case-insensitively as a phrase of whole words
+	Text         string
+	BuildVariant string
+	// Start and End bound when the lines were logged; either may be zero
+	Start time.Time
+	End   time.Time
+	// MessageTypes restricts the task log lines searched to those types,
+	// if set. Test log lines are always searched.
+	MessageTypes []string
+	Limit        int
+}
+
+// Match is a log line matching a search.
+type Match struct {
+	TaskId       string `json:"task_id"`
+	Execution    int    `json:"execution"`
+	BuildVariant string `json:"build_variant"`
+	TaskName     string `json:"task_name"`
+	// TestLogId, TestName and LineNumber are only set for test log lines
+	TestLogId  string    `json:"test_log_id,omitempty"`
+	TestName   string    `json:"test_name,omitempty"`
+	LineNumber int       `json:"line_number"`
+	Message    string    `json:"message"`
+	Timestamp  time.Time `json:"ts"`
+	// URL is the path of the log page, linking to the line
+	URL string `json:"url"`
+}
+
+// Search returns the lines of the project's logs that match the query, most
+// recent first.
+func Search(q Query) ([]Match, error) {
+	text := strings.TrimSpace(q.Text)
+	if q.Project == "" {
+		return nil, fmt.Errorf("a project is required to search logs")
+	}
+	if text == "" {
+		return nil, fmt.Errorf("search text must not be empty")
+	}
+	tokens := searchTokens(text)
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("search text must contain a word")
+	}
+	if q.Limit <= 0 || q.Limit > MaxLimit {
+		q.Limit = MaxLimit
+	}
+
+	filter := bson.M{
+		ProjectKey: q.Project,
+		TokensKey:  bson.M{"$all": tokens},
+	}
+	if q.BuildVariant != "" {
+		filter[BuildVariantKey] = q.BuildVariant
+	}
+	timeRange := bson.M{}
+	if !q.Start.IsZero() {
+		timeRange["$gte"] = q.Start
+	}
+	if !q.End.IsZero() {
+		timeRange["$lte"] = q.End
+	}
+	if len(timeRange) != 0 {
+		filter[TimestampKey] = timeRange
+	}
+
+	chunks, err := Find(db.Query(filter).Sort([]string{"-" + TimestampKey}).Limit(q.Limit))
+	if err != nil {
+		return nil, err
+	}
+	logs := newLogReader()
+	matches := []Match{}
+	for _, chunk := range chunks {
+		lines, err := logs.lines(&chunk)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, chunk.matches(lines, text, q.MessageTypes)...)
+		if len(matches) >= q.Limit {
+			return matches[:q.Limit], nil
+		}
+	}
+	return matches, nil
+}
+
+// searchTokens returns the words a chunk must have to contain the text.
+// Words too long to be indexed are left out.
+func searchTokens(text string) []string {
+	tokens := []string{}
+	for _, word := range tokenize(text) {
+		if len(word) <= maxTokenLength {
+			tokens = append(tokens, word)
+		}
+	}
+	return tokens
+}
+
+// logReader reads the lines of chunks back from the log store, reading each
+// log at most once per search.
+type logReader struct {
+	taskLogs map[string][]model.LogMessage
+	testLogs map[string]*model.TestLog
+	// the task log messages already returned for a chunk, so that the same
+	// message isn't matched twice when chunks logged at the same time overlap
+	seen map[string]map[int]bool
+}
+
+func newLogReader() *logReader {
+	return &logReader{
+		taskLogs: map[string][]model.LogMessage{},
+		testLogs: map[string]*model.TestLog{},
+		seen:     map[string]map[int]bool{},
+	}
+}
+
+// lines returns the chunk's lines, or none if its log is gone.
+func (lr *logReader) lines(c *Chunk) ([]Line, error) {
+	if c.TestLogId != "" {
+		testLog, ok := lr.testLogs[c.TestLogId]
+		if !ok {
+			var err error
+			if testLog, err = model.FindOneTestLogById(c.TestLogId); err != nil {
+				return nil, fmt.Errorf("error reading test log %v: %v", c.TestLogId, err)
+			}
+			lr.testLogs[c.TestLogId] = testLog
+		}
+		if testLog == nil || c.FirstLine >= len(testLog.Lines) {
+			return nil, nil
+		}
+		last := c.FirstLine + c.NumLines
+		if last > len(testLog.Lines) {
+			last = len(testLog.Lines)
+		}
+		lines := make([]Line, 0, last-c.FirstLine)
+		for _, line := range testLog.Lines[c.FirstLine:last] {
+			lines = append(lines, Line{Message: line})
+		}
+		return lines, nil
+	}
+
+	key := fmt.Sprintf("%v/%v", c.TaskId, c.Execution)
+	messages, ok := lr.taskLogs[key]
+	if !ok {
+		channel, err := model.GetRawTaskLogChannel(c.TaskId, c.Execution, nil, nil)
+		if err != nil {
+			return nil, fmt.Errorf("error reading logs of task %v: %v", c.TaskId, err)
+		}
+		for msg := range channel {
+			messages = append(messages, msg)
+		}
+		lr.taskLogs[key] = messages
+		lr.seen[key] = map[int]bool{}
+	}
+	lines := []Line{}
+	for i, msg := range messages {
+		if msg.Timestamp.Before(c.Timestamp) || msg.Timestamp.After(c.EndTime) || lr.seen[key][i] {
+			continue
+		}
+		lr.seen[key][i] = true
+		lines = append(lines, Line{Message: msg.Message, Type: msg.Type, Timestamp: msg.Timestamp})
+	}
+	return lines, nil
+}
+
+// matches returns the chunk's lines that contain the text, ignoring case,
+// and have one of the message types.
+func (c *Chunk) matches(lines []Line, text string, msgTypes []string) []Match {
+	text = strings.ToLower(text)
+	matches := []Match{}
+	for i, line := range lines {
+		if !strings.Contains(strings.ToLower(line.Message), text) {
+			continue
+		}
+		match := Match{
+			TaskId:       c.TaskId,
+			Execution:    c.Execution,
+			BuildVariant: c.BuildVariant,
+			TaskName:     c.TaskName,
+			Message:      line.Message,
+			Timestamp:    line.Timestamp,
+		}
+		if c.TestLogId != "" {
+			match.TestLogId = c.TestLogId
+			match.TestName = c.TestName
+			match.LineNumber = c.FirstLine + i
+			match.Timestamp = c.Timestamp
+			match.URL = fmt.Sprintf("/test_log/%v#L%v", c.TestLogId, match.LineNumber)
+		} else {
+			msg := model.LogMessage{Type: line.Type}
+			if !msg.MatchesFilter(nil, msgTypes) {
+				continue
+			}
+			// task log pages link to lines by the millisecond they were logged
+			match.URL = fmt.Sprintf("/task_log_raw/%v/%v?type=ALL#T%v", c.TaskId, c.Execution,
+				line.Timestamp.UnixNano()/int64(time.Millisecond))
+		}
+		matches = append(matches, match)
+	}
+	return matches
+}
@@ -0,0 +1,68 @@
+package logsearch
+
+import (
+	"github.com/evergreen-ci/evergreen/model"
+	. "github.com/smartystreets/goconvey/convey"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestChunkMatches(t *testing.T) {
+	ts := time.Date(2016, time.March, 1, 12, 0, 0, 500*int(time.Millisecond), time.UTC)
+
+	Convey("With a chunk of a task log", t, func() {
+		chunk := &Chunk{TaskId: "t1", Execution: 2}
+		lines := []Line{
+			{Message: "starting", Type: model.TaskLogPrefix, Timestamp: ts},
+			{Message: "Segmentation Fault (core dumped)", Type: model.TaskLogPrefix, Timestamp: ts},
+			{Message: "segmentation fault in agent", Type: model.AgentLogPrefix, Timestamp: ts},
+		}
+
+		Convey("lines containing the text in any case should match", func() {
+			matches := chunk.matches(lines, "segmentation fault", nil)
+			So(len(matches), ShouldEqual, 2)
+			So(matches[0].Message, ShouldEqual, "Segmentation Fault (core dumped)")
+			So(matches[0].URL, ShouldEqual, "/task_log_raw/t1/2?type=ALL#T1456833600500")
+		})
+
+		Convey("only lines of the given types should match", func() {
+			matches := chunk.matches(lines, "segmentation fault", []string{model.TaskLogPrefix})
+			So(len(matches), ShouldEqual, 1)
+			So(chunk.matches(lines, "segmentation fault", []string{model.SystemLogPrefix}), ShouldBeEmpty)
+		})
+	})
+
+	Convey("With a chunk of a test log", t, func() {
+		chunk := &Chunk{
+			TaskId:    "t1",
+			TestLogId: "log1",
+			TestName:  "a.js",
+			FirstLine: 1000,
+		}
+		lines := []Line{{Message: "ok"}, {Message: "assert failed"}}
+
+		Convey("matches should link to the line number in the test log", func() {
+			matches := chunk.matches(lines, "ASSERT", []string{model.TaskLogPrefix})
+			So(len(matches), ShouldEqual, 1)
+			So(matches[0].LineNumber, ShouldEqual, 1001)
+			So(matches[0].URL, ShouldEqual, "/test_log/log1#L1001")
+		})
+	})
+}
+
+func TestTokens(t *testing.T) {
+	Convey("Lines should be indexed by their distinct lowercase words", t, func() {
+		tokens := tokenSet{}
+		tokens.add("Segmentation fault (core dumped)")
+		tokens.add("core.1234: segmentation")
+		tokens.add(strings.Repeat("a", maxTokenLength+1))
+		So(tokens.sorted(), ShouldResemble,
+			[]string{"1234", "core", "dumped", "fault", "segmentation"})
+	})
+
+	Convey("Searches should look for each word of the text", t, func() {
+		So(searchTokens(`Segmentation "fault"`), ShouldResemble, []string{"segmentation", "fault"})
+		So(searchTokens("!!!"), ShouldBeEmpty)
+	})
+}
@@ -31,6 +31,7 @@ var (
 	PatchesKey       = bsonutil.MustHaveTag(Patch{}, "Patches")
 	ActivatedKey     = bsonutil.MustHaveTag(Patch{}, "Activated")
 	PatchedConfigKey = bsonutil.MustHaveTag(Patch{}, "PatchedConfig")
+	ReviewRefKey     = bsonutil.MustHaveTag(Patch{}, "ReviewRef")
 
 	// BSON fields for the module patch struct
 	ModulePatchNameKey    = bsonutil.MustHaveTag(ModulePatch{}, "ModuleName")
@@ -86,6 +87,15 @@ func ByUserProjectAndGitspec(user string, project string, gitspec string) db.Q {
 	})
 }
 
+// ByProjectAndReviewRef produces a query that returns the patches for the
+// given project created from the given code review ref.
+func ByProjectAndReviewRef(project, reviewRef string) db.Q {
+	return db.Query(bson.M{
+		ProjectKey:   project,
+		ReviewRefKey: reviewRef,
+	})
+}
+
 // ByUser produces a query that returns the patch for a given version.
 func ByVersion(version string) db.Q {
 	return db.Query(bson.D{{VersionKey, version}})
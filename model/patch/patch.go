@@ -31,6 +31,10 @@ type Patch struct {
 	Patches       []ModulePatch `bson:"patches"`
 	Activated     bool          `bson:"activated"`
 	PatchedConfig string        `bson:"patched_config"`
+
+	// ReviewRef is the code review ref, such as a Gerrit patch set's, that
+	// the patch was created from.
+	ReviewRef string `bson:"review_ref,omitempty"`
 }
 
 // this stores request details for a patch
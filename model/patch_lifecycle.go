@@ -239,16 +239,24 @@ func FinalizePatch(p *patch.Patch, settings *evergreen.Settings) (
 		return
 	}
 
-	gitCommit, err := thirdparty.GetCommitEvent(
-		settings.Credentials["github"],
-		projectRef.Owner, projectRef.Repo, p.Githash,
-	)
-	if err != nil {
-		return nil, fmt.Errorf("Couldn't fetch commit information: %v", err)
-	}
-	if gitCommit == nil {
-		return nil, fmt.Errorf("Couldn't fetch commit information: git commit" +
-			" doesn't exist?")
+	// commit information is only available from GitHub; patches of projects
+	// hosted elsewhere are described by the patch itself
+	author, authorEmail, message := p.Author, "", p.Description
+	if projectRef.RepoKind == "" || projectRef.RepoKind == evergreen.GithubRepoKind {
+		gitCommit, err := thirdparty.GetCommitEvent(
+			settings.Credentials["github"],
+			projectRef.Owner, projectRef.Repo, p.Githash,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("Couldn't fetch commit information: %v", err)
+		}
+		if gitCommit == nil {
+			return nil, fmt.Errorf("Couldn't fetch commit information: git commit" +
+				" doesn't exist?")
+		}
+		author = gitCommit.Commit.Committer.Name
+		authorEmail = gitCommit.Commit.Committer.Email
+		message = gitCommit.Commit.Message
 	}
 
 	patchVersion = &version.Version{
@@ -256,9 +264,9 @@ func FinalizePatch(p *patch.Patch, settings *evergreen.Settings) (
 		CreateTime:    time.Now(),
 		Identifier:    p.Project,
 		Revision:      p.Githash,
-		Author:        gitCommit.Commit.Committer.Name,
-		AuthorEmail:   gitCommit.Commit.Committer.Email,
-		Message:       gitCommit.Commit.Message,
+		Author:        author,
+		AuthorEmail:   authorEmail,
+		Message:       message,
 		BuildIds:      []string{},
 		BuildVariants: []version.BuildStatus{},
 		Config:        string(p.PatchedConfig),
@@ -0,0 +1,68 @@
+package perf
+
+import (
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/evergreen-ci/evergreen/db/bsonutil"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+var (
+	// BSON fields for perf result structs
+	TaskIdKey              = bsonutil.MustHaveTag(TaskPerf{}, "TaskId")
+	ProjectKey             = bsonutil.MustHaveTag(TaskPerf{}, "Project")
+	BuildVariantKey        = bsonutil.MustHaveTag(TaskPerf{}, "BuildVariant")
+	TaskNameKey            = bsonutil.MustHaveTag(TaskPerf{}, "TaskName")
+	RevisionOrderNumberKey = bsonutil.MustHaveTag(TaskPerf{}, "RevisionOrderNumber")
+	RequesterKey           = bsonutil.MustHaveTag(TaskPerf{}, "Requester")
+)
+
+// === Queries ===
+
+// ByTaskId returns a query for the results of the given task.
+func ByTaskId(id string) db.Q {
+	return db.Query(bson.D{{TaskIdKey, id}})
+}
+
+// ByTaskHistory returns the results of the most recent mainline runs of a
+// task on a variant, up to and including the given revision order number,
+// newest first. Patch results are left out so they don't skew the history.
+func ByTaskHistory(project, variant, taskName string, maxOrder, limit int) db.Q {
+	return db.Query(bson.M{
+		ProjectKey:             project,
+		BuildVariantKey:        variant,
+		TaskNameKey:            taskName,
+		RequesterKey:           evergreen.RepotrackerVersionRequester,
+		RevisionOrderNumberKey: bson.M{"$lte": maxOrder},
+	}).Sort([]string{"-" + RevisionOrderNumberKey}).Limit(limit)
+}
+
+// === DB Logic ===
+
+// Upsert stores the task's results, replacing any sent by a previous execution.
+func (tp *TaskPerf) Upsert() error {
+	_, err := db.Upsert(
+		Collection,
+		bson.M{TaskIdKey: tp.TaskId},
+		tp,
+	)
+	return err
+}
+
+// FindOne gets one TaskPerf for the given query.
+func FindOne(query db.Q) (*TaskPerf, error) {
+	tp := &TaskPerf{}
+	err := db.FindOneQ(Collection, query, tp)
+	if err == mgo.ErrNotFound {
+		return nil, nil
+	}
+	return tp, err
+}
+
+// FindAll gets every TaskPerf for the given query.
+func FindAll(query db.Q) ([]TaskPerf, error) {
+	tps := []TaskPerf{}
+	err := db.FindAllQ(Collection, query, &tps)
+	return tps, err
+}
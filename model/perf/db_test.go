@@ -0,0 +1,50 @@
+package perf
+
+import (
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/evergreen-ci/evergreen/testutil"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func init() {
+	db.SetGlobalSessionProvider(db.SessionFactoryFromConfig(evergreen.TestConfig()))
+}
+
+func TestTaskHistory(t *testing.T) {
+	Convey("With results stored for several runs of a task", t, func() {
+		testutil.HandleTestingErr(db.Clear(Collection), t, "Error clearing collection")
+		for i, requester := range []string{
+			evergreen.RepotrackerVersionRequester,
+			evergreen.PatchVersionRequester,
+			evergreen.RepotrackerVersionRequester,
+			evergreen.RepotrackerVersionRequester,
+		} {
+			tp := taskPerfAt(i+1, 100)
+			tp.Project = "proj"
+			tp.BuildVariant = "bv"
+			tp.TaskName = "bench"
+			tp.Requester = requester
+			So(tp.Upsert(), ShouldBeNil)
+		}
+
+		Convey("upserting the same task again should replace its results", func() {
+			tp := taskPerfAt(4, 50)
+			tp.Project, tp.BuildVariant, tp.TaskName = "proj", "bv", "bench"
+			tp.Requester = evergreen.RepotrackerVersionRequester
+			So(tp.Upsert(), ShouldBeNil)
+			stored, err := FindOne(ByTaskId(tp.TaskId))
+			So(err, ShouldBeNil)
+			So(stored.Results[0].Value, ShouldEqual, 50)
+		})
+
+		Convey("the history should hold mainline runs up to the revision, newest first", func() {
+			history, err := FindAll(ByTaskHistory("proj", "bv", "bench", 3, 10))
+			So(err, ShouldBeNil)
+			So(len(history), ShouldEqual, 2)
+			So(history[0].RevisionOrderNumber, ShouldEqual, 3)
+			So(history[1].RevisionOrderNumber, ShouldEqual, 1)
+		})
+	})
+}
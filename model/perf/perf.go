@@ -0,0 +1,42 @@
+package perf
+
+import (
+	"fmt"
+	"time"
+)
+
+const Collection = "perf_results"
+
+// TaskPerf holds the benchmark results reported by a task with the perf.send
+// command. There is one document per task; results sent by a later execution
+// of the task replace those of the earlier one.
+type TaskPerf struct {
+	TaskId              string    `bson:"_id" json:"task_id"`
+	Execution           int       `bson:"execution" json:"execution"`
+	Project             string    `bson:"project" json:"project"`
+	BuildVariant        string    `bson:"build_variant" json:"build_variant"`
+	TaskName            string    `bson:"task_name" json:"task_name"`
+	Revision            string    `bson:"revision" json:"revision"`
+	RevisionOrderNumber int       `bson:"order" json:"order"`
+	Requester           string    `bson:"requester" json:"requester"`
+	CreateTime          time.Time `bson:"create_time" json:"create_time"`
+	Results             []Result  `bson:"results" json:"results"`
+}
+
+// Result is a single benchmark measurement.
+type Result struct {
+	// Name is the name of the benchmark, e.g. "insert_vector"
+	Name string `bson:"name" json:"name"`
+	// Metric is what was measured, e.g. "ops_per_sec" or "latency_ms"
+	Metric string `bson:"metric" json:"metric"`
+	// Value is the measurement itself
+	Value float64 `bson:"value" json:"value"`
+	// Threads is the number of threads the benchmark was run with
+	Threads int `bson:"threads" json:"threads"`
+}
+
+// SeriesKey identifies the series a result belongs to. Results are only
+// comparable across revisions when their series keys match.
+func (r Result) SeriesKey() string {
+	return fmt.Sprintf("%v.%v.%v", r.Name, r.Metric, r.Threads)
+}
@@ -0,0 +1,99 @@
+package perf
+
+import (
+	"github.com/evergreen-ci/evergreen/util"
+	"math"
+)
+
+const (
+	// DefaultThreshold is the percent change from the baseline at which a
+	// result counts as a regression.
+	DefaultThreshold = 10.0
+	// DefaultWindow is the number of previous revisions the baseline is taken from.
+	DefaultWindow = 5
+
+	// MinHistoryPoints is how many previous points a series needs before
+	// regressions are reported for it, so that one noisy run can't set the
+	// baseline. Smaller windows would never report a regression.
+	MinHistoryPoints = 3
+)
+
+// DetectorOptions control when a change in a result counts as a regression.
+type DetectorOptions struct {
+	// Threshold is the percent change from the baseline that's tolerated
+	Threshold float64 `json:"threshold"`
+	// Window is the number of previous revisions to take the baseline from
+	Window int `json:"window"`
+	// LowerIsBetter lists the metrics for which a smaller value is an
+	// improvement, e.g. latencies. Larger values are better for all others.
+	LowerIsBetter []string `json:"lower_is_better"`
+}
+
+// Regression is a result that got worse than its recent history.
+type Regression struct {
+	Result Result `json:"result"`
+	// Baseline is the mean of the series over the previous revisions
+	Baseline float64 `json:"baseline"`
+	// PercentWorse is how much worse than the baseline the result is
+	PercentWorse float64 `json:"percent_worse"`
+}
+
+// DetectRegressions compares each of the current results to the mean of the
+// same series over the previous revisions in history, and returns those that
+// are worse than the mean by more than the threshold. The history may contain
+// the current task itself and is expected to be sorted newest first, as
+// returned by ByTaskHistory.
+func DetectRegressions(current TaskPerf, history []TaskPerf, opts DetectorOptions) []Regression {
+	if opts.Threshold <= 0 {
+		opts.Threshold = DefaultThreshold
+	}
+	if opts.Window <= 0 {
+		opts.Window = DefaultWindow
+	}
+
+	// gather the previous values of each series, newest first
+	previous := map[string][]float64{}
+	for _, tp := range history {
+		if tp.TaskId == current.TaskId || tp.RevisionOrderNumber >= current.RevisionOrderNumber {
+			continue
+		}
+		for _, r := range tp.Results {
+			key := r.SeriesKey()
+			if len(previous[key]) < opts.Window {
+				previous[key] = append(previous[key], r.Value)
+			}
+		}
+	}
+
+	regressions := []Regression{}
+	for _, r := range current.Results {
+		values := previous[r.SeriesKey()]
+		if len(values) < MinHistoryPoints {
+			continue
+		}
+		baseline := mean(values)
+		if baseline == 0 {
+			continue
+		}
+		change := (r.Value - baseline) / math.Abs(baseline) * 100
+		if !util.SliceContains(opts.LowerIsBetter, r.Metric) {
+			change = -change
+		}
+		if change > opts.Threshold {
+			regressions = append(regressions, Regression{
+				Result:       r,
+				Baseline:     baseline,
+				PercentWorse: change,
+			})
+		}
+	}
+	return regressions
+}
+
+func mean(values []float64) float64 {
+	total := 0.0
+	for _, v := range values {
+		total += v
+	}
+	return total / float64(len(values))
+}
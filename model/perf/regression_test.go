@@ -0,0 +1,69 @@
+package perf
+
+import (
+	"fmt"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func taskPerfAt(order int, values ...float64) TaskPerf {
+	tp := TaskPerf{TaskId: fmt.Sprintf("t%v", order), RevisionOrderNumber: order}
+	for _, v := range values {
+		tp.Results = append(tp.Results, Result{Name: "insert", Metric: "ops_per_sec", Value: v, Threads: 1})
+	}
+	return tp
+}
+
+func TestDetectRegressions(t *testing.T) {
+	Convey("With a steady history of throughput results", t, func() {
+		history := []TaskPerf{
+			taskPerfAt(4, 1000),
+			taskPerfAt(3, 1010),
+			taskPerfAt(2, 990),
+			taskPerfAt(1, 1000),
+		}
+
+		Convey("a small drop should not be reported", func() {
+			current := taskPerfAt(5, 950)
+			So(DetectRegressions(current, history, DetectorOptions{}), ShouldBeEmpty)
+		})
+
+		Convey("a drop beyond the threshold should be reported", func() {
+			current := taskPerfAt(5, 800)
+			regressions := DetectRegressions(current, history, DetectorOptions{})
+			So(len(regressions), ShouldEqual, 1)
+			So(regressions[0].Baseline, ShouldEqual, 1000)
+			So(regressions[0].PercentWorse, ShouldAlmostEqual, 20)
+		})
+
+		Convey("an increase should not be reported", func() {
+			current := taskPerfAt(5, 1500)
+			So(DetectRegressions(current, history, DetectorOptions{}), ShouldBeEmpty)
+		})
+
+		Convey("an increase should be reported if lower values are better", func() {
+			current := taskPerfAt(5, 1500)
+			opts := DetectorOptions{LowerIsBetter: []string{"ops_per_sec"}}
+			regressions := DetectRegressions(current, history, opts)
+			So(len(regressions), ShouldEqual, 1)
+			So(regressions[0].PercentWorse, ShouldAlmostEqual, 50)
+		})
+
+		Convey("the threshold should be configurable", func() {
+			current := taskPerfAt(5, 950)
+			So(len(DetectRegressions(current, history, DetectorOptions{Threshold: 2})), ShouldEqual, 1)
+		})
+
+		Convey("the current task and later revisions should not count towards the baseline", func() {
+			current := taskPerfAt(3, 800)
+			regressions := DetectRegressions(current, history, DetectorOptions{})
+			So(regressions, ShouldBeEmpty) // only two earlier points
+		})
+
+		Convey("results from other series should not be compared", func() {
+			current := taskPerfAt(5, 800)
+			current.Results[0].Threads = 8
+			So(DetectRegressions(current, history, DetectorOptions{}), ShouldBeEmpty)
+		})
+	})
+}
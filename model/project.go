@@ -2,6 +2,7 @@ package model
 
 import (
 	"fmt"
+	"github.com/evergreen-ci/evergreen"
 	"github.com/evergreen-ci/evergreen/command"
 	"github.com/evergreen-ci/evergreen/db/bsonutil"
 	"github.com/evergreen-ci/evergreen/model/build"
@@ -24,6 +25,10 @@ const (
 	DefaultCommandType = TestCommandType
 )
 
+// TaskTagSelectorPrefix marks a task name in a build variant or dependency as
+// a tag, standing for all of the tasks with that tag, as in ".storage_tests".
+const TaskTagSelectorPrefix = "."
+
 type Project struct {
 	Enabled            bool                       `yaml:"enabled" bson:"enabled"`
 	Stepback           bool                       `yaml:"stepback" bson:"stepback"`
@@ -146,6 +151,11 @@ type PluginCommandConf struct {
 	// variants.
 	Variants []string `yaml:"variants" bson:"variants"`
 
+	// If is a condition on the task's expansions, such as
+	// "is_patch && build_variant != 'windows'". If it is set and evaluates to
+	// false, the command is skipped.
+	If string `yaml:"if" bson:"if"`
+
 	// TimeoutSecs indicates the maximum duration the command is allowed to run
 	// for. If undefined, it is unbounded.
 	TimeoutSecs int `yaml:"timeout_secs" bson:"timeout_secs"`
@@ -223,6 +233,19 @@ type ProjectTask struct {
 	DependsOn       []TaskDependency    `yaml:"depends_on" bson:"depends_on"`
 	Commands        []PluginCommandConf `yaml:"commands" bson:"commands"`
 
+	// Tags group tasks so that build variants and dependencies can refer to
+	// all of them at once, using TaskTagSelectorPrefix.
+	Tags []string `yaml:"tags" bson:"tags"`
+
+	// Expansions are added to the task's expansions when it runs.
+	Expansions map[string]string `yaml:"expansions" bson:"expansions"`
+
+	// MatrixParameters make this a parametrized task definition, which is
+	// replaced by one task for each combination of the parameters' values
+	// when the project is loaded.
+	MatrixParameters      []MatrixParameter `yaml:"matrix_parameters" bson:"matrix_parameters"`
+	MatrixParameterValues map[string]string `yaml:"matrix_parameter_values" bson:"matrix_parameter_values"`
+
 	// Use a *bool so that there are 3 possible states:
 	//   1. nil   = not overriding the project setting (default)
 	//   2. true  = overriding the project setting with true
@@ -341,11 +364,11 @@ func NewTaskConfig(d *distro.Distro, p *Project, t *Task, r *ProjectRef) (*TaskC
 		return nil, fmt.Errorf("couldn't find buildvariant: '%v'", t.BuildVariant)
 	}
 
-	e := populateExpansions(d, bv, t)
+	e := populateExpansions(d, bv, p.FindProjectTask(t.DisplayName), t)
 	return &TaskConfig{d, r, p, t, bv, e, d.WorkDir}, nil
 }
 
-func populateExpansions(d *distro.Distro, bv *BuildVariant, t *Task) *command.Expansions {
+func populateExpansions(d *distro.Distro, bv *BuildVariant, pt *ProjectTask, t *Task) *command.Expansions {
 	expansions := command.NewExpansions(map[string]string{})
 	expansions.Put("execution", fmt.Sprintf("%v", t.Execution))
 	expansions.Put("task_id", t.Id)
@@ -356,10 +379,16 @@ func populateExpansions(d *distro.Distro, bv *BuildVariant, t *Task) *command.Ex
 	expansions.Put("revision", t.Revision)
 	expansions.Put("project", t.Project)
 	expansions.Put("branch_name", t.Project)
+	if t.Requester == evergreen.PatchVersionRequester {
+		expansions.Put("is_patch", "true")
+	}
 	for _, e := range d.Expansions {
 		expansions.Put(e.Key, e.Value)
 	}
 	expansions.Update(bv.Expansions)
+	if pt != nil {
+		expansions.Update(pt.Expansions)
+	}
 	return expansions
 }
 
@@ -486,6 +515,243 @@ func addMatrixVariants(project *Project) error {
 	return nil
 }
 
+// Creates the task for one combination of a parametrized task's matrix
+// parameter values. The name, tags and dependencies are expanded with the
+// parameter values, and the values and their expansions are added to the
+// task's expansions. If the name doesn't refer to any parameters, the values
+// are appended to it, as in "jstests_wiredTiger".
+func expandTaskMatrixParameters(pt ProjectTask,
+	matrixParameterValues []MatrixParameterValue) (*ProjectTask, error) {
+	newTask := pt
+	newTask.MatrixParameters = nil
+
+	matrixParameterMap := make(map[string]string)
+	for i, parameter := range pt.MatrixParameters {
+		matrixParameterMap[parameter.Name] = matrixParameterValues[i].Value
+	}
+	matrixParameterExpansions := command.NewExpansions(matrixParameterMap)
+
+	name := pt.Name
+	if !strings.Contains(name, "${") {
+		for _, value := range matrixParameterValues {
+			name += "_" + value.Value
+		}
+	}
+	name, err := matrixParameterExpansions.ExpandString(name)
+	if err != nil {
+		return nil, err
+	}
+	newTask.Name = name
+
+	// generated tasks can be referred to as a group by the definition's name
+	newTask.Tags = []string{}
+	if !strings.Contains(pt.Name, "${") {
+		newTask.Tags = append(newTask.Tags, pt.Name)
+	}
+	for _, tag := range pt.Tags {
+		tag, err := matrixParameterExpansions.ExpandString(tag)
+		if err != nil {
+			return nil, err
+		}
+		newTask.Tags = append(newTask.Tags, tag)
+	}
+
+	newTask.DependsOn = make([]TaskDependency, 0, len(pt.DependsOn))
+	for _, dep := range pt.DependsOn {
+		if dep.Name, err = matrixParameterExpansions.ExpandString(dep.Name); err != nil {
+			return nil, err
+		}
+		if dep.Variant, err = matrixParameterExpansions.ExpandString(dep.Variant); err != nil {
+			return nil, err
+		}
+		newTask.DependsOn = append(newTask.DependsOn, dep)
+	}
+
+	// the definition's expansions come first, then the parameter values, then
+	// the expansions attached to each value
+	newTask.Expansions = make(map[string]string)
+	for k, v := range pt.Expansions {
+		newTask.Expansions[k] = v
+	}
+	for k, v := range matrixParameterMap {
+		newTask.Expansions[k] = v
+	}
+	for _, value := range matrixParameterValues {
+		for k, v := range value.Expansions {
+			newTask.Expansions[k] = v
+		}
+	}
+	for key, expansion := range newTask.Expansions {
+		expansion, err := matrixParameterExpansions.ExpandString(expansion)
+		if err != nil {
+			return nil, err
+		}
+		newTask.Expansions[key] = expansion
+	}
+
+	newTask.MatrixParameterValues = matrixParameterMap
+	return &newTask, nil
+}
+
+// Returns the tasks for every combination of a parametrized task's matrix
+// parameter values.
+func expandTaskMatrix(pt ProjectTask) ([]ProjectTask, error) {
+	combinations := [][]MatrixParameterValue{{}}
+	for _, parameter := range pt.MatrixParameters {
+		if len(parameter.Values) == 0 {
+			return nil, fmt.Errorf("matrix parameter '%v' of task '%v' has no values",
+				parameter.Name, pt.Name)
+		}
+		next := make([][]MatrixParameterValue, 0, len(combinations)*len(parameter.Values))
+		for _, combination := range combinations {
+			for _, value := range parameter.Values {
+				newCombination := make([]MatrixParameterValue, 0, len(combination)+1)
+				newCombination = append(newCombination, combination...)
+				next = append(next, append(newCombination, value))
+			}
+		}
+		combinations = next
+	}
+
+	tasks := make([]ProjectTask, 0, len(combinations))
+	for _, combination := range combinations {
+		newTask, err := expandTaskMatrixParameters(pt, combination)
+		if err != nil {
+			return nil, fmt.Errorf("error expanding matrix parameters of task '%v': %v",
+				pt.Name, err)
+		}
+		tasks = append(tasks, *newTask)
+	}
+	return tasks, nil
+}
+
+// Replace each of the project's parametrized tasks with the tasks generated
+// from its matrix parameters
+func addMatrixTasks(project *Project) error {
+	tasks := make([]ProjectTask, 0, len(project.Tasks))
+	for _, pt := range project.Tasks {
+		if len(pt.MatrixParameters) == 0 {
+			tasks = append(tasks, pt)
+			continue
+		}
+		generated, err := expandTaskMatrix(pt)
+		if err != nil {
+			return err
+		}
+		tasks = append(tasks, generated...)
+	}
+	project.Tasks = tasks
+	return nil
+}
+
+// IsTaskTagSelector returns whether the task name refers to a tag.
+func IsTaskTagSelector(name string) bool {
+	return strings.HasPrefix(name, TaskTagSelectorPrefix)
+}
+
+// TasksWithTag returns the names of the project's tasks with the given tag.
+func (p *Project) TasksWithTag(tag string) []string {
+	names := []string{}
+	for _, pt := range p.Tasks {
+		if util.SliceContains(pt.Tags, tag) {
+			names = append(names, pt.Name)
+		}
+	}
+	return names
+}
+
+// Replace tag selectors in dependencies with a dependency on each task with
+// the tag, other than the dependent task itself
+func (p *Project) expandDependencySelectors(deps []TaskDependency, taskName string) []TaskDependency {
+	hasSelector := false
+	for _, dep := range deps {
+		hasSelector = hasSelector || IsTaskTagSelector(dep.Name)
+	}
+	if !hasSelector {
+		return deps
+	}
+
+	expanded := make([]TaskDependency, 0, len(deps))
+	for _, dep := range deps {
+		if !IsTaskTagSelector(dep.Name) {
+			expanded = append(expanded, dep)
+		}
+	}
+	for _, dep := range deps {
+		if !IsTaskTagSelector(dep.Name) {
+			continue
+		}
+		names := p.TasksWithTag(dep.Name[len(TaskTagSelectorPrefix):])
+		if len(names) == 0 {
+			// left for the validator to report
+			expanded = append(expanded, dep)
+			continue
+		}
+		for _, name := range names {
+			if name == taskName || hasDependency(expanded, name, dep.Variant) {
+				continue
+			}
+			newDep := dep
+			newDep.Name = name
+			expanded = append(expanded, newDep)
+		}
+	}
+	return expanded
+}
+
+func hasDependency(deps []TaskDependency, name, variant string) bool {
+	for _, dep := range deps {
+		if dep.Name == name && dep.Variant == variant {
+			return true
+		}
+	}
+	return false
+}
+
+// Replace tag selectors in build variants' task lists and in dependencies
+// with the tasks that have the tag. Tasks listed by name take precedence over
+// the same tasks selected by tag. Selectors that match no tasks are left in
+// place, so that the validator reports them.
+func expandTaskSelectors(project *Project) {
+	for i, pt := range project.Tasks {
+		project.Tasks[i].DependsOn = project.expandDependencySelectors(pt.DependsOn, pt.Name)
+	}
+
+	for i, bv := range project.BuildVariants {
+		listed := make(map[string]bool)
+		for _, bvt := range bv.Tasks {
+			if !IsTaskTagSelector(bvt.Name) {
+				listed[bvt.Name] = true
+			}
+		}
+
+		tasks := make([]BuildVariantTask, 0, len(bv.Tasks))
+		for _, bvt := range bv.Tasks {
+			if !IsTaskTagSelector(bvt.Name) {
+				bvt.DependsOn = project.expandDependencySelectors(bvt.DependsOn, bvt.Name)
+				tasks = append(tasks, bvt)
+				continue
+			}
+			names := project.TasksWithTag(bvt.Name[len(TaskTagSelectorPrefix):])
+			if len(names) == 0 {
+				tasks = append(tasks, bvt)
+				continue
+			}
+			for _, name := range names {
+				if listed[name] {
+					continue
+				}
+				listed[name] = true
+				newBvt := bvt
+				newBvt.Name = name
+				newBvt.DependsOn = project.expandDependencySelectors(bvt.DependsOn, name)
+				tasks = append(tasks, newBvt)
+			}
+		}
+		project.BuildVariants[i].Tasks = tasks
+	}
+}
+
 // GetSpecForTask returns a ProjectTask spec for the given name.
 // Returns an empty ProjectTask if none exists.
 func (p Project) GetSpecForTask(name string) ProjectTask {
@@ -621,7 +887,14 @@ func LoadProjectInto(data []byte, identifier string, project *Project) error {
 		return fmt.Errorf("Parse error unmarshalling project: %v", err)
 	}
 	project.Identifier = identifier
-	return addMatrixVariants(project)
+	if err := addMatrixVariants(project); err != nil {
+		return err
+	}
+	if err := addMatrixTasks(project); err != nil {
+		return err
+	}
+	expandTaskSelectors(project)
+	return nil
 }
 
 func (p *Project) FindBuildVariant(build string) *BuildVariant {
@@ -1,11 +1,14 @@
 package model
 
 import (
+	"crypto/sha1"
 	"fmt"
 	"github.com/evergreen-ci/evergreen/db"
 	"github.com/evergreen-ci/evergreen/db/bsonutil"
 	"gopkg.in/mgo.v2"
 	"gopkg.in/mgo.v2/bson"
+	"path"
+	"regexp"
 )
 
 // The ProjectRef struct contains general information, independent of any
@@ -30,6 +33,41 @@ type ProjectRef struct {
 	// the set of alert deliveries to be processed for that trigger.
 	Alerts map[string][]AlertConfig `bson:"alert_settings" json:"alert_config"`
 
+	// ArtifactRetention is the set of rules that decide when files attached
+	// to this project's tasks expire and are cleaned up.
+	ArtifactRetention []ArtifactRetentionRule `bson:"artifact_retention" json:"artifact_retention"`
+
+	// SystemFailureRetries is the number of times a task that fails because of
+	// a system failure is automatically rescheduled on a different host.
+	SystemFailureRetries int `bson:"system_failure_retries" json:"system_failure_retries"`
+
+	// Admins are the ids of users, besides superusers, allowed to manage the
+	// project's tasks in the distro task queues.
+	Admins []string `bson:"admins" json:"admins"`
+
+	// RemoteURL is the location of the repository of projects that aren't
+	// hosted on GitHub, as given to git clone.
+	RemoteURL string `bson:"remote_url" json:"remote_url" yaml:"remote_url"`
+
+	// GerritURL is the Gerrit server of Gerrit projects, whose project name
+	// on the server is the Repo field.
+	GerritURL string `bson:"gerrit_url" json:"gerrit_url" yaml:"gerrit_url"`
+
+	// BranchPatterns are patterns, such as "v3.*", of further branches of
+	// the repository to track. Each matching branch is tracked as a project
+	// of its own, whose project ref is generated from this one.
+	BranchPatterns []string `bson:"branch_patterns" json:"branch_patterns"`
+
+	// Parent is the identifier of the project ref that the project ref of a
+	// branch matching its BranchPatterns was generated from. The branch's
+	// project inherits the parent's vars and alerts.
+	Parent string `bson:"parent,omitempty" json:"parent,omitempty"`
+
+	// GithubStatus is how the results of the project's mainline versions are
+	// reported back to GitHub as commit statuses: one per build variant, a
+	// summary of the whole version, or not at all when empty.
+	GithubStatus string `bson:"github_status" json:"github_status"`
+
 	// RepoDetails contain the details of the status of the consistency
 	// between what is in GitHub and what is in Evergreen
 	RepotrackerError *RepositoryErrorDetails `bson:"repotracker_error" json:"repotracker_error"`
@@ -51,6 +89,16 @@ type AlertConfig struct {
 	Settings bson.M `bson:"settings" json:"settings"`
 }
 
+// ArtifactRetentionRule expires the files attached to finished tasks after a number
+// of days. Empty Requester and Visibility fields match every task and every file.
+type ArtifactRetentionRule struct {
+	// Requester is either evergreen.PatchVersionRequester or
+	// evergreen.RepotrackerVersionRequester
+	Requester  string `bson:"requester" json:"requester"`
+	Visibility string `bson:"visibility" json:"visibility"`
+	MaxAgeDays int    `bson:"max_age_days" json:"max_age_days"`
+}
+
 type EmailAlertData struct {
 	Recipients []string `bson:"recipients"`
 }
@@ -61,6 +109,8 @@ var (
 	ProjectRefRepoKey               = bsonutil.MustHaveTag(ProjectRef{}, "Repo")
 	ProjectRefBranchKey             = bsonutil.MustHaveTag(ProjectRef{}, "Branch")
 	ProjectRefRepoKindKey           = bsonutil.MustHaveTag(ProjectRef{}, "RepoKind")
+	ProjectRefRemoteURLKey          = bsonutil.MustHaveTag(ProjectRef{}, "RemoteURL")
+	ProjectRefGerritURLKey          = bsonutil.MustHaveTag(ProjectRef{}, "GerritURL")
 	ProjectRefEnabledKey            = bsonutil.MustHaveTag(ProjectRef{}, "Enabled")
 	ProjectRefPrivateKey            = bsonutil.MustHaveTag(ProjectRef{}, "Private")
 	ProjectRefBatchTimeKey          = bsonutil.MustHaveTag(ProjectRef{}, "BatchTime")
@@ -72,12 +122,25 @@ var (
 	ProjectRefLocalConfig           = bsonutil.MustHaveTag(ProjectRef{}, "LocalConfig")
 	ProjectRefAlertsKey             = bsonutil.MustHaveTag(ProjectRef{}, "Alerts")
 	ProjectRefRepotrackerError      = bsonutil.MustHaveTag(ProjectRef{}, "RepotrackerError")
+	ProjectRefArtifactRetentionKey  = bsonutil.MustHaveTag(ProjectRef{}, "ArtifactRetention")
+	ProjectRefSystemFailureRetries  = bsonutil.MustHaveTag(ProjectRef{}, "SystemFailureRetries")
+	ProjectRefAdminsKey             = bsonutil.MustHaveTag(ProjectRef{}, "Admins")
+	ProjectRefBranchPatternsKey     = bsonutil.MustHaveTag(ProjectRef{}, "BranchPatterns")
+	ProjectRefParentKey             = bsonutil.MustHaveTag(ProjectRef{}, "Parent")
+	ProjectRefGithubStatusKey       = bsonutil.MustHaveTag(ProjectRef{}, "GithubStatus")
 )
 
 const (
 	ProjectRefCollection = "project_ref"
+
+	// values of GithubStatus
+	GithubStatusVariants = "variants"
+	GithubStatusSummary  = "summary"
 )
 
+// characters of branch names that aren't used in project identifiers
+var branchIdentifierRegex = regexp.MustCompile("[^A-Za-z0-9._-]")
+
 func (projectRef *ProjectRef) Insert() error {
 	return db.Insert(ProjectRefCollection, projectRef)
 }
@@ -133,6 +196,59 @@ func FindAllProjectRefs() ([]ProjectRef, error) {
 	return projectRefs, err
 }
 
+// FindBranchProjectRefs returns the project refs generated for the branches
+// tracked by the parent project ref.
+func FindBranchProjectRefs(parent string) ([]ProjectRef, error) {
+	projectRefs := []ProjectRef{}
+	err := db.FindAll(
+		ProjectRefCollection,
+		bson.M{ProjectRefParentKey: parent},
+		db.NoProjection,
+		db.NoSort,
+		db.NoSkip,
+		db.NoLimit,
+		&projectRefs,
+	)
+	return projectRefs, err
+}
+
+// FindBranchProjectRef returns the project ref that tracks the branch of the
+// given project: either the project ref itself, if it tracks the branch
+// directly, or the one generated for the branch. It returns nil if the
+// project doesn't track the branch.
+func FindBranchProjectRef(identifier, branch string) (*ProjectRef, error) {
+	projectRef, err := FindOneProjectRef(identifier)
+	if err != nil || projectRef == nil {
+		return nil, err
+	}
+	if projectRef.Branch == branch {
+		return projectRef, nil
+	}
+	return FindOneBranchProjectRef(projectRef.Identifier, branch)
+}
+
+// FindOneBranchProjectRef returns the project ref generated for the branch
+// of the parent project, or nil if there isn't one. Branch project refs are
+// looked up by parent and branch rather than by identifier, since the
+// identifier of a branch whose name collides with another's is made unique.
+func FindOneBranchProjectRef(parent, branch string) (*ProjectRef, error) {
+	projectRef := &ProjectRef{}
+	err := db.FindOne(
+		ProjectRefCollection,
+		bson.M{
+			ProjectRefParentKey: parent,
+			ProjectRefBranchKey: branch,
+		},
+		db.NoProjection,
+		db.NoSort,
+		projectRef,
+	)
+	if err == mgo.ErrNotFound {
+		return nil, nil
+	}
+	return projectRef, err
+}
+
 // UntrackStaleProjectRefs sets all project_refs in the db not in the array
 // of project identifiers to "untracked."
 func UntrackStaleProjectRefs(activeProjects []string) error {
@@ -159,6 +275,8 @@ func (projectRef *ProjectRef) Upsert() error {
 		bson.M{
 			"$set": bson.M{
 				ProjectRefRepoKindKey:           projectRef.RepoKind,
+				ProjectRefRemoteURLKey:          projectRef.RemoteURL,
+				ProjectRefGerritURLKey:          projectRef.GerritURL,
 				ProjectRefEnabledKey:            projectRef.Enabled,
 				ProjectRefPrivateKey:            projectRef.Private,
 				ProjectRefBatchTimeKey:          projectRef.BatchTime,
@@ -173,6 +291,12 @@ func (projectRef *ProjectRef) Upsert() error {
 				ProjectRefLocalConfig:           projectRef.LocalConfig,
 				ProjectRefAlertsKey:             projectRef.Alerts,
 				ProjectRefRepotrackerError:      projectRef.RepotrackerError,
+				ProjectRefArtifactRetentionKey:  projectRef.ArtifactRetention,
+				ProjectRefSystemFailureRetries:  projectRef.SystemFailureRetries,
+				ProjectRefAdminsKey:             projectRef.Admins,
+				ProjectRefBranchPatternsKey:     projectRef.BranchPatterns,
+				ProjectRefParentKey:             projectRef.Parent,
+				ProjectRefGithubStatusKey:       projectRef.GithubStatus,
 			},
 		},
 	)
@@ -184,6 +308,96 @@ func (projectRef *ProjectRef) String() string {
 	return projectRef.Identifier
 }
 
+// IsAdmin returns true if the user is one of the project's admins.
+func (projectRef *ProjectRef) IsAdmin(userId string) bool {
+	for _, admin := range projectRef.Admins {
+		if admin == userId {
+			return true
+		}
+	}
+	return false
+}
+
+// TracksBranch returns true if the branch matches one of the project ref's
+// branch patterns.
+func (projectRef *ProjectRef) TracksBranch(branch string) bool {
+	if branch == projectRef.Branch {
+		return false
+	}
+	for _, pattern := range projectRef.BranchPatterns {
+		if matched, err := path.Match(pattern, branch); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// BranchIdentifier returns the identifier of the project that tracks the
+// branch, as in "mongodb-mongo_v3.0".
+func (projectRef *ProjectRef) BranchIdentifier(branch string) string {
+	if branch == projectRef.Branch {
+		return projectRef.Identifier
+	}
+	return projectRef.Identifier + "_" + branchIdentifierRegex.ReplaceAllString(branch, "-")
+}
+
+// UniqueBranchIdentifier returns the branch identifier with a hash of the
+// branch name appended, for branches such as "v3/0" and "v3-0" whose
+// identifiers would otherwise be the same.
+func (projectRef *ProjectRef) UniqueBranchIdentifier(branch string) string {
+	hash := sha1.Sum([]byte(branch))
+	return fmt.Sprintf("%v-%x", projectRef.BranchIdentifier(branch), hash[:4])
+}
+
+// NewBranchProjectRef returns the project ref of a project that tracks the
+// branch with the settings of this one. Alerts and vars aren't copied, since
+// they are inherited from this project ref.
+func (projectRef *ProjectRef) NewBranchProjectRef(branch string) *ProjectRef {
+	displayName := projectRef.DisplayName
+	if displayName == "" {
+		displayName = projectRef.Identifier
+	}
+	return &ProjectRef{
+		Identifier:           projectRef.BranchIdentifier(branch),
+		DisplayName:          fmt.Sprintf("%v (%v)", displayName, branch),
+		Parent:               projectRef.Identifier,
+		Branch:               branch,
+		Owner:                projectRef.Owner,
+		Repo:                 projectRef.Repo,
+		RepoKind:             projectRef.RepoKind,
+		RemoteURL:            projectRef.RemoteURL,
+		GerritURL:            projectRef.GerritURL,
+		RemotePath:           projectRef.RemotePath,
+		LocalConfig:          projectRef.LocalConfig,
+		Enabled:              projectRef.Enabled,
+		Private:              projectRef.Private,
+		Tracked:              projectRef.Tracked,
+		BatchTime:            projectRef.BatchTime,
+		DeactivatePrevious:   projectRef.DeactivatePrevious,
+		ArtifactRetention:    projectRef.ArtifactRetention,
+		SystemFailureRetries: projectRef.SystemFailureRetries,
+		Admins:               projectRef.Admins,
+		GithubStatus:         projectRef.GithubStatus,
+	}
+}
+
+// GetAlerts returns the alerts configured for the trigger. Project refs
+// generated for branches use their parent's alerts.
+func (projectRef *ProjectRef) GetAlerts(trigger string) ([]AlertConfig, error) {
+	if projectRef.Parent == "" {
+		return projectRef.Alerts[trigger], nil
+	}
+	parent, err := FindOneProjectRef(projectRef.Parent)
+	if err != nil {
+		return nil, err
+	}
+	if parent == nil {
+		return nil, fmt.Errorf("parent project %v of %v not found",
+			projectRef.Parent, projectRef.Identifier)
+	}
+	return parent.Alerts[trigger], nil
+}
+
 // GetBatchTime returns the Batch Time of the ProjectRef
 func (p *ProjectRef) GetBatchTime(variant *BuildVariant) int {
 	if variant.BatchTime != nil {
@@ -194,6 +408,9 @@ func (p *ProjectRef) GetBatchTime(variant *BuildVariant) int {
 
 // Location generates and returns the ssh hostname and path to the repo.
 func (projectRef *ProjectRef) Location() (string, error) {
+	if projectRef.RemoteURL != "" {
+		return projectRef.RemoteURL, nil
+	}
 	if projectRef.Owner == "" {
 		return "", fmt.Errorf("No owner in project ref: %v", projectRef.Identifier)
 	}
@@ -36,3 +36,41 @@ func TestFindOneProjectRef(t *testing.T) {
 		})
 	})
 }
+
+func TestBranchProjectRefs(t *testing.T) {
+	Convey("With a project ref tracking branch patterns", t, func() {
+		projectRef := &ProjectRef{
+			Identifier:     "mongodb-mongo",
+			Branch:         "master",
+			BranchPatterns: []string{"v*", "release/*"},
+			BatchTime:      10,
+		}
+		Convey("only matching branches should be tracked", func() {
+			So(projectRef.TracksBranch("v3.0"), ShouldBeTrue)
+			So(projectRef.TracksBranch("release/1.2"), ShouldBeTrue)
+			So(projectRef.TracksBranch("master"), ShouldBeFalse)
+			So(projectRef.TracksBranch("feature"), ShouldBeFalse)
+		})
+		Convey("branch identifiers should replace disallowed characters", func() {
+			So(projectRef.BranchIdentifier("master"), ShouldEqual, "mongodb-mongo")
+			So(projectRef.BranchIdentifier("v3.0"), ShouldEqual, "mongodb-mongo_v3.0")
+			So(projectRef.BranchIdentifier("release/1.2"), ShouldEqual,
+				"mongodb-mongo_release-1.2")
+		})
+		Convey("unique branch identifiers should differ for branches with the same "+
+			"branch identifier", func() {
+			So(projectRef.BranchIdentifier("v3/0"), ShouldEqual, projectRef.BranchIdentifier("v3-0"))
+			So(projectRef.UniqueBranchIdentifier("v3/0"), ShouldStartWith, "mongodb-mongo_v3-0-")
+			So(projectRef.UniqueBranchIdentifier("v3/0"), ShouldNotEqual,
+				projectRef.UniqueBranchIdentifier("v3-0"))
+		})
+		Convey("branch project refs should copy settings and set the parent", func() {
+			branchRef := projectRef.NewBranchProjectRef("v3.0")
+			So(branchRef.Identifier, ShouldEqual, "mongodb-mongo_v3.0")
+			So(branchRef.Parent, ShouldEqual, "mongodb-mongo")
+			So(branchRef.Branch, ShouldEqual, "v3.0")
+			So(branchRef.BatchTime, ShouldEqual, 10)
+			So(branchRef.BranchPatterns, ShouldBeEmpty)
+		})
+	})
+}
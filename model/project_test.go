@@ -364,3 +364,111 @@ func TestPopulateBVT(t *testing.T) {
 func boolPtr(b bool) *bool {
 	return &b
 }
+
+func TestTaskMatrix(t *testing.T) {
+	Convey("With a project with a parametrized task", t, func() {
+		config := `
+tasks:
+- name: compile
+- name: jstests
+  tags: ["${engine}_tests"]
+  depends_on:
+  - name: compile
+  expansions:
+    test_flags: "--storageEngine=${engine}"
+  matrix_parameters:
+  - name: suite
+    values:
+    - value: core
+    - value: aggregation
+      expansions:
+        resmoke_jobs: "1"
+  - name: engine
+    values:
+    - value: mmapv1
+    - value: wiredTiger
+- name: "lint_${language}"
+  tags: ["lint"]
+  matrix_parameters:
+  - name: language
+    values:
+    - value: js
+    - value: python
+- name: report
+  depends_on:
+  - name: .jstests
+buildvariants:
+- name: linux
+  tasks:
+  - name: compile
+  - name: .wiredTiger_tests
+  - name: .lint
+    priority: 5
+  - name: lint_js
+    priority: 10
+  - name: .nonexistent
+`
+		project := &Project{}
+		So(LoadProjectInto([]byte(config), "proj", project), ShouldBeNil)
+
+		Convey("each combination of values should become a task", func() {
+			names := []string{}
+			for _, pt := range project.Tasks {
+				names = append(names, pt.Name)
+			}
+			So(names, ShouldResemble, []string{"compile",
+				"jstests_core_mmapv1", "jstests_core_wiredTiger",
+				"jstests_aggregation_mmapv1", "jstests_aggregation_wiredTiger",
+				"lint_js", "lint_python", "report"})
+
+			pt := project.FindProjectTask("jstests_aggregation_wiredTiger")
+			So(pt, ShouldNotBeNil)
+			So(pt.Tags, ShouldResemble, []string{"jstests", "wiredTiger_tests"})
+			So(pt.DependsOn, ShouldResemble, []TaskDependency{{Name: "compile"}})
+			So(pt.Expansions, ShouldResemble, map[string]string{
+				"suite":        "aggregation",
+				"engine":       "wiredTiger",
+				"resmoke_jobs": "1",
+				"test_flags":   "--storageEngine=wiredTiger",
+			})
+			So(pt.MatrixParameterValues, ShouldResemble, map[string]string{
+				"suite":  "aggregation",
+				"engine": "wiredTiger",
+			})
+			So(len(pt.MatrixParameters), ShouldEqual, 0)
+
+			pt = project.FindProjectTask("lint_python")
+			So(pt, ShouldNotBeNil)
+			So(pt.Tags, ShouldResemble, []string{"lint"})
+		})
+
+		Convey("tag selectors should be replaced by the tagged tasks", func() {
+			report := project.FindProjectTask("report")
+			So(report, ShouldNotBeNil)
+			So(len(report.DependsOn), ShouldEqual, 4)
+			So(report.DependsOn[0].Name, ShouldEqual, "jstests_core_mmapv1")
+
+			bv := project.FindBuildVariant("linux")
+			So(bv, ShouldNotBeNil)
+			names := []string{}
+			for _, bvt := range bv.Tasks {
+				names = append(names, bvt.Name)
+			}
+			So(names, ShouldResemble, []string{"compile",
+				"jstests_core_wiredTiger", "jstests_aggregation_wiredTiger",
+				"lint_python", "lint_js", ".nonexistent"})
+			So(bv.Tasks[3].Priority, ShouldEqual, 5)
+			So(bv.Tasks[4].Priority, ShouldEqual, 10)
+		})
+	})
+
+	Convey("A matrix parameter without values should be an error", t, func() {
+		config := `
+tasks:
+- name: jstests
+  matrix_parameters:
+  - name: engine
+`
+		So(LoadProjectInto([]byte(config), "proj", &Project{}), ShouldNotBeNil)
+	})
+}
@@ -49,6 +49,40 @@ func FindOneProjectVars(projectId string) (*ProjectVars, error) {
 	return projectVars, nil
 }
 
+// FindMergedProjectVars returns the vars of the project, which for a project
+// generated for a branch are its parent's vars overridden by its own.
+func FindMergedProjectVars(projectId string) (*ProjectVars, error) {
+	projectVars, err := FindOneProjectVars(projectId)
+	if err != nil {
+		return nil, err
+	}
+	projectRef, err := FindOneProjectRef(projectId)
+	if err != nil {
+		return nil, err
+	}
+	if projectRef == nil || projectRef.Parent == "" {
+		return projectVars, nil
+	}
+	parentVars, err := FindOneProjectVars(projectRef.Parent)
+	if err != nil {
+		return nil, err
+	}
+	if parentVars == nil {
+		return projectVars, nil
+	}
+
+	merged := &ProjectVars{Id: projectId, Vars: map[string]string{}}
+	for k, v := range parentVars.Vars {
+		merged.Vars[k] = v
+	}
+	if projectVars != nil {
+		for k, v := range projectVars.Vars {
+			merged.Vars[k] = v
+		}
+	}
+	return merged, nil
+}
+
 func (projectVars *ProjectVars) Upsert() (*mgo.ChangeInfo, error) {
 	return db.Upsert(
 		ProjectVarsCollection,
@@ -0,0 +1,129 @@
+package subscription
+
+import (
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/evergreen-ci/evergreen/db/bsonutil"
+	"github.com/evergreen-ci/evergreen/util"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"time"
+)
+
+const (
+	Collection = "notification_subscriptions"
+
+	// Subscriptions are delivered by email, or posted to the chat webhook
+	EmailMethod = "email"
+	ChatMethod  = "chat"
+)
+
+// Subscription is a user's or a team's request to be notified when a trigger
+// fires for a project. The trigger is either the name of a notify handler
+// (e.g. "build_failure") or the id of an alerts trigger (e.g. "task_failed").
+// Empty BuildVariants and Tasks match every variant and task.
+type Subscription struct {
+	Id bson.ObjectId `bson:"_id" json:"id"`
+	// Owner is the id of the user who created the subscription. Subscriptions
+	// without a team are delivered to the owner.
+	Owner string `bson:"owner" json:"owner"`
+	// Method is how the subscription is delivered; empty means by email
+	Method string `bson:"method,omitempty" json:"method"`
+	// Team and Address are set for subscriptions that are delivered to a team.
+	// The address of a team's chat subscription is its channel.
+	Team          string    `bson:"team,omitempty" json:"team"`
+	Address       string    `bson:"address,omitempty" json:"address"`
+	Project       string    `bson:"project" json:"project"`
+	Trigger       string    `bson:"trigger" json:"trigger"`
+	Requester     string    `bson:"requester" json:"requester"`
+	BuildVariants []string  `bson:"build_variants,omitempty" json:"build_variants"`
+	Tasks         []string  `bson:"tasks,omitempty" json:"tasks"`
+	CreatedAt     time.Time `bson:"created_at" json:"created_at"`
+}
+
+var (
+	IdKey            = bsonutil.MustHaveTag(Subscription{}, "Id")
+	OwnerKey         = bsonutil.MustHaveTag(Subscription{}, "Owner")
+	MethodKey        = bsonutil.MustHaveTag(Subscription{}, "Method")
+	TeamKey          = bsonutil.MustHaveTag(Subscription{}, "Team")
+	AddressKey       = bsonutil.MustHaveTag(Subscription{}, "Address")
+	ProjectKey       = bsonutil.MustHaveTag(Subscription{}, "Project")
+	TriggerKey       = bsonutil.MustHaveTag(Subscription{}, "Trigger")
+	RequesterKey     = bsonutil.MustHaveTag(Subscription{}, "Requester")
+	BuildVariantsKey = bsonutil.MustHaveTag(Subscription{}, "BuildVariants")
+	TasksKey         = bsonutil.MustHaveTag(Subscription{}, "Tasks")
+	CreatedAtKey     = bsonutil.MustHaveTag(Subscription{}, "CreatedAt")
+)
+
+// All is a query for all subscriptions.
+var All = db.Query(nil)
+
+// ById returns a query for the subscription with the given id.
+func ById(id bson.ObjectId) db.Q {
+	return db.Query(bson.M{IdKey: id})
+}
+
+// ByOwner returns a query for the subscriptions created by the user, sorted
+// by project and trigger.
+func ByOwner(owner string) db.Q {
+	return db.Query(bson.M{OwnerKey: owner}).Sort([]string{ProjectKey, TriggerKey})
+}
+
+// ByProjectAndTrigger returns a query for the subscriptions to the trigger
+// on the project.
+func ByProjectAndTrigger(project, trigger string) db.Q {
+	return db.Query(bson.M{
+		ProjectKey: project,
+		TriggerKey: trigger,
+	})
+}
+
+// FindOne gets one Subscription for the given query.
+func FindOne(query db.Q) (*Subscription, error) {
+	subscription := &Subscription{}
+	err := db.FindOneQ(Collection, query, subscription)
+	if err == mgo.ErrNotFound {
+		return nil, nil
+	}
+	return subscription, err
+}
+
+// Find gets all Subscriptions for the given query.
+func Find(query db.Q) ([]Subscription, error) {
+	subscriptions := []Subscription{}
+	err := db.FindAllQ(Collection, query, &subscriptions)
+	return subscriptions, err
+}
+
+// Remove deletes the subscription with the given id.
+func Remove(id bson.ObjectId) error {
+	return db.Remove(Collection, bson.M{IdKey: id})
+}
+
+// Insert writes the subscription to the database.
+func (s *Subscription) Insert() error {
+	return db.Insert(Collection, s)
+}
+
+// IsTeam returns true if the subscription is delivered to a team address
+// rather than to its owner.
+func (s *Subscription) IsTeam() bool {
+	return s.Team != ""
+}
+
+// IsChat returns true if the subscription is posted to the chat webhook.
+func (s *Subscription) IsChat() bool {
+	return s.Method == ChatMethod
+}
+
+// Matches returns true if the subscription covers the task of the build
+// variant. An empty task name, as for build notifications, only checks the
+// build variant.
+func (s *Subscription) Matches(buildVariant, taskName string) bool {
+	if len(s.BuildVariants) != 0 && !util.SliceContains(s.BuildVariants, buildVariant) {
+		return false
+	}
+	if taskName != "" && len(s.Tasks) != 0 && !util.SliceContains(s.Tasks, taskName) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,34 @@
+package subscription
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestSubscriptionMatches(t *testing.T) {
+	Convey("With a subscription to some variants and tasks", t, func() {
+		subscription := &Subscription{
+			BuildVariants: []string{"linux-64", "windows"},
+			Tasks:         []string{"compile"},
+		}
+		Convey("only the listed variants and tasks should match", func() {
+			So(subscription.Matches("linux-64", "compile"), ShouldBeTrue)
+			So(subscription.Matches("windows", "compile"), ShouldBeTrue)
+			So(subscription.Matches("osx", "compile"), ShouldBeFalse)
+			So(subscription.Matches("linux-64", "test"), ShouldBeFalse)
+		})
+		Convey("builds should only be matched by variant", func() {
+			So(subscription.Matches("linux-64", ""), ShouldBeTrue)
+			So(subscription.Matches("osx", ""), ShouldBeFalse)
+		})
+	})
+	Convey("A subscription without variants or tasks should match everything", t, func() {
+		subscription := &Subscription{}
+		So(subscription.Matches("linux-64", "compile"), ShouldBeTrue)
+		So(subscription.Matches("osx", ""), ShouldBeTrue)
+	})
+	Convey("Subscriptions with a team should be team subscriptions", t, func() {
+		So((&Subscription{Team: "server", Address: "server@example.com"}).IsTeam(), ShouldBeTrue)
+		So((&Subscription{Owner: "me"}).IsTeam(), ShouldBeFalse)
+	})
+}
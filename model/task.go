@@ -26,6 +26,9 @@ const (
 var (
 	ZeroTime       = time.Unix(0, 0)
 	AgentHeartbeat = "heartbeat"
+	// HostTerminated describes tasks whose host was terminated from outside
+	// Evergreen, e.g. an interrupted spot instance, while they were running
+	HostTerminated = "host terminated"
 )
 
 type Task struct {
@@ -75,6 +78,12 @@ type Task struct {
 	Archived            bool   `bson:"archived,omitempty" json:"archived",omitempty`
 	RevisionOrderNumber int    `bson:"order,omitempty" json:"order,omitempty"`
 
+	// the number of times this task was automatically restarted after a
+	// system failure, and the hosts it failed on, which it won't be
+	// dispatched to again
+	SystemFailureRetries int      `bson:"system_failure_retries,omitempty" json:"system_failure_retries,omitempty"`
+	ExcludedHosts        []string `bson:"excluded_hosts,omitempty" json:"excluded_hosts,omitempty"`
+
 	// task requester - this is used to help tell the
 	// reason this task was created. e.g. it could be
 	// because the repotracker requested it (via tracking the
@@ -189,6 +198,9 @@ var (
 	TaskPriorityKey            = bsonutil.MustHaveTag(Task{}, "Priority")
 	TaskMinQueuePosKey         = bsonutil.MustHaveTag(Task{}, "MinQueuePos")
 
+	TaskSystemFailureRetriesKey = bsonutil.MustHaveTag(Task{}, "SystemFailureRetries")
+	TaskExcludedHostsKey        = bsonutil.MustHaveTag(Task{}, "ExcludedHosts")
+
 	// BSON fields for the test result struct
 	TestResultStatusKey    = bsonutil.MustHaveTag(TestResult{}, "Status")
 	TestResultTestFileKey  = bsonutil.MustHaveTag(TestResult{}, "TestFile")
@@ -690,6 +702,41 @@ func FindTasksForHostIds(ids []string) ([]Task, error) {
 		db.NoLimit)
 }
 
+// FindLastTasksOnHost returns up to limit tasks that finished on the host
+// after the given time, most recent first. Previous executions of restarted
+// tasks are included, since a task that failed and was reset only shows up
+// in the old tasks collection.
+func FindLastTasksOnHost(hostId string, since time.Time, limit int) ([]Task, error) {
+	query := bson.M{
+		TaskHostIdKey:     hostId,
+		TaskStatusKey:     bson.M{"$in": evergreen.CompletedStatuses},
+		TaskFinishTimeKey: bson.M{"$gt": since},
+	}
+	sort := []string{"-" + TaskFinishTimeKey}
+	tasks, err := FindAllTasks(query, db.NoProjection, sort, db.NoSkip, limit)
+	if err != nil {
+		return nil, err
+	}
+	oldTasks := []Task{}
+	err = db.FindAll(OldTasksCollection, query, db.NoProjection, sort, db.NoSkip, limit, &oldTasks)
+	if err != nil {
+		return nil, err
+	}
+
+	// merge the two lists, which are both sorted by finish time
+	merged := make([]Task, 0, len(tasks)+len(oldTasks))
+	for len(merged) < limit && (len(tasks) > 0 || len(oldTasks) > 0) {
+		if len(oldTasks) == 0 || (len(tasks) > 0 && tasks[0].FinishTime.After(oldTasks[0].FinishTime)) {
+			merged = append(merged, tasks[0])
+			tasks = tasks[1:]
+		} else {
+			merged = append(merged, oldTasks[0])
+			oldTasks = oldTasks[1:]
+		}
+	}
+	return merged, nil
+}
+
 // Get history of tasks for a given build variant, project, and display name.
 func FindCompletedTasksByVariantAndName(project string, buildVariant string,
 	taskName string, limit int, beforeTaskId string) ([]Task, error) {
@@ -1106,6 +1153,69 @@ func (t *Task) TryReset(user, origin string, p *Project, detail *apimodels.TaskE
 	return err
 }
 
+// IsSystemFailure returns true if the task failed because of a problem with
+// its host or a system command, rather than because of the code under test.
+func (t *Task) IsSystemFailure() bool {
+	return t.Status == evergreen.TaskFailed && isSystemFailure(&t.Details)
+}
+
+func isSystemFailure(detail *apimodels.TaskEndDetail) bool {
+	return detail.Type == SystemCommandType || detail.Description == AgentHeartbeat ||
+		detail.Description == HostTerminated
+}
+
+// FailedTests returns the files of the task's failed tests.
+func (t *Task) FailedTests() []string {
+	failed := []string{}
+	for _, test := range t.TestResults {
+		if test.Status == evergreen.TestFailedStatus {
+			failed = append(failed, test.TestFile)
+		}
+	}
+	return failed
+}
+
+// IsExcludedHost returns true if the task previously hit a system failure on
+// the host and shouldn't be dispatched to it again.
+func (t *Task) IsExcludedHost(hostId string) bool {
+	return util.SliceContains(t.ExcludedHosts, hostId)
+}
+
+// TryRetrySystemFailure marks the task as ended and, if it failed because of
+// a system failure and has been retried fewer than maxRetries times, resets it
+// so that it is rescheduled on a different host. It returns true if the task
+// was retried; otherwise the caller is responsible for finishing the task.
+func (t *Task) TryRetrySystemFailure(origin string, detail *apimodels.TaskEndDetail, maxRetries int) (bool, error) {
+	if t.Aborted || detail.Status != evergreen.TaskFailed || !isSystemFailure(detail) {
+		return false, nil
+	}
+	if t.SystemFailureRetries >= maxRetries || t.Execution >= evergreen.MaxTaskExecution {
+		return false, nil
+	}
+
+	if err := t.markEnd(origin, time.Now(), detail); err != nil {
+		return false, fmt.Errorf("Error marking task as ended: %v", err)
+	}
+
+	update := bson.M{"$inc": bson.M{TaskSystemFailureRetriesKey: 1}}
+	if t.HostId != "" {
+		update["$addToSet"] = bson.M{TaskExcludedHostsKey: t.HostId}
+	}
+	if err := UpdateOneTask(bson.M{TaskIdKey: t.Id}, update); err != nil {
+		return false, fmt.Errorf("Error recording system failure retry: %v", err)
+	}
+
+	if err := t.reset(); err != nil {
+		return false, err
+	}
+	t.SystemFailureRetries++
+	if t.HostId != "" && !t.IsExcludedHost(t.HostId) {
+		t.ExcludedHosts = append(t.ExcludedHosts, t.HostId)
+	}
+	event.LogTaskSystemFailureRetried(t.Id, t.HostId)
+	return true, nil
+}
+
 func (t *Task) reset() error {
 	if err := t.Archive(); err != nil {
 		return fmt.Errorf("Can't restart task because it can't be archived: %v", err)
@@ -1222,7 +1332,7 @@ func (t *Task) UpdateBuildStatus() error {
 				if task.Status != evergreen.TaskSucceeded {
 					failedTask = true
 					finishedTasks = -1
-					err = b.MarkFinished(evergreen.BuildFailed, finishTime)
+					err = markBuildFinished(b, evergreen.BuildFailed, finishTime)
 					if err != nil {
 						evergreen.Logger.Errorf(slogger.ERROR, "Error marking build as finished: %v", err)
 						return err
@@ -1271,13 +1381,13 @@ func (t *Task) UpdateBuildStatus() error {
 		if !failedTask {
 			if pushTaskExists { // this build has a push task associated with it.
 				if pushCompleted && pushSuccess { // the push succeeded, so mark the build as succeeded.
-					err = b.MarkFinished(evergreen.BuildSucceeded, finishTime)
+					err = markBuildFinished(b, evergreen.BuildSucceeded, finishTime)
 					if err != nil {
 						evergreen.Logger.Errorf(slogger.ERROR, "Error marking build as finished: %v", err)
 						return err
 					}
 				} else if pushCompleted && !pushSuccess { // the push failed, mark build failed.
-					err = b.MarkFinished(evergreen.BuildFailed, finishTime)
+					err = markBuildFinished(b, evergreen.BuildFailed, finishTime)
 					if err != nil {
 						evergreen.Logger.Errorf(slogger.ERROR, "Error marking build as finished: %v", err)
 						return err
@@ -1291,7 +1401,7 @@ func (t *Task) UpdateBuildStatus() error {
 					return err
 				}
 			} else { // this build has no push task. so go ahead and mark it success/failure.
-				if err = b.MarkFinished(evergreen.BuildSucceeded, finishTime); err != nil {
+				if err = markBuildFinished(b, evergreen.BuildSucceeded, finishTime); err != nil {
 					evergreen.Logger.Errorf(slogger.ERROR, "Error marking build as finished: %v", err)
 					return err
 				}
@@ -1308,7 +1418,7 @@ func (t *Task) UpdateBuildStatus() error {
 			}
 		} else {
 			// some task failed
-			if err = b.MarkFinished(evergreen.BuildFailed, finishTime); err != nil {
+			if err = markBuildFinished(b, evergreen.BuildFailed, finishTime); err != nil {
 				evergreen.Logger.Errorf(slogger.ERROR, "Error marking build as finished: %v", err)
 				return err
 			}
@@ -189,7 +189,9 @@ func FindTaskLogsBeforeTime(taskId string, execution int, ts time.Time, limit in
 	return result, err
 }
 
-func GetRawTaskLogChannel(taskId string, execution int, severities []string,
+// GetRawTaskLogChannel streams the task's log messages from the database,
+// oldest first.
+func (MongoLogStore) GetRawTaskLogChannel(taskId string, execution int, severities []string,
 	msgTypes []string) (chan LogMessage, error) {
 	session, db, err := getSessionAndDB()
 	if err != nil {
@@ -219,18 +221,6 @@ func GetRawTaskLogChannel(taskId string, execution int, severities []string,
 	}
 	iter := db.C(TaskLogCollection).Find(query).Sort(TaskLogTimestampKey).Iter()
 
-	oldMsgTypes := []string{}
-	for _, msgType := range msgTypes {
-		switch msgType {
-		case SystemLogPrefix:
-			oldMsgTypes = append(oldMsgTypes, "system")
-		case AgentLogPrefix:
-			oldMsgTypes = append(oldMsgTypes, "agent")
-		case TaskLogPrefix:
-			oldMsgTypes = append(oldMsgTypes, "task")
-		}
-	}
-
 	go func() {
 		defer session.Close()
 		defer close(channel)
@@ -238,16 +228,9 @@ func GetRawTaskLogChannel(taskId string, execution int, severities []string,
 
 		for iter.Next(&logObj) {
 			for _, logMsg := range logObj.Messages {
-				if len(severities) > 0 &&
-					!util.SliceContains(severities, logMsg.Severity) {
+				if !logMsg.MatchesFilter(severities, msgTypes) {
 					continue
 				}
-				if len(msgTypes) > 0 {
-					if !(util.SliceContains(msgTypes, logMsg.Type) ||
-						util.SliceContains(oldMsgTypes, logMsg.Type)) {
-						continue
-					}
-				}
 				channel <- logMsg
 			}
 		}
@@ -260,6 +243,37 @@ func GetRawTaskLogChannel(taskId string, execution int, severities []string,
 Functions that operate on individual log messages
 ******************************************************/
 
+// MatchesFilter returns true if the message has one of the severities and one
+// of the types, which may be given in either their current or older form.
+// Empty slices match everything.
+func (self *LogMessage) MatchesFilter(severities []string, msgTypes []string) bool {
+	if len(severities) != 0 && !util.SliceContains(severities, self.Severity) {
+		return false
+	}
+	if len(msgTypes) == 0 {
+		return true
+	}
+	for _, msgType := range msgTypes {
+		if self.Type == msgType || self.Type == oldLogType(msgType) {
+			return true
+		}
+	}
+	return false
+}
+
+// oldLogType returns the name older log messages use for the type prefix.
+func oldLogType(msgType string) string {
+	switch msgType {
+	case SystemLogPrefix:
+		return "system"
+	case AgentLogPrefix:
+		return "agent"
+	case TaskLogPrefix:
+		return "task"
+	}
+	return ""
+}
+
 func (self *LogMessage) Insert(taskId string, execution int) error {
 	// get the most recent task log document
 	mostRecent, err := FindMostRecentTaskLogs(taskId, execution, 1)
@@ -286,25 +300,15 @@ func (self *LogMessage) Insert(taskId string, execution int) error {
 
 }
 
+// FindMostRecentLogMessages returns the task's most recent log messages from
+// the database, newest first.
 // note: to ignore severity or type filtering, pass in empty slices
-func FindMostRecentLogMessages(taskId string, execution int, numMsgs int,
+func (MongoLogStore) FindMostRecentLogMessages(taskId string, execution int, numMsgs int,
 	severities []string, msgTypes []string) ([]LogMessage, error) {
 	logMsgs := []LogMessage{}
 	numMsgsNeeded := numMsgs
 	lastTimeStamp := time.Date(2020, 0, 0, 0, 0, 0, 0, time.UTC)
 
-	oldMsgTypes := []string{}
-	for _, msgType := range msgTypes {
-		switch msgType {
-		case SystemLogPrefix:
-			oldMsgTypes = append(oldMsgTypes, "system")
-		case AgentLogPrefix:
-			oldMsgTypes = append(oldMsgTypes, "agent")
-		case TaskLogPrefix:
-			oldMsgTypes = append(oldMsgTypes, "task")
-		}
-	}
-
 	// keep grabbing task logs from farther back until there are enough messages
 	for numMsgsNeeded != 0 {
 		numTaskLogsToFetch := numMsgsNeeded / MessagesPerLog
@@ -328,16 +332,9 @@ func FindMostRecentLogMessages(taskId string, execution int, numMsgs int,
 			}
 			for _, logMsg := range messages {
 				// filter by severity and type
-				if len(severities) != 0 &&
-					!util.SliceContains(severities, logMsg.Severity) {
+				if !logMsg.MatchesFilter(severities, msgTypes) {
 					continue
 				}
-				if len(msgTypes) != 0 {
-					if !(util.SliceContains(msgTypes, logMsg.Type) ||
-						util.SliceContains(oldMsgTypes, logMsg.Type)) {
-						continue
-					}
-				}
 				// the message is relevant, store it
 				logMsgs = append(logMsgs, logMsg)
 				numMsgsNeeded--
@@ -19,9 +19,27 @@ const (
 
 // represents the next n tasks to be run on hosts of the distro
 type TaskQueue struct {
-	Id     bson.ObjectId   `bson:"_id,omitempty" json:"_id"`
-	Distro string          `bson:"distro" json:"distro"`
-	Queue  []TaskQueueItem `bson:"queue" json:"queue"`
+	Id        bson.ObjectId      `bson:"_id,omitempty" json:"_id"`
+	Distro    string             `bson:"distro" json:"distro"`
+	Queue     []TaskQueueItem    `bson:"queue" json:"queue"`
+	Overrides TaskQueueOverrides `bson:"overrides" json:"overrides"`
+}
+
+// TaskQueueOverrides are manual changes to a distro's queue. The scheduler
+// applies them on top of its own ordering each time it rebuilds the queue,
+// until the tasks they refer to are no longer waiting to run.
+type TaskQueueOverrides struct {
+	// tasks to run before any others, in this order
+	Pinned []string `bson:"pinned,omitempty" json:"pinned,omitempty"`
+	// tasks moved within the part of the queue belonging to their project
+	Moved []TaskQueueMove `bson:"moved,omitempty" json:"moved,omitempty"`
+}
+
+// TaskQueueMove places a task at a (0-based) position among the queued tasks
+// of its project.
+type TaskQueueMove struct {
+	TaskId   string `bson:"task_id" json:"task_id"`
+	Position int    `bson:"position" json:"position"`
 }
 
 type TaskQueueItem struct {
@@ -37,9 +55,15 @@ type TaskQueueItem struct {
 
 var (
 	// bson fields for the task queue struct
-	TaskQueueIdKey     = bsonutil.MustHaveTag(TaskQueue{}, "Id")
-	TaskQueueDistroKey = bsonutil.MustHaveTag(TaskQueue{}, "Distro")
-	TaskQueueQueueKey  = bsonutil.MustHaveTag(TaskQueue{}, "Queue")
+	TaskQueueIdKey        = bsonutil.MustHaveTag(TaskQueue{}, "Id")
+	TaskQueueDistroKey    = bsonutil.MustHaveTag(TaskQueue{}, "Distro")
+	TaskQueueQueueKey     = bsonutil.MustHaveTag(TaskQueue{}, "Queue")
+	TaskQueueOverridesKey = bsonutil.MustHaveTag(TaskQueue{}, "Overrides")
+
+	// bson fields for the task queue overrides
+	TaskQueueOverridesPinnedKey = bsonutil.MustHaveTag(TaskQueueOverrides{}, "Pinned")
+	TaskQueueOverridesMovedKey  = bsonutil.MustHaveTag(TaskQueueOverrides{}, "Moved")
+	TaskQueueMoveTaskIdKey      = bsonutil.MustHaveTag(TaskQueueMove{}, "TaskId")
 
 	// bson fields for the individual task queue items
 	TaskQueueItemIdKey          = bsonutil.MustHaveTag(TaskQueueItem{}, "Id")
@@ -154,3 +178,193 @@ func (self *TaskQueue) DequeueTask(taskId string) error {
 		},
 	)
 }
+
+// ExpectedStartTimes estimates when each task in the queue will start, given
+// the times at which each of the distro's hosts will be free to take a task.
+// Tasks are handed, in queue order, to whichever host is free first, and keep
+// it busy for their expected duration. With no hosts, nothing can be estimated
+// and zero times are returned.
+func (self *TaskQueue) ExpectedStartTimes(hostFreeTimes []time.Time) []time.Time {
+	startTimes := make([]time.Time, len(self.Queue))
+	if len(hostFreeTimes) == 0 {
+		return startTimes
+	}
+	freeTimes := make([]time.Time, len(hostFreeTimes))
+	copy(freeTimes, hostFreeTimes)
+
+	for idx, queueItem := range self.Queue {
+		next := 0
+		for hostIdx, freeTime := range freeTimes {
+			if freeTime.Before(freeTimes[next]) {
+				next = hostIdx
+			}
+		}
+		startTimes[idx] = freeTimes[next]
+		freeTimes[next] = freeTimes[next].Add(queueItem.ExpectedDuration)
+	}
+	return startTimes
+}
+
+// IsEmpty returns true if there are no overrides.
+func (self *TaskQueueOverrides) IsEmpty() bool {
+	return len(self.Pinned) == 0 && len(self.Moved) == 0
+}
+
+// clear drops any override for the task.
+func (self *TaskQueueOverrides) clear(taskId string) {
+	pinned := []string{}
+	for _, id := range self.Pinned {
+		if id != taskId {
+			pinned = append(pinned, id)
+		}
+	}
+	moved := []TaskQueueMove{}
+	for _, move := range self.Moved {
+		if move.TaskId != taskId {
+			moved = append(moved, move)
+		}
+	}
+	self.Pinned, self.Moved = pinned, moved
+}
+
+// Pin puts the task at the front of the queue, behind any tasks pinned
+// before it.
+func (self *TaskQueueOverrides) Pin(taskId string) {
+	self.clear(taskId)
+	self.Pinned = append(self.Pinned, taskId)
+}
+
+// Move places the task at the position among its project's queued tasks.
+func (self *TaskQueueOverrides) Move(taskId string, position int) {
+	self.clear(taskId)
+	self.Moved = append(self.Moved, TaskQueueMove{TaskId: taskId, Position: position})
+}
+
+// Order applies the overrides to a prioritized queue, given as the ids of its
+// tasks and the projects they belong to. It returns the indexes of the tasks
+// in their new order, along with the ids of any
+// overridden tasks that are no longer in the queue.
+func (self *TaskQueueOverrides) Order(ids, projects []string) ([]int, []string) {
+	indexes := make(map[string]int, len(ids))
+	for idx, id := range ids {
+		indexes[id] = idx
+	}
+	stale := []string{}
+	order := make([]int, 0, len(ids))
+	for idx := range ids {
+		order = append(order, idx)
+	}
+
+	// moves only shuffle the slots held by the task's project, leaving other
+	// projects' tasks where they are
+	for _, move := range self.Moved {
+		moved, ok := indexes[move.TaskId]
+		if !ok {
+			stale = append(stale, move.TaskId)
+			continue
+		}
+		slots := []int{}
+		members := []int{}
+		for pos, idx := range order {
+			if projects[idx] != projects[moved] {
+				continue
+			}
+			slots = append(slots, pos)
+			if idx != moved {
+				members = append(members, idx)
+			}
+		}
+		position := move.Position
+		if position < 0 {
+			position = 0
+		}
+		if position > len(members) {
+			position = len(members)
+		}
+		members = append(members[:position], append([]int{moved}, members[position:]...)...)
+		for i, pos := range slots {
+			order[pos] = members[i]
+		}
+	}
+
+	pinned := []int{}
+	isPinned := map[int]bool{}
+	for _, id := range self.Pinned {
+		idx, ok := indexes[id]
+		if !ok {
+			stale = append(stale, id)
+			continue
+		}
+		if isPinned[idx] {
+			continue
+		}
+		pinned = append(pinned, idx)
+		isPinned[idx] = true
+	}
+	if len(pinned) == 0 {
+		return order, stale
+	}
+	for _, idx := range order {
+		if !isPinned[idx] {
+			pinned = append(pinned, idx)
+		}
+	}
+	return pinned, stale
+}
+
+// SetTaskQueueOverrides saves the overrides for the distro's queue.
+func SetTaskQueueOverrides(distro string, overrides TaskQueueOverrides) error {
+	_, err := db.Upsert(
+		TaskQueuesCollection,
+		bson.M{
+			TaskQueueDistroKey: distro,
+		},
+		bson.M{
+			"$set": bson.M{
+				TaskQueueOverridesKey: overrides,
+			},
+		},
+	)
+	return err
+}
+
+// PruneTaskQueueOverrides drops the overrides for the given tasks from the
+// distro's queue.
+func PruneTaskQueueOverrides(distro string, taskIds []string) error {
+	return db.Update(
+		TaskQueuesCollection,
+		bson.M{
+			TaskQueueDistroKey: distro,
+		},
+		bson.M{
+			"$pull": bson.M{
+				fmt.Sprintf("%v.%v", TaskQueueOverridesKey, TaskQueueOverridesPinnedKey): bson.M{
+					"$in": taskIds,
+				},
+				fmt.Sprintf("%v.%v", TaskQueueOverridesKey, TaskQueueOverridesMovedKey): bson.M{
+					TaskQueueMoveTaskIdKey: bson.M{"$in": taskIds},
+				},
+			},
+		},
+	)
+}
+
+// RemoveTaskFromQueue takes the task out of the distro's queue by
+// unscheduling it, so that the scheduler doesn't queue it again, and drops
+// any overrides for it. Scheduling the task again puts it back in the queue.
+func RemoveTaskFromQueue(distro, taskId, caller string) error {
+	if err := SetTaskActivated(taskId, caller, false); err != nil {
+		return fmt.Errorf("error unscheduling task %v: %v", taskId, err)
+	}
+	if err := PruneTaskQueueOverrides(distro, []string{taskId}); err != nil {
+		return fmt.Errorf("error dropping queue overrides for task %v: %v", taskId, err)
+	}
+	taskQueue, err := FindTaskQueueForDistro(distro)
+	if err != nil {
+		return err
+	}
+	if taskQueue == nil {
+		return nil
+	}
+	return taskQueue.DequeueTask(taskId)
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/evergreen-ci/evergreen/db"
 	. "github.com/smartystreets/goconvey/convey"
 	"testing"
+	"time"
 )
 
 var (
@@ -74,3 +75,128 @@ func TestDequeueTask(t *testing.T) {
 
 	})
 }
+
+func TestTaskQueueOverridesOrder(t *testing.T) {
+	Convey("With a prioritized queue holding tasks from two projects", t, func() {
+		ids := []string{"a1", "b1", "a2", "b2", "a3"}
+		projects := []string{"a", "b", "a", "b", "a"}
+		overrides := &TaskQueueOverrides{}
+
+		orderedIds := func() []string {
+			order, _ := overrides.Order(ids, projects)
+			ordered := []string{}
+			for _, idx := range order {
+				ordered = append(ordered, ids[idx])
+			}
+			return ordered
+		}
+
+		Convey("no overrides should leave the order alone", func() {
+			So(orderedIds(), ShouldResemble, ids)
+		})
+
+		Convey("pinned tasks should go to the front in the order pinned", func() {
+			overrides.Pin("a3")
+			overrides.Pin("b2")
+			So(orderedIds(), ShouldResemble, []string{"a3", "b2", "a1", "b1", "a2"})
+		})
+
+		Convey("moved tasks should only trade places with their project's tasks", func() {
+			overrides.Move("a3", 0)
+			So(orderedIds(), ShouldResemble, []string{"a3", "b1", "a1", "b2", "a2"})
+
+			overrides.Move("a3", 10)
+			So(orderedIds(), ShouldResemble, ids)
+		})
+
+		Convey("a new override should replace the task's previous one", func() {
+			overrides.Move("a2", 2)
+			overrides.Pin("a2")
+			So(overrides.Moved, ShouldBeEmpty)
+			So(orderedIds(), ShouldResemble, []string{"a2", "a1", "b1", "b2", "a3"})
+		})
+
+		Convey("overrides for tasks no longer queued should be reported", func() {
+			overrides.Pin("gone1")
+			overrides.Pin("gone2")
+			overrides.Move("gone3", 1)
+			order, stale := overrides.Order(ids, projects)
+			So(len(order), ShouldEqual, len(ids))
+			So(stale, ShouldContain, "gone1")
+			So(stale, ShouldContain, "gone2")
+			So(stale, ShouldContain, "gone3")
+		})
+	})
+}
+
+func TestRemoveTaskFromQueue(t *testing.T) {
+	Convey("With a queue holding a task that has been pinned", t, func() {
+		So(db.ClearCollections(TaskQueuesCollection, TasksCollection), ShouldBeNil)
+		for _, id := range []string{"t1", "t2"} {
+			task := &Task{Id: id, DistroId: "d1", Activated: true, Status: evergreen.TaskUndispatched,
+				DispatchTime: ZeroTime}
+			So(task.Insert(), ShouldBeNil)
+		}
+		taskQueue := &TaskQueue{
+			Distro: "d1",
+			Queue:  []TaskQueueItem{{Id: "t1"}, {Id: "t2"}},
+		}
+		So(taskQueue.Save(), ShouldBeNil)
+		taskQueue.Overrides.Pin("t2")
+		So(SetTaskQueueOverrides("d1", taskQueue.Overrides), ShouldBeNil)
+
+		Convey("removing the task should unschedule it and take it out of the queue", func() {
+			So(RemoveTaskFromQueue("d1", "t2", "me"), ShouldBeNil)
+
+			taskQueue, err := FindTaskQueueForDistro("d1")
+			So(err, ShouldBeNil)
+			So(len(taskQueue.Queue), ShouldEqual, 1)
+			So(taskQueue.Queue[0].Id, ShouldEqual, "t1")
+			So(taskQueue.Overrides.IsEmpty(), ShouldBeTrue)
+
+			undispatched, err := FindUndispatchedTasks()
+			So(err, ShouldBeNil)
+			So(len(undispatched), ShouldEqual, 1)
+			So(undispatched[0].Id, ShouldEqual, "t1")
+
+			Convey("and scheduling it again should make it runnable again", func() {
+				So(SetTaskActivated("t2", "me", true), ShouldBeNil)
+				undispatched, err := FindUndispatchedTasks()
+				So(err, ShouldBeNil)
+				So(len(undispatched), ShouldEqual, 2)
+
+				// nothing keeps it out of the queue the scheduler builds
+				order, stale := taskQueue.Overrides.Order([]string{"t1", "t2"}, []string{"p", "p"})
+				So(order, ShouldResemble, []int{0, 1})
+				So(stale, ShouldBeEmpty)
+			})
+		})
+	})
+}
+
+func TestExpectedStartTimes(t *testing.T) {
+	Convey("With a queue of tasks with known durations", t, func() {
+		now := time.Now()
+		taskQueue := &TaskQueue{
+			Queue: []TaskQueueItem{
+				{Id: "t1", ExpectedDuration: time.Hour},
+				{Id: "t2", ExpectedDuration: 30 * time.Minute},
+				{Id: "t3", ExpectedDuration: time.Minute},
+			},
+		}
+
+		Convey("with no hosts, no start times should be estimated", func() {
+			for _, startTime := range taskQueue.ExpectedStartTimes(nil) {
+				So(startTime.IsZero(), ShouldBeTrue)
+			}
+		})
+
+		Convey("tasks should be handed to whichever host is free first", func() {
+			startTimes := taskQueue.ExpectedStartTimes(
+				[]time.Time{now, now.Add(10 * time.Minute)})
+			So(startTimes[0], ShouldResemble, now)
+			So(startTimes[1], ShouldResemble, now.Add(10*time.Minute))
+			So(startTimes[2], ShouldResemble, now.Add(40*time.Minute))
+		})
+	})
+}
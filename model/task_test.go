@@ -2,6 +2,7 @@ package model
 
 import (
 	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/apimodels"
 	"github.com/evergreen-ci/evergreen/db"
 	"github.com/evergreen-ci/evergreen/model/build"
 	"github.com/evergreen-ci/evergreen/model/host"
@@ -607,6 +608,76 @@ func TestMarkAsDispatched(t *testing.T) {
 
 }
 
+func TestTryRetrySystemFailure(t *testing.T) {
+	Convey("With a task that is running on a host", t, func() {
+		testutil.HandleTestingErr(
+			db.ClearCollections(TasksCollection, OldTasksCollection, build.Collection),
+			t, "Error clearing test collections")
+
+		task := &Task{
+			Id:        "t1",
+			BuildId:   "b1",
+			HostId:    "h1",
+			Status:    evergreen.TaskStarted,
+			Activated: true,
+		}
+		b := &build.Build{
+			Id:    "b1",
+			Tasks: []build.TaskCache{{Id: "t1"}},
+		}
+		So(task.Insert(), ShouldBeNil)
+		So(b.Insert(), ShouldBeNil)
+
+		systemFailure := &apimodels.TaskEndDetail{
+			Status: evergreen.TaskFailed,
+			Type:   SystemCommandType,
+		}
+
+		Convey("a system failure should reset the task and exclude the host", func() {
+			retried, err := task.TryRetrySystemFailure("test", systemFailure, 1)
+			So(err, ShouldBeNil)
+			So(retried, ShouldBeTrue)
+
+			dbTask, err := FindTask("t1")
+			So(err, ShouldBeNil)
+			So(dbTask.Status, ShouldEqual, evergreen.TaskUndispatched)
+			So(dbTask.Execution, ShouldEqual, 1)
+			So(dbTask.SystemFailureRetries, ShouldEqual, 1)
+			So(dbTask.IsExcludedHost("h1"), ShouldBeTrue)
+
+			oldTask, err := FindOneOldTask(bson.M{TaskIdKey: "t1_0"}, db.NoProjection, db.NoSort)
+			So(err, ShouldBeNil)
+			So(oldTask.IsSystemFailure(), ShouldBeTrue)
+
+			Convey("but not once the project's retries are used up", func() {
+				retried, err := dbTask.TryRetrySystemFailure("test", systemFailure, 1)
+				So(err, ShouldBeNil)
+				So(retried, ShouldBeFalse)
+			})
+		})
+
+		Convey("a test failure should not be retried", func() {
+			testFailure := &apimodels.TaskEndDetail{
+				Status: evergreen.TaskFailed,
+				Type:   DefaultCommandType,
+			}
+			retried, err := task.TryRetrySystemFailure("test", testFailure, 1)
+			So(err, ShouldBeNil)
+			So(retried, ShouldBeFalse)
+
+			dbTask, err := FindTask("t1")
+			So(err, ShouldBeNil)
+			So(dbTask.Status, ShouldEqual, evergreen.TaskStarted)
+		})
+
+		Convey("a project without a retry policy should not retry", func() {
+			retried, err := task.TryRetrySystemFailure("test", systemFailure, 0)
+			So(err, ShouldBeNil)
+			So(retried, ShouldBeFalse)
+		})
+	})
+}
+
 func TestTimeAggregations(t *testing.T) {
 	Convey("With multiple tasks with different times", t, func() {
 		So(db.Clear(TasksCollection), ShouldBeNil)
@@ -16,6 +16,8 @@ type TestLog struct {
 	Task          string   `json:"task" bson:"task"`
 	TaskExecution int      `json:"execution" bson:"execution"`
 	Lines         []string `json:"lines" bson:"lines"`
+	// BlobKey locates the log's lines when they are kept outside the database
+	BlobKey string `json:"-" bson:"blob_key,omitempty"`
 }
 
 var (
@@ -24,9 +26,11 @@ var (
 	TestLogTaskKey          = bsonutil.MustHaveTag(TestLog{}, "Task")
 	TestLogTaskExecutionKey = bsonutil.MustHaveTag(TestLog{}, "TaskExecution")
 	TestLogLinesKey         = bsonutil.MustHaveTag(TestLog{}, "Lines")
+	TestLogBlobKeyKey       = bsonutil.MustHaveTag(TestLog{}, "BlobKey")
 )
 
-func FindOneTestLogById(id string) (*TestLog, error) {
+// FindOneTestLogById returns the TestLog with the given id from the database.
+func (MongoLogStore) FindOneTestLogById(id string) (*TestLog, error) {
 	tl := &TestLog{}
 	err := db.FindOne(
 		TestLogCollection,
@@ -45,7 +49,7 @@ func FindOneTestLogById(id string) (*TestLog, error) {
 
 // FindOneTestLog returns a TestLog, given the test's name, task id,
 // and execution.
-func FindOneTestLog(name, task string, execution int) (*TestLog, error) {
+func (MongoLogStore) FindOneTestLog(name, task string, execution int) (*TestLog, error) {
 	tl := &TestLog{}
 	err := db.FindOne(
 		TestLogCollection,
@@ -31,7 +31,8 @@ var (
 )
 
 var (
-	SettingsTZKey = bsonutil.MustHaveTag(UserSettings{}, "Timezone")
+	SettingsTZKey         = bsonutil.MustHaveTag(UserSettings{}, "Timezone")
+	SettingsChatHandleKey = bsonutil.MustHaveTag(UserSettings{}, "ChatHandle")
 )
 
 func ById(userId string) db.Q {
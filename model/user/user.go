@@ -29,6 +29,8 @@ type PubKey struct {
 
 type UserSettings struct {
 	Timezone string `json:"timezone" bson:"timezone"`
+	// ChatHandle is the user's handle for direct chat messages about their patches
+	ChatHandle string `json:"chat_handle" bson:"chat_handle,omitempty"`
 }
 
 func (u *DBUser) Username() string {
@@ -49,6 +49,26 @@ func monitorReachability(settings *evergreen.Settings) []error {
 
 	}
 
+	// hosts running tasks aren't checked for reachability, since their tasks'
+	// heartbeats show if they're up, but hosts that their provider can take
+	// back are checked for interruptions, so that their tasks are requeued
+	busyHosts, err := host.Find(host.ByRunningTaskNotMonitoredSince(threshold))
+	if err != nil {
+		errors = append(errors, fmt.Errorf("error finding hosts running tasks"+
+			" not monitored recently: %v", err))
+		return errors
+	}
+
+	for _, host := range busyHosts {
+
+		if err := checkHostInterruption(host, settings); err != nil {
+			errors = append(errors, fmt.Errorf("error checking interruption"+
+				" for host %v: %v", host.Id, err))
+			continue
+		}
+
+	}
+
 	evergreen.Logger.Logf(slogger.INFO, "Finished running host reachability checks")
 
 	return errors
@@ -107,8 +127,9 @@ func checkHostReachability(host host.Host,
 		evergreen.Logger.Logf(slogger.INFO, "Host %v terminated externally; updating"+
 			" db status to terminated", host.Id)
 
-		// the instance was terminated from outside our control
-		if err := host.SetTerminated(); err != nil {
+		// the instance was terminated from outside our control. any task it
+		// was running is requeued by the task monitor
+		if err := host.Terminate(); err != nil {
 			return fmt.Errorf("error setting host %v terminated: %v",
 				host.Id, err)
 		}
@@ -119,3 +140,49 @@ func checkHostReachability(host host.Host,
 	return nil
 
 }
+
+// check whether a host running a task was interrupted by its provider, and
+// terminate it if so. the task monitor then requeues the host's task
+func checkHostInterruption(host host.Host, settings *evergreen.Settings) error {
+
+	cloudHost, err := providers.GetCloudHost(&host, settings)
+	if err != nil {
+		return fmt.Errorf("error getting cloud host for host %v: %v",
+			host.Id, err)
+	}
+
+	interruptible, ok := cloudHost.CloudMgr.(cloud.InterruptibleManager)
+	if !ok {
+		return nil
+	}
+
+	evergreen.Logger.Logf(slogger.INFO, "Running interruption check for host %v...",
+		host.Id)
+
+	interrupted, err := interruptible.IsInterrupted(&host)
+	if err != nil {
+		return fmt.Errorf("error checking interruption of host %v: %v",
+			host.Id, err)
+	}
+	if !interrupted {
+		return host.SetReachabilityChecked()
+	}
+
+	evergreen.Logger.Logf(slogger.INFO, "Host %v running task %v was interrupted"+
+		" by its provider; terminating", host.Id, host.RunningTask)
+
+	// don't wait for the provider to take the instance back
+	if err := cloudHost.TerminateInstance(); err != nil {
+		return fmt.Errorf("error terminating host %v: %v", host.Id, err)
+	}
+
+	// not every provider marks the host as terminated
+	if host.Status != evergreen.HostTerminated {
+		if err := host.Terminate(); err != nil {
+			return fmt.Errorf("error setting host %v terminated: %v",
+				host.Id, err)
+		}
+	}
+
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/evergreen-ci/evergreen"
 	"github.com/evergreen-ci/evergreen/cloud/providers/mock"
 	"github.com/evergreen-ci/evergreen/db"
+	"github.com/evergreen-ci/evergreen/model"
 	"github.com/evergreen-ci/evergreen/model/host"
 	"github.com/evergreen-ci/evergreen/testutil"
 	. "github.com/smartystreets/goconvey/convey"
@@ -20,8 +21,8 @@ func TestMonitorReachability(t *testing.T) {
 	Convey("When checking the reachability of hosts", t, func() {
 
 		// reset the db
-		testutil.HandleTestingErr(db.ClearCollections(host.Collection),
-			t, "error clearing hosts collection")
+		testutil.HandleTestingErr(db.ClearCollections(host.Collection, model.TasksCollection),
+			t, "error clearing collections")
 
 		Convey("hosts that have been checked up on recently should"+
 			" not be checked", func() {
@@ -81,6 +82,60 @@ func TestMonitorReachability(t *testing.T) {
 
 		})
 
+		Convey("hosts running tasks should be terminated if their provider"+
+			" interrupted them", func() {
+
+			mock.InterruptedHosts = map[string]bool{"h1": true}
+			defer func() { mock.InterruptedHosts = map[string]bool{} }()
+
+			// this spot host was taken back while running a task
+			host1 := &host.Host{
+				Id: "h1",
+				LastReachabilityCheck: time.Now().Add(-15 * time.Minute),
+				Status:                evergreen.HostRunning,
+				StartedBy:             evergreen.User,
+				RunningTask:           "t1",
+				Provider:              mock.ProviderName,
+			}
+			testutil.HandleTestingErr(host1.Insert(), t, "error inserting host")
+
+			// this one is still running its task
+			host2 := &host.Host{
+				Id: "h2",
+				LastReachabilityCheck: time.Now().Add(-15 * time.Minute),
+				Status:                evergreen.HostRunning,
+				StartedBy:             evergreen.User,
+				RunningTask:           "t2",
+				Provider:              mock.ProviderName,
+			}
+			testutil.HandleTestingErr(host2.Insert(), t, "error inserting host")
+
+			task1 := &model.Task{Id: "t1", HostId: "h1", Status: evergreen.TaskStarted}
+			testutil.HandleTestingErr(task1.Insert(), t, "error inserting task")
+
+			So(monitorReachability(nil), ShouldBeNil)
+
+			// the interrupted host should be terminated, keeping its task
+			host1, err := host.FindOne(host.ById("h1"))
+			So(err, ShouldBeNil)
+			So(host1.Status, ShouldEqual, evergreen.HostTerminated)
+			So(host1.RunningTask, ShouldEqual, "t1")
+
+			// the other should only have been checked on
+			host2, err = host.FindOne(host.ById("h2"))
+			So(err, ShouldBeNil)
+			So(host2.Status, ShouldEqual, evergreen.HostRunning)
+			So(host2.LastReachabilityCheck, ShouldHappenAfter, time.Now().Add(-time.Minute))
+
+			// and the interrupted host's task should be flagged for requeueing
+			doomed, err := flagTasksOnTerminatedHosts()
+			So(err, ShouldBeNil)
+			So(len(doomed), ShouldEqual, 1)
+			So(doomed[0].task.Id, ShouldEqual, "t1")
+			So(doomed[0].reason, ShouldEqual, HostTerminated)
+
+		})
+
 	})
 
 }
@@ -0,0 +1,134 @@
+package monitor
+
+import (
+	"fmt"
+	"github.com/10gen-labs/slogger/v1"
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/alerts"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/event"
+	"github.com/evergreen-ci/evergreen/model/host"
+	"time"
+)
+
+// quarantineFailingHosts is a hostMonitoringFunc responsible for quarantining
+// hosts whose most recent tasks all failed because of system failures, so
+// that one bad host doesn't fail every task it is given. returns a slice of
+// any errors that occur
+func quarantineFailingHosts(settings *evergreen.Settings) []error {
+	threshold := settings.Monitor.QuarantineThreshold
+	if threshold <= 0 {
+		return nil
+	}
+
+	evergreen.Logger.Logf(slogger.INFO, "Checking hosts for repeated system failures...")
+
+	// used to store any errors that occur
+	var errors []error
+
+	hosts, err := host.Find(host.IsLive)
+	if err != nil {
+		errors = append(errors, fmt.Errorf("error finding live hosts: %v", err))
+		return errors
+	}
+
+	// continue on error so that other hosts can be checked
+	for _, h := range hosts {
+		if h.Status != evergreen.HostRunning {
+			continue
+		}
+		if err := checkHostSystemFailures(&h, threshold); err != nil {
+			errors = append(errors, fmt.Errorf("error checking system failures"+
+				" for host %v: %v", h.Id, err))
+		}
+	}
+
+	evergreen.Logger.Logf(slogger.INFO, "Finished checking hosts for repeated system failures")
+
+	return errors
+}
+
+// checkHostSystemFailures quarantines the host if the last threshold tasks
+// that finished on it and attempts to provision it all failed, the tasks with
+// system failures. Failures from before the host was last quarantined are
+// ignored, so that a host an admin puts back into service gets a clean slate.
+func checkHostSystemFailures(h *host.Host, threshold int) error {
+	tasks, err := model.FindLastTasksOnHost(h.Id, h.QuarantineTime, threshold)
+	if err != nil {
+		return fmt.Errorf("error finding tasks: %v", err)
+	}
+	provisionFailures, err := event.Find(event.RecentHostProvisionFailures(h.Id,
+		h.QuarantineTime, threshold))
+	if err != nil {
+		return fmt.Errorf("error finding provisioning failures: %v", err)
+	}
+	if len(tasks)+len(provisionFailures) < threshold {
+		return nil
+	}
+
+	// walk back through the most recent outcomes, both lists being sorted
+	// most recent first
+	lastTaskId := ""
+	for i := 0; i < threshold; i++ {
+		if len(provisionFailures) > 0 &&
+			(len(tasks) == 0 || provisionFailures[0].Timestamp.After(tasks[0].FinishTime)) {
+			provisionFailures = provisionFailures[1:]
+			continue
+		}
+		task := tasks[0]
+		tasks = tasks[1:]
+		if !task.IsSystemFailure() {
+			return nil
+		}
+		if lastTaskId == "" {
+			lastTaskId = task.Id
+			if task.Archived {
+				lastTaskId = task.OldTaskId
+			}
+		}
+	}
+
+	evergreen.Logger.Logf(slogger.WARN, "Quarantining host %v after %v consecutive"+
+		" system or provisioning failures (most recent failed task: '%v')",
+		h.Id, threshold, lastTaskId)
+
+	if err := h.QuarantineForSystemFailures(lastTaskId); err != nil {
+		return fmt.Errorf("error quarantining host: %v", err)
+	}
+	if err := alerts.RunHostQuarantinedTriggers(h); err != nil {
+		evergreen.Logger.Logf(slogger.ERROR, "Error queueing alert for quarantined"+
+			" host %v: %v", h.Id, err)
+	}
+	return nil
+}
+
+// decommissionQuarantinedHosts is a hostMonitoringFunc responsible for
+// decommissioning hosts that were quarantined for system failures longer ago
+// than the grace period, which gives admins time to inspect them. returns a
+// slice of any errors that occur
+func decommissionQuarantinedHosts(settings *evergreen.Settings) []error {
+	if settings.Monitor.QuarantineThreshold <= 0 {
+		return nil
+	}
+
+	// used to store any errors that occur
+	var errors []error
+
+	gracePeriod := time.Duration(settings.Monitor.QuarantineGraceMinutes) * time.Minute
+	hosts, err := host.Find(host.ByQuarantinedBefore(time.Now().Add(-gracePeriod)))
+	if err != nil {
+		errors = append(errors, fmt.Errorf("error finding quarantined hosts: %v", err))
+		return errors
+	}
+
+	for _, h := range hosts {
+		evergreen.Logger.Logf(slogger.INFO, "Decommissioning host %v, which was"+
+			" quarantined at %v", h.Id, h.QuarantineTime)
+		if err := h.SetDecommissioned(); err != nil {
+			errors = append(errors, fmt.Errorf("error decommissioning quarantined"+
+				" host %v: %v", h.Id, err))
+		}
+	}
+
+	return errors
+}
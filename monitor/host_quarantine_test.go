@@ -0,0 +1,155 @@
+package monitor
+
+import (
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/apimodels"
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/alert"
+	"github.com/evergreen-ci/evergreen/model/event"
+	"github.com/evergreen-ci/evergreen/model/host"
+	"github.com/evergreen-ci/evergreen/testutil"
+	. "github.com/smartystreets/goconvey/convey"
+	"gopkg.in/mgo.v2/bson"
+	"testing"
+	"time"
+)
+
+func TestQuarantineFailingHosts(t *testing.T) {
+
+	testConfig := evergreen.TestConfig()
+	testConfig.Monitor.QuarantineThreshold = 2
+
+	db.SetGlobalSessionProvider(db.SessionFactoryFromConfig(testConfig))
+
+	systemFailure := apimodels.TaskEndDetail{
+		Status: evergreen.TaskFailed,
+		Type:   model.SystemCommandType,
+	}
+	testFailure := apimodels.TaskEndDetail{
+		Status: evergreen.TaskFailed,
+		Type:   model.DefaultCommandType,
+	}
+
+	Convey("When checking hosts for repeated system failures", t, func() {
+
+		testutil.HandleTestingErr(db.ClearCollections(host.Collection,
+			model.TasksCollection, model.OldTasksCollection, alert.Collection,
+			event.Collection),
+			t, "error clearing collections")
+
+		h := &host.Host{
+			Id:        "h1",
+			Status:    evergreen.HostRunning,
+			StartedBy: evergreen.User,
+		}
+		testutil.HandleTestingErr(h.Insert(), t, "error inserting host")
+
+		now := time.Now()
+		insertTask := func(id string, finished time.Time, detail apimodels.TaskEndDetail) {
+			task := &model.Task{
+				Id:         id,
+				HostId:     "h1",
+				Status:     detail.Status,
+				Details:    detail,
+				FinishTime: finished,
+			}
+			testutil.HandleTestingErr(task.Insert(), t, "error inserting task")
+		}
+		insertProvisionFailure := func(failed time.Time) {
+			e := event.Event{
+				Timestamp:  failed,
+				ResourceId: "h1",
+				EventType:  event.EventHostProvisionFailed,
+				Data: event.DataWrapper{&event.HostEventData{
+					ResourceType: event.ResourceTypeHost,
+				}},
+			}
+			testutil.HandleTestingErr(event.NewDBEventLogger(event.Collection).LogEvent(e),
+				t, "error inserting event")
+		}
+
+		Convey("a host whose last tasks all hit system failures should"+
+			" be quarantined", func() {
+
+			insertTask("t1", now.Add(-3*time.Minute), testFailure)
+			insertTask("t2", now.Add(-2*time.Minute), systemFailure)
+			insertTask("t3", now.Add(-time.Minute), systemFailure)
+
+			So(quarantineFailingHosts(testConfig), ShouldBeNil)
+
+			h, err := host.FindOne(host.ById("h1"))
+			So(err, ShouldBeNil)
+			So(h.Status, ShouldEqual, evergreen.HostQuarantined)
+			So(h.QuarantineTime.IsZero(), ShouldBeFalse)
+
+			Convey("and decommissioned once the grace period is over", func() {
+				testConfig.Monitor.QuarantineGraceMinutes = 0
+				So(decommissionQuarantinedHosts(testConfig), ShouldBeNil)
+
+				h, err := host.FindOne(host.ById("h1"))
+				So(err, ShouldBeNil)
+				So(h.Status, ShouldEqual, evergreen.HostDecommissioned)
+			})
+		})
+
+		Convey("a host with a recent test failure should not be"+
+			" quarantined", func() {
+
+			insertTask("t1", now.Add(-3*time.Minute), systemFailure)
+			insertTask("t2", now.Add(-2*time.Minute), systemFailure)
+			insertTask("t3", now.Add(-time.Minute), testFailure)
+
+			So(quarantineFailingHosts(testConfig), ShouldBeNil)
+
+			h, err := host.FindOne(host.ById("h1"))
+			So(err, ShouldBeNil)
+			So(h.Status, ShouldEqual, evergreen.HostRunning)
+		})
+
+		Convey("provisioning failures should count towards quarantining"+
+			" the host", func() {
+
+			insertTask("t1", now.Add(-3*time.Minute), testFailure)
+			insertTask("t2", now.Add(-2*time.Minute), systemFailure)
+			insertProvisionFailure(now.Add(-time.Minute))
+
+			So(quarantineFailingHosts(testConfig), ShouldBeNil)
+
+			h, err := host.FindOne(host.ById("h1"))
+			So(err, ShouldBeNil)
+			So(h.Status, ShouldEqual, evergreen.HostQuarantined)
+		})
+
+		Convey("a host with a test failure after a provisioning failure"+
+			" should not be quarantined", func() {
+
+			insertProvisionFailure(now.Add(-3 * time.Minute))
+			insertProvisionFailure(now.Add(-2 * time.Minute))
+			insertTask("t1", now.Add(-time.Minute), testFailure)
+
+			So(quarantineFailingHosts(testConfig), ShouldBeNil)
+
+			h, err := host.FindOne(host.ById("h1"))
+			So(err, ShouldBeNil)
+			So(h.Status, ShouldEqual, evergreen.HostRunning)
+		})
+
+		Convey("failures from before the host was last quarantined should"+
+			" be ignored", func() {
+
+			insertTask("t1", now.Add(-3*time.Minute), systemFailure)
+			insertTask("t2", now.Add(-2*time.Minute), systemFailure)
+			So(host.UpdateOne(
+				bson.M{host.IdKey: "h1"},
+				bson.M{"$set": bson.M{host.QuarantineTimeKey: now.Add(-90 * time.Second)}},
+			), ShouldBeNil)
+
+			So(quarantineFailingHosts(testConfig), ShouldBeNil)
+
+			h, err := host.FindOne(host.ById("h1"))
+			So(err, ShouldBeNil)
+			So(h.Status, ShouldEqual, evergreen.HostRunning)
+		})
+	})
+}
@@ -17,6 +17,7 @@ var (
 	// to be cleaned up
 	defaultTaskFlaggingFuncs = []taskFlaggingFunc{
 		flagTimedOutHeartbeats,
+		flagTasksOnTerminatedHosts,
 	}
 
 	// the functions the host monitor will run through to find hosts needing
@@ -33,6 +34,8 @@ var (
 	// the functions the host monitor will run through to do simpler checks
 	defaultHostMonitoringFuncs = []hostMonitoringFunc{
 		monitorReachability,
+		quarantineFailingHosts,
+		decommissionQuarantinedHosts,
 	}
 
 	// the functions the notifier will use to build notifications that need
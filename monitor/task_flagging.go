@@ -5,12 +5,14 @@ import (
 	"github.com/10gen-labs/slogger/v1"
 	"github.com/evergreen-ci/evergreen"
 	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/host"
 	"time"
 )
 
 const (
 	// reasons for cleaning up a task
 	HeartbeatTimeout = "task heartbeat timed out"
+	HostTerminated   = "task host was terminated"
 )
 
 var (
@@ -58,3 +60,40 @@ func flagTimedOutHeartbeats() ([]doomedTaskWrapper, error) {
 
 	return wrappers, nil
 }
+
+// flagTasksOnTerminatedHosts is a taskFlaggingFunc to flag any tasks whose
+// host was terminated from outside Evergreen, such as an interrupted spot
+// instance, so that they're requeued without waiting for their heartbeat to
+// time out. Hosts terminated longer ago than the heartbeat timeout are left
+// to flagTimedOutHeartbeats.
+func flagTasksOnTerminatedHosts() ([]doomedTaskWrapper, error) {
+
+	evergreen.Logger.Logf(slogger.INFO, "Finding tasks on terminated hosts...")
+
+	threshold := time.Now().Add(-HeartbeatTimeoutThreshold)
+	hosts, err := host.Find(host.ByTerminatedWithRunningTaskSince(threshold))
+	if err != nil {
+		return nil, fmt.Errorf("error finding terminated hosts: %v", err)
+	}
+
+	wrappers := []doomedTaskWrapper{}
+	for _, h := range hosts {
+		task, err := model.FindTask(h.RunningTask)
+		if err != nil {
+			return nil, fmt.Errorf("error finding task %v on terminated host %v: %v",
+				h.RunningTask, h.Id, err)
+		}
+		if task == nil || task.HostId != h.Id {
+			continue
+		}
+		if task.Status != evergreen.TaskStarted && task.Status != evergreen.TaskDispatched {
+			continue
+		}
+		wrappers = append(wrappers, doomedTaskWrapper{*task, HostTerminated})
+	}
+
+	evergreen.Logger.Logf(slogger.INFO, "Found %v tasks on terminated hosts",
+		len(wrappers))
+
+	return wrappers, nil
+}
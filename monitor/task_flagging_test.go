@@ -4,6 +4,7 @@ import (
 	"github.com/evergreen-ci/evergreen"
 	"github.com/evergreen-ci/evergreen/db"
 	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/host"
 	"github.com/evergreen-ci/evergreen/testutil"
 	. "github.com/smartystreets/goconvey/convey"
 	"testing"
@@ -87,3 +88,74 @@ func TestFlaggingTimedOutHeartbeats(t *testing.T) {
 
 	})
 }
+
+func TestFlaggingTasksOnTerminatedHosts(t *testing.T) {
+
+	testConfig := evergreen.TestConfig()
+
+	db.SetGlobalSessionProvider(db.SessionFactoryFromConfig(testConfig))
+
+	Convey("When flagging tasks whose host was terminated", t, func() {
+
+		// reset the db
+		testutil.HandleTestingErr(db.ClearCollections(model.TasksCollection, host.Collection),
+			t, "error clearing collections")
+
+		task1 := &model.Task{Id: "t1", HostId: "h1", Status: evergreen.TaskStarted}
+		testutil.HandleTestingErr(task1.Insert(), t, "error inserting task")
+		task2 := &model.Task{Id: "t2", HostId: "h2", Status: evergreen.TaskSucceeded}
+		testutil.HandleTestingErr(task2.Insert(), t, "error inserting task")
+
+		Convey("running tasks on recently terminated hosts should be picked up", func() {
+
+			host1 := &host.Host{
+				Id:              "h1",
+				Status:          evergreen.HostTerminated,
+				RunningTask:     "t1",
+				TerminationTime: time.Now().Add(-time.Minute),
+			}
+			testutil.HandleTestingErr(host1.Insert(), t, "error inserting host")
+
+			flagged, err := flagTasksOnTerminatedHosts()
+			So(err, ShouldBeNil)
+			So(len(flagged), ShouldEqual, 1)
+			So(flagged[0].task.Id, ShouldEqual, "t1")
+			So(flagged[0].reason, ShouldEqual, HostTerminated)
+
+		})
+
+		Convey("hosts terminated before the heartbeat timeout should be"+
+			" ignored", func() {
+
+			host1 := &host.Host{
+				Id:              "h1",
+				Status:          evergreen.HostTerminated,
+				RunningTask:     "t1",
+				TerminationTime: time.Now().Add(-time.Hour),
+			}
+			testutil.HandleTestingErr(host1.Insert(), t, "error inserting host")
+
+			flagged, err := flagTasksOnTerminatedHosts()
+			So(err, ShouldBeNil)
+			So(len(flagged), ShouldEqual, 0)
+
+		})
+
+		Convey("tasks that already finished should be ignored", func() {
+
+			host2 := &host.Host{
+				Id:              "h2",
+				Status:          evergreen.HostTerminated,
+				RunningTask:     "t2",
+				TerminationTime: time.Now().Add(-time.Minute),
+			}
+			testutil.HandleTestingErr(host2.Insert(), t, "error inserting host")
+
+			flagged, err := flagTasksOnTerminatedHosts()
+			So(err, ShouldBeNil)
+			So(len(flagged), ShouldEqual, 0)
+
+		})
+
+	})
+}
@@ -113,6 +113,8 @@ func cleanUpTask(wrapper doomedTaskWrapper, projects map[string]model.Project) e
 	switch wrapper.reason {
 	case HeartbeatTimeout:
 		err = cleanUpTimedOutHeartbeat(wrapper.task, project, host)
+	case HostTerminated:
+		err = cleanUpTerminatedHostTask(wrapper.task, project, host)
 	default:
 		return fmt.Errorf("unknown reason for cleaning up task: %v", wrapper.reason)
 	}
@@ -133,10 +135,38 @@ func cleanUpTimedOutHeartbeat(task model.Task, project model.Project, host *host
 		TimedOut:    true,
 		Status:      evergreen.TaskFailed,
 	}
+	return resetSystemFailedTask(task, project, host, detail)
+}
+
+// clean up a task whose host was terminated out from under it
+func cleanUpTerminatedHostTask(task model.Task, project model.Project, host *host.Host) error {
+	detail := &apimodels.TaskEndDetail{
+		Description: model.HostTerminated,
+		Status:      evergreen.TaskFailed,
+	}
+	return resetSystemFailedTask(task, project, host, detail)
+}
 
-	// try to reset the task
-	if err := task.TryReset("", RunnerName, &project, detail); err != nil {
-		return fmt.Errorf("error trying to reset task %v: %v", task.Id, err)
+// resetSystemFailedTask requeues a task that failed because of its host.
+// A system failure is retried on another host if the project allows it,
+// falling back to a plain reset otherwise.
+func resetSystemFailedTask(task model.Task, project model.Project, host *host.Host,
+	detail *apimodels.TaskEndDetail) error {
+	projectRef, err := model.FindOneProjectRef(task.Project)
+	if err != nil {
+		return fmt.Errorf("error finding project ref for task %v: %v", task.Id, err)
+	}
+	retried := false
+	if projectRef != nil {
+		retried, err = task.TryRetrySystemFailure(RunnerName, detail, projectRef.SystemFailureRetries)
+		if err != nil {
+			return fmt.Errorf("error trying to retry task %v: %v", task.Id, err)
+		}
+	}
+	if !retried {
+		if err := task.TryReset("", RunnerName, &project, detail); err != nil {
+			return fmt.Errorf("error trying to reset task %v: %v", task.Id, err)
+		}
 	}
 
 	// clear out the host's running task
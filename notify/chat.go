@@ -0,0 +1,160 @@
+package notify
+
+import (
+	"fmt"
+	"github.com/10gen-labs/slogger/v1"
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/build"
+	"github.com/evergreen-ci/evergreen/thirdparty"
+	"github.com/evergreen-ci/evergreen/util"
+	"strings"
+)
+
+// number of blamelist entries and failures listed in a chat message
+const maxChatListLength = 10
+
+// ChatNotifier posts notifications to a Slack or Mattermost compatible
+// incoming webhook, as an alternative to emailing them.
+type ChatNotifier struct {
+	evergreen.ChatConfig
+	UIRoot string
+}
+
+// ConstructChatNotifier returns a notifier for the configured chat webhook, or
+// nil if chat notifications aren't configured.
+func ConstructChatNotifier(settings *evergreen.Settings) *ChatNotifier {
+	if settings.Chat.WebhookURL == "" {
+		return nil
+	}
+	return &ChatNotifier{settings.Chat, settings.Ui.Url}
+}
+
+// Send posts the notification to the channel, given as "#channel", or to the
+// user, given as "@handle".
+func (self *ChatNotifier) Send(channel string, email Email) error {
+	return thirdparty.PostChatMessage(self.WebhookURL, &thirdparty.ChatMessage{
+		Channel:     channel,
+		Username:    self.Username,
+		IconURL:     self.IconURL,
+		Text:        email.GetSubject(),
+		Attachments: []thirdparty.ChatAttachment{email.GetChatAttachment(self.UIRoot)},
+	})
+}
+
+// Helper function to post notifications to chat
+func TrySendChatNotification(channel string, email Email, chat *ChatNotifier) (err error) {
+	_, err = util.RetryArithmeticBackoff(func() error {
+		err = chat.Send(channel, email)
+		if err != nil {
+			evergreen.Logger.Errorf(slogger.ERROR, "Error posting chat notification: %v", err)
+			return util.RetriableError{err}
+		}
+		return nil
+	}, NumSmtpRetries, SmtpSleepTime)
+	return err
+}
+
+// chatColor returns the color of the attachment for a build or task status,
+// which share the same success and failure values.
+func chatColor(status string) string {
+	switch status {
+	case evergreen.TaskSucceeded:
+		return thirdparty.ChatColorGood
+	case evergreen.TaskFailed:
+		return thirdparty.ChatColorDanger
+	}
+	return thirdparty.ChatColorWarning
+}
+
+// chatList joins the items into lines, eliding the ones past the maximum.
+func chatList(items []string) string {
+	if len(items) > maxChatListLength {
+		more := len(items) - maxChatListLength
+		items = append(items[:maxChatListLength:maxChatListLength], fmt.Sprintf("and %v more", more))
+	}
+	return strings.Join(items, "\n")
+}
+
+// chatBlamelist lists the changes of the notification, one per line.
+func chatBlamelist(changeInfo []ChangeInfo) thirdparty.ChatField {
+	changes := []string{}
+	for _, change := range changeInfo {
+		revision := change.Revision
+		if len(revision) > 7 {
+			revision = revision[0:7]
+		}
+		message := strings.SplitN(change.Message, "\n", 2)[0]
+		changes = append(changes, fmt.Sprintf("%v %v: %v", revision, change.Author, message))
+	}
+	return thirdparty.ChatField{Title: "Blamelist", Value: chatList(changes)}
+}
+
+func buildChatAttachment(uiRoot string, notification *TriggeredBuildNotification,
+	subject string) thirdparty.ChatAttachment {
+	current := notification.Current
+	attachment := thirdparty.ChatAttachment{
+		Fallback:  subject,
+		Color:     chatColor(current.Status),
+		Title:     fmt.Sprintf("Build #%v on %v", current.BuildNumber, current.DisplayName),
+		TitleLink: fmt.Sprintf("%v/build/%v", uiRoot, current.Id),
+	}
+
+	failedTasks := []string{}
+	for _, task := range getFailedTasks(current, notification.Key.NotificationName) {
+		failedTasks = append(failedTasks, failedTaskLink(uiRoot, task))
+	}
+	if len(failedTasks) != 0 {
+		attachment.Fields = append(attachment.Fields, thirdparty.ChatField{
+			Title: "Failed tasks",
+			Value: chatList(failedTasks),
+		})
+	}
+	if len(notification.Info) != 0 {
+		attachment.Fields = append(attachment.Fields, chatBlamelist(notification.Info))
+	}
+	return attachment
+}
+
+func failedTaskLink(uiRoot string, task build.TaskCache) string {
+	return thirdparty.ChatLink(fmt.Sprintf("%v/task/%v", uiRoot, task.Id), task.DisplayName)
+}
+
+func taskChatAttachment(uiRoot string, notification *TriggeredTaskNotification,
+	subject string) thirdparty.ChatAttachment {
+	current := notification.Current
+	attachment := thirdparty.ChatAttachment{
+		Fallback:  subject,
+		Color:     chatColor(current.Status),
+		Title:     fmt.Sprintf("%v on %v", current.DisplayName, current.BuildVariant),
+		TitleLink: fmt.Sprintf("%v/task/%v/%v", uiRoot, current.Id, current.Execution),
+	}
+
+	failedTests := []string{}
+	for _, test := range getFailedTests(current, notification.Key.NotificationName) {
+		failedTests = append(failedTests, failedTestLink(test))
+	}
+	if len(failedTests) != 0 {
+		attachment.Fields = append(attachment.Fields, thirdparty.ChatField{
+			Title: "Failed tests",
+			Value: chatList(failedTests),
+		})
+	}
+	if len(notification.Info) != 0 {
+		attachment.Fields = append(attachment.Fields, chatBlamelist(notification.Info))
+	}
+	attachment.Fields = append(attachment.Fields, thirdparty.ChatField{
+		Title: "Logs",
+		Value: thirdparty.ChatLink(fmt.Sprintf("%v/task_log_raw/%v/%v?type=ALL",
+			uiRoot, current.Id, current.Execution), "all logs"),
+		Short: true,
+	})
+	return attachment
+}
+
+func failedTestLink(test model.TestResult) string {
+	if test.URL == "" {
+		return test.TestFile
+	}
+	return thirdparty.ChatLink(test.URL, test.TestFile)
+}
@@ -3,6 +3,8 @@ package notify
 import (
 	"github.com/10gen-labs/slogger/v1"
 	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model/subscription"
+	"github.com/evergreen-ci/evergreen/thirdparty"
 	"github.com/evergreen-ci/evergreen/util"
 	"strings"
 )
@@ -20,6 +22,11 @@ type Email interface {
 	GetChangeInfo() []ChangeInfo
 	// Should you skip sending this email given the provided variants to skip
 	ShouldSkip(skipVariants []string) bool
+	// Should you skip sending this email to the subscription, given the
+	// variants and tasks it covers
+	ShouldSkipSubscription(s *subscription.Subscription) bool
+	// the rich part of the notification when it's posted to chat
+	GetChatAttachment(uiRoot string) thirdparty.ChatAttachment
 }
 
 // "Base class" for structs that implement the Email interface. Stores the
@@ -59,6 +66,21 @@ func (self *BuildEmail) ShouldSkip(skipVariants []string) bool {
 	return false
 }
 
+func (self *BuildEmail) ShouldSkipSubscription(s *subscription.Subscription) bool {
+	buildVariant := self.Trigger.Current.BuildVariant
+	if !s.Matches(buildVariant, "") {
+		evergreen.Logger.Logf(slogger.DEBUG, "Skipping buildvariant %v “%v” notification "+
+			"for subscription %v: “%v”", buildVariant, self.Trigger.Key.NotificationName,
+			s.Id.Hex(), self.Subject)
+		return true
+	}
+	return false
+}
+
+func (self *BuildEmail) GetChatAttachment(uiRoot string) thirdparty.ChatAttachment {
+	return buildChatAttachment(uiRoot, &self.Trigger, self.Subject)
+}
+
 func (self *BuildEmail) IsLikelySystemFailure() bool {
 	return false
 }
@@ -85,6 +107,21 @@ func (self *TaskEmail) ShouldSkip(skipVariants []string) bool {
 	return false
 }
 
+func (self *TaskEmail) ShouldSkipSubscription(s *subscription.Subscription) bool {
+	current := self.Trigger.Current
+	if !s.Matches(current.BuildVariant, current.DisplayName) {
+		evergreen.Logger.Logf(slogger.DEBUG, "Skipping task %v on buildvariant %v “%v” "+
+			"notification for subscription %v: “%v”", current.DisplayName, current.BuildVariant,
+			self.Trigger.Key.NotificationName, s.Id.Hex(), self.Subject)
+		return true
+	}
+	return false
+}
+
+func (self *TaskEmail) GetChatAttachment(uiRoot string) thirdparty.ChatAttachment {
+	return taskChatAttachment(uiRoot, &self.Trigger, self.Subject)
+}
+
 func (self *TaskEmail) IsLikelySystemFailure() bool {
 	return strings.Contains(self.GetSubject(), UnresponsiveMessage)
 }
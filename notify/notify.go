@@ -7,6 +7,7 @@ import (
 	"github.com/evergreen-ci/evergreen/model"
 	"github.com/evergreen-ci/evergreen/model/build"
 	"github.com/evergreen-ci/evergreen/model/patch"
+	"github.com/evergreen-ci/evergreen/model/subscription"
 	"github.com/evergreen-ci/evergreen/model/user"
 	"github.com/evergreen-ci/evergreen/model/version"
 	"github.com/evergreen-ci/evergreen/util"
@@ -14,6 +15,7 @@ import (
 	"gopkg.in/yaml.v2"
 	"io/ioutil"
 	"net/mail"
+	"os"
 	"path"
 	"path/filepath"
 	"strings"
@@ -65,6 +67,7 @@ var (
 	taskFailureKey           = "task_failure"
 	taskSuccessKey           = "task_success"
 	taskSuccessToFailureKey  = "task_success_to_failure"
+	taskFailureToSuccessKey  = "task_failure_to_success"
 	taskCompletionKey        = "task_completion"
 	taskFailureKeys          = []string{taskFailureKey, taskSuccessToFailureKey}
 	buildFailureKeys         = []string{buildFailureKey, buildSuccessToFailureKey}
@@ -76,6 +79,8 @@ var (
 	successSubject    = "succeeded"
 	transitionSubject = "transitioned to failure"
 
+	successTransitionSubject = "transitioned to success"
+
 	// task/build status notification prefaces
 	mciSuccessPreface    = "[MCI-SUCCESS %v]"
 	mciFailurePreface    = "[MCI-FAILURE %v]"
@@ -98,6 +103,7 @@ var (
 		taskSuccessKey:           &TaskSuccessHandler{taskNotificationHandler, taskSuccessKey},
 		taskCompletionKey:        &TaskCompletionHandler{taskNotificationHandler, taskCompletionKey},
 		taskSuccessToFailureKey:  &TaskSuccessToFailureHandler{taskNotificationHandler, taskSuccessToFailureKey},
+		taskFailureToSuccessKey:  &TaskFailureToSuccessHandler{taskNotificationHandler, taskFailureToSuccessKey},
 	}
 )
 
@@ -167,6 +173,13 @@ func Run(settings *evergreen.Settings) error {
 		return err
 	}
 
+	// get the subscriptions users and teams manage in the UI
+	mciNotification.Subscriptions, err = subscription.Find(subscription.All)
+	if err != nil {
+		evergreen.Logger.Errorf(slogger.ERROR, "Error finding subscriptions: %v", err)
+		return err
+	}
+
 	templateGlobals := map[string]interface{}{
 		"UIRoot": settings.Ui.Url,
 	}
@@ -218,6 +231,11 @@ func ParseNotifications(configName string) (*MCINotification, error) {
 
 	notificationsFile := filepath.Join(configRoot, evergreen.NotificationsFile)
 	data, err := ioutil.ReadFile(notificationsFile)
+	if os.IsNotExist(err) {
+		// subscriptions can be managed entirely in the UI
+		evergreen.Logger.Logf(slogger.INFO, "No notifications file found at %v", notificationsFile)
+		return &MCINotification{}, nil
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -349,6 +367,7 @@ func ProcessNotifications(ae *web.App, configName string, mciNotification *MCINo
 func SendNotifications(settings *evergreen.Settings, mciNotification *MCINotification,
 	emails map[NotificationKey][]Email, mailer Mailer) (err error) {
 	evergreen.Logger.Logf(slogger.INFO, "Sending notifications...")
+	chat := ConstructChatNotifier(settings)
 
 	// parse all notifications, sending it to relevant recipients
 	for _, notification := range mciNotification.Notifications {
@@ -434,6 +453,16 @@ func SendNotifications(settings *evergreen.Settings, mciNotification *MCINotific
 
 				// send to the appropriate patch requester
 				for _, changeInfo := range email.GetChangeInfo() {
+					// message requesters who have a chat handle directly
+					if chat != nil && changeInfo.ChatHandle != "" {
+						err = TrySendChatNotification("@"+changeInfo.ChatHandle, email, chat)
+						if err != nil {
+							evergreen.Logger.Errorf(slogger.ERROR, "Unable to post "+
+								"chat notification %#v: %v", key, err)
+						}
+						continue
+					}
+
 					// send notification to each member of the blamelist
 					patchRequester := fmt.Sprintf("%v <%v>", changeInfo.Author,
 						changeInfo.Email)
@@ -449,6 +478,9 @@ func SendNotifications(settings *evergreen.Settings, mciNotification *MCINotific
 		}
 	}
 
+	// send user and team subscriptions
+	sendSubscriptionNotifications(mciNotification.Subscriptions, emails, mailer, chat)
+
 	return nil
 }
 
@@ -552,6 +584,7 @@ func constructChangeInfo(v *version.Version, notification *NotificationKey) (cha
 		} else {
 			changeInfo.Email = dbUser.Email()
 			changeInfo.Author = dbUser.DisplayName()
+			changeInfo.ChatHandle = dbUser.Settings.ChatHandle
 		}
 
 		changeInfo.Project = patch.Project
@@ -729,6 +762,14 @@ func notificationsToStruct(mciNotification *MCINotification) (notifyOn []Notific
 			}
 		}
 	}
+
+	// Get user and team subscriptions
+	for _, subscription := range mciNotification.Subscriptions {
+		key, ok := subscriptionKey(subscription)
+		if ok && !util.SliceContains(notifyOn, key) {
+			notifyOn = append(notifyOn, key)
+		}
+	}
 	return
 }
 
@@ -799,11 +840,12 @@ type Team struct {
 	Subscriptions []Subscription `yaml:"subscriptions"`
 }
 
-// store notifications file
+// store notifications file, along with the subscriptions stored in the database
 type MCINotification struct {
-	Notifications      []Notification `yaml:"notifications"`
-	Teams              []Team         `yaml:"teams"`
-	PatchNotifications []Subscription `yaml:"patch_notifications"`
+	Notifications      []Notification              `yaml:"notifications"`
+	Teams              []Team                      `yaml:"teams"`
+	PatchNotifications []Subscription              `yaml:"patch_notifications"`
+	Subscriptions      []subscription.Subscription `yaml:"-"`
 }
 
 // stores high level notifications key
@@ -816,10 +858,11 @@ type NotificationKey struct {
 
 // stores information pertaining to a repository's changes
 type ChangeInfo struct {
-	Revision string
-	Author   string
-	Email    string
-	Pushtime string
-	Project  string
-	Message  string
+	Revision   string
+	Author     string
+	Email      string
+	ChatHandle string
+	Pushtime   string
+	Project    string
+	Message    string
 }
@@ -0,0 +1,118 @@
+package notify
+
+import (
+	"fmt"
+	"github.com/10gen-labs/slogger/v1"
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model/subscription"
+	"github.com/evergreen-ci/evergreen/model/user"
+)
+
+// SubscriptionTriggers returns the names of the notifications users and teams
+// can subscribe to.
+func SubscriptionTriggers() []string {
+	triggers := []string{}
+	for name := range Handlers {
+		triggers = append(triggers, name)
+	}
+	return triggers
+}
+
+// subscriptionKey returns the notification key for the subscription, and false
+// if the subscription's trigger isn't handled by the notifier, as is the case
+// for subscriptions to alert triggers.
+func subscriptionKey(s subscription.Subscription) (NotificationKey, bool) {
+	if _, ok := Handlers[s.Trigger]; !ok {
+		return NotificationKey{}, false
+	}
+	requester := s.Requester
+	if requester == "" {
+		requester = evergreen.RepotrackerVersionRequester
+	}
+	return NotificationKey{
+		Project:               s.Project,
+		NotificationName:      s.Trigger,
+		NotificationType:      getType(s.Trigger),
+		NotificationRequester: requester,
+	}, true
+}
+
+// sendSubscriptionNotifications sends the triggered emails to each subscribed
+// user and team, by email or to chat. Users subscribed to patch notifications
+// are only sent the emails for their own patches.
+func sendSubscriptionNotifications(subscriptions []subscription.Subscription,
+	emails map[NotificationKey][]Email, mailer Mailer, chat *ChatNotifier) {
+	for _, s := range subscriptions {
+		key, ok := subscriptionKey(s)
+		if !ok || len(emails[key]) == 0 {
+			continue
+		}
+		if s.IsChat() && chat == nil {
+			evergreen.Logger.Logf(slogger.WARN, "Chat isn't configured, skipping subscription %v",
+				s.Id.Hex())
+			continue
+		}
+
+		triggered := emails[key]
+		recipient := fmt.Sprintf("%v <%v>", s.Team, s.Address)
+		if s.IsChat() {
+			recipient = s.Address
+		}
+		if !s.IsTeam() {
+			dbUser, err := user.FindOne(user.ById(s.Owner))
+			if err != nil {
+				evergreen.Logger.Errorf(slogger.ERROR, "Error finding user %v: %v", s.Owner, err)
+				continue
+			}
+			if dbUser == nil {
+				evergreen.Logger.Errorf(slogger.ERROR, "User %v of subscription %v not found",
+					s.Owner, s.Id.Hex())
+				continue
+			}
+			recipient = fmt.Sprintf("%v <%v>", dbUser.DisplayName(), dbUser.Email())
+			if s.IsChat() {
+				if dbUser.Settings.ChatHandle == "" {
+					evergreen.Logger.Logf(slogger.WARN, "User %v has no chat handle, "+
+						"skipping subscription %v", s.Owner, s.Id.Hex())
+					continue
+				}
+				recipient = "@" + dbUser.Settings.ChatHandle
+			}
+			if key.NotificationRequester == evergreen.PatchVersionRequester {
+				triggered = authoredEmails(triggered, dbUser.Email())
+			}
+		}
+
+		for _, email := range triggered {
+			if email.ShouldSkipSubscription(&s) {
+				continue
+			}
+			var err error
+			if s.IsChat() {
+				err = TrySendChatNotification(recipient, email, chat)
+			} else {
+				err = TrySendNotification([]string{recipient}, email.GetSubject(), email.GetBody(), mailer)
+			}
+			if err != nil {
+				evergreen.Logger.Errorf(slogger.ERROR, "Unable to send subscription "+
+					"notification %#v: %v", key, err)
+				continue
+			}
+		}
+	}
+}
+
+// authoredEmails returns the emails whose changes were made by the author with
+// the given address.
+func authoredEmails(emails []Email, address string) []Email {
+	authored := []Email{}
+	for _, email := range emails {
+		for _, changeInfo := range email.GetChangeInfo() {
+			if changeInfo.Email == address {
+				authored = append(authored, email)
+				break
+			}
+		}
+	}
+	return authored
+}
@@ -0,0 +1,286 @@
+package artifactPlugin
+
+import (
+	"fmt"
+	"github.com/10gen-labs/slogger/v1"
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/artifactstore"
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/artifact"
+	"github.com/evergreen-ci/evergreen/plugin"
+	"github.com/evergreen-ci/evergreen/util"
+	"github.com/mitchellh/mapstructure"
+	"gopkg.in/mgo.v2/bson"
+	"io"
+	"net/http"
+	"strings"
+)
+
+func init() {
+	plugin.Publish(&ArtifactPlugin{})
+}
+
+const (
+	ArtifactPluginName  = "artifact"
+	ArtifactUploadCmd   = "upload"
+	ArtifactDownloadCmd = "download"
+
+	UploadAPIEndpoint   = "upload"
+	DownloadAPIEndpoint = "download"
+	FileAPIEndpoint     = "file"
+)
+
+// ArtifactPlugin stores task artifacts in the artifact store configured
+// for the API server, so that projects don't need their own s3 credentials.
+// The store is set up in the "artifact" section of the plugin settings, e.g.
+//  plugins:
+//    artifact:
+//      backend: local
+//      local_path: /data/artifacts
+type ArtifactPlugin struct {
+	store artifactstore.Store
+}
+
+// Name returns the name of the plugin. Fulfills Plugin interface.
+func (ap *ArtifactPlugin) Name() string {
+	return ArtifactPluginName
+}
+
+// Configure sets up the artifact store. A missing configuration is not an
+// error; the API handlers reject requests until a store is configured.
+func (ap *ArtifactPlugin) Configure(conf map[string]interface{}) error {
+	if len(conf) == 0 {
+		return nil
+	}
+	storeConf := artifactstore.Config{}
+	if err := mapstructure.Decode(conf, &storeConf); err != nil {
+		return fmt.Errorf("error decoding artifact store settings: %v", err)
+	}
+	store, err := artifactstore.New(storeConf)
+	if err != nil {
+		return err
+	}
+	ap.store = store
+	return nil
+}
+
+// NewCommand returns commands of the given name.
+// Fulfills Plugin interface.
+func (ap *ArtifactPlugin) NewCommand(cmdName string) (plugin.Command, error) {
+	switch cmdName {
+	case ArtifactUploadCmd:
+		return &UploadCommand{}, nil
+	case ArtifactDownloadCmd:
+		return &DownloadCommand{}, nil
+	default:
+		return nil, fmt.Errorf("No such %v command: %v", ArtifactPluginName, cmdName)
+	}
+}
+
+func (ap *ArtifactPlugin) GetAPIHandler() http.Handler {
+	r := http.NewServeMux()
+	r.HandleFunc(fmt.Sprintf("/%v", UploadAPIEndpoint), ap.uploadHandler)
+	r.HandleFunc(fmt.Sprintf("/%v", DownloadAPIEndpoint), ap.downloadHandler)
+	r.HandleFunc(fmt.Sprintf("/%v/", FileAPIEndpoint), ap.fileHandler)
+	r.HandleFunc("/", http.NotFound) // 404 any request not routable to these endpoints
+	return r
+}
+
+// uploadHandler stores the request body and attaches it to the task's files.
+// The file's display name and visibility are passed as query parameters.
+// Only the agent running the task can upload its files.
+func (ap *ArtifactPlugin) uploadHandler(w http.ResponseWriter, r *http.Request) {
+	task := plugin.GetTask(r)
+	if task == nil {
+		http.Error(w, "task not found", http.StatusNotFound)
+		return
+	}
+	if !hasTaskSecret(r, task) {
+		http.Error(w, "wrong secret!", http.StatusConflict)
+		return
+	}
+	if ap.store == nil {
+		http.Error(w, "no artifact store is configured", http.StatusInternalServerError)
+		return
+	}
+	name := r.FormValue("name")
+	visibility := r.FormValue("visibility")
+	if name == "" {
+		http.Error(w, "name cannot be blank", http.StatusBadRequest)
+		return
+	}
+	if !util.SliceContains(artifact.ValidVisibilities, visibility) {
+		http.Error(w, fmt.Sprintf("invalid visibility setting: %v", visibility), http.StatusBadRequest)
+		return
+	}
+
+	digest, err := ap.store.Put(r.Body)
+	if err != nil {
+		message := fmt.Sprintf("error storing artifact for task %v: %v", task.Id, err)
+		evergreen.Logger.Errorf(slogger.ERROR, message)
+		http.Error(w, message, http.StatusInternalServerError)
+		return
+	}
+
+	file := artifact.File{
+		Name:       name,
+		Link:       fileLink(r, task.Id, digest),
+		Visibility: visibility,
+		Digest:     digest,
+	}
+	entry := &artifact.Entry{
+		TaskId:          task.Id,
+		TaskDisplayName: task.DisplayName,
+		BuildId:         task.BuildId,
+		Files:           []artifact.File{file},
+	}
+	if err := entry.Upsert(); err != nil {
+		message := fmt.Sprintf("error attaching artifact to task %v: %v", task.Id, err)
+		evergreen.Logger.Errorf(slogger.ERROR, message)
+		http.Error(w, message, http.StatusInternalServerError)
+		return
+	}
+	plugin.WriteJSON(w, http.StatusOK, file)
+}
+
+// downloadHandler streams a file attached to a task in the same version.
+// The task is looked up by the "task" and "variant" query parameters, which
+// default to the requesting task's own display name and variant. Only the
+// agent running the requesting task can download through it.
+func (ap *ArtifactPlugin) downloadHandler(w http.ResponseWriter, r *http.Request) {
+	task := plugin.GetTask(r)
+	if task == nil {
+		http.Error(w, "task not found", http.StatusNotFound)
+		return
+	}
+	if !hasTaskSecret(r, task) {
+		http.Error(w, "wrong secret!", http.StatusConflict)
+		return
+	}
+	taskName := r.FormValue("task")
+	if taskName == "" {
+		taskName = task.DisplayName
+	}
+	variant := r.FormValue("variant")
+	if variant == "" {
+		variant = task.BuildVariant
+	}
+	name := r.FormValue("name")
+
+	source, err := model.FindOneTask(
+		bson.M{
+			model.TaskVersionKey:      task.Version,
+			model.TaskBuildVariantKey: variant,
+			model.TaskDisplayNameKey:  taskName,
+		},
+		db.NoProjection,
+		db.NoSort,
+	)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if source == nil {
+		http.Error(w, fmt.Sprintf("no task '%v' on variant '%v'", taskName, variant), http.StatusNotFound)
+		return
+	}
+	file, err := findStoredFile(source.Id, func(f artifact.File) bool { return f.Name == name })
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if file == nil {
+		http.Error(w, fmt.Sprintf("task %v has no stored artifact named '%v'", source.Id, name),
+			http.StatusNotFound)
+		return
+	}
+	ap.serveFile(w, file)
+}
+
+// fileHandler streams the file with the digest in the request path. This is the
+// link shown on the task page, so the file must be attached to the task in the URL.
+// Files that aren't public are only served to logged in users and the task's agent.
+func (ap *ArtifactPlugin) fileHandler(w http.ResponseWriter, r *http.Request) {
+	task := plugin.GetTask(r)
+	if task == nil {
+		http.Error(w, "task not found", http.StatusNotFound)
+		return
+	}
+	digest := strings.TrimPrefix(r.URL.Path, fmt.Sprintf("/%v/", FileAPIEndpoint))
+	file, err := findStoredFile(task.Id, func(f artifact.File) bool { return f.Digest == digest })
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if file == nil {
+		http.NotFound(w, r)
+		return
+	}
+	if file.Visibility != artifact.Public && plugin.GetUser(r) == nil && !hasTaskSecret(r, task) {
+		http.Error(w, fmt.Sprintf("artifact '%v' is only visible to logged in users", file.Name),
+			http.StatusUnauthorized)
+		return
+	}
+	ap.serveFile(w, file)
+}
+
+func (ap *ArtifactPlugin) serveFile(w http.ResponseWriter, file *artifact.File) {
+	if ap.store == nil {
+		http.Error(w, "no artifact store is configured", http.StatusInternalServerError)
+		return
+	}
+	if file.Expired {
+		http.Error(w, fmt.Sprintf("artifact '%v' has expired", file.Name), http.StatusGone)
+		return
+	}
+	contents, err := ap.store.Get(file.Digest)
+	if err != nil {
+		message := fmt.Sprintf("error reading artifact '%v': %v", file.Name, err)
+		evergreen.Logger.Errorf(slogger.ERROR, message)
+		http.Error(w, message, http.StatusInternalServerError)
+		return
+	}
+	defer contents.Close()
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", file.Name))
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, contents); err != nil {
+		evergreen.Logger.Errorf(slogger.ERROR, "error sending artifact '%v': %v", file.Name, err)
+	}
+}
+
+// hasTaskSecret returns true if the request carries the task's secret, i.e.
+// it was made by the agent running the task.
+func hasTaskSecret(r *http.Request, task *model.Task) bool {
+	return task.Secret != "" && r.Header.Get(evergreen.TaskSecretHeader) == task.Secret
+}
+
+// findStoredFile returns the first file kept in the artifact store
+// that is attached to the task and matches the predicate.
+func findStoredFile(taskId string, matches func(artifact.File) bool) (*artifact.File, error) {
+	entry, err := artifact.FindOne(artifact.ByTaskId(taskId))
+	if err != nil {
+		return nil, fmt.Errorf("error finding artifacts for task %v: %v", taskId, err)
+	}
+	if entry == nil {
+		return nil, nil
+	}
+	for i := range entry.Files {
+		if entry.Files[i].Digest != "" && matches(entry.Files[i]) {
+			return &entry.Files[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// fileLink builds the API server link for a stored file from the host
+// the agent used to reach the API server.
+func fileLink(r *http.Request, taskId, digest string) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%v://%v/api/2/task/%v/%v/%v/%v",
+		scheme, r.Host, taskId, ArtifactPluginName, FileAPIEndpoint, digest)
+}
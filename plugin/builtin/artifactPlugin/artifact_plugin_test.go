@@ -0,0 +1,124 @@
+package artifactPlugin_test
+
+import (
+	"fmt"
+	"github.com/10gen-labs/slogger/v1"
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/agent"
+	"github.com/evergreen-ci/evergreen/apiserver"
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/evergreen-ci/evergreen/model/artifact"
+	"github.com/evergreen-ci/evergreen/model/user"
+	"github.com/evergreen-ci/evergreen/plugin"
+	. "github.com/evergreen-ci/evergreen/plugin/builtin/artifactPlugin"
+	"github.com/evergreen-ci/evergreen/plugin/plugintest"
+	"github.com/evergreen-ci/evergreen/testutil"
+	. "github.com/smartystreets/goconvey/convey"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestArtifactUploadAndDownload(t *testing.T) {
+	storeDir, err := ioutil.TempDir("", "artifactstore")
+	testutil.HandleTestingErr(err, t, "couldn't create store dir")
+	defer os.RemoveAll(storeDir)
+
+	testConfig := evergreen.TestConfig()
+	testConfig.Plugins = evergreen.PluginConfig{
+		ArtifactPluginName: {"backend": "local", "local_path": storeDir},
+	}
+	db.SetGlobalSessionProvider(db.SessionFactoryFromConfig(testConfig))
+
+	Convey("With a running api server with a local artifact store", t, func() {
+		testutil.HandleTestingErr(db.ClearCollections(artifact.Collection, user.Collection), t,
+			"error clearing collections")
+		registry := plugin.NewSimpleRegistry()
+		artifactPlugin := &ArtifactPlugin{}
+		testutil.HandleTestingErr(registry.Register(artifactPlugin), t, "couldn't register plugin")
+		server, err := apiserver.CreateTestServer(testConfig, nil, plugin.APIPlugins, true)
+		testutil.HandleTestingErr(err, t, "couldn't set up testing server")
+		defer server.Close()
+
+		taskConfig, err := plugintest.CreateTestConfig("testdata/plugin_artifact.yml", t)
+		testutil.HandleTestingErr(err, t, "couldn't create test config")
+		taskConfig.Expansions.Update(taskConfig.BuildVariant.Expansions)
+		logger := agent.NewTestLogger(&evergreen.SliceAppender{[]*slogger.Log{}})
+		httpCom := plugintest.TestAgentCommunicator(taskConfig.Task.Id, taskConfig.Task.Secret, server.URL)
+		pluginCom := &agent.TaskJSONCommunicator{artifactPlugin.Name(), httpCom}
+
+		localFile := filepath.Join(taskConfig.WorkDir, "artifact.txt")
+		So(ioutil.WriteFile(localFile, []byte("hello world"), 0644), ShouldBeNil)
+
+		Convey("the file should be uploaded, attached and downloadable again", func() {
+			for _, task := range taskConfig.Project.Tasks {
+				for _, command := range task.Commands {
+					pluginCmds, err := registry.GetCommands(command, taskConfig.Project.Functions)
+					testutil.HandleTestingErr(err, t, "couldn't get plugin command")
+					So(pluginCmds, ShouldNotBeNil)
+					So(pluginCmds[0].Execute(logger, pluginCom, taskConfig, make(chan bool)), ShouldBeNil)
+				}
+			}
+
+			entry, err := artifact.FindOne(artifact.ByTaskId(taskConfig.Task.Id))
+			So(err, ShouldBeNil)
+			So(entry, ShouldNotBeNil)
+			So(len(entry.Files), ShouldEqual, 1)
+			So(entry.Files[0].Name, ShouldEqual, "artifact.txt")
+			So(entry.Files[0].Visibility, ShouldEqual, artifact.Private)
+			So(entry.Files[0].Digest, ShouldNotEqual, "")
+
+			downloaded, err := ioutil.ReadFile(filepath.Join(taskConfig.WorkDir, "downloaded", "artifact.txt"))
+			So(err, ShouldBeNil)
+			So(string(downloaded), ShouldEqual, "hello world")
+
+			Convey("and the attached link should not serve the private file anonymously", func() {
+				resp, err := http.Get(entry.Files[0].Link)
+				So(err, ShouldBeNil)
+				defer resp.Body.Close()
+				So(resp.StatusCode, ShouldEqual, http.StatusUnauthorized)
+			})
+
+			Convey("and the attached link should serve the file to a logged in user", func() {
+				u := &user.DBUser{Id: "artifact_user", APIKey: "artifact_key"}
+				So(u.Insert(), ShouldBeNil)
+				req, err := http.NewRequest("GET", entry.Files[0].Link, nil)
+				So(err, ShouldBeNil)
+				req.Header.Add("Api-User", u.Id)
+				req.Header.Add("Api-Key", u.APIKey)
+				resp, err := http.DefaultClient.Do(req)
+				So(err, ShouldBeNil)
+				defer resp.Body.Close()
+				So(resp.StatusCode, ShouldEqual, http.StatusOK)
+				body, err := ioutil.ReadAll(resp.Body)
+				So(err, ShouldBeNil)
+				So(string(body), ShouldEqual, "hello world")
+			})
+		})
+
+		Convey("uploading without the task's secret should fail", func() {
+			req, err := http.NewRequest("POST", fmt.Sprintf("%v/api/2/task/%v/%v/%v?name=a.txt&visibility=%v",
+				server.URL, taskConfig.Task.Id, ArtifactPluginName, UploadAPIEndpoint, artifact.Public),
+				strings.NewReader("not from the agent"))
+			So(err, ShouldBeNil)
+			resp, err := http.DefaultClient.Do(req)
+			So(err, ShouldBeNil)
+			defer resp.Body.Close()
+			So(resp.StatusCode, ShouldEqual, http.StatusConflict)
+
+			entry, err := artifact.FindOne(artifact.ByTaskId(taskConfig.Task.Id))
+			So(err, ShouldBeNil)
+			So(entry, ShouldBeNil)
+		})
+
+		Convey("downloading an artifact that doesn't exist should fail", func() {
+			resp, err := pluginCom.TaskGetJSON(DownloadAPIEndpoint + "?name=missing")
+			So(err, ShouldBeNil)
+			defer resp.Body.Close()
+			So(resp.StatusCode, ShouldEqual, http.StatusNotFound)
+		})
+	})
+}
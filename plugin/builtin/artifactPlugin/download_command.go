@@ -0,0 +1,137 @@
+package artifactPlugin
+
+import (
+	"fmt"
+	"github.com/10gen-labs/slogger/v1"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/plugin"
+	"github.com/evergreen-ci/evergreen/util"
+	"github.com/mitchellh/mapstructure"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+var (
+	maxDownloadAttempts = 5
+	downloadRetrySleep  = 5 * time.Second
+)
+
+// DownloadCommand fetches an artifact uploaded by a task in the same
+// version from the API server's artifact store.
+type DownloadCommand struct {
+	// ArtifactName is the display name of the artifact to fetch
+	ArtifactName string `mapstructure:"name" plugin:"expand"`
+
+	// Task and Variant identify the task that uploaded the artifact.
+	// They default to the running task's own display name and variant.
+	Task    string `mapstructure:"task" plugin:"expand"`
+	Variant string `mapstructure:"variant" plugin:"expand"`
+
+	// LocalFile is the path to write the artifact to
+	LocalFile string `mapstructure:"local_file" plugin:"expand"`
+}
+
+func (dc *DownloadCommand) Name() string {
+	return ArtifactDownloadCmd
+}
+
+func (dc *DownloadCommand) Plugin() string {
+	return ArtifactPluginName
+}
+
+// ParseParams decodes and validates the command's parameters.
+func (dc *DownloadCommand) ParseParams(params map[string]interface{}) error {
+	if err := mapstructure.Decode(params, dc); err != nil {
+		return fmt.Errorf("error decoding %v params: %v", dc.Name(), err)
+	}
+	if dc.ArtifactName == "" {
+		return fmt.Errorf("error validating %v params: name cannot be blank", dc.Name())
+	}
+	if dc.LocalFile == "" {
+		return fmt.Errorf("error validating %v params: local_file cannot be blank", dc.Name())
+	}
+	return nil
+}
+
+// Execute expands the parameters and downloads the artifact, retrying on failure.
+func (dc *DownloadCommand) Execute(log plugin.Logger, com plugin.PluginCommunicator,
+	conf *model.TaskConfig, stop chan bool) error {
+
+	if err := plugin.ExpandValues(dc, conf.Expansions); err != nil {
+		return err
+	}
+	if !filepath.IsAbs(dc.LocalFile) {
+		dc.LocalFile = filepath.Join(conf.WorkDir, dc.LocalFile)
+	}
+
+	log.LogTask(slogger.INFO, "Downloading artifact '%v' to %v", dc.ArtifactName, dc.LocalFile)
+
+	errChan := make(chan error)
+	go func() {
+		retriableDownload := util.RetriableFunc(
+			func() error {
+				err := dc.download(com)
+				if err != nil {
+					log.LogExecution(slogger.ERROR, "Error downloading artifact: %v", err)
+				}
+				return err
+			},
+		)
+		_, err := util.RetryArithmeticBackoff(retriableDownload, maxDownloadAttempts, downloadRetrySleep)
+		errChan <- err
+	}()
+
+	select {
+	case err := <-errChan:
+		if err != nil {
+			return fmt.Errorf("artifact download failed: %v", err)
+		}
+		log.LogTask(slogger.INFO, "Artifact '%v' downloaded", dc.ArtifactName)
+		return nil
+	case <-stop:
+		log.LogExecution(slogger.INFO, "Received signal to terminate execution of artifact download")
+		return nil
+	}
+}
+
+// download makes a single attempt at fetching the artifact. Only server
+// and connection errors are retriable; a missing artifact is not.
+func (dc *DownloadCommand) download(com plugin.PluginCommunicator) error {
+	query := url.Values{}
+	query.Set("name", dc.ArtifactName)
+	query.Set("task", dc.Task)
+	query.Set("variant", dc.Variant)
+	resp, err := com.TaskGetJSON(fmt.Sprintf("%v?%v", DownloadAPIEndpoint, query.Encode()))
+	if resp != nil {
+		defer resp.Body.Close()
+	}
+	if err != nil {
+		return util.RetriableError{err}
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(resp.Body)
+		err = fmt.Errorf("unexpected response (%v): %v", resp.StatusCode, string(body))
+		if resp.StatusCode >= http.StatusInternalServerError {
+			return util.RetriableError{err}
+		}
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dc.LocalFile), 0755); err != nil {
+		return err
+	}
+	file, err := os.Create(dc.LocalFile)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if _, err := io.Copy(file, resp.Body); err != nil {
+		return util.RetriableError{err}
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package artifactPlugin
+
+import (
+	"errors"
+	"fmt"
+	"github.com/10gen-labs/slogger/v1"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/artifact"
+	"github.com/evergreen-ci/evergreen/plugin"
+	"github.com/evergreen-ci/evergreen/util"
+	"github.com/mitchellh/mapstructure"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+var (
+	maxUploadAttempts = 5
+	uploadRetrySleep  = 5 * time.Second
+)
+
+var errSkippedFile = errors.New("missing optional file was skipped")
+
+// UploadCommand sends a local file to the API server's artifact store
+// and attaches it to the task.
+type UploadCommand struct {
+	// LocalFile is the path to the file to upload
+	LocalFile string `mapstructure:"local_file" plugin:"expand"`
+
+	// DisplayName is the name the file is shown and downloaded under.
+	// Defaults to the base name of the local file.
+	DisplayName string `mapstructure:"display_name" plugin:"expand"`
+
+	// Visibility determines who can see file links in the UI, as for s3.put.
+	Visibility string `mapstructure:"visibility" plugin:"expand"`
+
+	// Optional, when set to true, causes this command to be skipped over without
+	// an error when the path specified in local_file does not exist.
+	Optional bool `mapstructure:"optional"`
+}
+
+func (uc *UploadCommand) Name() string {
+	return ArtifactUploadCmd
+}
+
+func (uc *UploadCommand) Plugin() string {
+	return ArtifactPluginName
+}
+
+// ParseParams decodes and validates the command's parameters.
+func (uc *UploadCommand) ParseParams(params map[string]interface{}) error {
+	if err := mapstructure.Decode(params, uc); err != nil {
+		return fmt.Errorf("error decoding %v params: %v", uc.Name(), err)
+	}
+	if err := uc.validateParams(); err != nil {
+		return fmt.Errorf("error validating %v params: %v", uc.Name(), err)
+	}
+	return nil
+}
+
+func (uc *UploadCommand) validateParams() error {
+	if uc.LocalFile == "" {
+		return fmt.Errorf("local_file cannot be blank")
+	}
+	if !plugin.IsExpandable(uc.Visibility) &&
+		!util.SliceContains(artifact.ValidVisibilities, uc.Visibility) {
+		return fmt.Errorf("invalid visibility setting: %v", uc.Visibility)
+	}
+	return nil
+}
+
+// Execute expands the parameters and uploads the file, retrying on failure.
+func (uc *UploadCommand) Execute(log plugin.Logger, com plugin.PluginCommunicator,
+	conf *model.TaskConfig, stop chan bool) error {
+
+	if err := plugin.ExpandValues(uc, conf.Expansions); err != nil {
+		return err
+	}
+	if err := uc.validateParams(); err != nil {
+		return fmt.Errorf("expanded params are not valid: %v", err)
+	}
+	if !filepath.IsAbs(uc.LocalFile) {
+		uc.LocalFile = filepath.Join(conf.WorkDir, uc.LocalFile)
+	}
+	if uc.DisplayName == "" {
+		uc.DisplayName = filepath.Base(uc.LocalFile)
+	}
+
+	log.LogTask(slogger.INFO, "Uploading %v as artifact '%v'", uc.LocalFile, uc.DisplayName)
+
+	errChan := make(chan error)
+	go func() {
+		retriableUpload := util.RetriableFunc(
+			func() error {
+				err := uc.upload(com)
+				if err != nil && err != errSkippedFile {
+					log.LogExecution(slogger.ERROR, "Error uploading artifact: %v", err)
+					return util.RetriableError{err}
+				}
+				return err
+			},
+		)
+		_, err := util.RetryArithmeticBackoff(retriableUpload, maxUploadAttempts, uploadRetrySleep)
+		errChan <- err
+	}()
+
+	select {
+	case err := <-errChan:
+		if err == errSkippedFile {
+			log.LogExecution(slogger.INFO, "Artifact upload skipped optional missing file.")
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("artifact upload failed: %v", err)
+		}
+		log.LogTask(slogger.INFO, "Artifact '%v' uploaded", uc.DisplayName)
+		return nil
+	case <-stop:
+		log.LogExecution(slogger.INFO, "Received signal to terminate execution of artifact upload")
+		return nil
+	}
+}
+
+// upload makes a single attempt at sending the file to the API server.
+func (uc *UploadCommand) upload(com plugin.PluginCommunicator) error {
+	file, err := os.Open(uc.LocalFile)
+	if err != nil {
+		if os.IsNotExist(err) && uc.Optional {
+			return errSkippedFile
+		}
+		return err
+	}
+	defer file.Close()
+
+	query := url.Values{}
+	query.Set("name", uc.DisplayName)
+	query.Set("visibility", uc.Visibility)
+	resp, err := com.TaskPostStream(fmt.Sprintf("%v?%v", UploadAPIEndpoint, query.Encode()), file)
+	if resp != nil {
+		defer resp.Body.Close()
+	}
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected response (%v): %v", resp.StatusCode, string(body))
+	}
+	return nil
+}
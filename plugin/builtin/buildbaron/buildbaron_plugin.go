@@ -0,0 +1,349 @@
+// Package buildbaron helps triage task failures: it suggests the open JIRA
+// tickets that match a failed task's tests, files new tickets for failures,
+// and marks failures as known issues so that repeats of them don't alert.
+package buildbaron
+
+import (
+	"fmt"
+	"github.com/10gen-labs/slogger/v1"
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/knownissue"
+	"github.com/evergreen-ci/evergreen/plugin"
+	"github.com/evergreen-ci/evergreen/thirdparty"
+	"github.com/evergreen-ci/evergreen/util"
+	"github.com/gorilla/mux"
+	"github.com/mitchellh/mapstructure"
+	"gopkg.in/mgo.v2/bson"
+	"html/template"
+	"net/http"
+	"strings"
+	"time"
+)
+
+func init() {
+	plugin.Publish(&BuildBaronPlugin{})
+}
+
+const (
+	BuildBaronPluginName = "buildbaron"
+
+	// the issue type of the tickets filed for failures
+	TicketIssueType = "Bug"
+)
+
+// projectConfig holds the JIRA projects used for triaging one evergreen
+// project's failures.
+type projectConfig struct {
+	// TicketCreateProject is the JIRA project new tickets are filed in
+	TicketCreateProject string `mapstructure:"ticket_create_project"`
+	// TicketSearchProjects are the JIRA projects searched for open tickets
+	TicketSearchProjects []string `mapstructure:"ticket_search_projects"`
+}
+
+type buildBaronParams struct {
+	Projects map[string]projectConfig `mapstructure:"projects"`
+}
+
+// BuildBaronPlugin adds a failure triage panel to the task pages of the
+// configured projects, using the JIRA credentials in the settings.
+type BuildBaronPlugin struct {
+	Projects map[string]projectConfig
+}
+
+// Name returns the name of the plugin. Fulfills Plugin interface.
+func (bbp *BuildBaronPlugin) Name() string {
+	return BuildBaronPluginName
+}
+
+// Configure reads the JIRA projects of each evergreen project from the
+// plugin's settings.
+func (bbp *BuildBaronPlugin) Configure(conf map[string]interface{}) error {
+	params := &buildBaronParams{}
+	if err := mapstructure.Decode(conf, params); err != nil {
+		return err
+	}
+	for projectId, proj := range params.Projects {
+		if proj.TicketCreateProject == "" {
+			return fmt.Errorf("ticket_create_project must be set for project '%v'", projectId)
+		}
+		if len(proj.TicketSearchProjects) == 0 {
+			return fmt.Errorf("ticket_search_projects must be set for project '%v'", projectId)
+		}
+	}
+	bbp.Projects = params.Projects
+	return nil
+}
+
+// GetUIHandler returns the routes the triage panel calls.
+func (bbp *BuildBaronPlugin) GetUIHandler() http.Handler {
+	r := mux.NewRouter()
+	r.HandleFunc("/jira_search/{task_id}", bbp.searchHandler).Methods("GET")
+	r.HandleFunc("/file_ticket/{task_id}", bbp.fileTicketHandler).Methods("POST")
+	r.HandleFunc("/known_issues/{task_id}", bbp.addKnownIssueHandler).Methods("POST")
+	r.HandleFunc("/known_issues/{task_id}/{issue_id}", bbp.removeKnownIssueHandler).Methods("DELETE")
+	return r
+}
+
+// panelData is the data the triage panel is rendered with.
+type panelData struct {
+	TaskId        string                  `json:"task_id"`
+	FailedTests   []string                `json:"failed_tests"`
+	KnownIssues   []knownissue.KnownIssue `json:"known_issues"`
+	JiraHost      string                  `json:"jira_host"`
+	TicketProject string                  `json:"ticket_project"`
+	// the prefilled fields of a new ticket for the failure
+	Summary     string `json:"summary"`
+	Description string `json:"description"`
+}
+
+// GetPanelConfig adds the triage panel to the pages of failed tasks.
+func (bbp *BuildBaronPlugin) GetPanelConfig() (*plugin.PanelConfig, error) {
+	return &plugin.PanelConfig{
+		StaticRoot: plugin.StaticWebRootFromSourceFile(),
+		Panels: []plugin.UIPanel{
+			{
+				Page:     plugin.TaskPage,
+				Position: plugin.PageRight,
+				Includes: []template.HTML{
+					"<script type=\"text/javascript\" src=\"/plugin/buildbaron/static/js/build_baron.js\"></script>",
+				},
+				PanelHTML: "<div ng-include=\"'/plugin/buildbaron/static/partials/task_build_baron.html'\" " +
+					"ng-controller='BuildBaronCtrl' ng-show='plugins.buildbaron'></div>",
+				DataFunc: func(context plugin.UIContext) (interface{}, error) {
+					task := context.Task
+					if task == nil || task.Status != evergreen.TaskFailed {
+						return nil, nil
+					}
+					proj, ok := bbp.Projects[task.Project]
+					if !ok || context.Settings.Jira.Host == "" {
+						return nil, nil
+					}
+					return getPanelData(task, proj, &context.Settings)
+				},
+			},
+		},
+	}, nil
+}
+
+func getPanelData(task *model.Task, proj projectConfig, settings *evergreen.Settings) (*panelData, error) {
+	failedTests := task.FailedTests()
+	issues, err := knownissue.Find(knownissue.ByTask(task.Project, task.DisplayName))
+	if err != nil {
+		return nil, fmt.Errorf("error finding known issues for task: %v", err)
+	}
+	covering := []knownissue.KnownIssue{}
+	for _, issue := range issues {
+		if issue.Covers(task.BuildVariant, failedTests) {
+			covering = append(covering, issue)
+		}
+	}
+	blamelist, err := getBlamelist(task)
+	if err != nil {
+		return nil, fmt.Errorf("error finding blamelist for task: %v", err)
+	}
+	return &panelData{
+		TaskId:        task.Id,
+		FailedTests:   failedTests,
+		KnownIssues:   covering,
+		JiraHost:      settings.Jira.Host,
+		TicketProject: proj.TicketCreateProject,
+		Summary:       ticketSummary(task, failedTests),
+		Description:   ticketDescription(task, failedTests, blamelist, settings.Ui.Url),
+	}, nil
+}
+
+// getJiraHandler returns a handler using the JIRA credentials in the
+// request's settings.
+func getJiraHandler(r *http.Request) thirdparty.JiraHandler {
+	jira := plugin.MustHaveContext(r).Settings.Jira
+	return thirdparty.NewJiraHandler(jira.Host, jira.Username, jira.Password)
+}
+
+// getTriageTask loads the failed task of the request, writing an error
+// response and returning nil if it isn't found or its project isn't
+// configured for triage.
+func (bbp *BuildBaronPlugin) getTriageTask(w http.ResponseWriter, r *http.Request) (*model.Task, *projectConfig) {
+	taskId := mux.Vars(r)["task_id"]
+	task, err := model.FindTask(taskId)
+	if err != nil {
+		message := fmt.Sprintf("error finding task %v: %v", taskId, err)
+		evergreen.Logger.Errorf(slogger.ERROR, message)
+		http.Error(w, message, http.StatusInternalServerError)
+		return nil, nil
+	}
+	if task == nil {
+		http.Error(w, "task not found", http.StatusNotFound)
+		return nil, nil
+	}
+	proj, ok := bbp.Projects[task.Project]
+	if !ok {
+		http.Error(w, fmt.Sprintf("project '%v' isn't configured for failure triage", task.Project),
+			http.StatusNotFound)
+		return nil, nil
+	}
+	return task, &proj
+}
+
+// searchResult is the response of the search handler.
+type searchResult struct {
+	Search string                  `json:"search"`
+	Issues []thirdparty.JiraTicket `json:"issues"`
+}
+
+// searchHandler responds with the open tickets matching the task's failure.
+func (bbp *BuildBaronPlugin) searchHandler(w http.ResponseWriter, r *http.Request) {
+	task, proj := bbp.getTriageTask(w, r)
+	if task == nil {
+		return
+	}
+	jql := searchJQL(proj.TicketSearchProjects, searchTerms(task))
+	jira := getJiraHandler(r)
+	results, err := jira.JQLSearch(jql)
+	if err != nil {
+		message := fmt.Sprintf("error searching JIRA for task %v: %v", task.Id, err)
+		evergreen.Logger.Errorf(slogger.ERROR, message)
+		http.Error(w, message, http.StatusInternalServerError)
+		return
+	}
+	plugin.WriteJSON(w, http.StatusOK, searchResult{jql, results.Issues})
+}
+
+// fileTicketRequest is the body of a request to file a ticket.
+type fileTicketRequest struct {
+	Summary     string `json:"summary"`
+	Description string `json:"description"`
+}
+
+// fileTicketHandler files a ticket for the task's failure, as edited by the
+// user, and responds with its key.
+func (bbp *BuildBaronPlugin) fileTicketHandler(w http.ResponseWriter, r *http.Request) {
+	u := plugin.MustHaveContext(r).User
+	if u == nil {
+		http.Error(w, "not logged in", http.StatusUnauthorized)
+		return
+	}
+	task, proj := bbp.getTriageTask(w, r)
+	if task == nil {
+		return
+	}
+	req := &fileTicketRequest{}
+	if err := util.ReadJSONInto(r.Body, req); err != nil {
+		http.Error(w, fmt.Sprintf("error reading ticket: %v", err), http.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(req.Summary) == "" {
+		http.Error(w, "a summary is required", http.StatusBadRequest)
+		return
+	}
+
+	jira := getJiraHandler(r)
+	created, err := jira.CreateTicket(map[string]interface{}{
+		"project":     map[string]string{"key": proj.TicketCreateProject},
+		"issuetype":   map[string]string{"name": TicketIssueType},
+		"summary":     req.Summary,
+		"description": fmt.Sprintf("%v\n\nFiled by %v from Evergreen.", req.Description, u.DisplayName()),
+	})
+	if err != nil {
+		message := fmt.Sprintf("error filing ticket for task %v: %v", task.Id, err)
+		evergreen.Logger.Errorf(slogger.ERROR, message)
+		http.Error(w, message, http.StatusInternalServerError)
+		return
+	}
+	plugin.WriteJSON(w, http.StatusOK, created)
+}
+
+// knownIssueRequest is the body of a request to mark a failure as a known
+// issue.
+type knownIssueRequest struct {
+	Ticket string `json:"ticket"`
+	// AllVariants marks the failure as known on every variant of the task
+	AllVariants bool `json:"all_variants"`
+}
+
+// addKnownIssueHandler marks the task's failure as tracked by a ticket.
+func (bbp *BuildBaronPlugin) addKnownIssueHandler(w http.ResponseWriter, r *http.Request) {
+	u := plugin.MustHaveContext(r).User
+	if u == nil {
+		http.Error(w, "not logged in", http.StatusUnauthorized)
+		return
+	}
+	task, _ := bbp.getTriageTask(w, r)
+	if task == nil {
+		return
+	}
+	req := &knownIssueRequest{}
+	if err := util.ReadJSONInto(r.Body, req); err != nil {
+		http.Error(w, fmt.Sprintf("error reading known issue: %v", err), http.StatusBadRequest)
+		return
+	}
+	req.Ticket = strings.TrimSpace(req.Ticket)
+	jira := getJiraHandler(r)
+	ticket, err := jira.GetJIRATicket(req.Ticket)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("ticket '%v' not found: %v", req.Ticket, err), http.StatusBadRequest)
+		return
+	}
+	// known issues of resolved tickets are deleted, so they can't be added
+	if ticket.Fields != nil && ticket.Fields.Resolution != nil {
+		http.Error(w, fmt.Sprintf("ticket '%v' is already resolved", req.Ticket), http.StatusBadRequest)
+		return
+	}
+
+	issue := &knownissue.KnownIssue{
+		Id:        bson.NewObjectId(),
+		Project:   task.Project,
+		TaskName:  task.DisplayName,
+		Tests:     task.FailedTests(),
+		Ticket:    req.Ticket,
+		TaskId:    task.Id,
+		CreatedBy: u.Id,
+		CreatedAt: time.Now(),
+	}
+	if !req.AllVariants {
+		issue.BuildVariant = task.BuildVariant
+	}
+	if err := issue.Insert(); err != nil {
+		message := fmt.Sprintf("error saving known issue for task %v: %v", task.Id, err)
+		evergreen.Logger.Errorf(slogger.ERROR, message)
+		http.Error(w, message, http.StatusInternalServerError)
+		return
+	}
+	plugin.WriteJSON(w, http.StatusOK, issue)
+}
+
+// removeKnownIssueHandler removes a known issue of the task, so that its
+// failures alert again.
+func (bbp *BuildBaronPlugin) removeKnownIssueHandler(w http.ResponseWriter, r *http.Request) {
+	if plugin.MustHaveContext(r).User == nil {
+		http.Error(w, "not logged in", http.StatusUnauthorized)
+		return
+	}
+	task, _ := bbp.getTriageTask(w, r)
+	if task == nil {
+		return
+	}
+	id := mux.Vars(r)["issue_id"]
+	if !bson.IsObjectIdHex(id) {
+		http.Error(w, fmt.Sprintf("invalid known issue id '%v'", id), http.StatusBadRequest)
+		return
+	}
+	issue, err := knownissue.FindOne(knownissue.ById(bson.ObjectIdHex(id)))
+	if err != nil {
+		message := fmt.Sprintf("error finding known issue %v: %v", id, err)
+		evergreen.Logger.Errorf(slogger.ERROR, message)
+		http.Error(w, message, http.StatusInternalServerError)
+		return
+	}
+	if issue == nil || issue.Project != task.Project || issue.TaskName != task.DisplayName {
+		http.Error(w, "known issue not found", http.StatusNotFound)
+		return
+	}
+	if err = knownissue.Remove(issue.Id); err != nil {
+		message := fmt.Sprintf("error removing known issue %v: %v", id, err)
+		evergreen.Logger.Errorf(slogger.ERROR, message)
+		http.Error(w, message, http.StatusInternalServerError)
+		return
+	}
+	plugin.WriteJSON(w, http.StatusOK, "removed known issue")
+}
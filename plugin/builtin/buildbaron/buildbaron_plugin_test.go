@@ -0,0 +1,84 @@
+package buildbaron
+
+import (
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/version"
+	. "github.com/smartystreets/goconvey/convey"
+	"strings"
+	"testing"
+)
+
+func TestConfigure(t *testing.T) {
+	Convey("With the build baron plugin", t, func() {
+		bbp := &BuildBaronPlugin{}
+
+		Convey("projects should be read from the settings", func() {
+			err := bbp.Configure(map[string]interface{}{
+				"projects": map[interface{}]interface{}{
+					"mongodb-mongo-master": map[interface{}]interface{}{
+						"ticket_create_project":  "BF",
+						"ticket_search_projects": []interface{}{"BF", "SERVER"},
+					},
+				},
+			})
+			So(err, ShouldBeNil)
+			So(bbp.Projects["mongodb-mongo-master"].TicketCreateProject, ShouldEqual, "BF")
+			So(bbp.Projects["mongodb-mongo-master"].TicketSearchProjects, ShouldResemble,
+				[]string{"BF", "SERVER"})
+		})
+
+		Convey("projects without JIRA projects should be an error", func() {
+			err := bbp.Configure(map[string]interface{}{
+				"projects": map[interface{}]interface{}{
+					"mongodb-mongo-master": map[interface{}]interface{}{
+						"ticket_create_project": "BF",
+					},
+				},
+			})
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestTriage(t *testing.T) {
+	Convey("With a task with failed tests", t, func() {
+		task := &model.Task{
+			Id:           "t1",
+			DisplayName:  "core",
+			BuildVariant: "linux-64",
+			Revision:     "abcdef1234567890",
+			TestResults: []model.TestResult{
+				{Status: evergreen.TestFailedStatus, TestFile: "jstests/core/a.js", URL: "http://logs/a"},
+				{Status: evergreen.TestSucceededStatus, TestFile: "jstests/core/b.js"},
+				{Status: evergreen.TestFailedStatus, TestFile: "jstests\\core\\c.js"},
+			},
+		}
+
+		Convey("JIRA should be searched for the failed tests' names", func() {
+			terms := searchTerms(task)
+			So(terms, ShouldResemble, []string{"a.js", "c.js"})
+			So(searchJQL([]string{"BF", "SERVER"}, terms), ShouldEqual,
+				`project in (BF, SERVER) and resolution is EMPTY and `+
+					`(text~"\"a.js\"" or text~"\"c.js\"") order by updatedDate desc`)
+		})
+
+		Convey("JIRA should be searched for the task without failed tests", func() {
+			task.TestResults = nil
+			So(searchTerms(task), ShouldResemble, []string{"core"})
+		})
+
+		Convey("new tickets should be prefilled with the failure", func() {
+			failed := task.FailedTests()
+			So(ticketSummary(task, failed), ShouldEqual, "core failed on linux-64: a.js, c.js")
+
+			blamelist := []version.Version{{Revision: "abcdef1234567890", Author: "me", Message: "fix it\nplease"}}
+			description := ticketDescription(task, failed, blamelist, "http://evergreen")
+			So(description, ShouldContainSubstring, "[core|http://evergreen/task/t1/0]")
+			So(description, ShouldContainSubstring, "* [jstests/core/a.js|http://logs/a]")
+			So(description, ShouldNotContainSubstring, "b.js")
+			So(description, ShouldContainSubstring, "* abcdef1234 me: fix it")
+			So(strings.Contains(description, "please"), ShouldBeFalse)
+		})
+	})
+}
@@ -0,0 +1,138 @@
+package buildbaron
+
+import (
+	"fmt"
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/version"
+	"github.com/evergreen-ci/evergreen/util"
+	"gopkg.in/mgo.v2/bson"
+	"path"
+	"strings"
+)
+
+const (
+	// the most failed tests searched for, listed in a ticket summary, and
+	// versions listed in a ticket's blamelist
+	maxSearchTerms    = 10
+	maxSummaryTests   = 3
+	maxBlamelistItems = 20
+)
+
+// searchTerms returns the terms to search JIRA for the task's failure: the
+// names of its failed tests without their directories, or the task's name if
+// no tests failed.
+func searchTerms(task *model.Task) []string {
+	terms := []string{}
+	for _, test := range task.FailedTests() {
+		name := testName(test)
+		if len(terms) < maxSearchTerms && !util.SliceContains(terms, name) {
+			terms = append(terms, name)
+		}
+	}
+	if len(terms) == 0 {
+		terms = append(terms, task.DisplayName)
+	}
+	return terms
+}
+
+// testName returns the name of the test file without its directories.
+func testName(test string) string {
+	return path.Base(strings.Replace(test, "\\", "/", -1))
+}
+
+// jqlQuote quotes the string as an exact phrase for a JQL text search.
+func jqlQuote(s string) string {
+	s = strings.Replace(s, "\\", "\\\\", -1)
+	s = strings.Replace(s, "\"", "\\\"", -1)
+	return fmt.Sprintf("\"\\\"%v\\\"\"", s)
+}
+
+// searchJQL returns the JQL query for the unresolved tickets in the projects
+// that mention any of the terms, most recently updated first.
+func searchJQL(projects []string, terms []string) string {
+	textClauses := []string{}
+	for _, term := range terms {
+		textClauses = append(textClauses, fmt.Sprintf("text~%v", jqlQuote(term)))
+	}
+	return fmt.Sprintf("project in (%v) and resolution is EMPTY and (%v) order by updatedDate desc",
+		strings.Join(projects, ", "), strings.Join(textClauses, " or "))
+}
+
+// ticketSummary returns the summary of a new ticket for the task's failure.
+func ticketSummary(task *model.Task, failedTests []string) string {
+	summary := fmt.Sprintf("%v failed on %v", task.DisplayName, task.BuildVariant)
+	if len(failedTests) == 0 {
+		return summary
+	}
+	names := []string{}
+	for i, test := range failedTests {
+		if i == maxSummaryTests {
+			names = append(names, fmt.Sprintf("%v more", len(failedTests)-maxSummaryTests))
+			break
+		}
+		names = append(names, testName(test))
+	}
+	return fmt.Sprintf("%v: %v", summary, strings.Join(names, ", "))
+}
+
+// ticketDescription returns the description of a new ticket for the task's
+// failure, in JIRA's wiki markup.
+func ticketDescription(task *model.Task, failedTests []string, blamelist []version.Version,
+	uiRoot string) string {
+	lines := []string{
+		fmt.Sprintf("*Task:* [%v|%v/task/%v/%v]", task.DisplayName, uiRoot, task.Id, task.Execution),
+		fmt.Sprintf("*Variant:* %v", task.BuildVariant),
+		fmt.Sprintf("*Revision:* %v", task.Revision),
+		fmt.Sprintf("*Logs:* [all logs|%v/task_log_raw/%v/%v?type=ALL]", uiRoot, task.Id, task.Execution),
+	}
+	if len(failedTests) != 0 {
+		lines = append(lines, "", "*Failed tests:*")
+		for _, test := range task.TestResults {
+			if test.Status != evergreen.TestFailedStatus {
+				continue
+			}
+			if test.URL != "" {
+				lines = append(lines, fmt.Sprintf("* [%v|%v]", test.TestFile, test.URL))
+			} else {
+				lines = append(lines, fmt.Sprintf("* %v", test.TestFile))
+			}
+		}
+	}
+	if len(blamelist) != 0 {
+		lines = append(lines, "", "*Blamelist:*")
+		for _, v := range blamelist {
+			revision := v.Revision
+			if len(revision) > 10 {
+				revision = revision[0:10]
+			}
+			message := strings.SplitN(v.Message, "\n", 2)[0]
+			lines = append(lines, fmt.Sprintf("* %v %v: %v", revision, v.Author, message))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// getBlamelist returns the mainline versions since the last success of the
+// task, up to and including its own, newest first. Patch tasks have no
+// blamelist.
+func getBlamelist(task *model.Task) ([]version.Version, error) {
+	if task.Requester != evergreen.RepotrackerVersionRequester {
+		return nil, nil
+	}
+	previous, err := model.PreviousCompletedTask(task, task.Project, []string{evergreen.TaskSucceeded})
+	if err != nil {
+		return nil, err
+	}
+	orders := bson.M{"$lte": task.RevisionOrderNumber}
+	if previous != nil {
+		orders["$gt"] = previous.RevisionOrderNumber
+	}
+	return version.Find(db.Query(bson.M{
+		version.IdentifierKey:          task.Project,
+		version.RequesterKey:           evergreen.RepotrackerVersionRequester,
+		version.RevisionOrderNumberKey: orders,
+	}).WithFields(version.RevisionKey, version.AuthorKey, version.MessageKey).
+		Sort([]string{"-" + version.RevisionOrderNumberKey}).Limit(maxBlamelistItems))
+}
@@ -0,0 +1,71 @@
+mciModule.controller('BuildBaronCtrl', function($scope, $http, $window) {
+  $scope.bb = $window.plugins.buildbaron;
+  if (!$scope.bb) {
+    return;
+  }
+  $scope.haveUser = !!$window.have_user;
+  $scope.tickets = [];
+  $scope.newTicket = {
+    summary: $scope.bb.summary,
+    description: $scope.bb.description
+  };
+  var root = '/plugin/buildbaron/';
+
+  $scope.searching = true;
+  $http.get(root + 'jira_search/' + $scope.bb.task_id).
+  success(function(data) {
+    $scope.searching = false;
+    $scope.tickets = data.issues || [];
+  }).
+  error(function(data) {
+    $scope.searching = false;
+    $scope.searchError = 'Error searching JIRA: ' + data;
+  });
+
+  $scope.addKnownIssue = function(ticket) {
+    if (!ticket) {
+      return;
+    }
+    $http.post(root + 'known_issues/' + $scope.bb.task_id, {
+      ticket: ticket,
+      all_variants: !!$scope.allVariants
+    }).
+    success(function(issue) {
+      $scope.bb.known_issues = ($scope.bb.known_issues || []).concat([issue]);
+      $scope.knownTicket = '';
+      $scope.actionError = '';
+    }).
+    error(function(data) {
+      $scope.actionError = 'Error marking known issue: ' + data;
+    });
+  };
+
+  $scope.removeKnownIssue = function(issue) {
+    $http.delete(root + 'known_issues/' + $scope.bb.task_id + '/' + issue.id).
+    success(function() {
+      $scope.bb.known_issues = _.reject($scope.bb.known_issues, function(i) {
+        return i.id == issue.id;
+      });
+      $scope.actionError = '';
+    }).
+    error(function(data) {
+      $scope.actionError = 'Error removing known issue: ' + data;
+    });
+  };
+
+  $scope.fileTicket = function() {
+    $scope.filing = true;
+    $http.post(root + 'file_ticket/' + $scope.bb.task_id, $scope.newTicket).
+    success(function(created) {
+      $scope.filing = false;
+      $scope.showTicketForm = false;
+      $scope.filedTicket = created.key;
+      $scope.knownTicket = created.key;
+      $scope.actionError = '';
+    }).
+    error(function(data) {
+      $scope.filing = false;
+      $scope.actionError = 'Error filing ticket: ' + data;
+    });
+  };
+});
@@ -66,7 +66,7 @@ func FetchVarsHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "task not found", http.StatusNotFound)
 		return
 	}
-	projectVars, err := model.FindOneProjectVars(task.Project)
+	projectVars, err := model.FindMergedProjectVars(task.Project)
 	if err != nil {
 		message := fmt.Sprintf("Failed to fetch vars for task %v: %v", task.Id, err)
 		evergreen.Logger.Logf(slogger.ERROR, message)
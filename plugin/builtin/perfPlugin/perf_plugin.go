@@ -0,0 +1,276 @@
+package perfPlugin
+
+import (
+	"fmt"
+	"github.com/10gen-labs/slogger/v1"
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/alerts"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/perf"
+	"github.com/evergreen-ci/evergreen/plugin"
+	"github.com/evergreen-ci/evergreen/util"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+func init() {
+	plugin.Publish(&PerfPlugin{})
+}
+
+const (
+	PerfPluginName = "perf"
+	PerfSendCmd    = "send"
+
+	PerfResultsAPIEndpoint = "results"
+
+	PerfPostRetries   = 5
+	PerfRetrySleepSec = 10 * time.Second
+
+	// number of revisions charted on the task page
+	PerfHistoryLength = 20
+)
+
+// SendRequest is the body of a request to the results endpoint.
+type SendRequest struct {
+	Results []perf.Result        `json:"results"`
+	Options perf.DetectorOptions `json:"options"`
+}
+
+// PerfPlugin stores benchmark results reported by tasks, charts them across
+// revisions on the task page, and alerts the project when a result regresses.
+type PerfPlugin struct{}
+
+// Name returns the name of the plugin. Fulfills Plugin interface.
+func (pp *PerfPlugin) Name() string {
+	return PerfPluginName
+}
+
+func (pp *PerfPlugin) Configure(map[string]interface{}) error {
+	return nil
+}
+
+// NewCommand returns commands of the given name.
+// Fulfills Plugin interface.
+func (pp *PerfPlugin) NewCommand(cmdName string) (plugin.Command, error) {
+	if cmdName == PerfSendCmd {
+		return &SendCommand{}, nil
+	}
+	return nil, fmt.Errorf("No such %v command: %v", PerfPluginName, cmdName)
+}
+
+func (pp *PerfPlugin) GetAPIHandler() http.Handler {
+	r := http.NewServeMux()
+	r.HandleFunc(fmt.Sprintf("/%v", PerfResultsAPIEndpoint), resultsHandler)
+	r.HandleFunc("/", http.NotFound) // 404 any request not routable to these endpoints
+	return r
+}
+
+func (pp *PerfPlugin) GetUIHandler() http.Handler {
+	return nil
+}
+
+// GetPanelConfig adds a panel charting the task's results to the task page.
+func (pp *PerfPlugin) GetPanelConfig() (*plugin.PanelConfig, error) {
+	return &plugin.PanelConfig{
+		StaticRoot: plugin.StaticWebRootFromSourceFile(),
+		Panels: []plugin.UIPanel{
+			{
+				Page:     plugin.TaskPage,
+				Position: plugin.PageCenter,
+				PanelHTML: "<div ng-include=\"'/plugin/perf/static/partials/task_perf_panel.html'\" " +
+					"ng-init='perfSeries=plugins.perf' ng-show='plugins.perf.length'></div>",
+				DataFunc: func(context plugin.UIContext) (interface{}, error) {
+					if context.Task == nil {
+						return nil, nil
+					}
+					return taskSeries(context.Task)
+				},
+			},
+		},
+	}, nil
+}
+
+// resultsHandler stores the results sent by a task and, for mainline
+// tasks, checks them against the task's history for regressions.
+func resultsHandler(w http.ResponseWriter, r *http.Request) {
+	task := plugin.GetTask(r)
+	if task == nil {
+		http.Error(w, "task not found", http.StatusNotFound)
+		return
+	}
+	req := &SendRequest{}
+	if err := util.ReadJSONInto(r.Body, req); err != nil {
+		http.Error(w, fmt.Sprintf("error reading perf results: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	taskPerf := &perf.TaskPerf{
+		TaskId:              task.Id,
+		Execution:           task.Execution,
+		Project:             task.Project,
+		BuildVariant:        task.BuildVariant,
+		TaskName:            task.DisplayName,
+		Revision:            task.Revision,
+		RevisionOrderNumber: task.RevisionOrderNumber,
+		Requester:           task.Requester,
+		CreateTime:          time.Now(),
+		Results:             req.Results,
+	}
+	if err := taskPerf.Upsert(); err != nil {
+		message := fmt.Sprintf("error storing perf results for task %v: %v", task.Id, err)
+		evergreen.Logger.Errorf(slogger.ERROR, message)
+		http.Error(w, message, http.StatusInternalServerError)
+		return
+	}
+
+	regressions := []perf.Regression{}
+	if task.Requester == evergreen.RepotrackerVersionRequester {
+		var err error
+		regressions, err = findRegressions(taskPerf, req.Options)
+		if err != nil {
+			message := fmt.Sprintf("error checking perf results of task %v for regressions: %v", task.Id, err)
+			evergreen.Logger.Errorf(slogger.ERROR, message)
+			http.Error(w, message, http.StatusInternalServerError)
+			return
+		}
+	}
+	if len(regressions) > 0 {
+		if err := alerts.RunPerfRegressionTriggers(task, describeRegressions(regressions)); err != nil {
+			evergreen.Logger.Errorf(slogger.ERROR, "error queueing perf regression alert for task %v: %v",
+				task.Id, err)
+		}
+	}
+	plugin.WriteJSON(w, http.StatusOK, regressions)
+}
+
+// findRegressions runs the regression detector against the task's history.
+func findRegressions(taskPerf *perf.TaskPerf, opts perf.DetectorOptions) ([]perf.Regression, error) {
+	window := opts.Window
+	if window <= 0 {
+		window = perf.DefaultWindow
+	}
+	// the history includes the task itself, which the detector skips
+	history, err := perf.FindAll(perf.ByTaskHistory(taskPerf.Project, taskPerf.BuildVariant,
+		taskPerf.TaskName, taskPerf.RevisionOrderNumber, window+1))
+	if err != nil {
+		return nil, err
+	}
+	return perf.DetectRegressions(*taskPerf, history, opts), nil
+}
+
+// describeRegressions returns a summary of the regressions for the alert.
+func describeRegressions(regressions []perf.Regression) string {
+	lines := []string{}
+	for _, r := range regressions {
+		lines = append(lines, fmt.Sprintf("%v %v (%v threads): %.4g, %.1f%% worse than the recent average of %.4g",
+			r.Result.Name, r.Result.Metric, r.Result.Threads, r.Result.Value, r.PercentWorse, r.Baseline))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// seriesPoint is a result at one revision, positioned on a chart whose
+// axes both run from 0 to 1.
+type seriesPoint struct {
+	TaskId   string  `json:"task_id"`
+	Revision string  `json:"revision"`
+	Value    float64 `json:"value"`
+	X        float64 `json:"x"`
+	Y        float64 `json:"y"`
+	// PrevX and PrevY position the previous point, for drawing the line to it
+	PrevX float64 `json:"prev_x"`
+	PrevY float64 `json:"prev_y"`
+}
+
+// series holds the history of one benchmark metric, oldest first.
+type series struct {
+	Name    string        `json:"name"`
+	Metric  string        `json:"metric"`
+	Threads int           `json:"threads"`
+	Min     float64       `json:"min"`
+	Max     float64       `json:"max"`
+	Points  []seriesPoint `json:"points"`
+	// Current is the point for the task being viewed
+	Current *seriesPoint `json:"current"`
+}
+
+// taskSeries returns the charts for the task page, or nil if the task
+// didn't send any results.
+func taskSeries(task *model.Task) ([]series, error) {
+	current, err := perf.FindOne(perf.ByTaskId(task.Id))
+	if err != nil {
+		return nil, fmt.Errorf("error finding perf results for task: %v", err)
+	}
+	if current == nil {
+		return nil, nil
+	}
+	history, err := perf.FindAll(perf.ByTaskHistory(task.Project, task.BuildVariant, task.DisplayName,
+		task.RevisionOrderNumber, PerfHistoryLength))
+	if err != nil {
+		return nil, fmt.Errorf("error finding perf history for task: %v", err)
+	}
+	// patch results aren't part of the mainline history, so add them on the end
+	if task.Requester != evergreen.RepotrackerVersionRequester {
+		history = append([]perf.TaskPerf{*current}, history...)
+	}
+	return buildSeries(history, current.TaskId), nil
+}
+
+// buildSeries groups the results in the history, which is sorted newest
+// first, into one series per benchmark metric.
+func buildSeries(history []perf.TaskPerf, currentId string) []series {
+	byKey := map[string]*series{}
+	keys := []string{}
+	for i := len(history) - 1; i >= 0; i-- {
+		for _, r := range history[i].Results {
+			key := r.SeriesKey()
+			s, ok := byKey[key]
+			if !ok {
+				s = &series{Name: r.Name, Metric: r.Metric, Threads: r.Threads, Min: r.Value, Max: r.Value}
+				byKey[key] = s
+				keys = append(keys, key)
+			}
+			if r.Value < s.Min {
+				s.Min = r.Value
+			}
+			if r.Value > s.Max {
+				s.Max = r.Value
+			}
+			s.Points = append(s.Points, seriesPoint{
+				TaskId:   history[i].TaskId,
+				Revision: history[i].Revision,
+				Value:    r.Value,
+			})
+		}
+	}
+	sort.Strings(keys)
+
+	allSeries := []series{}
+	for _, key := range keys {
+		s := byKey[key]
+		for i := range s.Points {
+			p := &s.Points[i]
+			p.X, p.Y = 0.5, 0.5
+			if len(s.Points) > 1 {
+				p.X = float64(i) / float64(len(s.Points)-1)
+			}
+			if s.Max > s.Min {
+				// larger values are drawn higher up the chart
+				p.Y = 1 - (p.Value-s.Min)/(s.Max-s.Min)
+			}
+			p.PrevX, p.PrevY = p.X, p.Y
+			if i > 0 {
+				p.PrevX, p.PrevY = s.Points[i-1].X, s.Points[i-1].Y
+			}
+			if p.TaskId == currentId {
+				s.Current = p
+			}
+		}
+		// only chart the metrics the task being viewed reported
+		if s.Current != nil {
+			allSeries = append(allSeries, *s)
+		}
+	}
+	return allSeries
+}
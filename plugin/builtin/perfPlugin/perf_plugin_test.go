@@ -0,0 +1,49 @@
+package perfPlugin
+
+import (
+	"github.com/evergreen-ci/evergreen/model/perf"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestBuildSeries(t *testing.T) {
+	Convey("With results from three revisions, newest first", t, func() {
+		history := []perf.TaskPerf{
+			{TaskId: "t3", Results: []perf.Result{{Name: "insert", Metric: "ops", Value: 300}}},
+			{TaskId: "t2", Results: []perf.Result{{Name: "insert", Metric: "ops", Value: 100}}},
+			{TaskId: "t1", Results: []perf.Result{
+				{Name: "insert", Metric: "ops", Value: 200},
+				{Name: "remove", Metric: "ops", Value: 50},
+			}},
+		}
+
+		Convey("only series reported by the current task should be charted", func() {
+			allSeries := buildSeries(history, "t3")
+			So(len(allSeries), ShouldEqual, 1)
+			s := allSeries[0]
+			So(s.Name, ShouldEqual, "insert")
+			So(s.Min, ShouldEqual, 100)
+			So(s.Max, ShouldEqual, 300)
+
+			Convey("with points oldest first, scaled to the chart", func() {
+				So(len(s.Points), ShouldEqual, 3)
+				So(s.Points[0].TaskId, ShouldEqual, "t1")
+				So(s.Points[0].X, ShouldEqual, 0)
+				So(s.Points[0].Y, ShouldEqual, 0.5)
+				So(s.Points[1].Y, ShouldEqual, 1)
+				So(s.Points[1].PrevX, ShouldEqual, 0)
+				So(s.Points[2].X, ShouldEqual, 1)
+				So(s.Points[2].Y, ShouldEqual, 0)
+				So(s.Current.TaskId, ShouldEqual, "t3")
+			})
+		})
+
+		Convey("a series with a single point should be centered", func() {
+			allSeries := buildSeries(history, "t1")
+			So(len(allSeries), ShouldEqual, 2)
+			So(allSeries[1].Name, ShouldEqual, "remove")
+			So(allSeries[1].Points[0].X, ShouldEqual, 0.5)
+			So(allSeries[1].Points[0].Y, ShouldEqual, 0.5)
+		})
+	})
+}
@@ -0,0 +1,165 @@
+package perfPlugin
+
+import (
+	"fmt"
+	"github.com/10gen-labs/slogger/v1"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/perf"
+	"github.com/evergreen-ci/evergreen/plugin"
+	"github.com/evergreen-ci/evergreen/util"
+	"github.com/mitchellh/mapstructure"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// SendCommand reads benchmark results from a json file and sends them to
+// the API server. The file holds a list of results, e.g.
+//  {"results": [
+//    {"name": "insert", "metric": "ops_per_sec", "value": 10230.5, "threads": 8}
+//  ]}
+type SendCommand struct {
+	// File is the path to the results file, relative to the working directory
+	File string `mapstructure:"file" plugin:"expand"`
+
+	// Threshold is the percent a result may be worse than the average of the
+	// previous revisions before an alert is raised. Defaults to 10.
+	Threshold float64 `mapstructure:"threshold"`
+
+	// Window is the number of previous revisions to average. Defaults to 5,
+	// and must be at least 3 so that there is enough history to compare with.
+	Window int `mapstructure:"window"`
+
+	// LowerIsBetter lists the metrics, such as latencies, that improve as
+	// they get smaller. All other metrics are expected to grow.
+	LowerIsBetter []string `mapstructure:"lower_is_better"`
+}
+
+// resultsFile is the format of the file read by the command.
+type resultsFile struct {
+	Results []perf.Result `json:"results"`
+}
+
+func (sc *SendCommand) Name() string {
+	return PerfSendCmd
+}
+
+func (sc *SendCommand) Plugin() string {
+	return PerfPluginName
+}
+
+// ParseParams decodes and validates the command's parameters.
+func (sc *SendCommand) ParseParams(params map[string]interface{}) error {
+	if err := mapstructure.Decode(params, sc); err != nil {
+		return fmt.Errorf("error decoding %v params: %v", sc.Name(), err)
+	}
+	if sc.File == "" {
+		return fmt.Errorf("error validating %v params: file cannot be blank", sc.Name())
+	}
+	if sc.Threshold < 0 || sc.Window < 0 {
+		return fmt.Errorf("error validating %v params: threshold and window cannot be negative", sc.Name())
+	}
+	if sc.Window != 0 && sc.Window < perf.MinHistoryPoints {
+		return fmt.Errorf("error validating %v params: window must be at least %v",
+			sc.Name(), perf.MinHistoryPoints)
+	}
+	return nil
+}
+
+// Execute reads the results file and posts its contents, retrying on failure.
+func (sc *SendCommand) Execute(log plugin.Logger, com plugin.PluginCommunicator,
+	conf *model.TaskConfig, stop chan bool) error {
+
+	if err := plugin.ExpandValues(sc, conf.Expansions); err != nil {
+		return err
+	}
+	results, err := readResults(filepath.Join(conf.WorkDir, sc.File))
+	if err != nil {
+		return err
+	}
+	req := SendRequest{
+		Results: results,
+		Options: perf.DetectorOptions{
+			Threshold:     sc.Threshold,
+			Window:        sc.Window,
+			LowerIsBetter: sc.LowerIsBetter,
+		},
+	}
+
+	log.LogTask(slogger.INFO, "Sending %v performance results", len(results))
+	errChan := make(chan error)
+	go func() {
+		errChan <- sc.send(log, com, req)
+	}()
+
+	select {
+	case err := <-errChan:
+		return err
+	case <-stop:
+		log.LogExecution(slogger.INFO, "Received signal to terminate execution of perf send command")
+		return nil
+	}
+}
+
+// readResults reads and validates the results file.
+func readResults(path string) ([]perf.Result, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't open results file: %v", err)
+	}
+	defer file.Close()
+	contents := &resultsFile{}
+	if err = util.ReadJSONInto(file, contents); err != nil {
+		return nil, fmt.Errorf("couldn't read results file: %v", err)
+	}
+	for i, r := range contents.Results {
+		if r.Name == "" || r.Metric == "" {
+			return nil, fmt.Errorf("result %v in %v is missing a name or metric", i, path)
+		}
+	}
+	return contents.Results, nil
+}
+
+// send posts the results and logs any regressions the server found.
+func (sc *SendCommand) send(log plugin.Logger, com plugin.PluginCommunicator, req SendRequest) error {
+	regressions := []perf.Regression{}
+	retriableSend := util.RetriableFunc(
+		func() error {
+			resp, err := com.TaskPostJSON(PerfResultsAPIEndpoint, req)
+			if resp != nil {
+				defer resp.Body.Close()
+			}
+			if err != nil {
+				log.LogExecution(slogger.WARN, "error posting perf results: %v", err)
+				return util.RetriableError{err}
+			}
+			if resp.StatusCode == http.StatusBadRequest {
+				body, _ := ioutil.ReadAll(resp.Body)
+				return fmt.Errorf("error posting perf results: %v", string(body))
+			}
+			if resp.StatusCode != http.StatusOK {
+				body, _ := ioutil.ReadAll(resp.Body)
+				err = fmt.Errorf("error posting perf results (%v): %v", resp.StatusCode, string(body))
+				log.LogExecution(slogger.WARN, "%v", err)
+				return util.RetriableError{err}
+			}
+			return util.ReadJSONInto(resp.Body, &regressions)
+		},
+	)
+
+	retryFail, err := util.Retry(retriableSend, PerfPostRetries, PerfRetrySleepSec)
+	if retryFail {
+		return fmt.Errorf("sending perf results failed after %v tries: %v", PerfPostRetries, err)
+	}
+	if err != nil {
+		return fmt.Errorf("sending perf results failed: %v", err)
+	}
+
+	for _, r := range regressions {
+		log.LogTask(slogger.WARN, "Possible regression in %v %v (%v threads): %v is %.1f%% worse than %v",
+			r.Result.Name, r.Result.Metric, r.Result.Threads, r.Result.Value, r.PercentWorse, r.Baseline)
+	}
+	log.LogTask(slogger.INFO, "Sending performance results succeeded")
+	return nil
+}
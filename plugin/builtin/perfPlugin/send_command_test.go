@@ -0,0 +1,74 @@
+package perfPlugin_test
+
+import (
+	"github.com/10gen-labs/slogger/v1"
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/agent"
+	"github.com/evergreen-ci/evergreen/apiserver"
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/evergreen-ci/evergreen/model/perf"
+	"github.com/evergreen-ci/evergreen/plugin"
+	. "github.com/evergreen-ci/evergreen/plugin/builtin/perfPlugin"
+	"github.com/evergreen-ci/evergreen/plugin/plugintest"
+	"github.com/evergreen-ci/evergreen/testutil"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestPerfSend(t *testing.T) {
+	testConfig := evergreen.TestConfig()
+	db.SetGlobalSessionProvider(db.SessionFactoryFromConfig(testConfig))
+
+	Convey("With a running api server and the perf plugin", t, func() {
+		testutil.HandleTestingErr(db.Clear(perf.Collection), t, "error clearing perf results")
+		registry := plugin.NewSimpleRegistry()
+		perfPlugin := &PerfPlugin{}
+		testutil.HandleTestingErr(registry.Register(perfPlugin), t, "couldn't register plugin")
+		server, err := apiserver.CreateTestServer(testConfig, nil, plugin.APIPlugins, true)
+		testutil.HandleTestingErr(err, t, "couldn't set up testing server")
+		defer server.Close()
+
+		taskConfig, err := plugintest.CreateTestConfig("testdata/plugin_perf.yml", t)
+		testutil.HandleTestingErr(err, t, "couldn't create test config")
+		taskConfig.WorkDir = "."
+		logger := agent.NewTestLogger(&evergreen.SliceAppender{[]*slogger.Log{}})
+		httpCom := plugintest.TestAgentCommunicator(taskConfig.Task.Id, taskConfig.Task.Secret, server.URL)
+		pluginCom := &agent.TaskJSONCommunicator{perfPlugin.Name(), httpCom}
+
+		Convey("the results in the file should be stored with the task", func() {
+			for _, task := range taskConfig.Project.Tasks {
+				for _, command := range task.Commands {
+					pluginCmds, err := registry.GetCommands(command, taskConfig.Project.Functions)
+					testutil.HandleTestingErr(err, t, "couldn't get plugin command")
+					So(pluginCmds, ShouldNotBeNil)
+					So(pluginCmds[0].Execute(logger, pluginCom, taskConfig, make(chan bool)), ShouldBeNil)
+				}
+			}
+
+			taskPerf, err := perf.FindOne(perf.ByTaskId(taskConfig.Task.Id))
+			So(err, ShouldBeNil)
+			So(taskPerf, ShouldNotBeNil)
+			So(taskPerf.TaskName, ShouldEqual, taskConfig.Task.DisplayName)
+			So(len(taskPerf.Results), ShouldEqual, 3)
+			So(taskPerf.Results[1].Threads, ShouldEqual, 8)
+			So(taskPerf.Results[2].Value, ShouldEqual, 3.2)
+		})
+	})
+}
+
+func TestPerfSendParseParams(t *testing.T) {
+	Convey("When parsing the params of a perf.send command", t, func() {
+		sc := &SendCommand{}
+
+		Convey("a window too small to detect regressions should be an error", func() {
+			So(sc.ParseParams(map[string]interface{}{"file": "perf.json", "window": 2}),
+				ShouldNotBeNil)
+		})
+
+		Convey("the default or a large enough window should be accepted", func() {
+			So(sc.ParseParams(map[string]interface{}{"file": "perf.json"}), ShouldBeNil)
+			So(sc.ParseParams(map[string]interface{}{"file": "perf.json",
+				"window": perf.MinHistoryPoints}), ShouldBeNil)
+		})
+	})
+}
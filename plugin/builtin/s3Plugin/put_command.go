@@ -281,6 +281,8 @@ func (s3pc *S3PutCommand) AttachTaskFiles(log plugin.Logger,
 		Name:       displayName,
 		Link:       fileLink,
 		Visibility: s3pc.Visibility,
+		Bucket:     s3pc.Bucket,
+		FileKey:    remoteFile,
 	}
 
 	err := com.PostTaskFiles([]*artifact.File{file})
@@ -399,8 +399,10 @@ func (c *S3CopyCommand) AttachTaskFiles(pluginLogger plugin.Logger,
 
 	pluginLogger.LogExecution(slogger.INFO, "attaching file with name %v", displayName)
 	file := artifact.File{
-		Name: displayName,
-		Link: fileLink,
+		Name:    displayName,
+		Link:    fileLink,
+		Bucket:  request.S3DestinationBucket,
+		FileKey: remotePath,
 	}
 
 	files := []*artifact.File{&file}
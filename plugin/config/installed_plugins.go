@@ -17,10 +17,13 @@ package config
 
 // ===== PLUGINS INCLUDED WITH MCI =====
 import _ "github.com/evergreen-ci/evergreen/plugin/builtin/archive"
+import _ "github.com/evergreen-ci/evergreen/plugin/builtin/artifactPlugin"
 import _ "github.com/evergreen-ci/evergreen/plugin/builtin/attach"
+import _ "github.com/evergreen-ci/evergreen/plugin/builtin/buildbaron"
 import _ "github.com/evergreen-ci/evergreen/plugin/builtin/expansions"
 import _ "github.com/evergreen-ci/evergreen/plugin/builtin/git"
 import _ "github.com/evergreen-ci/evergreen/plugin/builtin/helloworld"
+import _ "github.com/evergreen-ci/evergreen/plugin/builtin/perfPlugin"
 import _ "github.com/evergreen-ci/evergreen/plugin/builtin/gotest"
 import _ "github.com/evergreen-ci/evergreen/plugin/builtin/s3Plugin"
 import _ "github.com/evergreen-ci/evergreen/plugin/builtin/s3copy"
@@ -6,6 +6,7 @@ import (
 	"github.com/10gen-labs/slogger/v1"
 	"github.com/evergreen-ci/evergreen/model"
 	"github.com/evergreen-ci/evergreen/model/artifact"
+	"github.com/evergreen-ci/evergreen/model/user"
 	"github.com/gorilla/context"
 	"io"
 	"net/http"
@@ -89,6 +90,9 @@ type PluginCommunicator interface {
 	// Make a GET request to the given endpoint with content type "application/json"
 	TaskGetJSON(endpoint string) (*http.Response, error)
 
+	// Make a POST request to the given endpoint, streaming 'data' as the raw request body
+	TaskPostStream(endpoint string, data io.Reader) (*http.Response, error)
+
 	// Make a POST request against the results api endpoint
 	TaskPostResults(results *model.TestResults) error
 
@@ -230,6 +234,25 @@ func GetTask(request *http.Request) *model.Task {
 	return nil
 }
 
+type pluginUserContext int
+
+const pluginUserContextKey pluginUserContext = 0
+
+// SetUser puts the user who made an API request, if any, into the context of
+// the request. The user can be retrieved in a handler function by using "GetUser()"
+func SetUser(request *http.Request, u *user.DBUser) {
+	context.Set(request, pluginUserContextKey, u)
+}
+
+// GetUser returns the logged in user who made a plugin API request, or nil
+// if the request was made anonymously or by an agent.
+func GetUser(request *http.Request) *user.DBUser {
+	if rv := context.Get(request, pluginUserContextKey); rv != nil {
+		return rv.(*user.DBUser)
+	}
+	return nil
+}
+
 // SimpleRegistry is a simple, local, map-based implementation
 // of a plugin registry.
 type SimpleRegistry struct {
@@ -65,6 +65,12 @@ func MustHaveContext(request *http.Request) UIContext {
 	panic("no UI context found")
 }
 
+// SetContext attaches a UIContext to the http.Request, so that plugin UI
+// handlers can load it with MustHaveContext.
+func SetContext(request *http.Request, c UIContext) {
+	context.Set(request, pluginContextKey, c)
+}
+
 // UIDataFunction is a function which is called to populate panels
 // which are injected into Task/Build/Version pages at runtime.
 type UIDataFunction func(context UIContext) (interface{}, error)
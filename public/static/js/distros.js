@@ -1,4 +1,4 @@
-mciModule.controller('DistrosCtrl', function($scope, $window, mciDistroRestService) {
+mciModule.controller('DistrosCtrl', function($scope, $window, $timeout, mciDistroRestService) {
 
   $scope.distros = $window.distros;
 
@@ -17,6 +17,9 @@ mciModule.controller('DistrosCtrl', function($scope, $window, mciDistroRestServi
   }, {
     'id': 'docker',
     'display': 'Docker'
+  }, {
+    'id': 'kubernetes',
+    'display': 'Kubernetes Pod'
   }];
 
   $scope.architectures = [{
@@ -130,6 +133,22 @@ mciModule.controller('DistrosCtrl', function($scope, $window, mciDistroRestServi
     $scope.activeDistro.settings.mount_points.splice(index, 1);
   }
 
+  $scope.addPlacement = function() {
+    if ($scope.activeDistro.settings == null) {
+      $scope.activeDistro.settings = {};
+    }
+    if ($scope.activeDistro.settings.placements == null) {
+      $scope.activeDistro.settings.placements = [];
+    }
+    $scope.activeDistro.settings.placements.push({});
+    $scope.scrollElement('#placements-table');
+  }
+
+  $scope.removePlacement = function(placement) {
+    var index = $scope.activeDistro.settings.placements.indexOf(placement);
+    $scope.activeDistro.settings.placements.splice(index, 1);
+  }
+
   $scope.addSSHOption = function() {
     if ($scope.activeDistro.ssh_options == null) {
       $scope.activeDistro.ssh_options = [];
@@ -199,6 +218,56 @@ mciModule.controller('DistrosCtrl', function($scope, $window, mciDistroRestServi
     );
   };
 
+  // how often to check on a running dry run, in milliseconds
+  var dryRunPollInterval = 5000;
+
+  $scope.dryRun = null;
+  $scope.dryRunError = '';
+  $scope.canary = '';
+
+  $scope.pollDryRun = function(distroId, runId) {
+    mciDistroRestService.getDryRun(distroId, runId, {
+      success: function(run, status) {
+        $scope.dryRun = run;
+        if (run.status == 'running') {
+          $timeout(function() {
+            $scope.pollDryRun(distroId, runId);
+          }, dryRunPollInterval);
+        }
+      },
+      error: function(jqXHR, status, errorThrown) {
+        $scope.dryRunError = jqXHR;
+        console.log(jqXHR);
+      }
+    });
+  };
+
+  // testConfiguration provisions one host with the configuration as it is
+  // in the form, without saving it, and shows the output of each step
+  $scope.testConfiguration = function() {
+    $scope.dryRun = null;
+    $scope.dryRunError = '';
+    mciDistroRestService.dryRunDistro(
+      $scope.activeDistro._id, {
+        distro: $scope.activeDistro,
+        canary: $scope.canary
+      }, {
+        success: function(run, status) {
+          $scope.dryRun = run;
+          $scope.pollDryRun(run.distro_id, run.id);
+        },
+        error: function(jqXHR, status, errorThrown) {
+          if (_.isArray(jqXHR)) {
+            $scope.dryRunError = _.pluck(jqXHR, 'message').join('\n');
+          } else {
+            $scope.dryRunError = jqXHR;
+          }
+          console.log(jqXHR);
+        }
+      }
+    );
+  };
+
   $scope.newDistro = function() {
     if (!$scope.hasNew) {
       var defaultOptions = {
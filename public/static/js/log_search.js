@@ -0,0 +1,43 @@
+mciModule.controller('LogSearchCtrl', function($scope, $http, $window, $location) {
+  $scope.userTz = $window.userTz;
+  $scope.project = $window.project;
+  $scope.matches = [];
+
+  // keep the search in the url so it can be shared
+  var params = $location.search();
+  $scope.search = {
+    q: params.q || '',
+    variant: params.variant || '',
+    start: params.start || '',
+    end: params.end || ''
+  };
+
+  $scope.runSearch = function() {
+    var query = _.pick($scope.search, _.filter(_.keys($scope.search), function(key) {
+      return $scope.search[key] !== '';
+    }));
+    if (!query.q) {
+      return;
+    }
+    $location.search(query);
+    $scope.searching = true;
+    $scope.searchError = '';
+    $http.get('/rest/v1/projects/' + encodeURIComponent($scope.project) + '/log_search', {params: query}).
+    success(function(data) {
+      $scope.searching = false;
+      $scope.searched = true;
+      $scope.matches = data.matches;
+      $scope.start = data.start;
+      $scope.end = data.end;
+    }).
+    error(function(data) {
+      $scope.searching = false;
+      $scope.matches = [];
+      $scope.searchError = (data && data.message) || 'Error searching logs';
+    });
+  };
+
+  if ($scope.search.q) {
+    $scope.runSearch();
+  }
+});
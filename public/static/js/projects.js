@@ -130,8 +130,16 @@ mciModule.controller('ProjectCtrl', function($scope, $window, $http) {
           branch_name: $scope.projectRef.branch_name,
           owner_name: $scope.projectRef.owner_name,
           repo_name: $scope.projectRef.repo_name,
+          repo_kind: $scope.projectRef.repo_kind || "github",
+          remote_url: $scope.projectRef.remote_url,
+          gerrit_url: $scope.projectRef.gerrit_url,
           enabled: $scope.projectRef.enabled,
           alert_config: $scope.projectRef.alert_config || {},
+          artifact_retention: $scope.projectRef.artifact_retention || [],
+          system_failure_retries: $scope.projectRef.system_failure_retries || 0,
+          admins: $scope.projectRef.admins || [],
+          branch_patterns: $scope.projectRef.branch_patterns || [],
+          github_status: $scope.projectRef.github_status || "",
           repotracker_error: $scope.projectRef.repotracker_error || {},
         };
 
@@ -152,6 +160,7 @@ mciModule.controller('ProjectCtrl', function($scope, $window, $http) {
  
   $scope.saveProject = function() {
     $scope.settingsFormData.batch_time = parseInt($scope.settingsFormData.batch_time)
+    $scope.settingsFormData.system_failure_retries = parseInt($scope.settingsFormData.system_failure_retries) || 0
     $http.post('/project/' + $scope.settingsFormData.identifier, $scope.settingsFormData).
       success(function(data, status) {
         $scope.saveMessage = "Settings Saved.";
@@ -195,6 +204,45 @@ mciModule.controller('ProjectCtrl', function($scope, $window, $http) {
     $scope.settingsFormData.alert_config[triggerId].splice(index, 1)
   }
 
+  $scope.retentionRequesters = [
+    {id: "", display: "all tasks"},
+    {id: "patch_request", display: "patch tasks"},
+    {id: "gitter_request", display: "mainline tasks"},
+  ]
+
+  $scope.retentionVisibilities = [
+    {id: "", display: "all files"},
+    {id: "public", display: "public files"},
+    {id: "private", display: "private files"},
+    {id: "none", display: "hidden files"},
+  ]
+
+  $scope.addRetentionRule = function(rule){
+    var days = parseInt(rule.max_age_days)
+    if(isNaN(days) || days <= 0){
+      return
+    }
+    $scope.settingsFormData.artifact_retention.push({
+      requester: rule.requester || "",
+      visibility: rule.visibility || "",
+      max_age_days: days,
+    })
+    $scope.newRetentionRule = {}
+    $scope.isDirty = true
+  }
+
+  $scope.removeRetentionRule = function(index){
+    $scope.settingsFormData.artifact_retention.splice(index, 1)
+    $scope.isDirty = true
+  }
+
+  $scope.getRetentionRuleDisplay = function(rule){
+    var requester = _.findWhere($scope.retentionRequesters, {id: rule.requester})
+    var visibility = _.findWhere($scope.retentionVisibilities, {id: rule.visibility})
+    return "Delete " + visibility.display + " of " + requester.display +
+      " " + rule.max_age_days + " days after they finish"
+  }
+
   $scope.setLastRevision = function() {
     if ($scope.settingsFormData.repotracker_error.exists) {
       var revisionUrl = '/project/' + $scope.settingsFormData.identifier + "/repo_revision";
@@ -234,6 +282,7 @@ mciModule.directive('adminNewAlert', function() {
           {id:"task_failed", display:"Any task fails..."},
           {id:"first_task_failed", display:"The first failure in a version occurs..."},
           {id:"task_fail_transition", display:"A task that had passed in a previous run fails"},
+          {id:"perf_regression", display:"A performance metric regresses..."},
         ]
         scope.availableActions= [
           {id:"email", display:"Send an e-mail"},
@@ -232,6 +232,8 @@ mciServices.rest.factory('mciSpawnRestService', ['mciBaseRestService', function(
         config.data['key_name'] = spawnInfo.spawnKey.name;
         config.data['public_key'] = spawnInfo.spawnKey.key;
         config.data['userdata'] = spawnInfo.userData;
+        config.data['home_volume'] = !!spawnInfo.homeVolume;
+        config.data['task_id'] = spawnInfo.taskId;
         baseSvc.putResource(resource, [], config, callbacks);
     };
 
@@ -244,6 +246,24 @@ mciServices.rest.factory('mciSpawnRestService', ['mciBaseRestService', function(
         baseSvc.postResource(resource, [], config, callbacks);
     };
 
+    service.stopHost = function(hostId, data, callbacks) {
+        var config = {
+            data: data
+        };
+        config.data['action'] = 'stop';
+        config.data['host_id'] = hostId;
+        baseSvc.postResource(resource, [], config, callbacks);
+    };
+
+    service.startHost = function(hostId, data, callbacks) {
+        var config = {
+            data: data
+        };
+        config.data['action'] = 'start';
+        config.data['host_id'] = hostId;
+        baseSvc.postResource(resource, [], config, callbacks);
+    };
+
     service.updateRDPPassword = function(action, hostId, rdpPassword, data, callbacks) {
         var config = {
             data: data
@@ -303,5 +323,16 @@ mciServices.rest.factory('mciDistroRestService', ['mciBaseRestService', function
         baseSvc.deleteResource(resource, [distroId], {}, callbacks);
     }
 
+    service.dryRunDistro = function(distroId, data, callbacks) {
+        var config = {
+            data: data
+        };
+        baseSvc.postResource(resource, [distroId, 'dry_run'], config, callbacks);
+    }
+
+    service.getDryRun = function(distroId, runId, callbacks) {
+        baseSvc.getResource(resource, [distroId, 'dry_run', runId], {}, callbacks);
+    }
+
     return service;
 }]);
\ No newline at end of file
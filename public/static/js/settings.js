@@ -43,6 +43,7 @@ mciModule.controller('SettingsCtrl', function($scope, $http, $window) {
 
   $scope.user_tz = $window.user_tz;
   $scope.new_tz = $scope.user_tz || "America/New_York";
+  $scope.new_chat_handle = $window.user_chat_handle;
   $scope.userConf = $window.userConf;
 
 
@@ -64,8 +65,8 @@ mciModule.controller('SettingsCtrl', function($scope, $http, $window) {
       });
   }
 
-  $scope.updateUserSettings = function(new_tz) {
-    data = {timezone: new_tz};
+  $scope.updateUserSettings = function(new_tz, new_chat_handle) {
+    data = {timezone: new_tz, chat_handle: new_chat_handle};
     $http.put('/settings/', data)
       .success(function(data, status) {
         window.location.reload()
@@ -75,3 +76,68 @@ mciModule.controller('SettingsCtrl', function($scope, $http, $window) {
       });
    };
 });
+
+mciModule.controller('SubscriptionsCtrl', function($scope, $http, $window) {
+  $scope.subscriptions = $window.subscriptions || [];
+  $scope.projects = $window.subscriptionProjects || [];
+  $scope.triggers = $window.subscriptionTriggers || [];
+  $scope.chatEnabled = $window.chatEnabled;
+  $scope.methods = ["email", "chat"];
+  $scope.requesters = [
+    {id: "gitter_request", display: "commits"},
+    {id: "patch_request", display: "patches"},
+  ];
+
+  var emptySubscription = function() {
+    return {requester: "gitter_request", method: "email", build_variants: [], tasks: []};
+  };
+  $scope.newSubscription = emptySubscription();
+
+  var findTrigger = function(id) {
+    return _.find($scope.triggers, function(t) { return t.id == id; });
+  };
+
+  $scope.triggerDisplay = function(id) {
+    var trigger = findTrigger(id);
+    return trigger ? trigger.display : id;
+  };
+
+  $scope.requesterDisplay = function(id) {
+    var requester = _.find($scope.requesters, function(r) { return r.id == id; });
+    return requester ? requester.display : id;
+  };
+
+  $scope.isTaskLevel = function(id) {
+    var trigger = findTrigger(id);
+    return trigger && trigger.task_level;
+  };
+
+  $scope.addSubscription = function() {
+    var data = $scope.newSubscription;
+    if (!$scope.isTaskLevel(data.trigger)) {
+      data.tasks = [];
+    }
+    if (!data.team) {
+      data.address = "";
+    }
+    $http.post('/settings/subscriptions', data)
+      .success(function(subscription, status) {
+        $scope.subscriptions.push(subscription);
+        $scope.newSubscription = emptySubscription();
+        $scope.subscriptionError = "";
+      })
+      .error(function(data, status, errorThrown) {
+        $scope.subscriptionError = data;
+      });
+  };
+
+  $scope.removeSubscription = function(subscription) {
+    $http.delete('/settings/subscriptions/' + subscription.id)
+      .success(function(data, status) {
+        $scope.subscriptions = _.without($scope.subscriptions, subscription);
+      })
+      .error(function(data, status, errorThrown) {
+        alert("Failed to remove subscription: " + data);
+      });
+  };
+});
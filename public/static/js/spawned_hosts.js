@@ -14,8 +14,11 @@ mciModule.controller('SpawnedHostsCtrl', function($scope, $window, $timeout, mci
     $scope.curHostData;
     $scope.hostExtensionLengths = {};
 
-    // max of 7 days time to expiration
-    $scope.maxHoursToExpiration = 24*7;
+    // the furthest out the spawn policy lets a host's expiration be set
+    $scope.maxHoursToExpiration = $window.maxHoursToExpiration || 24*7;
+
+    // the task to load onto the spawned host, if the page was opened for one
+    $scope.spawnTask = $window.spawnTask;
     $scope.saveKey = false;
     $scope.currKeyName = '';
     $scope.newKey = {
@@ -103,6 +106,9 @@ mciModule.controller('SpawnedHostsCtrl', function($scope, $window, $timeout, mci
     // spawns / terminates from the pervious post since they are async, and
     // every 60 seconds after that to pick up changes.
     $timeout($scope.fetchSpawnedHosts, 1);
+    if ($scope.spawnTask) {
+      $timeout(function() { $scope.openSpawnModal('spawnHost'); }, 1);
+    }
     $timeout($scope.fetchSpawnedHosts, 5000);
     setInterval(function(){$scope.fetchSpawnedHosts();}, 60000);
 
@@ -179,6 +185,32 @@ mciModule.controller('SpawnedHostsCtrl', function($scope, $window, $timeout, mci
       );
     };
 
+    $scope.stopHost = function() {
+      mciSpawnRestService.stopHost(
+        $scope.curHostData.id, {}, {
+          success: function(data, status) {
+            window.location.reload(true);
+          },
+          error: function(jqXHR, status, errorThrown) {
+            alert('Error stopping host: ' + jqXHR);
+          }
+        }
+      );
+    };
+
+    $scope.startHost = function() {
+      mciSpawnRestService.startHost(
+        $scope.curHostData.id, {}, {
+          success: function(data, status) {
+            window.location.reload(true);
+          },
+          error: function(jqXHR, status, errorThrown) {
+            alert('Error starting host: ' + jqXHR);
+          }
+        }
+      );
+    };
+
     $scope.terminateHost = function() {
       mciSpawnRestService.terminateHost(
         'terminate',
@@ -210,9 +242,19 @@ mciModule.controller('SpawnedHostsCtrl', function($scope, $window, $timeout, mci
           return 0;
         });
         $scope.selectedDistro = $scope.spawnableDistros[0].distro;
+        // hosts for a task have to be of the task's distro
+        if ($scope.spawnTask) {
+          var taskDistro = _.find($scope.spawnableDistros, function(spawnableDistro) {
+            return spawnableDistro.distro.name == $scope.spawnTask.distro;
+          });
+          if (taskDistro) {
+            $scope.selectedDistro = taskDistro.distro;
+          }
+        }
         $scope.spawnInfo = {
           'distroId': $scope.selectedDistro.name,
           'spawnKey': $scope.newKey,
+          'taskId': $scope.spawnTask ? $scope.spawnTask.id : '',
         };
       };
     };
@@ -249,6 +291,10 @@ mciModule.controller('SpawnedHostsCtrl', function($scope, $window, $timeout, mci
     // User Interface helper functions
     // set the spawn request distro based on user selection
     $scope.setSpawnableDistro = function(spawnableDistro) {
+      // a task can only be reproduced on its own distro
+      if ($scope.spawnInfo.taskId) {
+        return;
+      }
       $scope.selectedDistro = spawnableDistro
       $scope.spawnInfo.distroId = spawnableDistro.name;
     };
@@ -275,6 +321,7 @@ mciModule.controller('SpawnedHostsCtrl', function($scope, $window, $timeout, mci
         case 'starting':
           return 'label block-status-started';
           break;
+        case 'stopped':
         case 'decommissioned':
         case 'unreachable':
         case 'quarantined':
@@ -316,7 +363,7 @@ mciModule.controller('SpawnedHostsCtrl', function($scope, $window, $timeout, mci
         var remainingTimeDur = moment.duration(remainingTimeSec, 'seconds');
         remainingTimeDur.add(extensionLength.hours, 'hours');
 
-        // you should only be able to extend duration for a max of 7 days
+        // you should only be able to extend duration up to the spawn policy limit
         if (remainingTimeDur.as('hours') < $scope.maxHoursToExpiration) {
           $scope.hostExtensionLengths.push(extensionLength);
         }
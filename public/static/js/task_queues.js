@@ -1,9 +1,11 @@
 mciModule.controller('TaskQueuesCtrl',
-  ['$scope', '$window', '$location', 'mciTaskStatisticsRestService',
-  function($scope, $window, $location, taskStatisticsRestService) {
+  ['$scope', '$window', '$location', '$http', 'mciTaskStatisticsRestService',
+  function($scope, $window, $location, $http, taskStatisticsRestService) {
 
   $scope.taskQueues = $window.taskQueues;
   $scope.hostStats = $window.hostStats;
+  $scope.canModifyQueues = $window.canModifyQueues;
+  $scope.queueErrors = {};
 
   $scope.taskStats = []
 
@@ -94,6 +96,44 @@ mciModule.controller('TaskQueuesCtrl',
     $('body').animate({scrollTop: $('#'+distro).offset().top-60}, 'fast');
   };
 
+  // expectedStart describes when the task is expected to start, if the
+  // distro has any hosts to estimate it from
+  $scope.expectedStart = function(queueItem) {
+    if (!queueItem.expected_start || moment(queueItem.expected_start).year() <= 1) {
+      return 'start unknown';
+    }
+    var wait = moment(queueItem.expected_start).diff(moment(), 'seconds');
+    if (wait <= 0) {
+      return 'starting now';
+    }
+    return 'starts in ' + moment.duration(wait, 'seconds').humanize();
+  };
+
+  $scope.modifyQueue = function(distro, queueItem, action, position) {
+    var url = '/json/task_queue/' + encodeURIComponent(distro) + '/' + encodeURIComponent(queueItem._id);
+    $http.post(url, {action: action, position: position || 0}).
+      success(function(data, status) {
+        $scope.queues[distro] = data.queue;
+        $scope.queueErrors[distro] = '';
+      }).
+      error(function(data, status) {
+        $scope.queueErrors[distro] = 'Could not ' + action + ' task: ' + (data.error || data);
+      });
+  };
+
+  // moveWithinProject moves the task up (-1) or down (1) among the queued
+  // tasks of its project
+  $scope.moveWithinProject = function(distro, queueItem, offset) {
+    var projectItems = _.filter($scope.queues[distro], function(item) {
+      return item.project == queueItem.project;
+    });
+    var position = _.pluck(projectItems, '_id').indexOf(queueItem._id) + offset;
+    if (position < 0 || position >= projectItems.length) {
+      return;
+    }
+    $scope.modifyQueue(distro, queueItem, 'move', position);
+  };
+
   $scope.requesterColumn = function(queueItem) {
     if (queueItem.requester === 'gitter_request') {
       return queueItem.project + ' (' +
@@ -84,16 +84,52 @@ mciModule
       return url
     };
 
+    // the server-side search params, read from the query string. the
+    // revision and date only pick the page to start on, so they're kept
+    // out of the pagination links
+    var searchParams = ['variant', 'status', 'author', 'message'];
+    $scope.search = {};
+    _.each($window.location.search.replace(/^\?/, '').split('&'), function(pair) {
+      var kv = pair.split('=');
+      if (kv.length == 2 && kv[1] !== '') {
+        $scope.search[decodeURIComponent(kv[0])] = decodeURIComponent(kv[1].replace(/\+/g, ' '));
+      }
+    });
+
+    // get the query string for the search params, with the given extra params
+    function getQuery(extra) {
+      var params = _.extend(_.pick($scope.search, searchParams), extra);
+      var keys = _.filter(_.keys(params), function(key) {
+        return params[key] !== '' && params[key] !== undefined;
+      });
+      var query = _.map(keys, function(key) {
+        return encodeURIComponent(key) + '=' + encodeURIComponent(params[key]);
+      }).join('&');
+      return query ? '?' + query : '';
+    };
+
     // refs for the next and previous page of the waterfall
     $scope.previousPage = function() {
-      return getUrl() + '?skip=' + previousSkip + '#/filter/' + $scope.filter.variant +
+      return getUrl() + getQuery({skip: previousSkip}) + '#/filter/' + $scope.filter.variant +
         '/' + $scope.filter.task;
     };
     $scope.nextPage = function() {
-      return getUrl() + '?skip=' + nextSkip + '#/filter/' + $scope.filter.variant +
+      return getUrl() + getQuery({skip: nextSkip}) + '#/filter/' + $scope.filter.variant +
         '/' + $scope.filter.task;
     };
 
+    // reload the waterfall with the search params, starting at the given
+    // revision or date, if any
+    $scope.runSearch = function() {
+      $window.location.href = getUrl() + getQuery(_.pick($scope.search, 'revision', 'date'));
+    };
+    $scope.clearSearch = function() {
+      $window.location.href = getUrl();
+    };
+    $scope.searching = _.some(searchParams, function(param) {
+      return !!$scope.search[param];
+    });
+
     // initialize the filter
     var filterOpts = $location.path().split('/');
     $scope.filter = {
@@ -0,0 +1,102 @@
+package repotracker
+
+import (
+	"fmt"
+	"github.com/10gen-labs/slogger/v1"
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model"
+)
+
+// BranchLister is implemented by repository pollers that can list the
+// repository's branches, which is needed to track branch patterns
+type BranchLister interface {
+	GetBranches() ([]string, error)
+}
+
+// SyncBranchProjects makes sure that there is an enabled project ref for each
+// of the repository's branches that match the parent's branch patterns, with
+// the parent's current settings. The project refs of branches that no longer
+// exist are disabled, keeping their history.
+func SyncBranchProjects(parent *model.ProjectRef, lister BranchLister) error {
+	branches, err := lister.GetBranches()
+	if err != nil {
+		return fmt.Errorf("error listing branches of %v: %v", parent.Identifier, err)
+	}
+
+	existing, err := model.FindBranchProjectRefs(parent.Identifier)
+	if err != nil {
+		return fmt.Errorf("error finding branch projects of %v: %v", parent.Identifier, err)
+	}
+	existingByBranch := make(map[string]model.ProjectRef, len(existing))
+	for _, ref := range existing {
+		existingByBranch[ref.Branch] = ref
+	}
+
+	for _, branch := range branches {
+		if !parent.TracksBranch(branch) {
+			continue
+		}
+		branchRef := parent.NewBranchProjectRef(branch)
+		if old, ok := existingByBranch[branch]; ok {
+			// keep the identifier the branch was first tracked under
+			branchRef.Identifier = old.Identifier
+			branchRef.RepotrackerError = old.RepotrackerError
+			delete(existingByBranch, branch)
+		} else {
+			identifier, err := availableBranchIdentifier(parent, branch)
+			if err != nil {
+				return err
+			}
+			if identifier == "" {
+				evergreen.Logger.Logf(slogger.ERROR, "Not tracking branch %v of %v: "+
+					"no project identifier is available for it", branch, parent.Identifier)
+				continue
+			}
+			branchRef.Identifier = identifier
+			evergreen.Logger.Logf(slogger.INFO, "Tracking branch %v of %v as project %v",
+				branch, parent.Identifier, branchRef.Identifier)
+		}
+		if err = branchRef.Upsert(); err != nil {
+			return fmt.Errorf("error saving project %v: %v", branchRef.Identifier, err)
+		}
+	}
+
+	// whatever is left was generated for branches that are gone or no longer
+	// match the patterns
+	for branch, ref := range existingByBranch {
+		if !ref.Enabled {
+			continue
+		}
+		evergreen.Logger.Logf(slogger.INFO, "Disabling project %v: branch %v of %v "+
+			"is no longer tracked", ref.Identifier, branch, parent.Identifier)
+		ref.Enabled = false
+		if err = ref.Upsert(); err != nil {
+			return fmt.Errorf("error disabling project %v: %v", ref.Identifier, err)
+		}
+	}
+	return nil
+}
+
+// availableBranchIdentifier returns an identifier for the project tracking
+// the branch that isn't used by another project ref: the branch identifier,
+// or, if a different project or branch already has it, the branch identifier
+// made unique with a hash of the branch name. It returns an empty string if
+// both are taken.
+func availableBranchIdentifier(parent *model.ProjectRef, branch string) (string, error) {
+	for _, identifier := range []string{
+		parent.BranchIdentifier(branch),
+		parent.UniqueBranchIdentifier(branch),
+	} {
+		ref, err := model.FindOneProjectRef(identifier)
+		if err != nil {
+			return "", fmt.Errorf("error finding project %v: %v", identifier, err)
+		}
+		if ref == nil {
+			return identifier, nil
+		}
+		evergreen.Logger.Logf(slogger.WARN, "Project identifier %v for branch %v of %v is "+
+			"already taken (parent '%v', branch '%v')", identifier, branch, parent.Identifier,
+			ref.Parent, ref.Branch)
+	}
+	return "", nil
+}
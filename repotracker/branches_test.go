@@ -0,0 +1,74 @@
+package repotracker
+
+import (
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/testutil"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+type staticBranchLister []string
+
+func (l staticBranchLister) GetBranches() ([]string, error) {
+	return l, nil
+}
+
+func TestSyncBranchProjects(t *testing.T) {
+	dropTestDB(t)
+	Convey("With a project ref tracking branch patterns", t, func() {
+		parent := &model.ProjectRef{
+			Identifier:     "mongodb-mongo",
+			Branch:         "master",
+			BranchPatterns: []string{"v3*"},
+			Enabled:        true,
+		}
+		testutil.HandleTestingErr(parent.Insert(), t, "Error inserting project ref")
+
+		Convey("an unrelated project ref with the branch identifier should not be "+
+			"overwritten", func() {
+			other := &model.ProjectRef{Identifier: "mongodb-mongo_v3.0", Branch: "master"}
+			testutil.HandleTestingErr(other.Insert(), t, "Error inserting project ref")
+
+			So(SyncBranchProjects(parent, staticBranchLister{"v3.0"}), ShouldBeNil)
+
+			otherFromDB, err := model.FindOneProjectRef(other.Identifier)
+			So(err, ShouldBeNil)
+			So(otherFromDB.Parent, ShouldEqual, "")
+			So(otherFromDB.Branch, ShouldEqual, "master")
+
+			branchRef, err := model.FindOneBranchProjectRef(parent.Identifier, "v3.0")
+			So(err, ShouldBeNil)
+			So(branchRef, ShouldNotBeNil)
+			So(branchRef.Identifier, ShouldEqual, parent.UniqueBranchIdentifier("v3.0"))
+		})
+
+		Convey("branches with colliding identifiers should each get a project "+
+			"that keeps its identifier", func() {
+			So(SyncBranchProjects(parent, staticBranchLister{"v3/0", "v3-0"}), ShouldBeNil)
+
+			slashRef, err := model.FindOneBranchProjectRef(parent.Identifier, "v3/0")
+			So(err, ShouldBeNil)
+			dashRef, err := model.FindOneBranchProjectRef(parent.Identifier, "v3-0")
+			So(err, ShouldBeNil)
+			So(slashRef, ShouldNotBeNil)
+			So(dashRef, ShouldNotBeNil)
+			So(slashRef.Identifier, ShouldNotEqual, dashRef.Identifier)
+
+			So(SyncBranchProjects(parent, staticBranchLister{"v3-0", "v3/0"}), ShouldBeNil)
+			branchRefs, err := model.FindBranchProjectRefs(parent.Identifier)
+			So(err, ShouldBeNil)
+			So(len(branchRefs), ShouldEqual, 2)
+			for _, ref := range branchRefs {
+				if ref.Branch == "v3/0" {
+					So(ref.Identifier, ShouldEqual, slashRef.Identifier)
+				} else {
+					So(ref.Identifier, ShouldEqual, dashRef.Identifier)
+				}
+			}
+		})
+
+		Reset(func() {
+			dropTestDB(t)
+		})
+	})
+}
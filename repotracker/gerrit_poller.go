@@ -0,0 +1,188 @@
+package repotracker
+
+import (
+	"fmt"
+	"github.com/10gen-labs/slogger/v1"
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/patch"
+	"github.com/evergreen-ci/evergreen/model/user"
+	"github.com/evergreen-ci/evergreen/thirdparty"
+	"github.com/evergreen-ci/evergreen/validator"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/yaml.v2"
+	"strings"
+	"time"
+)
+
+// PatchPoller is implemented by repository pollers that also create patches,
+// for instance from changes awaiting code review
+type PatchPoller interface {
+	// Creates and finalizes patches for any new changes
+	CreatePatches(settings *evergreen.Settings) error
+}
+
+// GerritRepositoryPoller polls a project hosted on a Gerrit server. Commits
+// are read from a mirror of the repository like a GitRepositoryPoller, and
+// the open changes to the project's branch are turned into patches
+type GerritRepositoryPoller struct {
+	*GitRepositoryPoller
+	Gerrit thirdparty.GerritHandler
+}
+
+// NewGerritRepositoryPoller constructs and returns a pointer to a
+// GerritRepositoryPoller struct
+func NewGerritRepositoryPoller(projectRef *model.ProjectRef, mirrorRoot,
+	credentials string) *GerritRepositoryPoller {
+	return &GerritRepositoryPoller{
+		GitRepositoryPoller: NewGitRepositoryPoller(projectRef, mirrorRoot),
+		Gerrit:              thirdparty.NewGerritHandler(projectRef.GerritURL, credentials),
+	}
+}
+
+// CreatePatches creates and finalizes a patch for the current patch set of
+// each open change that doesn't have one yet. Only changes owned by Evergreen
+// users are patched, since a patch runs the change's code and configuration
+// on our hosts and anyone with an account on the Gerrit server can open one.
+func (gerritPoller *GerritRepositoryPoller) CreatePatches(settings *evergreen.Settings) error {
+	projectRef := gerritPoller.ProjectRef
+	changes, err := gerritPoller.Gerrit.GetOpenChanges(projectRef.Repo, projectRef.Branch)
+	if err != nil {
+		return fmt.Errorf("error getting open changes for %v: %v", projectRef.Identifier, err)
+	}
+
+	for _, change := range changes {
+		patchSet, ok := change.CurrentPatchSet()
+		if !ok {
+			continue
+		}
+		existing, err := patch.FindOne(patch.ByProjectAndReviewRef(projectRef.Identifier,
+			patchSet.Ref).WithFields(patch.IdKey))
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			continue
+		}
+		author := changeAuthor(change)
+		dbUser, err := user.FindOne(user.ById(author))
+		if err != nil {
+			return err
+		}
+		if dbUser == nil {
+			evergreen.Logger.Logf(slogger.DEBUG, "Not creating a patch for change %v of "+
+				"project %v: owner '%v' is not an Evergreen user", change.Number,
+				projectRef.Identifier, author)
+			continue
+		}
+		if err = gerritPoller.createPatch(change, patchSet, dbUser, settings); err != nil {
+			// a bad change shouldn't hold up the others
+			evergreen.Logger.Logf(slogger.ERROR, "error creating patch for change %v "+
+				"of project %v: %v", change.Number, projectRef.Identifier, err)
+		}
+	}
+	return nil
+}
+
+// changeAuthor returns the Evergreen user id of the change's owner.
+func changeAuthor(change thirdparty.GerritChange) string {
+	if change.Owner.Username != "" {
+		return change.Owner.Username
+	}
+	return change.Owner.Email
+}
+
+// createPatch creates a patch, authored by the change's owner, of the changes
+// made by the patch set on top of the branch it was made against, and
+// finalizes it
+func (gerritPoller *GerritRepositoryPoller) createPatch(change thirdparty.GerritChange,
+	patchSet thirdparty.GerritRevision, owner *user.DBUser, settings *evergreen.Settings) error {
+	projectRef := gerritPoller.ProjectRef
+	dir := gerritPoller.MirrorDir
+
+	revision, err := thirdparty.GitFetchRef(dir, patchSet.Ref)
+	if err != nil {
+		return err
+	}
+	baseRevision, err := thirdparty.GitMergeBase(dir, revision, gerritPoller.branchRef())
+	if err != nil {
+		return err
+	}
+	diff, err := thirdparty.GitDiff(dir, baseRevision, revision)
+	if err != nil {
+		return err
+	}
+
+	var summaries []thirdparty.Summary
+	if diff != "" {
+		gitOutput, err := thirdparty.GitApplyNumstat(diff)
+		if err != nil {
+			return fmt.Errorf("couldn't validate patch: %v", err)
+		}
+		if summaries, err = thirdparty.ParseGitSummary(gitOutput); err != nil {
+			return fmt.Errorf("couldn't validate patch: %v", err)
+		}
+	}
+
+	// the project configuration is taken from the change itself
+	project, err := gerritPoller.GetRemoteConfig(revision)
+	if err != nil {
+		return err
+	}
+	if errs := validator.CheckProjectSyntax(project); len(errs) != 0 {
+		var message string
+		for _, err := range errs {
+			message += fmt.Sprintf("\n\t=> %v", err)
+		}
+		return fmt.Errorf("invalid project configuration: %v", message)
+	}
+	projectYamlBytes, err := yaml.Marshal(project)
+	if err != nil {
+		return fmt.Errorf("error marshalling patched config: %v", err)
+	}
+
+	patchFileId := bson.NewObjectId().Hex()
+	patchDoc := &patch.Patch{
+		Id: bson.NewObjectId(),
+		Description: fmt.Sprintf("Gerrit change %v, patch set %v: %v",
+			change.Number, patchSet.Number, change.Subject),
+		Author:        owner.Id,
+		Project:       projectRef.Identifier,
+		Githash:       baseRevision,
+		CreateTime:    time.Now(),
+		Status:        evergreen.PatchCreated,
+		BuildVariants: []string{"all"},
+		Patches: []patch.ModulePatch{
+			patch.ModulePatch{
+				ModuleName: "",
+				Githash:    baseRevision,
+				PatchSet: patch.PatchSet{
+					PatchFileId: patchFileId,
+					Summary:     summaries,
+				},
+			},
+		},
+		PatchedConfig: string(projectYamlBytes),
+		ReviewRef:     patchSet.Ref,
+	}
+
+	// number the patch like the owner's own
+	if patchDoc.PatchNumber, err = owner.IncPatchNumber(); err != nil {
+		return fmt.Errorf("error computing patch num %v", err)
+	}
+
+	err = db.WriteGridFile(patch.GridFSPrefix, patchFileId, strings.NewReader(diff))
+	if err != nil {
+		return fmt.Errorf("failed to write patch file to db: %v", err)
+	}
+	if err = patchDoc.Insert(); err != nil {
+		return fmt.Errorf("error inserting patch: %v", err)
+	}
+	if _, err = model.FinalizePatch(patchDoc, settings); err != nil {
+		return err
+	}
+	evergreen.Logger.Logf(slogger.INFO, "Created patch %v for change %v of project %v",
+		patchDoc.Id.Hex(), change.Number, projectRef.Identifier)
+	return nil
+}
@@ -0,0 +1,166 @@
+package repotracker
+
+import (
+	"fmt"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/thirdparty"
+	"os"
+	"path/filepath"
+)
+
+// GitRepositoryPoller is a struct that implements the behavior required of a
+// RepoPoller for any git remote, by keeping a local bare mirror of the
+// remote's branches
+type GitRepositoryPoller struct {
+	ProjectRef *model.ProjectRef
+	MirrorDir  string
+}
+
+// NewGitRepositoryPoller constructs and returns a pointer to a
+// GitRepositoryPoller struct, which mirrors the project's repository in a
+// directory under mirrorRoot
+func NewGitRepositoryPoller(projectRef *model.ProjectRef,
+	mirrorRoot string) *GitRepositoryPoller {
+	if mirrorRoot == "" {
+		mirrorRoot = filepath.Join(os.TempDir(), "evergreen_mirrors")
+	}
+	// the projects of a repository's branches share its mirror
+	mirrorName := projectRef.Identifier
+	if projectRef.Parent != "" {
+		mirrorName = projectRef.Parent
+	}
+	return &GitRepositoryPoller{
+		ProjectRef: projectRef,
+		MirrorDir:  filepath.Join(mirrorRoot, mirrorName),
+	}
+}
+
+// branchRef returns the ref of the tracked branch in the mirror
+func (gitRepoPoller *GitRepositoryPoller) branchRef() string {
+	return "refs/heads/" + gitRepoPoller.ProjectRef.Branch
+}
+
+// updateMirror fetches the latest changes from the remote repository
+func (gitRepoPoller *GitRepositoryPoller) updateMirror() error {
+	if gitRepoPoller.ProjectRef.RemoteURL == "" {
+		return fmt.Errorf("no remote url in project ref: %v",
+			gitRepoPoller.ProjectRef.Identifier)
+	}
+	if err := thirdparty.GitUpdateMirror(gitRepoPoller.ProjectRef.RemoteURL,
+		gitRepoPoller.MirrorDir); err != nil {
+		return fmt.Errorf("error updating mirror of %v: %v",
+			gitRepoPoller.ProjectRef.RemoteURL, err)
+	}
+	return nil
+}
+
+// gitCommitsToRevisions converts GitCommit structs to model.Revision structs
+func gitCommitsToRevisions(commits []thirdparty.GitCommit) []model.Revision {
+	revisions := make([]model.Revision, 0, len(commits))
+	for _, commit := range commits {
+		revisions = append(revisions, model.Revision{
+			Author:          commit.Author,
+			AuthorEmail:     commit.AuthorEmail,
+			RevisionMessage: commit.Message,
+			Revision:        commit.Revision,
+			CreateTime:      commit.CreateTime,
+		})
+	}
+	return revisions
+}
+
+// GetRemoteConfig reads the project's configuration file from the mirror as
+// at a given revision, fetching from the remote first if the revision isn't
+// in the mirror yet
+func (gitRepoPoller *GitRepositoryPoller) GetRemoteConfig(
+	projectFileRevision string) (*model.Project, error) {
+	projectRef := gitRepoPoller.ProjectRef
+	if !thirdparty.GitRevisionExists(gitRepoPoller.MirrorDir, projectFileRevision) {
+		if err := gitRepoPoller.updateMirror(); err != nil {
+			return nil, err
+		}
+	}
+
+	projectFileBytes, err := thirdparty.GitShowFile(gitRepoPoller.MirrorDir,
+		projectFileRevision, projectRef.RemotePath)
+	if err != nil {
+		return nil, err
+	}
+
+	projectConfig := &model.Project{}
+	err = model.LoadProjectInto(projectFileBytes, projectRef.Identifier, projectConfig)
+	if err != nil {
+		return nil, thirdparty.YAMLFormatError{err.Error()}
+	}
+	return projectConfig, nil
+}
+
+// GetRevisionsSince fetches all of the commits to the project's branch that
+// were made after 'revision'
+func (gitRepoPoller *GitRepositoryPoller) GetRevisionsSince(revision string,
+	maxRevisionsToSearch int) ([]model.Revision, error) {
+	if err := gitRepoPoller.updateMirror(); err != nil {
+		return nil, err
+	}
+	dir := gitRepoPoller.MirrorDir
+	branch := gitRepoPoller.branchRef()
+
+	if !thirdparty.GitRevisionExists(dir, revision) ||
+		!thirdparty.GitIsAncestor(dir, revision, branch) {
+		// the branch was rewritten, so suggest where it diverged
+		revisionDetails := &model.RepositoryErrorDetails{
+			Exists:          true,
+			InvalidRevision: revision,
+		}
+		var revisionError error
+		baseRevision, err := thirdparty.GitMergeBase(dir, revision, branch)
+		if err != nil {
+			revisionError = fmt.Errorf("unable to find a suggested merge base commit "+
+				"for revision %v, must fix on projects settings page: %v", revision, err)
+		} else {
+			revisionDetails.MergeBaseRevision = baseRevision
+			revisionError = fmt.Errorf("base revision, %v not found, suggested base "+
+				"revision, %v found, must confirm on project settings page",
+				revision, baseRevision)
+		}
+		if len(revision) > 10 {
+			revisionDetails.InvalidRevision = revision[:10]
+		}
+
+		gitRepoPoller.ProjectRef.RepotrackerError = revisionDetails
+		if err = gitRepoPoller.ProjectRef.Upsert(); err != nil {
+			return []model.Revision{}, fmt.Errorf("unable to update projectRef "+
+				"revision details: %v", err)
+		}
+		return []model.Revision{}, revisionError
+	}
+
+	commits, err := thirdparty.GitLog(dir, revision+".."+branch, maxRevisionsToSearch)
+	if err != nil {
+		return nil, err
+	}
+	return gitCommitsToRevisions(commits), nil
+}
+
+// GetBranches returns the names of the repository's branches
+func (gitRepoPoller *GitRepositoryPoller) GetBranches() ([]string, error) {
+	if err := gitRepoPoller.updateMirror(); err != nil {
+		return nil, err
+	}
+	return thirdparty.GitBranches(gitRepoPoller.MirrorDir)
+}
+
+// GetRecentRevisions fetches the most recent 'maxRevisions' commits to the
+// project's branch
+func (gitRepoPoller *GitRepositoryPoller) GetRecentRevisions(maxRevisions int) (
+	[]model.Revision, error) {
+	if err := gitRepoPoller.updateMirror(); err != nil {
+		return nil, err
+	}
+	commits, err := thirdparty.GitLog(gitRepoPoller.MirrorDir,
+		gitRepoPoller.branchRef(), maxRevisions)
+	if err != nil {
+		return nil, err
+	}
+	return gitCommitsToRevisions(commits), nil
+}
@@ -0,0 +1,29 @@
+package repotracker
+
+import (
+	"github.com/evergreen-ci/evergreen/thirdparty"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+	"time"
+)
+
+func TestGitCommitsToRevisions(t *testing.T) {
+	Convey("Revisions should keep the details of their commits", t, func() {
+		createTime := time.Unix(1400000000, 0)
+		revisions := gitCommitsToRevisions([]thirdparty.GitCommit{
+			{
+				Revision:    "abc",
+				Author:      "someone",
+				AuthorEmail: "someone@example.com",
+				Message:     "a change",
+				CreateTime:  createTime,
+			},
+		})
+		So(len(revisions), ShouldEqual, 1)
+		So(revisions[0].Revision, ShouldEqual, "abc")
+		So(revisions[0].Author, ShouldEqual, "someone")
+		So(revisions[0].AuthorEmail, ShouldEqual, "someone@example.com")
+		So(revisions[0].RevisionMessage, ShouldEqual, "a change")
+		So(revisions[0].CreateTime, ShouldResemble, createTime)
+	})
+}
@@ -166,6 +166,12 @@ func (gRepoPoller *GithubRepositoryPoller) GetRevisionsSince(revision string,
 	return
 }
 
+// GetBranches fetches the names of the repository's branches
+func (gRepoPoller *GithubRepositoryPoller) GetBranches() ([]string, error) {
+	return thirdparty.GetGithubBranches(gRepoPoller.OauthToken,
+		gRepoPoller.ProjectRef.Owner, gRepoPoller.ProjectRef.Repo)
+}
+
 // GetRecentRevisions fetches the most recent 'numRevisions'
 func (gRepoPoller *GithubRepositoryPoller) GetRecentRevisions(maxRevisions int) (
 	revisions []model.Revision, err error) {
@@ -208,6 +208,9 @@ func (repoTracker *RepoTracker) sendFailureNotification(lastRevision string,
 		projectRef.Identifier, lastRevision)
 	url := fmt.Sprintf("%v/%v/%v/commits/%v", thirdparty.GithubBase,
 		projectRef.Owner, projectRef.Repo, projectRef.Branch)
+	if projectRef.RemoteURL != "" {
+		url = fmt.Sprintf("%v (branch %v)", projectRef.RemoteURL, projectRef.Branch)
+	}
 	message := fmt.Sprintf("Could not find last known revision '%v' "+
 		"within the most recent %v revisions at %v: %v", lastRevision,
 		settings.RepoTracker.MaxRepoRevisionsToSearch, url, err)
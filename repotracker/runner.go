@@ -1,6 +1,7 @@
 package repotracker
 
 import (
+	"fmt"
 	"github.com/10gen-labs/slogger/v1"
 	"github.com/evergreen-ci/evergreen"
 	"github.com/evergreen-ci/evergreen/db"
@@ -23,6 +24,20 @@ func (r *Runner) Description() string {
 	return Description
 }
 
+// NewRepoPoller returns the poller for the project's kind of repository.
+func NewRepoPoller(config *evergreen.Settings, projectRef *model.ProjectRef) (RepoPoller, error) {
+	switch projectRef.RepoKind {
+	case evergreen.GithubRepoKind, "":
+		return NewGithubRepositoryPoller(projectRef, config.Credentials["github"]), nil
+	case evergreen.GitRepoKind:
+		return NewGitRepositoryPoller(projectRef, config.RepoTracker.MirrorDirectory), nil
+	case evergreen.GerritRepoKind:
+		return NewGerritRepositoryPoller(projectRef, config.RepoTracker.MirrorDirectory,
+			config.Credentials["gerrit"]), nil
+	}
+	return nil, fmt.Errorf("unknown repository kind '%v'", projectRef.RepoKind)
+}
+
 func (r *Runner) Run(config *evergreen.Settings) error {
 	lockAcquired, err := db.WaitTillAcquireGlobalLock(RunnerName, db.LockTimeout)
 	if err != nil {
@@ -47,11 +62,46 @@ func (r *Runner) Run(config *evergreen.Settings) error {
 		return evergreen.Logger.Errorf(slogger.ERROR, "Error finding tracked projects %v", err)
 	}
 
+	// generate the projects of branches matching each project's branch
+	// patterns before polling, so that new branches are polled right away
+	branchesSynced := false
+	for _, projectRef := range allProjects {
+		if len(projectRef.BranchPatterns) == 0 || !projectRef.Enabled {
+			continue
+		}
+		poller, err := NewRepoPoller(config, &projectRef)
+		if err != nil {
+			evergreen.Logger.Errorf(slogger.ERROR, "Error polling project %v: %v",
+				projectRef.Identifier, err)
+			continue
+		}
+		lister, ok := poller.(BranchLister)
+		if !ok {
+			continue
+		}
+		if err = SyncBranchProjects(&projectRef, lister); err != nil {
+			evergreen.Logger.Errorf(slogger.ERROR, "Error syncing branches: %v", err)
+			continue
+		}
+		branchesSynced = true
+	}
+	if branchesSynced {
+		if allProjects, err = model.FindAllTrackedProjectRefs(); err != nil {
+			return evergreen.Logger.Errorf(slogger.ERROR, "Error finding tracked projects %v", err)
+		}
+	}
+
 	for _, projectRef := range allProjects {
+		poller, err := NewRepoPoller(config, &projectRef)
+		if err != nil {
+			evergreen.Logger.Errorf(slogger.ERROR, "Error polling project %v: %v",
+				projectRef.Identifier, err)
+			continue
+		}
 		tracker := &RepoTracker{
 			config,
 			&projectRef,
-			NewGithubRepositoryPoller(&projectRef, config.Credentials["github"]),
+			poller,
 		}
 
 		numNewRepoRevisionsToFetch := config.RepoTracker.NumNewRepoRevisionsToFetch
@@ -64,6 +114,12 @@ func (r *Runner) Run(config *evergreen.Settings) error {
 			evergreen.Logger.Errorf(slogger.ERROR, "Error fetching revisions: %v", err)
 			continue
 		}
+
+		if patchPoller, ok := poller.(PatchPoller); ok && projectRef.Enabled {
+			if err = patchPoller.CreatePatches(config); err != nil {
+				evergreen.Logger.Errorf(slogger.ERROR, "Error creating patches: %v", err)
+			}
+		}
 	}
 
 	runtime := time.Now().Sub(startTime)
@@ -26,6 +26,8 @@ func GetRestRoutes(ruis restUISAPI) []RouteInfo {
 	return []RouteInfo{
 		{"/projects/{project_id}/versions", restapi.getRecentVersions, "recent_versions", "GET"},
 		{"/projects/{project_id}/revisions/{revision}", restapi.getVersionInfoViaRevision, "version_info_via_revision", "GET"},
+		{"/projects/{project_id}/branches/{branch}/versions", restapi.getRecentVersions, "branch_recent_versions", "GET"},
+		{"/projects/{project_id}/branches/{branch}/revisions/{revision}", restapi.getVersionInfoViaRevision, "branch_version_info_via_revision", "GET"},
 		{"/versions/{version_id}", restapi.getVersionInfo, "version_info", "GET"},
 		{"/versions/{version_id}", restapi.modifyVersionInfo, "", "PATCH"},
 		{"/versions/{version_id}/status", restapi.getVersionStatus, "version_status", "GET"},
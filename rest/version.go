@@ -110,10 +110,46 @@ func copyVersion(srcVersion *version.Version, destVersion *restVersion) {
 	destVersion.Requester = srcVersion.Requester
 }
 
+// getProjectId returns the id of the project in the request's route. For
+// routes with a branch, it is the id of the project that tracks the branch,
+// or the empty string if the branch isn't tracked.
+func getProjectId(r *http.Request) (string, error) {
+	vars := mux.Vars(r)
+	branch, ok := vars["branch"]
+	if !ok {
+		return vars["project_id"], nil
+	}
+	projectRef, err := model.FindBranchProjectRef(vars["project_id"], branch)
+	if err != nil || projectRef == nil {
+		return "", err
+	}
+	return projectRef.Identifier, nil
+}
+
+// writeProjectIdError writes the response for a project that couldn't be
+// found from the request's route.
+func (restapi restAPI) writeProjectIdError(w http.ResponseWriter, r *http.Request, err error) {
+	vars := mux.Vars(r)
+	if err != nil {
+		msg := fmt.Sprintf("Error finding branch '%v' of project '%v'", vars["branch"],
+			vars["project_id"])
+		evergreen.Logger.Logf(slogger.ERROR, "%v: %v", msg, err)
+		restapi.WriteJSON(w, http.StatusInternalServerError, responseError{Message: msg})
+		return
+	}
+	msg := fmt.Sprintf("Project '%v' doesn't track branch '%v'", vars["project_id"],
+		vars["branch"])
+	restapi.WriteJSON(w, http.StatusNotFound, responseError{Message: msg})
+}
+
 // Returns a JSON response of an array with the NumRecentVersions
 // most recent versions (sorted on commit order number descending).
 func (restapi restAPI) getRecentVersions(w http.ResponseWriter, r *http.Request) {
-	projectId := mux.Vars(r)["project_id"]
+	projectId, err := getProjectId(r)
+	if err != nil || projectId == "" {
+		restapi.writeProjectIdError(w, r, err)
+		return
+	}
 
 	versions, err := version.Find(version.ByMostRecentForRequester(projectId, evergreen.RepotrackerVersionRequester).Limit(10))
 	if err != nil {
@@ -220,8 +256,12 @@ func (restapi restAPI) getVersionInfo(w http.ResponseWriter, r *http.Request) {
 // specified by its revision and project name in the request.
 func (restapi restAPI) getVersionInfoViaRevision(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
-	projectId := vars["project_id"]
 	revision := vars["revision"]
+	projectId, err := getProjectId(r)
+	if err != nil || projectId == "" {
+		restapi.writeProjectIdError(w, r, err)
+		return
+	}
 
 	srcVersion, err := version.FindOne(version.ByProjectIdAndRevision(projectId, revision))
 	if err != nil || srcVersion == nil {
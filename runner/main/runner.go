@@ -8,6 +8,7 @@ import (
 	"github.com/10gen-labs/slogger/v1"
 	"github.com/evergreen-ci/evergreen"
 	"github.com/evergreen-ci/evergreen/db"
+	"github.com/evergreen-ci/evergreen/githubstatus"
 	"github.com/evergreen-ci/evergreen/notify"
 	. "github.com/evergreen-ci/evergreen/runner"
 	"os"
@@ -61,6 +62,7 @@ func main() {
 	}
 
 	db.SetGlobalSessionProvider(db.SessionFactoryFromConfig(settings))
+	githubstatus.Register(settings)
 
 	// just run one process if an argument was passed in
 	if flag.Arg(0) != "" {
@@ -6,6 +6,8 @@ package runner
 import (
 	"github.com/evergreen-ci/evergreen"
 	"github.com/evergreen-ci/evergreen/alerts"
+	"github.com/evergreen-ci/evergreen/artifactcleanup"
+	"github.com/evergreen-ci/evergreen/githubstatus"
 	"github.com/evergreen-ci/evergreen/hostinit"
 	"github.com/evergreen-ci/evergreen/monitor"
 	"github.com/evergreen-ci/evergreen/notify"
@@ -35,5 +37,8 @@ var (
 		&scheduler.Runner{},
 		&taskrunner.Runner{},
 		&alerts.QueueProcessor{},
+		&alerts.KnownIssuePruner{},
+		&artifactcleanup.Runner{},
+		&githubstatus.Runner{},
 	}
 )
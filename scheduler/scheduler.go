@@ -86,6 +86,13 @@ func (self *Scheduler) Schedule() error {
 			return fmt.Errorf("Error prioritizing tasks: %v", err)
 		}
 
+		// honor any manual changes made to the distro's queue
+		prioritizedTasks, err = applyQueueOverrides(d.Id, prioritizedTasks)
+		if err != nil {
+			return fmt.Errorf("Error applying task queue overrides for distro "+
+				"%v: %v", d.Id, err)
+		}
+
 		// Update the running minimums of queue position
 		// The value is 1-based primarily so that we can differentiate between
 		// no value and being first in a queue
@@ -336,3 +343,38 @@ func (self *Scheduler) spawnHosts(newHostsNeeded map[string]int) (
 	}
 	return hostsSpawnedPerDistro, nil
 }
+
+// applyQueueOverrides reorders the prioritized tasks for a distro according to
+// the manual overrides saved with its queue, and drops the overrides for tasks
+// that are no longer waiting to run.
+func applyQueueOverrides(distroId string, tasks []model.Task) ([]model.Task, error) {
+	taskQueue, err := model.FindTaskQueueForDistro(distroId)
+	if err != nil {
+		return nil, err
+	}
+	if taskQueue == nil || taskQueue.Overrides.IsEmpty() {
+		return tasks, nil
+	}
+
+	ids := make([]string, 0, len(tasks))
+	projects := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		ids = append(ids, task.Id)
+		projects = append(projects, task.Project)
+	}
+	order, stale := taskQueue.Overrides.Order(ids, projects)
+
+	if len(stale) > 0 {
+		evergreen.Logger.Logf(slogger.INFO, "Dropping queue overrides for %v "+
+			"task(s) no longer queued on distro %v", len(stale), distroId)
+		if err = model.PruneTaskQueueOverrides(distroId, stale); err != nil {
+			return nil, err
+		}
+	}
+
+	overridden := make([]model.Task, 0, len(order))
+	for _, idx := range order {
+		overridden = append(overridden, tasks[idx])
+	}
+	return overridden, nil
+}
@@ -16,6 +16,9 @@ db.patchfiles.files.createIndex({"filename":1})
 //======event_log======//
 db.event_log.ensureIndex({ "r_id" : 1, "data.r_type" : 1, "ts" : 1 })
 
+//======github_statuses======//
+db.github_statuses.ensureIndex({ "queue_status" : 1, "created_at" : 1 })
+
 //======hosts======//
 db.hosts.ensureIndex({ "status": 1 })
 db.hosts.ensureIndex({ "started_by" : 1, "status" : 1 })
@@ -26,6 +29,11 @@ db.hosts.ensureIndex({ "version" : 1 })
 db.hosts.ensureIndex({ "author" : 1 })
 db.hosts.ensureIndex({ "distro._id" : 1, "status" : 1 })
 
+//======log_search======//
+db.log_search.ensureIndex({ "project" : 1, "tokens" : 1, "ts" : -1 })
+// keep a month of searchable logs
+db.log_search.ensureIndex({ "ts" : 1 }, { expireAfterSeconds : 2592000 })
+
 //======pushes======//
 db.pushes.ensureIndex({ "status" : 1, "location" : 1, "order" : 1 })
 
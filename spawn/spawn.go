@@ -7,11 +7,14 @@ import (
 	"fmt"
 	"github.com/10gen-labs/slogger/v1"
 	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/cloud"
 	"github.com/evergreen-ci/evergreen/cloud/providers"
 	"github.com/evergreen-ci/evergreen/command"
 	"github.com/evergreen-ci/evergreen/hostinit"
+	"github.com/evergreen-ci/evergreen/model"
 	"github.com/evergreen-ci/evergreen/model/distro"
 	"github.com/evergreen-ci/evergreen/model/host"
+	"github.com/evergreen-ci/evergreen/util"
 	"gopkg.in/yaml.v2"
 	"net/url"
 	"strings"
@@ -21,10 +24,18 @@ import (
 const (
 	MaxPerUser        = 3
 	DefaultExpiration = time.Duration(24 * time.Hour)
+
+	// defaults for the spawn policy in the settings
+	DefaultMaxExpirationHours = 24 * 7
+	DefaultHomeVolumeSize     = 50
+
+	// where the home volume is mounted, relative to the distro user's home
+	HomeVolumeMountDir = "home_volume"
 )
 
 var (
 	SpawnLimitErr = errors.New("User is already running the max allowed # of spawn hosts")
+	ExpirationErr = errors.New("Host expiration cannot be extended past the spawn host limit")
 )
 
 // BadOptionsErr represents an in valid set of spawn options.
@@ -47,6 +58,13 @@ type Options struct {
 	UserName  string
 	PublicKey string
 	UserData  string
+	// HomeVolume attaches the user's persistent home volume to the host,
+	// creating it if they don't have one yet.
+	HomeVolume bool
+	// TaskId, if set, is the task whose environment is set up on the host:
+	// its source, artifacts and expansions are fetched and the project's
+	// pre commands are run, so that the task can be reproduced.
+	TaskId string
 }
 
 // New returns an initialized Spawn controller.
@@ -67,6 +85,20 @@ func (sm Spawn) Validate(so Options) error {
 		return BadOptionsErr{fmt.Sprintf("Spawning not allowed for dist %v", so.Distro)}
 	}
 
+	if so.TaskId != "" {
+		t, err := model.FindTask(so.TaskId)
+		if err != nil {
+			return fmt.Errorf("Error occurred finding task %v: %v", so.TaskId, err)
+		}
+		if t == nil {
+			return BadOptionsErr{fmt.Sprintf("task %v not found", so.TaskId)}
+		}
+		if t.DistroId != "" && t.DistroId != d.Id {
+			return BadOptionsErr{fmt.Sprintf("task %v runs on distro %v, not %v",
+				so.TaskId, t.DistroId, d.Id)}
+		}
+	}
+
 	// if the user already has too many active spawned hosts, deny the request
 	activeSpawnedHosts, err := host.Find(host.ByUserWithRunningStatus(so.UserName))
 	if err != nil {
@@ -217,10 +249,33 @@ func (sm Spawn) CreateHost(so Options) (*host.Host, error) {
 		}
 	}
 
+	if so.HomeVolume {
+		if err = sm.attachHomeVolume(h, so.UserName); err != nil {
+			if err := h.SetDecommissioned(); err != nil {
+				evergreen.Logger.Logf(slogger.ERROR, "error decommissioning host %v: %v", h.Id, err)
+			}
+			return nil, fmt.Errorf("error attaching home volume to host %v; decommissioning: %v",
+				h.Id, err)
+		}
+	}
+
 	// modify the setup script to add the user's public key
 	h.Distro.Setup += fmt.Sprintf("\necho \"\n%v\" >> ~%v/.ssh/authorized_keys\n",
 		so.PublicKey, h.Distro.User)
 
+	// set up the task's environment once the rest of the host is ready
+	if so.TaskId != "" {
+		td, err := sm.loadTaskData(h, so.TaskId, so.UserName)
+		if err != nil {
+			return h, fmt.Errorf("error loading data of task %v: %v", so.TaskId, err)
+		}
+		taskSetup, err := td.setupScript()
+		if err != nil {
+			return h, fmt.Errorf("error building setup script for task %v: %v", so.TaskId, err)
+		}
+		h.Distro.Setup += taskSetup
+	}
+
 	// replace expansions in the script
 	exp := command.NewExpansions(init.Settings.Expansions)
 	h.Distro.Setup, err = exp.ExpandString(h.Distro.Setup)
@@ -236,3 +291,183 @@ func (sm Spawn) CreateHost(so Options) (*host.Host, error) {
 
 	return h, nil
 }
+
+// MaxExpiration returns the furthest into the future a spawn host's
+// expiration time may be set.
+func (sm Spawn) MaxExpiration() time.Duration {
+	hours := sm.settings.Spawn.MaxExpirationHours
+	if hours <= 0 {
+		hours = DefaultMaxExpirationHours
+	}
+	return time.Duration(hours) * time.Hour
+}
+
+// ExtendHost pushes back the host's expiration time by the given number of
+// hours. It returns ExpirationErr if that would exceed the spawn policy.
+func (sm Spawn) ExtendHost(h *host.Host, hours int) error {
+	if hours <= 0 {
+		return BadOptionsErr{"hours must be positive"}
+	}
+	newExpiration, err := checkExpiration(h.ExpirationTime, hours, sm.MaxExpiration(), time.Now())
+	if err != nil {
+		return err
+	}
+	return h.SetExpirationTime(newExpiration)
+}
+
+// checkExpiration returns the expiration time extended by the given hours, or
+// ExpirationErr if that is further than max from now.
+func checkExpiration(expiration time.Time, hours int, max time.Duration, now time.Time) (time.Time, error) {
+	newExpiration := expiration.Add(time.Duration(hours) * time.Hour)
+	if newExpiration.After(now.Add(max)) {
+		return time.Time{}, ExpirationErr
+	}
+	return newExpiration, nil
+}
+
+// StopHost stops a running spawn host. Its disks are kept, so it can be
+// started again later.
+func (sm Spawn) StopHost(h *host.Host) error {
+	if h.Status != evergreen.HostRunning {
+		return BadOptionsErr{fmt.Sprintf("host %v is not running", h.Id)}
+	}
+	cloudHost, err := providers.GetCloudHost(h, sm.settings)
+	if err != nil {
+		return err
+	}
+	if err = cloudHost.StopInstance(); err != nil {
+		return err
+	}
+	return h.SetStopped()
+}
+
+// StartHost starts a stopped spawn host and waits for it to come back up.
+// The host may come back with a new DNS name.
+func (sm Spawn) StartHost(h *host.Host) error {
+	if h.Status != evergreen.HostStopped {
+		return BadOptionsErr{fmt.Sprintf("host %v is not stopped", h.Id)}
+	}
+	cloudHost, err := providers.GetCloudHost(h, sm.settings)
+	if err != nil {
+		return err
+	}
+	if err = cloudHost.StartInstance(); err != nil {
+		return err
+	}
+
+	// the host stays marked as stopped until it's up, since the host
+	// initializer would try to provision it again if it looked new
+
+	startTime := time.Now()
+	for {
+		if time.Now().Sub(startTime) > 15*time.Minute {
+			return fmt.Errorf("host %v took too long to start", h.Id)
+		}
+		time.Sleep(5000 * time.Millisecond)
+
+		status, err := cloudHost.GetInstanceStatus()
+		if err != nil {
+			return fmt.Errorf("error checking status of host %v: %v", h.Id, err)
+		}
+		if status == cloud.StatusRunning {
+			break
+		}
+	}
+
+	dnsName, err := cloudHost.GetDNSName()
+	if err != nil {
+		return fmt.Errorf("error getting DNS name of host %v: %v", h.Id, err)
+	}
+	if err = h.SetDNSName(dnsName); err != nil {
+		return err
+	}
+	return h.SetRunning()
+}
+
+// DeleteVolume destroys one of the user's volumes. Volumes can't be
+// deleted while they're attached to a host.
+func (sm Spawn) DeleteVolume(v *host.Volume) error {
+	if v.HostId != "" {
+		return BadOptionsErr{fmt.Sprintf("volume %v is attached to host %v", v.Id, v.HostId)}
+	}
+	cloudManager, err := providers.GetCloudManager(v.Provider, sm.settings)
+	if err != nil {
+		return err
+	}
+	if err = cloudManager.DeleteVolume(v); err != nil {
+		return err
+	}
+	return v.Remove()
+}
+
+// attachHomeVolume attaches the user's free home volume to the host,
+// creating one if necessary, and adds the commands to mount it to the
+// host's setup script.
+func (sm Spawn) attachHomeVolume(h *host.Host, userName string) error {
+	cloudManager, err := providers.GetCloudManager(h.Provider, sm.settings)
+	if err != nil {
+		return err
+	}
+
+	volumes, err := host.FindVolumes(host.VolumesByUser(userName))
+	if err != nil {
+		return err
+	}
+	var volume *host.Volume
+	for i := range volumes {
+		if volumes[i].HostId == "" && volumes[i].Provider == h.Provider {
+			volume = &volumes[i]
+			break
+		}
+	}
+
+	if volume == nil {
+		size := sm.settings.Spawn.HomeVolumeSize
+		if size <= 0 {
+			size = DefaultHomeVolumeSize
+		}
+		volume, err = cloudManager.CreateVolume(h, size)
+		if err != nil {
+			return err
+		}
+		volume.Id = util.RandomString()
+		volume.CreatedBy = userName
+		volume.CreationTime = time.Now()
+		if err = volume.Insert(); err != nil {
+			return err
+		}
+	}
+
+	deviceName, err := cloudManager.AttachVolume(h, volume)
+	if err != nil {
+		return err
+	}
+	if err = volume.SetAttached(h.Id, deviceName); err != nil {
+		return err
+	}
+	if err = h.SetHomeVolume(volume.Id); err != nil {
+		return err
+	}
+	evergreen.Logger.Logf(slogger.INFO, "Attached volume %v to host %v as %v",
+		volume.Id, h.Id, deviceName)
+
+	h.Distro.Setup += mountVolumeScript(deviceName, h.Distro.User)
+	return nil
+}
+
+// mountVolumeScript returns the shell commands that format the volume on
+// first use and mount it in the user's home directory.
+func mountVolumeScript(deviceName, user string) string {
+	// devices attached as /dev/sdX can show up as /dev/xvdX
+	xvdName := strings.Replace(deviceName, "/dev/sd", "/dev/xvd", 1)
+	mountDir := fmt.Sprintf("~%v/%v", user, HomeVolumeMountDir)
+	return fmt.Sprintf(`
+dev=%v
+if [ ! -b $dev ] && [ -b %v ]; then dev=%v; fi
+for i in $(seq 1 60); do if [ -b $dev ]; then break; fi; sleep 2; done
+if ! blkid $dev; then mkfs -t ext4 $dev; fi
+mkdir -p %v
+mount $dev %v
+chown %v %v
+`, deviceName, xvdName, xvdName, mountDir, mountDir, user, mountDir)
+}
@@ -0,0 +1,42 @@
+package spawn
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckExpiration(t *testing.T) {
+	Convey("With a host expiring in a day and a week-long limit", t, func() {
+		now := time.Now()
+		expiration := now.Add(24 * time.Hour)
+		max := 7 * 24 * time.Hour
+
+		Convey("extending within the limit should push back the expiration", func() {
+			newExpiration, err := checkExpiration(expiration, 48, max, now)
+			So(err, ShouldBeNil)
+			So(newExpiration, ShouldResemble, expiration.Add(48*time.Hour))
+		})
+
+		Convey("extending right up to the limit should be allowed", func() {
+			_, err := checkExpiration(expiration, 6*24, max, now)
+			So(err, ShouldBeNil)
+		})
+
+		Convey("extending past the limit should fail", func() {
+			_, err := checkExpiration(expiration, 6*24+1, max, now)
+			So(err, ShouldEqual, ExpirationErr)
+		})
+	})
+}
+
+func TestMountVolumeScript(t *testing.T) {
+	Convey("The mount script should try both device names", t, func() {
+		script := mountVolumeScript("/dev/sdf", "ubuntu")
+		So(script, ShouldContainSubstring, "dev=/dev/sdf")
+		So(script, ShouldContainSubstring, "/dev/xvdf")
+		So(script, ShouldContainSubstring, "mount $dev ~ubuntu/home_volume")
+		So(strings.Contains(script, "${"), ShouldBeFalse) // would be taken as an expansion
+	})
+}
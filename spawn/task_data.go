@@ -0,0 +1,353 @@
+package spawn
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/command"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/artifact"
+	"github.com/evergreen-ci/evergreen/model/host"
+	"github.com/evergreen-ci/evergreen/model/patch"
+	"github.com/evergreen-ci/evergreen/model/version"
+	"github.com/evergreen-ci/evergreen/util"
+	"github.com/mitchellh/mapstructure"
+	"gopkg.in/yaml.v2"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	// files written to the task's working directory on the host
+	TaskExpansionsFile  = ".evergreen_expansions.yml"
+	TaskSetupScriptFile = ".evergreen_task_setup.sh"
+	TaskSetupLogFile    = "task_setup.log"
+	TaskArtifactsDir    = "artifacts"
+)
+
+// characters that aren't safe in the names of downloaded artifacts
+var unsafeFileChars = regexp.MustCompile(`[^\w\.\-]+`)
+
+// taskData holds everything needed to recreate a task's working directory
+// on a spawn host.
+type taskData struct {
+	Task       *model.Task
+	Project    *model.Project
+	ProjectRef *model.ProjectRef
+	// Patch is nil unless the task is part of a patch build
+	Patch      *patch.Patch
+	Artifacts  []artifact.File
+	Expansions *command.Expansions
+	WorkDir    string
+	User       string
+}
+
+// loadTaskData gathers the data of the task with the given id, to be set
+// up on the host for the user. The project's variables are only included for
+// users who can read them, i.e. superusers and the project's admins, since
+// they often hold credentials.
+func (sm Spawn) loadTaskData(h *host.Host, taskId, userId string) (*taskData, error) {
+	t, err := model.FindTask(taskId)
+	if err != nil {
+		return nil, fmt.Errorf("error finding task %v: %v", taskId, err)
+	}
+	if t == nil {
+		return nil, fmt.Errorf("task %v not found", taskId)
+	}
+
+	ref, err := model.FindOneProjectRef(t.Project)
+	if err != nil {
+		return nil, fmt.Errorf("error finding project ref %v: %v", t.Project, err)
+	}
+	if ref == nil {
+		return nil, fmt.Errorf("project ref %v not found", t.Project)
+	}
+
+	// the version holds the configuration the task ran with, patched or not
+	v, err := version.FindOne(version.ById(t.Version))
+	if err != nil {
+		return nil, fmt.Errorf("error finding version %v: %v", t.Version, err)
+	}
+	if v == nil {
+		return nil, fmt.Errorf("version %v not found", t.Version)
+	}
+	project := &model.Project{}
+	if err = model.LoadProjectInto([]byte(v.Config), t.Project, project); err != nil {
+		return nil, fmt.Errorf("error loading project config of version %v: %v", v.Id, err)
+	}
+
+	conf, err := model.NewTaskConfig(&h.Distro, project, t, ref)
+	if err != nil {
+		return nil, err
+	}
+	// the agent adds the project's variables to every task's expansions
+	if sm.canReadProjectVars(ref, userId) {
+		projectVars, err := model.FindMergedProjectVars(t.Project)
+		if err != nil {
+			return nil, fmt.Errorf("error finding variables of project %v: %v", t.Project, err)
+		}
+		if projectVars != nil {
+			conf.Expansions.Update(projectVars.Vars)
+		}
+	}
+
+	td := &taskData{
+		Task:       t,
+		Project:    project,
+		ProjectRef: ref,
+		Expansions: conf.Expansions,
+		WorkDir:    conf.WorkDir,
+		User:       h.Distro.User,
+	}
+
+	if t.Requester == evergreen.PatchVersionRequester {
+		td.Patch, err = patch.FindOne(patch.ByVersion(t.Version))
+		if err != nil {
+			return nil, fmt.Errorf("error finding patch for version %v: %v", t.Version, err)
+		}
+		if td.Patch == nil {
+			return nil, fmt.Errorf("no patch found for version %v", t.Version)
+		}
+		if err = td.Patch.FetchPatchFiles(); err != nil {
+			return nil, fmt.Errorf("error fetching patch files: %v", err)
+		}
+	}
+
+	entry, err := artifact.FindOne(artifact.ByTaskId(t.Id))
+	if err != nil {
+		return nil, fmt.Errorf("error finding artifacts of task %v: %v", t.Id, err)
+	}
+	if entry != nil {
+		for _, file := range entry.Files {
+			if !file.Expired {
+				td.Artifacts = append(td.Artifacts, file)
+			}
+		}
+	}
+	return td, nil
+}
+
+// canReadProjectVars returns true if the user is a superuser or one of the
+// project's admins. When no superusers are configured, every user is one.
+func (sm Spawn) canReadProjectVars(ref *model.ProjectRef, userId string) bool {
+	if len(sm.settings.SuperUsers) == 0 || ref.IsAdmin(userId) {
+		return true
+	}
+	return util.SliceContains(sm.settings.SuperUsers, userId)
+}
+
+// setupScript returns the commands to add to the host's setup script. They
+// write out the task's setup script and run it, logging to the working
+// directory, so that a failure to recreate the task doesn't fail the
+// provisioning of the host. The script is kept so the user can run it again.
+func (td *taskData) setupScript() (string, error) {
+	taskScript, err := td.taskScript()
+	if err != nil {
+		return "", err
+	}
+	scriptPath := filepath.ToSlash(filepath.Join(td.WorkDir, TaskSetupScriptFile))
+	logPath := filepath.ToSlash(filepath.Join(td.WorkDir, TaskSetupLogFile))
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "\nmkdir -p '%v'\n", td.WorkDir)
+	writeDecoded(buf, taskScript, scriptPath)
+	fmt.Fprintf(buf, "(cd '%v' && sh '%v' > '%v' 2>&1) || echo 'setting up task %v failed; see %v'\n",
+		td.WorkDir, scriptPath, logPath, td.Task.Id, logPath)
+	fmt.Fprintf(buf, "chown -R %v '%v'\n", td.User, td.WorkDir)
+	return buf.String(), nil
+}
+
+// taskScript returns a script that sets up the task's working directory:
+// it writes out the task's expansions, downloads its artifacts and runs the
+// project's pre commands. It is run from the working directory.
+func (td *taskData) taskScript() (string, error) {
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "echo 'Setting up task %v'\n", td.Task.Id)
+
+	if len(td.Artifacts) > 0 {
+		fmt.Fprintf(buf, "\necho 'Downloading %v artifacts'\n", len(td.Artifacts))
+		fmt.Fprintf(buf, "mkdir -p %v\n", TaskArtifactsDir)
+		for _, file := range td.Artifacts {
+			name := unsafeFileChars.ReplaceAllString(file.Name, "_")
+			fmt.Fprintf(buf, "curl -sSL -o '%v/%v' '%v' || echo 'failed to download %v'\n",
+				TaskArtifactsDir, name, strings.Replace(file.Link, "'", "%27", -1), name)
+		}
+	}
+
+	if td.Project.Pre != nil {
+		commands, err := td.preCommands()
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(buf, "\necho 'Running pre commands'\n")
+		for i, cmd := range commands {
+			if err = td.writeCommand(buf, i+1, len(commands), cmd); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	// written last, so that it includes the updates from the pre commands
+	expansionsYAML, err := yaml.Marshal(map[string]string(*td.Expansions))
+	if err != nil {
+		return "", fmt.Errorf("error marshalling expansions: %v", err)
+	}
+	fmt.Fprintf(buf, "\necho 'Writing expansions to %v'\n", TaskExpansionsFile)
+	writeDecoded(buf, string(expansionsYAML), TaskExpansionsFile)
+	fmt.Fprintf(buf, "echo 'Done setting up task %v'\n", td.Task.Id)
+	return buf.String(), nil
+}
+
+// preCommands returns the project's pre commands for the task's variant,
+// with functions replaced by the commands they're made of.
+func (td *taskData) preCommands() ([]model.PluginCommandConf, error) {
+	commands := []model.PluginCommandConf{}
+	for _, cmd := range td.Project.Pre.List() {
+		if !cmd.RunOnVariant(td.Task.BuildVariant) {
+			continue
+		}
+		if cmd.Function == "" {
+			commands = append(commands, cmd)
+			continue
+		}
+		funcCommands, ok := td.Project.Functions[cmd.Function]
+		if !ok {
+			return nil, fmt.Errorf("function '%v' not found in project functions", cmd.Function)
+		}
+		for _, funcCmd := range funcCommands.List() {
+			// commands in a function see the variables passed to it
+			if funcCmd.Vars == nil {
+				funcCmd.Vars = cmd.Vars
+			}
+			commands = append(commands, funcCmd)
+		}
+	}
+	return commands, nil
+}
+
+// writeCommand writes the shell equivalent of a pre command. Only the
+// commands needed to get a task's source and run its setup scripts are
+// supported; the rest need the agent and are skipped.
+func (td *taskData) writeCommand(buf *bytes.Buffer, step, total int, cmd model.PluginCommandConf) error {
+	for key, val := range cmd.Vars {
+		newVal, err := td.Expansions.ExpandString(val)
+		if err != nil {
+			return fmt.Errorf("can't expand '%v': %v", val, err)
+		}
+		td.Expansions.Put(key, newVal)
+	}
+
+	fmt.Fprintf(buf, "\necho 'Running %v (step %v of %v)'\n", cmd.Command, step, total)
+	switch cmd.Command {
+	case "git.get_project":
+		params := struct {
+			Directory string `mapstructure:"directory"`
+		}{}
+		if err := td.decodeParams(cmd, &params, &params.Directory); err != nil {
+			return err
+		}
+		location, err := td.ProjectRef.Location()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(buf, "rm -rf '%v'\n", params.Directory)
+		fmt.Fprintf(buf, "git clone %v '%v'\n", location, params.Directory)
+		fmt.Fprintf(buf, "(cd '%v' && git checkout %v)\n", params.Directory, td.Task.Revision)
+		td.writePatch(buf, params.Directory)
+	case "git.apply_patch":
+		params := struct {
+			Directory string `mapstructure:"directory"`
+		}{}
+		if err := td.decodeParams(cmd, &params, &params.Directory); err != nil {
+			return err
+		}
+		td.writePatch(buf, params.Directory)
+	case "shell.exec":
+		params := struct {
+			Script     string `mapstructure:"script"`
+			WorkingDir string `mapstructure:"working_dir"`
+		}{}
+		if err := td.decodeParams(cmd, &params, &params.Script, &params.WorkingDir); err != nil {
+			return err
+		}
+		scriptFile := fmt.Sprintf(".evergreen_pre_%v.sh", step)
+		writeDecoded(buf, params.Script, scriptFile)
+		workingDir := "."
+		if params.WorkingDir != "" {
+			workingDir = params.WorkingDir
+		}
+		fmt.Fprintf(buf, "(cd '%v' && sh \"$OLDPWD/%v\")\n", workingDir, scriptFile)
+	case "expansions.update":
+		params := struct {
+			Updates []struct {
+				Key    string `mapstructure:"key"`
+				Value  string `mapstructure:"value"`
+				Concat string `mapstructure:"concat"`
+			} `mapstructure:"updates"`
+		}{}
+		if err := mapstructure.Decode(cmd.Params, &params); err != nil {
+			return fmt.Errorf("error decoding %v params: %v", cmd.Command, err)
+		}
+		for _, update := range params.Updates {
+			if update.Concat == "" {
+				value, err := td.Expansions.ExpandString(update.Value)
+				if err != nil {
+					return err
+				}
+				td.Expansions.Put(update.Key, value)
+			} else {
+				concat, err := td.Expansions.ExpandString(update.Concat)
+				if err != nil {
+					return err
+				}
+				td.Expansions.Put(update.Key, td.Expansions.Get(update.Key)+concat)
+			}
+		}
+	default:
+		fmt.Fprintf(buf, "echo 'Skipping %v: it can only be run by the agent'\n", cmd.Command)
+	}
+	return nil
+}
+
+// writePatch writes the commands that apply the task's patch, if it has
+// one, to the project's source in the directory.
+func (td *taskData) writePatch(buf *bytes.Buffer, directory string) {
+	if td.Patch == nil {
+		return
+	}
+	for _, part := range td.Patch.Patches {
+		// modules aren't cloned, so their patches can't be applied
+		if part.ModuleName != "" || part.PatchSet.Patch == "" {
+			continue
+		}
+		writeDecoded(buf, part.PatchSet.Patch, ".evergreen_patch.diff")
+		fmt.Fprintf(buf, "(cd '%v' && git apply --whitespace=fix < \"$OLDPWD/.evergreen_patch.diff\")\n",
+			directory)
+	}
+}
+
+// decodeParams decodes the command's parameters and applies the task's
+// expansions to the given fields.
+func (td *taskData) decodeParams(cmd model.PluginCommandConf, params interface{}, fields ...*string) error {
+	if err := mapstructure.Decode(cmd.Params, params); err != nil {
+		return fmt.Errorf("error decoding %v params: %v", cmd.Command, err)
+	}
+	for _, field := range fields {
+		expanded, err := td.Expansions.ExpandString(*field)
+		if err != nil {
+			return fmt.Errorf("error expanding %v params: %v", cmd.Command, err)
+		}
+		*field = expanded
+	}
+	return nil
+}
+
+// writeDecoded writes a command that writes the contents to the file.
+// The contents are base64 encoded, so that they need no escaping and are
+// left alone when expansions are applied to the setup script.
+func writeDecoded(buf *bytes.Buffer, contents, fileName string) {
+	fmt.Fprintf(buf, "echo '%v' | base64 -d > '%v'\n",
+		base64.StdEncoding.EncodeToString([]byte(contents)), fileName)
+}
@@ -0,0 +1,138 @@
+package spawn
+
+import (
+	"encoding/base64"
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/command"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/artifact"
+	"github.com/evergreen-ci/evergreen/model/patch"
+	. "github.com/smartystreets/goconvey/convey"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var testProjectConfig = `
+functions:
+  "compile":
+    command: shell.exec
+    params:
+      working_dir: src
+      script: make ${target}
+pre:
+  - command: git.get_project
+    params:
+      directory: ${workdir}/src
+  - command: expansions.update
+    params:
+      updates:
+        - key: target
+          value: all
+  - func: "compile"
+  - command: s3.get
+    params:
+      remote_file: foo.tgz
+  - command: shell.exec
+    variants: ["other"]
+    params:
+      script: echo other
+`
+
+var encodedFile = regexp.MustCompile(`echo '([\w+/=]*)' \| base64 -d > '([^']*)'`)
+
+// decodedFiles returns the contents of the files written by the script
+func decodedFiles(script string) map[string]string {
+	files := map[string]string{}
+	for _, match := range encodedFile.FindAllStringSubmatch(script, -1) {
+		contents, err := base64.StdEncoding.DecodeString(match[1])
+		So(err, ShouldBeNil)
+		files[match[2]] = string(contents)
+	}
+	return files
+}
+
+func TestTaskScript(t *testing.T) {
+	Convey("With a task from a project with pre commands", t, func() {
+		project := &model.Project{}
+		So(model.LoadProjectInto([]byte(testProjectConfig), "proj", project), ShouldBeNil)
+		td := &taskData{
+			Task:       &model.Task{Id: "t1", BuildVariant: "linux", Revision: "abc123"},
+			Project:    project,
+			ProjectRef: &model.ProjectRef{Identifier: "proj", Owner: "evergreen-ci", Repo: "sample"},
+			Expansions: command.NewExpansions(map[string]string{"workdir": "/data/mci"}),
+			WorkDir:    "/data/mci",
+			User:       "ubuntu",
+		}
+
+		Convey("the source should be cloned at the task's revision", func() {
+			script, err := td.taskScript()
+			So(err, ShouldBeNil)
+			So(script, ShouldContainSubstring, "git clone git@github.com:evergreen-ci/sample.git '/data/mci/src'")
+			So(script, ShouldContainSubstring, "git checkout abc123")
+			So(script, ShouldNotContainSubstring, "git apply")
+		})
+
+		Convey("functions should be run with the expansions updated by earlier commands", func() {
+			script, err := td.taskScript()
+			So(err, ShouldBeNil)
+			files := decodedFiles(script)
+			So(files[".evergreen_pre_3.sh"], ShouldEqual, "make all")
+			So(script, ShouldContainSubstring, "cd 'src'")
+			So(files[TaskExpansionsFile], ShouldContainSubstring, "target: all")
+		})
+
+		Convey("commands that need the agent and other variants' commands should be skipped", func() {
+			script, err := td.taskScript()
+			So(err, ShouldBeNil)
+			So(script, ShouldContainSubstring, "Skipping s3.get")
+			So(script, ShouldNotContainSubstring, "step 5")
+		})
+
+		Convey("patches to the project should be applied", func() {
+			td.Patch = &patch.Patch{Patches: []patch.ModulePatch{
+				{PatchSet: patch.PatchSet{Patch: "diff --git a/x b/x ${not_an_expansion}"}},
+				{ModuleName: "enterprise", PatchSet: patch.PatchSet{Patch: "module diff"}},
+			}}
+			script, err := td.taskScript()
+			So(err, ShouldBeNil)
+			So(strings.Count(script, "git apply"), ShouldEqual, 1)
+			So(decodedFiles(script)[".evergreen_patch.diff"], ShouldStartWith, "diff --git")
+		})
+
+		Convey("artifacts should be downloaded", func() {
+			td.Artifacts = []artifact.File{{Name: "Test Logs", Link: "http://example.com/logs.tgz"}}
+			script, err := td.taskScript()
+			So(err, ShouldBeNil)
+			So(script, ShouldContainSubstring, "curl -sSL -o 'artifacts/Test_Logs' 'http://example.com/logs.tgz'")
+		})
+
+		Convey("the setup script should survive expansion and not fail provisioning", func() {
+			setup, err := td.setupScript()
+			So(err, ShouldBeNil)
+			_, err = command.NewExpansions(map[string]string{}).ExpandString(setup)
+			So(err, ShouldBeNil)
+			So(setup, ShouldNotContainSubstring, "${")
+			So(setup, ShouldContainSubstring, "|| echo 'setting up task t1 failed")
+			So(setup, ShouldContainSubstring, "chown -R ubuntu '/data/mci'")
+		})
+	})
+}
+
+func TestCanReadProjectVars(t *testing.T) {
+	Convey("With a project with an admin", t, func() {
+		ref := &model.ProjectRef{Identifier: "proj", Admins: []string{"admin"}}
+
+		Convey("only superusers and the project's admins should read its variables", func() {
+			sm := New(&evergreen.Settings{SuperUsers: []string{"root"}})
+			So(sm.canReadProjectVars(ref, "root"), ShouldBeTrue)
+			So(sm.canReadProjectVars(ref, "admin"), ShouldBeTrue)
+			So(sm.canReadProjectVars(ref, "someone"), ShouldBeFalse)
+		})
+
+		Convey("every user should read them when there are no superusers", func() {
+			sm := New(&evergreen.Settings{})
+			So(sm.canReadProjectVars(ref, "someone"), ShouldBeTrue)
+		})
+	})
+}
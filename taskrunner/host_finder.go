@@ -15,12 +15,21 @@ type DBHostFinder struct{}
 
 // FindAvailableHosts finds all hosts available to have a task run on them.
 // It fetches hosts from the database whose status is "running" and who have
-// no task currently being run on them.
+// no task currently being run on them, so quarantined hosts are never given
+// new tasks. Hosts running a pull agent fetch their own tasks, so they are
+// left out.
 func (self *DBHostFinder) FindAvailableHosts() ([]host.Host, error) {
 	// find and return any hosts not currently running a task
-	availableHosts, err := host.Find(host.IsAvailableAndFree)
+	freeHosts, err := host.Find(host.IsAvailableAndFree)
 	if err != nil {
 		return nil, err
 	}
+	availableHosts := make([]host.Host, 0, len(freeHosts))
+	for _, h := range freeHosts {
+		if h.Distro.PullAgent {
+			continue
+		}
+		availableHosts = append(availableHosts, h)
+	}
 	return availableHosts, nil
 }
@@ -91,6 +91,20 @@ func TestDBHostFinder(t *testing.T) {
 			So(availableHosts[1].Id, ShouldEqual, hosts[1].Id)
 		})
 
+		Convey("hosts running a pull agent should not be returned", func() {
+			hosts[2].Distro.PullAgent = true
+			for _, host := range hosts {
+				testutil.HandleTestingErr(host.Insert(), t, "Error inserting host"+
+					" into database")
+			}
+
+			availableHosts, err := hostFinder.FindAvailableHosts()
+			testutil.HandleTestingErr(err, t, "Error finding available hosts")
+			So(len(availableHosts), ShouldEqual, 2)
+			So(availableHosts[0].Id, ShouldEqual, hosts[0].Id)
+			So(availableHosts[1].Id, ShouldEqual, hosts[1].Id)
+		})
+
 	})
 
 }
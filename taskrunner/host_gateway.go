@@ -74,7 +74,8 @@ func (self *AgentBasedHostGateway) RunTaskOnHost(settings *evergreen.Settings,
 	evergreen.Logger.Logf(slogger.INFO, "Starting agent on host %v for task %v...",
 		hostObj.Id, taskToRun.Id)
 
-	err = self.startAgentOnRemote(settings, &taskToRun, &hostObj, sshOptions)
+	agentArgs := fmt.Sprintf(`-task_id "%v" -task_secret "%v"`, taskToRun.Id, taskToRun.Secret)
+	err = self.startAgentOnRemote(settings, &hostObj, sshOptions, agentArgs)
 	if err != nil {
 		return "", fmt.Errorf("error starting agent on %v for task %v: %v", hostObj.Id, taskToRun.Id, err)
 	}
@@ -83,6 +84,40 @@ func (self *AgentBasedHostGateway) RunTaskOnHost(settings *evergreen.Settings,
 	return agentRevision, nil
 }
 
+// StartPullAgent copies the agent to the host and starts it in pull mode, in
+// which it authenticates with the host's secret and asks the API server for
+// each task it runs. Returns the revision of the agent started.
+func (self *AgentBasedHostGateway) StartPullAgent(settings *evergreen.Settings,
+	hostObj host.Host) (string, error) {
+
+	if hostObj.Secret == "" {
+		return "", fmt.Errorf("host %v has no secret to start a pull agent with", hostObj.Id)
+	}
+
+	cloudHost, err := providers.GetCloudHost(&hostObj, settings)
+	if err != nil {
+		return "", fmt.Errorf("Failed to get cloud host for %v: %v", hostObj.Id, err)
+	}
+	sshOptions, err := cloudHost.GetSSHOptions()
+	if err != nil {
+		return "", fmt.Errorf("Error getting ssh options for host %v: %v", hostObj.Id, err)
+	}
+
+	evergreen.Logger.Logf(slogger.INFO, "Prepping remote host %v for a pull agent...", hostObj.Id)
+	agentRevision, err := self.prepRemoteHost(settings, hostObj, sshOptions, evergreen.FindEvergreenHome())
+	if err != nil {
+		return "", fmt.Errorf("error prepping remote host %v: %v", hostObj.Id, err)
+	}
+
+	agentArgs := fmt.Sprintf(`-host_id "%v" -host_secret "%v"`, hostObj.Id, hostObj.Secret)
+	if err = self.startAgentOnRemote(settings, &hostObj, sshOptions, agentArgs); err != nil {
+		return "", fmt.Errorf("error starting pull agent on %v: %v", hostObj.Id, err)
+	}
+	evergreen.Logger.Logf(slogger.INFO, "Pull agent successfully started on host %v", hostObj.Id)
+
+	return agentRevision, nil
+}
+
 // Gets the git revision of the currently built agent
 func (self *AgentBasedHostGateway) GetAgentRevision() (string, error) {
 
@@ -104,12 +139,24 @@ func executableSubPath(id string) (string, error) {
 		return "", fmt.Errorf("error finding distro %v: %v", id, err)
 	}
 
+	return agentSubPath(d.Arch), nil
+}
+
+// agentSubPath returns the path of the agent compiled for the architecture,
+// within the directory containing the compiled agents.
+func agentSubPath(arch string) string {
 	mainName := "main"
-	if strings.HasPrefix(d.Arch, "windows") {
+	if strings.HasPrefix(arch, "windows") {
 		mainName = "main.exe"
 	}
 
-	return filepath.Join("snapshot", d.Arch, mainName), nil
+	return filepath.Join("snapshot", arch, mainName)
+}
+
+// AgentExecutablePath returns the path to the agent compiled for the
+// architecture.
+func (self *AgentBasedHostGateway) AgentExecutablePath(arch string) string {
+	return filepath.Join(self.ExecutablesDir, agentSubPath(arch))
 }
 
 // Prepare the remote machine to run a task.
@@ -229,23 +276,25 @@ func (self *AgentBasedHostGateway) prepRemoteHost(settings *evergreen.Settings,
 	return preSCPAgentRevision, nil
 }
 
-// Start the agent process on the specified remote host, and have it run
-// the specified task.
+// Start the agent process on the specified remote host, passing it the
+// given arguments to identify the task (or, for pull agents, the host) it
+// runs as.
 // Returns an error if starting the agent remotely fails.
 func (self *AgentBasedHostGateway) startAgentOnRemote(
-	settings *evergreen.Settings, task *model.Task,
-	hostObj *host.Host, sshOptions []string) error {
+	settings *evergreen.Settings, hostObj *host.Host, sshOptions []string,
+	agentArgs string) error {
 
 	// the path to the agent binary on the remote machine
 	pathToExecutable := filepath.Join(hostObj.Distro.WorkDir, "main")
 
 	// build the command to run on the remote machine
 	remoteCmd := fmt.Sprintf(
-		`%v -api_server "%v" -task_id "%v" -task_secret "%v" -log_prefix "%v" -https_cert "%v"`,
-		pathToExecutable, settings.ApiUrl, task.Id, task.Secret, filepath.Join(hostObj.Distro.WorkDir,
+		`%v -api_server "%v" %v -log_prefix "%v" -https_cert "%v"`,
+		pathToExecutable, settings.ApiUrl, agentArgs, filepath.Join(hostObj.Distro.WorkDir,
 			agentFile), settings.Expansions["api_httpscert_path"],
 	)
-	evergreen.Logger.Logf(slogger.INFO, "%v", remoteCmd)
+	// the agent's arguments include its secret, so they aren't logged
+	evergreen.Logger.Logf(slogger.INFO, "Starting agent %v on host %v", pathToExecutable, hostObj.Id)
 
 	// compute any info necessary to ssh into the host
 	hostInfo, err := util.ParseSSHInfo(hostObj.Host)
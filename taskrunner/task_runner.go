@@ -177,6 +177,15 @@ func DispatchTaskForHost(taskQueue *model.TaskQueue, assignedHost *host.Host) (
 			continue
 		}
 
+		// don't send a task back to a host it hit a system failure on; the
+		// scheduler puts it back in the queue for another host to pick up
+		if nextTask.IsExcludedHost(assignedHost.Id) {
+			evergreen.Logger.Logf(slogger.INFO, "Skipping task %v on host %v, "+
+				"which it previously hit a system failure on", nextTask.Id,
+				assignedHost.Id)
+			continue
+		}
+
 		// record that the task was dispatched on the host
 		err = nextTask.MarkAsDispatched(assignedHost, time.Now())
 		if err != nil {
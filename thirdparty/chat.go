@@ -0,0 +1,72 @@
+package thirdparty
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// how long to wait for the chat webhook to accept a message
+const chatPostTimeout = 30 * time.Second
+
+// Colors of chat message attachments
+const (
+	ChatColorGood    = "good"
+	ChatColorWarning = "warning"
+	ChatColorDanger  = "danger"
+)
+
+// ChatMessage is a message posted to a Slack or Mattermost compatible
+// incoming webhook. An empty Channel posts to the webhook's default channel;
+// channels are written as "#channel" and direct messages as "@handle".
+type ChatMessage struct {
+	Channel     string           `json:"channel,omitempty"`
+	Username    string           `json:"username,omitempty"`
+	IconURL     string           `json:"icon_url,omitempty"`
+	Text        string           `json:"text"`
+	Attachments []ChatAttachment `json:"attachments,omitempty"`
+}
+
+// ChatAttachment is the rich, formatted part of a chat message.
+type ChatAttachment struct {
+	Fallback  string      `json:"fallback"`
+	Color     string      `json:"color,omitempty"`
+	Title     string      `json:"title,omitempty"`
+	TitleLink string      `json:"title_link,omitempty"`
+	Text      string      `json:"text,omitempty"`
+	Fields    []ChatField `json:"fields,omitempty"`
+}
+
+// ChatField is a labeled value shown in a chat message attachment.
+type ChatField struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short"`
+}
+
+// ChatLink formats a link for a chat message.
+func ChatLink(url, text string) string {
+	return fmt.Sprintf("<%v|%v>", url, text)
+}
+
+// PostChatMessage posts the message to the incoming webhook.
+func PostChatMessage(webhookURL string, message *ChatMessage) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Timeout: chatPostTimeout}
+	resp, err := client.Post(webhookURL, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("error posting chat message: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("chat webhook returned %v: %v", resp.Status, string(body))
+	}
+	return nil
+}
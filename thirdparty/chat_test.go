@@ -0,0 +1,50 @@
+package thirdparty
+
+import (
+	"encoding/json"
+	. "github.com/smartystreets/goconvey/convey"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPostChatMessage(t *testing.T) {
+	Convey("With an incoming webhook", t, func() {
+		var received *ChatMessage
+		status := http.StatusOK
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received = &ChatMessage{}
+			if err := json.NewDecoder(r.Body).Decode(received); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.WriteHeader(status)
+		}))
+		Reset(server.Close)
+
+		message := &ChatMessage{
+			Channel: "#builds",
+			Text:    "compile failed",
+			Attachments: []ChatAttachment{{
+				Fallback: "compile failed",
+				Color:    ChatColorDanger,
+				Title:    "compile on linux-64",
+				Fields:   []ChatField{{Title: "Failed tests", Value: "a.js"}},
+			}},
+		}
+
+		Convey("messages should be posted as json", func() {
+			So(PostChatMessage(server.URL, message), ShouldBeNil)
+			So(received, ShouldNotBeNil)
+			So(received.Channel, ShouldEqual, "#builds")
+			So(received.Text, ShouldEqual, "compile failed")
+			So(len(received.Attachments), ShouldEqual, 1)
+			So(received.Attachments[0].Fields[0].Value, ShouldEqual, "a.js")
+		})
+
+		Convey("errors from the webhook should be returned", func() {
+			status = http.StatusNotFound
+			So(PostChatMessage(server.URL, message), ShouldNotBeNil)
+		})
+	})
+}
@@ -0,0 +1,109 @@
+package thirdparty
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"strings"
+)
+
+// Gerrit prefixes its JSON responses with this to prevent XSSI
+const gerritResponsePrefix = ")]}'"
+
+type GerritAccount struct {
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Username string `json:"username"`
+}
+
+type GerritRevision struct {
+	Number int    `json:"_number"`
+	Ref    string `json:"ref"`
+}
+
+type GerritChange struct {
+	Id              string                    `json:"id"`
+	Number          int                       `json:"_number"`
+	Project         string                    `json:"project"`
+	Branch          string                    `json:"branch"`
+	Subject         string                    `json:"subject"`
+	Owner           GerritAccount             `json:"owner"`
+	CurrentRevision string                    `json:"current_revision"`
+	Revisions       map[string]GerritRevision `json:"revisions"`
+}
+
+// CurrentPatchSet returns the change's latest patch set.
+func (c *GerritChange) CurrentPatchSet() (GerritRevision, bool) {
+	revision, ok := c.Revisions[c.CurrentRevision]
+	return revision, ok
+}
+
+type GerritHandler struct {
+	MyHttp   httpGet
+	Server   string
+	UserName string
+	Password string
+}
+
+// GetOpenChanges returns the open changes to the branch of the Gerrit project,
+// along with their current patch sets.
+func (gerritHandler *GerritHandler) GetOpenChanges(project, branch string) ([]GerritChange, error) {
+	query := fmt.Sprintf("status:open project:%v branch:%v", project, branch)
+	apiEndpoint := fmt.Sprintf("%v/changes/?q=%v&o=CURRENT_REVISION&o=DETAILED_ACCOUNTS",
+		gerritHandler.baseURL(), url.QueryEscape(query))
+
+	res, err := gerritHandler.MyHttp.doGet(apiEndpoint, gerritHandler.UserName, gerritHandler.Password)
+	if res != nil {
+		defer res.Body.Close()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		return nil, fmt.Errorf("HTTP results are nil even though err was nil")
+	}
+
+	if res.StatusCode >= 300 || res.StatusCode < 200 {
+		return nil, fmt.Errorf("HTTP request returned unexpected status `%v`", res.Status)
+	}
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read http body: %v", err.Error())
+	}
+
+	changes := []GerritChange{}
+	body = bytes.TrimPrefix(body, []byte(gerritResponsePrefix))
+	if err = json.Unmarshal(body, &changes); err != nil {
+		return nil, APIUnmarshalError{string(body), err.Error()}
+	}
+	return changes, nil
+}
+
+// baseURL returns the root of the REST API, which is under /a/ for
+// authenticated requests.
+func (gerritHandler *GerritHandler) baseURL() string {
+	base := strings.TrimRight(gerritHandler.Server, "/")
+	if gerritHandler.UserName != "" {
+		base += "/a"
+	}
+	return base
+}
+
+// NewGerritHandler returns a handler for the Gerrit server at the given URL,
+// authenticating with the credentials if they are given in the form
+// "username:password".
+func NewGerritHandler(server, credentials string) GerritHandler {
+	var username, password string
+	if idx := strings.Index(credentials, ":"); idx != -1 {
+		username, password = credentials[:idx], credentials[idx+1:]
+	}
+	return GerritHandler{
+		liveHttpGet{},
+		server,
+		username,
+		password,
+	}
+}
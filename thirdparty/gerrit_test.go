@@ -0,0 +1,52 @@
+package thirdparty
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestGerritOpenChanges(t *testing.T) {
+	Convey("With a Gerrit rest interface", t, func() {
+		Convey("open changes should be parsed from the response", func() {
+			body := `)]}'
+[{"id": "proj~master~I1", "_number": 12, "project": "proj", "branch": "master",
+  "subject": "Fix the thing", "owner": {"name": "Jane", "username": "jane"},
+  "current_revision": "abc",
+  "revisions": {"abc": {"_number": 3, "ref": "refs/changes/12/12/3"}}}]`
+			stub := stubHttpGet{&http.Response{StatusCode: 200, Status: "200 OK",
+				Body: ioutil.NopCloser(strings.NewReader(body))}, nil}
+			gerrit := GerritHandler{stub, "https://gerrit.example.com", "", ""}
+
+			changes, err := gerrit.GetOpenChanges("proj", "master")
+			So(err, ShouldBeNil)
+			So(len(changes), ShouldEqual, 1)
+			So(changes[0].Number, ShouldEqual, 12)
+			So(changes[0].Owner.Username, ShouldEqual, "jane")
+			patchSet, ok := changes[0].CurrentPatchSet()
+			So(ok, ShouldBeTrue)
+			So(patchSet.Number, ShouldEqual, 3)
+			So(patchSet.Ref, ShouldEqual, "refs/changes/12/12/3")
+		})
+
+		Convey("an unauthorized response should be an error", func() {
+			stub := stubHttpGet{&http.Response{StatusCode: 401,
+				Status: "401 Unauthorized",
+				Body:   ioutil.NopCloser(strings.NewReader(""))}, nil}
+			gerrit := GerritHandler{stub, "https://gerrit.example.com", "user", "pass"}
+
+			changes, err := gerrit.GetOpenChanges("proj", "master")
+			So(changes, ShouldBeNil)
+			So(err.Error(), ShouldEqual, "HTTP request returned unexpected status `401 Unauthorized`")
+		})
+
+		Convey("credentials should be split into a username and password", func() {
+			gerrit := NewGerritHandler("https://gerrit.example.com/", "user:pa:ss")
+			So(gerrit.UserName, ShouldEqual, "user")
+			So(gerrit.Password, ShouldEqual, "pa:ss")
+			So(gerrit.baseURL(), ShouldEqual, "https://gerrit.example.com/a")
+		})
+	})
+}
@@ -8,10 +8,29 @@ import (
 	"github.com/evergreen-ci/evergreen/util"
 	"io"
 	"io/ioutil"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
+)
+
+// GitCommit holds the details of a commit read from a local repository.
+type GitCommit struct {
+	Revision    string
+	Author      string
+	AuthorEmail string
+	Message     string
+	CreateTime  time.Time
+}
+
+// separate the fields of each commit and the commits themselves in the output
+// of git log
+const (
+	gitLogFieldSeparator  = "\x00"
+	gitLogCommitSeparator = "\x1e"
+	gitLogFormat          = "--format=%H%x00%an%x00%ae%x00%ct%x00%B%x1e"
 )
 
 // GitApplyNumstat attempts to apply a given patch; it returns the patch's bytes
@@ -109,3 +128,156 @@ func ParseGitSummary(gitOutput *bytes.Buffer) (summaries []Summary, err error) {
 	}
 	return summaries, nil
 }
+
+// runGit runs git with the given arguments in dir, returning its output.
+func runGit(dir string, args ...string) ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %v: %v (%v)", strings.Join(args, " "), err,
+			strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// GitUpdateMirror creates a bare mirror of the branches of the remote
+// repository in dir, if it doesn't exist already, and fetches the latest
+// changes to them.
+func GitUpdateMirror(remoteURL, dir string) error {
+	if _, err := os.Stat(filepath.Join(dir, "HEAD")); os.IsNotExist(err) {
+		if err = os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("couldn't create mirror directory: %v", err)
+		}
+		if _, err = runGit(dir, "init", "--bare"); err != nil {
+			return err
+		}
+		if _, err = runGit(dir, "remote", "add", "origin", remoteURL); err != nil {
+			return err
+		}
+		// only mirror branches, not other refs such as code review changes
+		if _, err = runGit(dir, "config", "remote.origin.fetch",
+			"+refs/heads/*:refs/heads/*"); err != nil {
+			return err
+		}
+	} else if _, err = runGit(dir, "remote", "set-url", "origin", remoteURL); err != nil {
+		return err
+	}
+	_, err := runGit(dir, "fetch", "--prune", "origin")
+	return err
+}
+
+// GitLog returns up to max commits (or all, if max <= 0) in the revision
+// range, such as "master" or "abc123..master", most recent first.
+func GitLog(dir, revisionRange string, max int) ([]GitCommit, error) {
+	args := []string{"log", gitLogFormat}
+	if max > 0 {
+		args = append(args, fmt.Sprintf("-n%v", max))
+	}
+	out, err := runGit(dir, append(args, revisionRange, "--")...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGitLog(out)
+}
+
+// ParseGitLog parses the output of git log in the format used by GitLog.
+func ParseGitLog(output []byte) ([]GitCommit, error) {
+	commits := []GitCommit{}
+	for _, entry := range strings.Split(string(output), gitLogCommitSeparator) {
+		entry = strings.TrimLeft(entry, "\n")
+		if entry == "" {
+			continue
+		}
+		fields := strings.SplitN(entry, gitLogFieldSeparator, 5)
+		if len(fields) != 5 {
+			return nil, fmt.Errorf("unexpected git log entry '%v'", entry)
+		}
+		timestamp, err := strconv.ParseInt(fields[3], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("bad commit time '%v' for %v: %v", fields[3],
+				fields[0], err)
+		}
+		commits = append(commits, GitCommit{
+			Revision:    fields[0],
+			Author:      fields[1],
+			AuthorEmail: fields[2],
+			CreateTime:  time.Unix(timestamp, 0),
+			Message:     strings.TrimSpace(fields[4]),
+		})
+	}
+	return commits, nil
+}
+
+// GitRevisionExists returns true if the revision is a commit in the
+// repository in dir.
+func GitRevisionExists(dir, revision string) bool {
+	_, err := runGit(dir, "cat-file", "-e", revision+"^{commit}")
+	return err == nil
+}
+
+// GitIsAncestor returns true if ancestor is an ancestor of (or the same
+// commit as) revision.
+func GitIsAncestor(dir, ancestor, revision string) bool {
+	_, err := runGit(dir, "merge-base", "--is-ancestor", ancestor, revision)
+	return err == nil
+}
+
+// GitMergeBase returns the best common ancestor of the two revisions.
+func GitMergeBase(dir, a, b string) (string, error) {
+	out, err := runGit(dir, "merge-base", a, b)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// GitShowFile returns the contents of the file at the given path as of the
+// revision, or a FileNotFoundError if there is no such file.
+func GitShowFile(dir, revision, path string) ([]byte, error) {
+	object := fmt.Sprintf("%v:%v", revision, path)
+	if _, err := runGit(dir, "cat-file", "-e", object); err != nil {
+		return nil, FileNotFoundError{object}
+	}
+	return runGit(dir, "show", object)
+}
+
+// GitFetchRef fetches a single ref, such as a code review change, from the
+// remote repository into the repository in dir, and returns its revision.
+func GitFetchRef(dir, ref string) (string, error) {
+	if _, err := runGit(dir, "fetch", "origin", ref); err != nil {
+		return "", err
+	}
+	out, err := runGit(dir, "rev-parse", "FETCH_HEAD")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// GitBranches returns the names of the branches in the repository in dir.
+func GitBranches(dir string) ([]string, error) {
+	out, err := runGit(dir, "for-each-ref", "--format=%(refname:short)", "refs/heads/")
+	if err != nil {
+		return nil, err
+	}
+	branches := []string{}
+	for _, branch := range strings.Split(string(out), "\n") {
+		if branch = strings.TrimSpace(branch); branch != "" {
+			branches = append(branches, branch)
+		}
+	}
+	return branches, nil
+}
+
+// GitDiff returns the diff between the two revisions, in a form that can be
+// applied with git apply.
+func GitDiff(dir, from, to string) (string, error) {
+	out, err := runGit(dir, "diff", "--binary", "--full-index", from, to)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
@@ -1,10 +1,15 @@
 package thirdparty
 
 import (
+	"fmt"
 	"github.com/evergreen-ci/evergreen"
 	"github.com/evergreen-ci/evergreen/db"
 	"github.com/evergreen-ci/evergreen/testutil"
 	. "github.com/smartystreets/goconvey/convey"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"testing"
 )
 
@@ -31,3 +36,92 @@ func TestGetGithubCommits(t *testing.T) {
 		})
 	})
 }
+
+func TestParseGitLog(t *testing.T) {
+	Convey("When parsing git log output", t, func() {
+		output := "abc\x00Jane Doe\x00jane@example.com\x001400000000\x00" +
+			"first line\n\nbody\n\x1e\ndef\x00John\x00john@example.com\x00" +
+			"1300000000\x00second\n\x1e\n"
+		commits, err := ParseGitLog([]byte(output))
+		So(err, ShouldBeNil)
+		So(len(commits), ShouldEqual, 2)
+		So(commits[0].Revision, ShouldEqual, "abc")
+		So(commits[0].Author, ShouldEqual, "Jane Doe")
+		So(commits[0].AuthorEmail, ShouldEqual, "jane@example.com")
+		So(commits[0].Message, ShouldEqual, "first line\n\nbody")
+		So(commits[0].CreateTime.Unix(), ShouldEqual, 1400000000)
+		So(commits[1].Revision, ShouldEqual, "def")
+
+		_, err = ParseGitLog([]byte("abc\x00incomplete\x1e"))
+		So(err, ShouldNotBeNil)
+	})
+}
+
+func TestGitMirror(t *testing.T) {
+	Convey("With a local repository to mirror", t, func() {
+		root, err := ioutil.TempDir("", "git_mirror_test")
+		So(err, ShouldBeNil)
+		Reset(func() { os.RemoveAll(root) })
+
+		remote := filepath.Join(root, "remote")
+		mirror := filepath.Join(root, "mirror")
+		git := func(args ...string) {
+			cmd := exec.Command("git", append([]string{"-c", "user.name=Test",
+				"-c", "user.email=test@example.com"}, args...)...)
+			cmd.Dir = remote
+			_, err := cmd.CombinedOutput()
+			So(err, ShouldBeNil)
+		}
+		commit := func(i int) {
+			So(ioutil.WriteFile(filepath.Join(remote, "config.yml"),
+				[]byte(fmt.Sprintf("version: %v\n", i)), 0644), ShouldBeNil)
+			git("add", "config.yml")
+			git("commit", "-m", fmt.Sprintf("commit %v", i))
+		}
+		So(os.MkdirAll(remote, 0755), ShouldBeNil)
+		git("init")
+		git("symbolic-ref", "HEAD", "refs/heads/master")
+		commit(1)
+		commit(2)
+
+		Convey("the mirror should have the remote's commits", func() {
+			So(GitUpdateMirror(remote, mirror), ShouldBeNil)
+			commits, err := GitLog(mirror, "refs/heads/master", 0)
+			So(err, ShouldBeNil)
+			So(len(commits), ShouldEqual, 2)
+			So(commits[0].Message, ShouldEqual, "commit 2")
+			So(commits[0].Author, ShouldEqual, "Test")
+
+			file, err := GitShowFile(mirror, commits[1].Revision, "config.yml")
+			So(err, ShouldBeNil)
+			So(string(file), ShouldEqual, "version: 1\n")
+			_, err = GitShowFile(mirror, commits[1].Revision, "missing.yml")
+			So(IsFileNotFound(err), ShouldBeTrue)
+
+			Convey("and updating it should fetch new commits", func() {
+				commit(3)
+				So(GitUpdateMirror(remote, mirror), ShouldBeNil)
+				newCommits, err := GitLog(mirror,
+					commits[0].Revision+"..refs/heads/master", 0)
+				So(err, ShouldBeNil)
+				So(len(newCommits), ShouldEqual, 1)
+				So(newCommits[0].Message, ShouldEqual, "commit 3")
+				So(GitIsAncestor(mirror, commits[1].Revision, "refs/heads/master"),
+					ShouldBeTrue)
+				So(GitRevisionExists(mirror, newCommits[0].Revision), ShouldBeTrue)
+				So(GitRevisionExists(mirror, "0123456789012345678901234567890123456789"),
+					ShouldBeFalse)
+
+				git("branch", "v3.0")
+				So(GitUpdateMirror(remote, mirror), ShouldBeNil)
+				branches, err := GitBranches(mirror)
+				So(err, ShouldBeNil)
+				So(branches, ShouldResemble, []string{"master", "v3.0"})
+
+				diff, err := GitDiff(mirror, commits[0].Revision, newCommits[0].Revision)
+				So(err, ShouldBeNil)
+				So(diff, ShouldContainSubstring, "+version: 3")
+			})
+		})
+	})
+}
@@ -19,6 +19,12 @@ const (
 	NumGithubRetries    = 3
 	GithubSleepTimeSecs = 1
 	GithubAPIBase       = "https://api.github.com"
+
+	// commit status states
+	GithubStatusPending = "pending"
+	GithubStatusSuccess = "success"
+	GithubStatusFailure = "failure"
+	GithubStatusError   = "error"
 )
 
 type GithubUser struct {
@@ -246,6 +252,72 @@ func GetBranchEvent(oauthToken, repoOwner, repo, branch string) (*BranchEvent,
 	return branchEvent, nil
 }
 
+// GetGithubBranches returns the names of all of the repository's branches
+func GetGithubBranches(oauthToken, repoOwner, repo string) ([]string, error) {
+	branchesURL := fmt.Sprintf("%v/repos/%v/%v/branches", GithubAPIBase,
+		repoOwner, repo)
+
+	branches := []string{}
+	for branchesURL != "" {
+		resp, err := tryGithubGet(oauthToken, branchesURL)
+		if err != nil || resp == nil {
+			if resp != nil {
+				resp.Body.Close()
+			}
+			errMsg := fmt.Sprintf("error querying ‘%v’: %v", branchesURL, err)
+			evergreen.Logger.Logf(slogger.ERROR, errMsg)
+			return nil, APIResponseError{errMsg}
+		}
+
+		// read each page in full before requesting the next
+		respBody, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, ResponseReadError{err.Error()}
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			requestError := APIRequestError{}
+			if err = json.Unmarshal(respBody, &requestError); err != nil {
+				return nil, APIRequestError{Message: string(respBody)}
+			}
+			return nil, requestError
+		}
+
+		page := []BranchEvent{}
+		if err = json.Unmarshal(respBody, &page); err != nil {
+			return nil, APIUnmarshalError{string(respBody), err.Error()}
+		}
+		for _, branch := range page {
+			branches = append(branches, branch.Name)
+		}
+		branchesURL = NextGithubPageLink(resp.Header)
+	}
+	return branches, nil
+}
+
+// SetGithubStatus sets the status of the revision of the repository. Statuses
+// with the same context replace each other.
+func SetGithubStatus(oauthToken, owner, repo, revision string, status GithubStatus) error {
+	statusURL := fmt.Sprintf("%v/repos/%v/%v/statuses/%v", GithubAPIBase, owner, repo, revision)
+	resp, err := tryGithubPost(statusURL, oauthToken, status)
+	if resp != nil {
+		defer resp.Body.Close()
+	}
+	if err != nil {
+		return fmt.Errorf("error setting status on %v: %v", statusURL, err)
+	}
+	if resp == nil {
+		return fmt.Errorf("nil response from url ‘%v’", statusURL)
+	}
+	if resp.StatusCode != http.StatusCreated {
+		respBody, _ := ioutil.ReadAll(resp.Body)
+		return APIResponseError{fmt.Sprintf("setting status on %v returned %v: %v",
+			statusURL, resp.Status, string(respBody))}
+	}
+	return nil
+}
+
 // githubRequest performs the specified http request. If the oauth token field is empty it will not use oauth
 func githubRequest(method string, url string, oauthToken string, data interface{}) (*http.Response, error) {
 	req, err := http.NewRequest(method, url, nil)
@@ -169,3 +169,12 @@ type GitHubCompareResponse struct {
 	AheadBy         int             `json:"ahead_by"`
 	Status          string          `json:"status"`
 }
+
+// GithubStatus is a commit status, shown next to the revision on GitHub.
+// State is one of the GithubStatus* constants.
+type GithubStatus struct {
+	State       string `json:"state"`
+	TargetURL   string `json:"target_url,omitempty"`
+	Description string `json:"description,omitempty"`
+	Context     string `json:"context"`
+}
@@ -1,9 +1,11 @@
 package thirdparty
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"net/http"
 	"net/url"
 )
 
@@ -26,6 +28,13 @@ type JiraSearchResults struct {
 	Issues     []JiraTicket `json:"issues"`
 }
 
+// JiraCreateTicketResponse unmarshals from the json returned by the rest api
+// at /rest/api/latest/issue when a ticket is created
+type JiraCreateTicketResponse struct {
+	jiraBase
+	Key string `json:"key"`
+}
+
 type TicketFields struct {
 	IssueType   *TicketType  `json:"issuetype"`
 	Summary     string       `json:"summary"`
@@ -36,6 +45,8 @@ type TicketFields struct {
 	Created     string       `json:"created"`
 	Updated     string       `json:"updated"`
 	Status      *JiraStatus  `json:"status"`
+	// Resolution is nil until the ticket is resolved
+	Resolution *JiraResolution `json:"resolution"`
 }
 
 type jiraBase struct {
@@ -48,6 +59,11 @@ type JiraStatus struct {
 	Name string `json:"name"`
 }
 
+type JiraResolution struct {
+	jiraBase
+	Name string `json:"name"`
+}
+
 type TicketType struct {
 	jiraBase
 	Description string `json:"description"`
@@ -147,6 +163,43 @@ func (jiraHandler *JiraHandler) JQLSearch(query string) (*JiraSearchResults, err
 	return results, nil
 }
 
+// CreateTicket creates a ticket with the given fields, which are keyed by
+// their JIRA field names (e.g. "project", "summary" and "issuetype").
+func (jiraHandler *JiraHandler) CreateTicket(fields map[string]interface{}) (*JiraCreateTicketResponse, error) {
+	apiEndpoint := fmt.Sprintf("https://%v/rest/api/latest/issue", jiraHandler.JiraServer)
+	data, err := json.Marshal(map[string]interface{}{"fields": fields})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", apiEndpoint, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(jiraHandler.UserName, jiraHandler.Password)
+	req.Header.Add("Content-Type", "application/json")
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read http body: %v", err.Error())
+	}
+	if res.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("HTTP request returned unexpected status `%v`: %v", res.Status, string(body))
+	}
+
+	created := &JiraCreateTicketResponse{}
+	if err = json.Unmarshal(body, created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
 func NewJiraHandler(server string, user string, password string) JiraHandler {
 	return JiraHandler{
 		liveHttpGet{},
@@ -3,6 +3,9 @@ package ui
 import (
 	"encoding/json"
 	"fmt"
+	"github.com/10gen-labs/slogger/v1"
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/hostinit"
 	"github.com/evergreen-ci/evergreen/model/distro"
 	"github.com/evergreen-ci/evergreen/model/event"
 	"github.com/evergreen-ci/evergreen/model/user"
@@ -174,6 +177,82 @@ func (uis *UIServer) addDistro(w http.ResponseWriter, r *http.Request) {
 	uis.WriteJSON(w, http.StatusOK, "distro successfully added")
 }
 
+// dryRunRequest is the distro configuration to test, which needn't be saved,
+// along with the command to run on its host once it's set up.
+type dryRunRequest struct {
+	Distro distro.Distro `json:"distro"`
+	Canary string        `json:"canary"`
+}
+
+// dryRunDistro starts a dry run of the requested distro configuration, and
+// returns the run for the client to poll while it provisions the host.
+func (uis *UIServer) dryRunDistro(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["distro_id"]
+
+	u := MustHaveUser(r)
+
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("error reading request: %v", err), http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	req := dryRunRequest{}
+	if err = json.Unmarshal(b, &req); err != nil {
+		http.Error(w, fmt.Sprintf("error unmarshaling request: %v", err), http.StatusBadRequest)
+		return
+	}
+	d := req.Distro
+	d.Id = id
+
+	// don't spend a host on a configuration we already know is invalid
+	vErrs := validator.CheckDistro(&d, &uis.Settings, false)
+	if len(vErrs) != 0 {
+		uis.WriteJSON(w, http.StatusBadRequest, vErrs)
+		return
+	}
+
+	// dry run hosts are set up over SSH, so they'd never run the user data
+	// that sets up hosts of these distros
+	if d.ProvisionWithUserData {
+		http.Error(w, fmt.Sprintf("distros with '%v' set can't be dry run", distro.ProvisionWithUserDataKey),
+			http.StatusBadRequest)
+		return
+	}
+
+	run := distro.NewDryRun(id, u.Username())
+	if err = run.Insert(); err != nil {
+		http.Error(w, fmt.Sprintf("error saving dry run: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	uis.WriteJSON(w, http.StatusOK, run)
+
+	go func() {
+		init := &hostinit.HostInit{Settings: &uis.Settings}
+		if err := init.DryRun(run, &d, req.Canary); err != nil {
+			evergreen.Logger.Logf(slogger.WARN, "Dry run %v of distro %v failed: %v", run.Id, id, err)
+		}
+	}()
+}
+
+func (uis *UIServer) getDistroDryRun(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["run_id"]
+
+	run, err := distro.FindOneDryRun(distro.DryRunById(id))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("error fetching dry run '%v': %v", id, err), http.StatusInternalServerError)
+		return
+	}
+	if run == nil {
+		http.Error(w, fmt.Sprintf("dry run '%v' not found", id), http.StatusNotFound)
+		return
+	}
+
+	uis.WriteJSON(w, http.StatusOK, run)
+}
+
 type sortableDistro struct {
 	distros []distro.Distro
 }
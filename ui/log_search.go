@@ -0,0 +1,110 @@
+package ui
+
+import (
+	"fmt"
+	"github.com/10gen-labs/slogger/v1"
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/logsearch"
+	"github.com/evergreen-ci/evergreen/model/user"
+	"net/http"
+	"time"
+)
+
+// by default, searches cover the last week of logs
+const defaultLogSearchWindow = 7 * 24 * time.Hour
+
+type logSearchError struct {
+	Message string `json:"message"`
+}
+
+type logSearchResults struct {
+	Start   time.Time         `json:"start"`
+	End     time.Time         `json:"end"`
+	Matches []logsearch.Match `json:"matches"`
+}
+
+func (uis *UIServer) logSearchPage(w http.ResponseWriter, r *http.Request) {
+	projCtx := MustHaveProjectContext(r)
+	if projCtx.Project == nil {
+		http.Error(w, "Project not found", http.StatusNotFound)
+		return
+	}
+	uis.WriteHTML(w, http.StatusOK, struct {
+		ProjectData projectContext
+		User        *user.DBUser
+	}{projCtx, GetUser(r)}, "base", "log_search.html", "base_angular.html", "menu.html")
+}
+
+// parseLogSearchTime parses a bound of the time range to search, either as
+// a day or a full RFC 3339 time. A day as the end of the range includes it.
+func parseLogSearchTime(value string, endOfDay bool) (time.Time, error) {
+	if day, err := time.Parse(waterfallDateFormat, value); err == nil {
+		if endOfDay {
+			return day.Add(24*time.Hour - time.Nanosecond), nil
+		}
+		return day, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time '%v': expected YYYY-MM-DD or RFC 3339", value)
+	}
+	return t, nil
+}
+
+// parseLogSearchQuery reads the search of the project's logs from the
+// request's q, variant, start, end and limit parameters.
+func parseLogSearchQuery(r *http.Request, project string) (*logsearch.Query, error) {
+	q := &logsearch.Query{
+		Project:      project,
+		Text:         r.FormValue("q"),
+		BuildVariant: r.FormValue("variant"),
+		End:          time.Now(),
+	}
+	if q.Text == "" {
+		return nil, fmt.Errorf("the q parameter is required")
+	}
+	var err error
+	if end := r.FormValue("end"); end != "" {
+		if q.End, err = parseLogSearchTime(end, true); err != nil {
+			return nil, err
+		}
+	}
+	q.Start = q.End.Add(-defaultLogSearchWindow)
+	if start := r.FormValue("start"); start != "" {
+		if q.Start, err = parseLogSearchTime(start, false); err != nil {
+			return nil, err
+		}
+	}
+	if q.Limit, err = getIntValue(r, "limit", 100); err != nil {
+		return nil, fmt.Errorf("invalid limit: %v", err)
+	}
+	return q, nil
+}
+
+// logSearchJSON searches the project's task and test logs. Users who aren't
+// logged in can only search the task's own output, as on the log pages.
+func (uis *UIServer) logSearchJSON(w http.ResponseWriter, r *http.Request) {
+	projCtx := MustHaveProjectContext(r)
+	if projCtx.Project == nil {
+		uis.WriteJSON(w, http.StatusNotFound, logSearchError{"Project not found"})
+		return
+	}
+
+	q, err := parseLogSearchQuery(r, projCtx.Project.Identifier)
+	if err != nil {
+		uis.WriteJSON(w, http.StatusBadRequest, logSearchError{err.Error()})
+		return
+	}
+	if GetUser(r) == nil {
+		q.MessageTypes = []string{model.TaskLogPrefix}
+	}
+
+	matches, err := logsearch.Search(*q)
+	if err != nil {
+		evergreen.Logger.Logf(slogger.ERROR, "Error searching logs of project '%v': %v", q.Project, err)
+		uis.WriteJSON(w, http.StatusInternalServerError, logSearchError{err.Error()})
+		return
+	}
+	uis.WriteJSON(w, http.StatusOK, logSearchResults{q.Start, q.End, matches})
+}
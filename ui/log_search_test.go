@@ -0,0 +1,47 @@
+package ui
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestParseLogSearchQuery(t *testing.T) {
+	Convey("When parsing a log search from a request", t, func() {
+
+		Convey("the params should be read into the query", func() {
+			r, err := http.NewRequest("GET",
+				"/log_search?q=segmentation+fault&variant=linux-64&start=2016-03-01&end=2016-03-07&limit=20", nil)
+			So(err, ShouldBeNil)
+			q, err := parseLogSearchQuery(r, "p")
+			So(err, ShouldBeNil)
+			So(q.Project, ShouldEqual, "p")
+			So(q.Text, ShouldEqual, "segmentation fault")
+			So(q.BuildVariant, ShouldEqual, "linux-64")
+			So(q.Start, ShouldResemble, time.Date(2016, time.March, 1, 0, 0, 0, 0, time.UTC))
+			So(q.End, ShouldResemble, time.Date(2016, time.March, 8, 0, 0, 0, 0, time.UTC).Add(-time.Nanosecond))
+			So(q.Limit, ShouldEqual, 20)
+		})
+
+		Convey("the search should default to the last week", func() {
+			r, err := http.NewRequest("GET", "/log_search?q=fault&end=2016-03-07T12:00:00Z", nil)
+			So(err, ShouldBeNil)
+			q, err := parseLogSearchQuery(r, "p")
+			So(err, ShouldBeNil)
+			So(q.Start, ShouldResemble, time.Date(2016, time.February, 29, 12, 0, 0, 0, time.UTC))
+		})
+
+		Convey("a search without text or with a bad time should be an error", func() {
+			r, err := http.NewRequest("GET", "/log_search?start=2016-03-01", nil)
+			So(err, ShouldBeNil)
+			_, err = parseLogSearchQuery(r, "p")
+			So(err, ShouldNotBeNil)
+
+			r, err = http.NewRequest("GET", "/log_search?q=fault&start=last+week", nil)
+			So(err, ShouldBeNil)
+			_, err = parseLogSearchQuery(r, "p")
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
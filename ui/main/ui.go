@@ -8,6 +8,9 @@ import (
 	"github.com/evergreen-ci/evergreen"
 	"github.com/evergreen-ci/evergreen/auth"
 	"github.com/evergreen-ci/evergreen/db"
+	"github.com/evergreen-ci/evergreen/logstore"
+	"github.com/evergreen-ci/evergreen/metrics"
+	"github.com/evergreen-ci/evergreen/model"
 	_ "github.com/evergreen-ci/evergreen/plugin/config"
 	"github.com/evergreen-ci/evergreen/ui"
 	"github.com/evergreen-ci/render"
@@ -43,6 +46,14 @@ func main() {
 	}
 	db.SetGlobalSessionProvider(db.SessionFactoryFromConfig(settings))
 
+	logStore, err := logstore.New(settings)
+	if err != nil {
+		fmt.Println("Failed to create log store:", err)
+		os.Exit(1)
+	}
+	model.SetLogStore(logStore)
+	metrics.RegisterServerMetrics()
+
 	home := evergreen.FindEvergreenHome()
 
 	userManager, err := auth.LoadUserManager(settings.AuthConfig)
@@ -90,6 +101,7 @@ func main() {
 	n := negroni.New()
 	n.Use(negroni.NewStatic(http.Dir(webHome)))
 	n.Use(ui.NewLogger())
+	n.Use(metrics.NewRequestTimer("ui"))
 	n.Use(negroni.HandlerFunc(ui.UserMiddleware(userManager)))
 	n.UseHandler(router)
 	graceful.Run(settings.Ui.HttpListenAddr, requestTimeout, n)
@@ -135,19 +135,29 @@ func (uis *UIServer) requireSuperUser(next http.HandlerFunc) http.HandlerFunc {
 			return
 		}
 
-		if user := GetUser(r); user != nil {
-			for _, id := range uis.Settings.SuperUsers {
-				if id == user.Id {
-					next(w, r)
-					return
-				}
-			}
+		if user := GetUser(r); user != nil && uis.isSuperUser(user) {
+			next(w, r)
+			return
 		}
 		uis.RedirectToLogin(w, r)
 		return
 	}
 }
 
+// isSuperUser returns true if the user is a superuser. When no superusers
+// are configured, every user is one.
+func (uis *UIServer) isSuperUser(u *user.DBUser) bool {
+	if len(uis.Settings.SuperUsers) == 0 {
+		return true
+	}
+	for _, id := range uis.Settings.SuperUsers {
+		if id == u.Id {
+			return true
+		}
+	}
+	return false
+}
+
 // RedirectToLogin forces a redirect to the login page. The redirect param is set on the query
 // so that the user will be returned to the original page after they login.
 func (uis *UIServer) RedirectToLogin(w http.ResponseWriter, r *http.Request) {
@@ -320,6 +330,17 @@ func (uis *UIServer) LoadProjectContext(rw http.ResponseWriter, r *http.Request)
 	// get it from the URL
 	if len(projectId) == 0 {
 		projectId = vars["project_id"]
+
+		// routes with a branch refer to the project that tracks it
+		if branch, ok := vars["branch"]; ok && len(projectId) > 0 {
+			branchRef, err := model.FindBranchProjectRef(projectId, branch)
+			if err != nil {
+				return *proj, err
+			}
+			if branchRef != nil {
+				projectId = branchRef.Identifier
+			}
+		}
 	}
 
 	// Still don't have a project ID to use, check if the user's cookie contains one
@@ -105,10 +105,18 @@ func (uis *UIServer) modifyProject(w http.ResponseWriter, r *http.Request) {
 		Enabled            bool              `json:"enabled"`
 		Owner              string            `json:"owner_name"`
 		Repo               string            `json:"repo_name"`
+		RepoKind           string            `json:"repo_kind"`
+		RemoteURL          string            `json:"remote_url"`
+		GerritURL          string            `json:"gerrit_url"`
 		AlertConfig        map[string][]struct {
 			Provider string                 `json:"provider"`
 			Settings map[string]interface{} `json:"settings"`
 		} `json:"alert_config"`
+		ArtifactRetention    []model.ArtifactRetentionRule `json:"artifact_retention"`
+		SystemFailureRetries int                           `json:"system_failure_retries"`
+		Admins               []string                      `json:"admins"`
+		BranchPatterns       []string                      `json:"branch_patterns"`
+		GithubStatus         string                        `json:"github_status"`
 	}{}
 
 	err = util.ReadJSONInto(r.Body, &responseRef)
@@ -118,6 +126,14 @@ func (uis *UIServer) modifyProject(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	switch responseRef.GithubStatus {
+	case "", model.GithubStatusVariants, model.GithubStatusSummary:
+	default:
+		http.Error(w, fmt.Sprintf("Invalid GitHub status reporting '%v'", responseRef.GithubStatus),
+			http.StatusBadRequest)
+		return
+	}
+
 	projectRef.DisplayName = responseRef.DisplayName
 	projectRef.RemotePath = responseRef.RemotePath
 	projectRef.BatchTime = responseRef.BatchTime
@@ -126,7 +142,15 @@ func (uis *UIServer) modifyProject(w http.ResponseWriter, r *http.Request) {
 	projectRef.Owner = responseRef.Owner
 	projectRef.DeactivatePrevious = responseRef.DeactivatePrevious
 	projectRef.Repo = responseRef.Repo
+	projectRef.RepoKind = responseRef.RepoKind
+	projectRef.RemoteURL = responseRef.RemoteURL
+	projectRef.GerritURL = responseRef.GerritURL
 	projectRef.Identifier = id
+	projectRef.ArtifactRetention = responseRef.ArtifactRetention
+	projectRef.SystemFailureRetries = responseRef.SystemFailureRetries
+	projectRef.Admins = responseRef.Admins
+	projectRef.BranchPatterns = responseRef.BranchPatterns
+	projectRef.GithubStatus = responseRef.GithubStatus
 
 	projectRef.Alerts = map[string][]model.AlertConfig{}
 	for triggerId, alerts := range responseRef.AlertConfig {
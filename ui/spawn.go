@@ -19,21 +19,41 @@ import (
 )
 
 const (
-	HostPasswordUpdate         = "updateRDPPassword"
-	HostExpirationExtension    = "extendHostExpiration"
-	HostTerminate              = "terminate"
-	MaxExpirationDurationHours = 24 * 7 // 7 days
+	HostPasswordUpdate      = "updateRDPPassword"
+	HostExpirationExtension = "extendHostExpiration"
+	HostTerminate           = "terminate"
+	HostStop                = "stop"
+	HostStart               = "start"
 )
 
 func (uis *UIServer) spawnPage(w http.ResponseWriter, r *http.Request) {
 	flashes := PopFlashes(uis.CookieStore, r, w)
 	projCtx := MustHaveProjectContext(r)
 
+	maxHours := int(spawn.New(&uis.Settings).MaxExpiration().Hours())
+
+	// the page can be opened to spawn a host for reproducing a task
+	var spawnTask *model.Task
+	if taskId := r.FormValue("task_id"); taskId != "" {
+		var err error
+		spawnTask, err = model.FindTask(taskId)
+		if err != nil {
+			uis.LoggedError(w, r, http.StatusInternalServerError, fmt.Errorf("Error finding task %v: %v", taskId, err))
+			return
+		}
+		if spawnTask == nil {
+			http.Error(w, fmt.Sprintf("task %v not found", taskId), http.StatusNotFound)
+			return
+		}
+	}
+
 	uis.WriteHTML(w, http.StatusOK, struct {
-		ProjectData projectContext
-		User        *user.DBUser
-		Flashes     []interface{}
-	}{projCtx, GetUser(r), flashes}, "base", "spawned_hosts.html", "base_angular.html", "menu.html")
+		ProjectData        projectContext
+		User               *user.DBUser
+		Flashes            []interface{}
+		MaxExpirationHours int
+		SpawnTask          *model.Task
+	}{projCtx, GetUser(r), flashes, maxHours, spawnTask}, "base", "spawned_hosts.html", "base_angular.html", "menu.html")
 }
 
 func (uis *UIServer) getSpawnedHosts(w http.ResponseWriter, r *http.Request) {
@@ -49,6 +69,19 @@ func (uis *UIServer) getSpawnedHosts(w http.ResponseWriter, r *http.Request) {
 	uis.WriteJSON(w, http.StatusOK, hosts)
 }
 
+func (uis *UIServer) getUserVolumes(w http.ResponseWriter, r *http.Request) {
+	user := MustHaveUser(r)
+
+	volumes, err := host.FindVolumes(host.VolumesByUser(user.Username()))
+	if err != nil {
+		uis.LoggedError(w, r, http.StatusInternalServerError,
+			fmt.Errorf("Error finding volumes for user %v: %v", user.Username(), err))
+		return
+	}
+
+	uis.WriteJSON(w, http.StatusOK, volumes)
+}
+
 func (uis *UIServer) getUserPublicKeys(w http.ResponseWriter, r *http.Request) {
 	user := MustHaveUser(r)
 	uis.WriteJSON(w, http.StatusOK, user.PublicKeys())
@@ -79,11 +112,13 @@ func (uis *UIServer) requestNewHost(w http.ResponseWriter, r *http.Request) {
 	authedUser := MustHaveUser(r)
 
 	putParams := struct {
-		Distro    string `json:"distro"`
-		KeyName   string `json:"key_name"`
-		PublicKey string `json:"public_key"`
-		SaveKey   bool   `json:"save_key"`
-		UserData  string `json:"userdata"`
+		Distro     string `json:"distro"`
+		KeyName    string `json:"key_name"`
+		PublicKey  string `json:"public_key"`
+		SaveKey    bool   `json:"save_key"`
+		UserData   string `json:"userdata"`
+		HomeVolume bool   `json:"home_volume"`
+		TaskId     string `json:"task_id"`
 	}{}
 
 	if err := util.ReadJSONInto(r.Body, &putParams); err != nil {
@@ -92,10 +127,12 @@ func (uis *UIServer) requestNewHost(w http.ResponseWriter, r *http.Request) {
 	}
 
 	opts := spawn.Options{
-		Distro:    putParams.Distro,
-		UserName:  authedUser.Username(),
-		PublicKey: putParams.PublicKey,
-		UserData:  putParams.UserData,
+		Distro:     putParams.Distro,
+		UserName:   authedUser.Username(),
+		PublicKey:  putParams.PublicKey,
+		UserData:   putParams.UserData,
+		HomeVolume: putParams.HomeVolume,
+		TaskId:     putParams.TaskId,
 	}
 
 	spawner := spawn.New(&uis.Settings)
@@ -210,26 +247,48 @@ func (uis *UIServer) modifySpawnHost(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "bad hours param", http.StatusBadRequest)
 			return
 		}
-		// ensure this request is valid
-		addtHourDuration := time.Duration(addtHours) * time.Hour
-		futureExpiration := host.ExpirationTime.Add(addtHourDuration)
-		expirationExtensionDuration := futureExpiration.Sub(time.Now()).Hours()
-		if expirationExtensionDuration > MaxExpirationDurationHours {
-			http.Error(w, fmt.Sprintf("Can not extend %v expiration by %v hours. "+
-				"Maximum extension is limited to %v hours", hostId,
-				int(expirationExtensionDuration), MaxExpirationDurationHours), http.StatusBadRequest)
-			return
-
-		}
-		if err = host.SetExpirationTime(futureExpiration); err != nil {
+		spawner := spawn.New(&uis.Settings)
+		if err = spawner.ExtendHost(host, addtHours); err != nil {
+			if _, ok := err.(spawn.BadOptionsErr); ok || err == spawn.ExpirationErr {
+				http.Error(w, fmt.Sprintf("Can not extend %v expiration by %v hours. "+
+					"Hosts can not be set to expire more than %v hours from now", hostId,
+					addtHours, spawner.MaxExpiration().Hours()), http.StatusBadRequest)
+				return
+			}
 			uis.LoggedError(w, r, http.StatusInternalServerError, fmt.Errorf("Error extending host expiration time: %v", err))
 			return
 		}
 		PushFlash(uis.CookieStore, r, w, NewSuccessFlash(fmt.Sprintf("Host expiration "+
 			"extension successful; %v will expire on %v", hostId,
-			futureExpiration.Format(time.RFC850))))
+			host.ExpirationTime.Format(time.RFC850))))
 		uis.WriteJSON(w, http.StatusOK, "Successfully extended host expiration time")
 		return
+	case HostStop:
+		if err = spawn.New(&uis.Settings).StopHost(host); err != nil {
+			errCode := http.StatusInternalServerError
+			if _, ok := err.(spawn.BadOptionsErr); ok {
+				errCode = http.StatusBadRequest
+			}
+			uis.LoggedError(w, r, errCode, fmt.Errorf("Error stopping host: %v", err))
+			return
+		}
+		PushFlash(uis.CookieStore, r, w, NewSuccessFlash(fmt.Sprintf("Host %v stopped", hostId)))
+		uis.WriteJSON(w, http.StatusOK, "host stopped")
+		return
+	case HostStart:
+		if host.Status != evergreen.HostStopped {
+			uis.WriteJSON(w, http.StatusBadRequest, fmt.Sprintf("Host %v is not stopped", host.Id))
+			return
+		}
+		// the host takes a few minutes to come up, so wait for it in the background
+		go func() {
+			if err := spawn.New(&uis.Settings).StartHost(host); err != nil {
+				evergreen.Logger.Logf(slogger.ERROR, "error starting host %v: %v", host.Id, err)
+			}
+		}()
+		PushFlash(uis.CookieStore, r, w, NewSuccessFlash(fmt.Sprintf("Host %v is starting", hostId)))
+		uis.WriteJSON(w, http.StatusOK, "host starting")
+		return
 	default:
 		http.Error(w, fmt.Sprintf("Unrecognized action: %v", updateParams.Action), http.StatusBadRequest)
 		return
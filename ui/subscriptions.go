@@ -0,0 +1,170 @@
+package ui
+
+import (
+	"fmt"
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/alerts"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/subscription"
+	"github.com/evergreen-ci/evergreen/model/user"
+	"github.com/evergreen-ci/evergreen/notify"
+	"github.com/evergreen-ci/evergreen/util"
+	"github.com/gorilla/mux"
+	"gopkg.in/mgo.v2/bson"
+	"net/http"
+	"net/mail"
+	"sort"
+	"strings"
+	"time"
+)
+
+type subscriptionTrigger struct {
+	Id      string `json:"id"`
+	Display string `json:"display"`
+	// TaskLevel is true for the triggers that can be filtered by task
+	TaskLevel bool `json:"task_level"`
+}
+
+// subscriptionTriggers returns the notifications and alerts users can
+// subscribe to, as a json-marshaling friendly list.
+func subscriptionTriggers() []subscriptionTrigger {
+	notifications := notify.SubscriptionTriggers()
+	sort.Strings(notifications)
+
+	triggers := []subscriptionTrigger{}
+	for _, name := range notifications {
+		triggers = append(triggers, subscriptionTrigger{
+			Id:        name,
+			Display:   strings.Replace(name, "_", " ", -1),
+			TaskLevel: strings.HasPrefix(name, "task"),
+		})
+	}
+	for _, taskTrigger := range alerts.AvailableTaskFailTriggers {
+		triggers = append(triggers, subscriptionTrigger{
+			Id:        taskTrigger.Id(),
+			Display:   taskTrigger.Display(),
+			TaskLevel: true,
+		})
+	}
+	return triggers
+}
+
+// validateSubscription returns an error describing the first problem found
+// with the subscription of the user.
+func (uis *UIServer) validateSubscription(s *subscription.Subscription, currentUser *user.DBUser) error {
+	if s.Project == "" {
+		return fmt.Errorf("a project is required")
+	}
+	projectRef, err := model.FindOneProjectRef(s.Project)
+	if err != nil {
+		return err
+	}
+	if projectRef == nil {
+		return fmt.Errorf("project '%v' not found", s.Project)
+	}
+
+	var trigger *subscriptionTrigger
+	for _, t := range subscriptionTriggers() {
+		if t.Id == s.Trigger {
+			trigger = &t
+			break
+		}
+	}
+	if trigger == nil {
+		return fmt.Errorf("unknown trigger '%v'", s.Trigger)
+	}
+	if !trigger.TaskLevel && len(s.Tasks) != 0 {
+		return fmt.Errorf("tasks can't be specified for '%v' notifications", s.Trigger)
+	}
+
+	if s.Requester != evergreen.RepotrackerVersionRequester &&
+		s.Requester != evergreen.PatchVersionRequester {
+		return fmt.Errorf("invalid requester '%v'", s.Requester)
+	}
+
+	switch s.Method {
+	case subscription.EmailMethod:
+		if s.Team != "" {
+			if s.Address == "" {
+				return fmt.Errorf("an email address is required for team subscriptions")
+			}
+			if _, err := mail.ParseAddress(s.Address); err != nil {
+				return fmt.Errorf("invalid email address '%v': %v", s.Address, err)
+			}
+		}
+	case subscription.ChatMethod:
+		if uis.Settings.Chat.WebhookURL == "" {
+			return fmt.Errorf("chat notifications aren't configured")
+		}
+		if s.Team != "" {
+			if !strings.HasPrefix(s.Address, "#") {
+				return fmt.Errorf("a channel like '#team' is required for team chat subscriptions")
+			}
+		} else if currentUser.Settings.ChatHandle == "" {
+			return fmt.Errorf("set your chat handle to subscribe by chat")
+		}
+	default:
+		return fmt.Errorf("invalid method '%v'", s.Method)
+	}
+	if s.Team == "" && s.Address != "" {
+		return fmt.Errorf("an address can only be specified for team subscriptions")
+	}
+	return nil
+}
+
+func (uis *UIServer) addSubscription(w http.ResponseWriter, r *http.Request) {
+	currentUser := MustHaveUser(r)
+
+	s := &subscription.Subscription{}
+	if err := util.ReadJSONInto(r.Body, s); err != nil {
+		uis.LoggedError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	s.Id = bson.NewObjectId()
+	s.Owner = currentUser.Id
+	s.CreatedAt = time.Now()
+	s.Team = strings.TrimSpace(s.Team)
+	s.Address = strings.TrimSpace(s.Address)
+	if s.Requester == "" {
+		s.Requester = evergreen.RepotrackerVersionRequester
+	}
+	if s.Method == "" {
+		s.Method = subscription.EmailMethod
+	}
+
+	if err := uis.validateSubscription(s, currentUser); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid subscription: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if err := s.Insert(); err != nil {
+		uis.LoggedError(w, r, http.StatusInternalServerError, fmt.Errorf("Error saving subscription: %v", err))
+		return
+	}
+	uis.WriteJSON(w, http.StatusOK, s)
+}
+
+func (uis *UIServer) removeSubscription(w http.ResponseWriter, r *http.Request) {
+	currentUser := MustHaveUser(r)
+
+	id := mux.Vars(r)["subscription_id"]
+	if !bson.IsObjectIdHex(id) {
+		http.Error(w, fmt.Sprintf("Invalid subscription id '%v'", id), http.StatusBadRequest)
+		return
+	}
+	s, err := subscription.FindOne(subscription.ById(bson.ObjectIdHex(id)))
+	if err != nil {
+		uis.LoggedError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	if s == nil || s.Owner != currentUser.Id {
+		http.Error(w, "Subscription not found", http.StatusNotFound)
+		return
+	}
+
+	if err = subscription.Remove(s.Id); err != nil {
+		uis.LoggedError(w, r, http.StatusInternalServerError, fmt.Errorf("Error removing subscription: %v", err))
+		return
+	}
+	uis.WriteJSON(w, http.StatusOK, "Removed subscription successfully")
+}
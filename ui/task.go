@@ -57,6 +57,9 @@ type uiTaskData struct {
 	Aborted          bool                    `json:"abort"`
 	MinQueuePos      int                     `json:"min_queue_pos"`
 
+	// the number of automatic retries after system failures
+	SystemFailureRetries int `json:"system_failure_retries"`
+
 	// from the host doc (the dns name)
 	HostDNS string `json:"host_dns,omitempty"`
 	// from the host doc (the host id)
@@ -188,6 +191,7 @@ func (uis *UIServer) taskPage(w http.ResponseWriter, r *http.Request) {
 		Repo:                projCtx.ProjectRef.Repo,
 		Archived:            archived,
 	}
+	task.SystemFailureRetries = projCtx.Task.SystemFailureRetries
 
 	// Activating and deactivating tasks should clear out the
 	// MinQueuePos but just in case, lets not show it if we shouldn't
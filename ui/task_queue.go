@@ -9,6 +9,7 @@ import (
 	"github.com/evergreen-ci/evergreen/model/host"
 	"github.com/evergreen-ci/evergreen/model/patch"
 	"github.com/evergreen-ci/evergreen/model/user"
+	"github.com/evergreen-ci/evergreen/util"
 	"github.com/gorilla/mux"
 	"gopkg.in/mgo.v2/bson"
 	"net/http"
@@ -28,6 +29,13 @@ type uiTaskQueueItem struct {
 	Version             string `json:"version"`
 	Build               string `json:"build"`
 
+	// estimates of when the task will start, and how long it will take
+	ExpectedStart    time.Time     `json:"expected_start"`
+	ExpectedDuration time.Duration `json:"exp_dur"`
+
+	// "pinned" or "moved", if the task's place in the queue was set by hand
+	Override string `json:"override,omitempty"`
+
 	// only if it's a patch request task
 	User string `json:"user,omitempty"`
 }
@@ -39,6 +47,13 @@ type uiTaskQueue struct {
 	Queue  []uiTaskQueueItem `json:"queue"`
 }
 
+// the actions that can be taken on a task in a queue
+const (
+	taskQueuePin    = "pin"
+	taskQueueMove   = "move"
+	taskQueueRemove = "remove"
+)
+
 // top-level ui struct for holding information on task
 // queues and host usage
 type uiResourceInfo struct {
@@ -111,107 +126,14 @@ func (uis *UIServer) allTaskQueues(w http.ResponseWriter, r *http.Request) {
 	// convert the task queues to the ui versions
 	uiTaskQueues := []uiTaskQueue{}
 	for _, tQ := range taskQueues {
-		asUI := uiTaskQueue{
-			Distro: tQ.Distro,
-			Queue:  []uiTaskQueueItem{},
-		}
-
-		if len(tQ.Queue) == 0 {
-			uiTaskQueues = append(uiTaskQueues, asUI)
-			continue
-		}
-
-		// convert the individual task queue items
-		taskIds := []string{}
-		for _, item := range tQ.Queue {
-
-			// cache the ids, for fetching the tasks from the db
-			taskIds = append(taskIds, item.Id)
-			queueItemAsUI := uiTaskQueueItem{
-				Id:                  item.Id,
-				DisplayName:         item.DisplayName,
-				BuildVariant:        item.BuildVariant,
-				RevisionOrderNumber: item.RevisionOrderNumber,
-				Requester:           item.Requester,
-				Revision:            item.Revision,
-				Project:             item.Project,
-			}
-			asUI.Queue = append(asUI.Queue, queueItemAsUI)
-		}
-
-		// find all the relevant tasks
-		tasks, err := model.FindAllTasks(
-			bson.M{
-				model.TaskIdKey: bson.M{
-					"$in": taskIds,
-				},
-			},
-			bson.M{
-				model.TaskVersionKey: 1,
-				model.TaskBuildIdKey: 1,
-			},
-			db.NoSort,
-			db.NoSkip,
-			db.NoLimit,
-		)
-
+		asUI, err := taskQueueForUI(tQ, cachedPatches)
 		if err != nil {
-			msg := fmt.Sprintf("Error finding tasks: %v", err)
+			msg := fmt.Sprintf("Error loading task queue for distro %v: %v", tQ.Distro, err)
 			evergreen.Logger.Errorf(slogger.ERROR, msg)
 			http.Error(w, msg, http.StatusInternalServerError)
 			return
 		}
-
-		// store all of the version and build ids in the relevant task queue
-		// items
-		for _, task := range tasks {
-			// this sucks, but it's because we're not guaranteed the order out
-			// of the db
-			for idx, queueItemAsUI := range asUI.Queue {
-				if queueItemAsUI.Id == task.Id {
-					queueItemAsUI.Version = task.Version
-					queueItemAsUI.Build = task.BuildId
-					asUI.Queue[idx] = queueItemAsUI
-				}
-			}
-		}
-
-		// add all of the necessary patch info into the relevant task queue
-		// items
-		for idx, queueItemAsUI := range asUI.Queue {
-			if queueItemAsUI.Requester == evergreen.PatchVersionRequester {
-				// fetch the patch, if necessary
-				var p *patch.Patch
-				var ok bool
-				if p, ok = cachedPatches[queueItemAsUI.Version]; ok {
-					queueItemAsUI.User = p.Author
-					asUI.Queue[idx] = queueItemAsUI
-				} else {
-					p, err = patch.FindOne(
-						patch.ByVersion(queueItemAsUI.Version).WithFields(patch.AuthorKey),
-					)
-					if err != nil {
-						msg := fmt.Sprintf("Error finding patch: %v", err)
-						evergreen.Logger.Errorf(slogger.ERROR, msg)
-						http.Error(w, msg, http.StatusInternalServerError)
-						return
-					}
-					if p == nil {
-						msg := fmt.Sprintf("Couldn't find patch for version %v", queueItemAsUI.Version)
-						evergreen.Logger.Errorf(slogger.ERROR, msg)
-						http.Error(w, msg, http.StatusInternalServerError)
-						return
-					}
-					cachedPatches[queueItemAsUI.Version] = p
-				}
-				queueItemAsUI.User = p.Author
-				asUI.Queue[idx] = queueItemAsUI
-
-			}
-		}
-
-		uiTaskQueues = append(uiTaskQueues, asUI)
-
+		uiTaskQueues = append(uiTaskQueues, *asUI)
 	}
 
 	// add other useful statistics to view alongside queue
@@ -256,3 +178,287 @@ func (uis *UIServer) allTaskQueues(w http.ResponseWriter, r *http.Request) {
 	}{projCtx, GetUser(r), []interface{}{}, uiResourceInfo{uiTaskQueues, hostStats}},
 		"base", "task_queues.html", "base_angular.html", "menu.html")
 }
+
+// taskQueueForUI converts a task queue to its ui version, filling in the
+// version, build and patch author of each task along with when it's expected
+// to start.
+func taskQueueForUI(tQ model.TaskQueue, cachedPatches map[string]*patch.Patch) (*uiTaskQueue, error) {
+	asUI := &uiTaskQueue{
+		Distro: tQ.Distro,
+		Queue:  []uiTaskQueueItem{},
+	}
+
+	if len(tQ.Queue) == 0 {
+		return asUI, nil
+	}
+
+	overrides := map[string]string{}
+	for _, id := range tQ.Overrides.Pinned {
+		overrides[id] = "pinned"
+	}
+	for _, move := range tQ.Overrides.Moved {
+		overrides[move.TaskId] = "moved"
+	}
+
+	freeTimes, err := hostFreeTimes(tQ.Distro, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	startTimes := tQ.ExpectedStartTimes(freeTimes)
+
+	// convert the individual task queue items
+	taskIds := []string{}
+	for idx, item := range tQ.Queue {
+
+		// cache the ids, for fetching the tasks from the db
+		taskIds = append(taskIds, item.Id)
+		queueItemAsUI := uiTaskQueueItem{
+			Id:                  item.Id,
+			DisplayName:         item.DisplayName,
+			BuildVariant:        item.BuildVariant,
+			RevisionOrderNumber: item.RevisionOrderNumber,
+			Requester:           item.Requester,
+			Revision:            item.Revision,
+			Project:             item.Project,
+			ExpectedStart:       startTimes[idx],
+			ExpectedDuration:    item.ExpectedDuration,
+			Override:            overrides[item.Id],
+		}
+		asUI.Queue = append(asUI.Queue, queueItemAsUI)
+	}
+
+	// find all the relevant tasks
+	tasks, err := model.FindAllTasks(
+		bson.M{
+			model.TaskIdKey: bson.M{
+				"$in": taskIds,
+			},
+		},
+		bson.M{
+			model.TaskVersionKey: 1,
+			model.TaskBuildIdKey: 1,
+		},
+		db.NoSort,
+		db.NoSkip,
+		db.NoLimit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("Error finding tasks: %v", err)
+	}
+
+	// store all of the version and build ids in the relevant task queue
+	// items
+	for _, task := range tasks {
+		// this sucks, but it's because we're not guaranteed the order out
+		// of the db
+		for idx, queueItemAsUI := range asUI.Queue {
+			if queueItemAsUI.Id == task.Id {
+				queueItemAsUI.Version = task.Version
+				queueItemAsUI.Build = task.BuildId
+				asUI.Queue[idx] = queueItemAsUI
+			}
+		}
+	}
+
+	// add all of the necessary patch info into the relevant task queue
+	// items
+	for idx, queueItemAsUI := range asUI.Queue {
+		if queueItemAsUI.Requester != evergreen.PatchVersionRequester {
+			continue
+		}
+		// fetch the patch, if necessary
+		p, ok := cachedPatches[queueItemAsUI.Version]
+		if !ok {
+			p, err = patch.FindOne(
+				patch.ByVersion(queueItemAsUI.Version).WithFields(patch.AuthorKey),
+			)
+			if err != nil {
+				return nil, fmt.Errorf("Error finding patch: %v", err)
+			}
+			if p == nil {
+				return nil, fmt.Errorf("Couldn't find patch for version %v", queueItemAsUI.Version)
+			}
+			cachedPatches[queueItemAsUI.Version] = p
+		}
+		queueItemAsUI.User = p.Author
+		asUI.Queue[idx] = queueItemAsUI
+	}
+
+	return asUI, nil
+}
+
+// hostFreeTimes returns when each of the distro's hosts is expected to be
+// free to take a new task: now for idle hosts, and when their current task is
+// expected to finish for busy ones.
+func hostFreeTimes(distroId string, now time.Time) ([]time.Time, error) {
+	hosts, err := host.Find(host.ByDistroId(distroId))
+	if err != nil {
+		return nil, fmt.Errorf("Error finding hosts for distro %v: %v", distroId, err)
+	}
+	freeTimes := make([]time.Time, 0, len(hosts))
+	for _, h := range hosts {
+		freeTime := now
+		if h.RunningTask != "" {
+			runningTask, err := model.FindTask(h.RunningTask)
+			if err != nil {
+				return nil, fmt.Errorf("Error finding task %v: %v", h.RunningTask, err)
+			}
+			if runningTask != nil {
+				startTime := runningTask.StartTime
+				if startTime.Before(runningTask.DispatchTime) {
+					startTime = runningTask.DispatchTime
+				}
+				if finish := startTime.Add(runningTask.ExpectedDuration); finish.After(now) {
+					freeTime = finish
+				}
+			}
+		}
+		freeTimes = append(freeTimes, freeTime)
+	}
+	return freeTimes, nil
+}
+
+// taskQueueJSON returns the queue for a single distro, along with the
+// expected start time of each task.
+func (uis *UIServer) taskQueueJSON(w http.ResponseWriter, r *http.Request) {
+	distroId := mux.Vars(r)["distro_id"]
+	taskQueue, err := model.FindTaskQueueForDistro(distroId)
+	if err != nil {
+		uis.LoggedError(w, r, http.StatusInternalServerError,
+			fmt.Errorf("Error finding task queue: %v", err))
+		return
+	}
+	if taskQueue == nil {
+		http.Error(w, fmt.Sprintf("no task queue for distro %v", distroId), http.StatusNotFound)
+		return
+	}
+
+	asUI, err := taskQueueForUI(*taskQueue, map[string]*patch.Patch{})
+	if err != nil {
+		uis.LoggedError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	uis.WriteJSON(w, http.StatusOK, asUI)
+}
+
+// modifyTaskQueue pins a task to the front of a distro's queue, moves it
+// within its project's part of the queue, or removes it. Only superusers and
+// the admins of the task's project may do so. Pins and moves are saved as
+// overrides that the scheduler keeps honoring while the task is queued;
+// removing a task unschedules it, until it's scheduled again.
+func (uis *UIServer) modifyTaskQueue(w http.ResponseWriter, r *http.Request) {
+	u := MustHaveUser(r)
+	distroId := mux.Vars(r)["distro_id"]
+	taskId := mux.Vars(r)["task_id"]
+
+	putParams := struct {
+		Action   string `json:"action"`
+		Position int    `json:"position"`
+	}{}
+	if err := util.ReadJSONInto(r.Body, &putParams); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	task, err := model.FindTask(taskId)
+	if err != nil {
+		uis.LoggedError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	if task == nil {
+		http.Error(w, fmt.Sprintf("task %v not found", taskId), http.StatusNotFound)
+		return
+	}
+
+	if !uis.isSuperUser(u) {
+		projectRef, err := model.FindOneProjectRef(task.Project)
+		if err != nil {
+			uis.LoggedError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+		if projectRef == nil || !projectRef.IsAdmin(u.Id) {
+			http.Error(w, "not authorized to modify this project's tasks", http.StatusUnauthorized)
+			return
+		}
+	}
+
+	taskQueue, err := model.FindTaskQueueForDistro(distroId)
+	if err != nil {
+		uis.LoggedError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	if taskQueue == nil {
+		http.Error(w, fmt.Sprintf("no task queue for distro %v", distroId), http.StatusNotFound)
+		return
+	}
+	queued := false
+	for _, item := range taskQueue.Queue {
+		if item.Id == taskId {
+			queued = true
+			break
+		}
+	}
+	if !queued {
+		http.Error(w, fmt.Sprintf("task %v is not in the queue for distro %v", taskId, distroId),
+			http.StatusNotFound)
+		return
+	}
+
+	switch putParams.Action {
+	case taskQueuePin:
+		taskQueue.Overrides.Pin(taskId)
+	case taskQueueMove:
+		taskQueue.Overrides.Move(taskId, putParams.Position)
+	case taskQueueRemove:
+		if err = model.RemoveTaskFromQueue(distroId, taskId, u.Id); err != nil {
+			uis.LoggedError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+		taskQueue, err = model.FindTaskQueueForDistro(distroId)
+		if err != nil {
+			uis.LoggedError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+		if taskQueue == nil {
+			http.Error(w, fmt.Sprintf("no task queue for distro %v", distroId), http.StatusNotFound)
+			return
+		}
+	default:
+		http.Error(w, fmt.Sprintf("Unrecognized action: %v", putParams.Action), http.StatusBadRequest)
+		return
+	}
+
+	if putParams.Action != taskQueueRemove {
+		if err = model.SetTaskQueueOverrides(distroId, taskQueue.Overrides); err != nil {
+			uis.LoggedError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		// reorder the saved queue now, rather than waiting for the scheduler
+		ids := make([]string, 0, len(taskQueue.Queue))
+		projects := make([]string, 0, len(taskQueue.Queue))
+		for _, item := range taskQueue.Queue {
+			ids = append(ids, item.Id)
+			projects = append(projects, item.Project)
+		}
+		order, _ := taskQueue.Overrides.Order(ids, projects)
+		reordered := make([]model.TaskQueueItem, 0, len(order))
+		for _, idx := range order {
+			reordered = append(reordered, taskQueue.Queue[idx])
+		}
+		taskQueue.Queue = reordered
+		if err = taskQueue.Save(); err != nil {
+			uis.LoggedError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+	}
+	evergreen.Logger.Logf(slogger.INFO, "User %v %v task %v in queue for distro %v",
+		u.Id, putParams.Action, taskId, distroId)
+
+	asUI, err := taskQueueForUI(*taskQueue, map[string]*patch.Patch{})
+	if err != nil {
+		uis.LoggedError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	uis.WriteJSON(w, http.StatusOK, asUI)
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/10gen-labs/slogger/v1"
 	"github.com/evergreen-ci/evergreen"
 	"github.com/evergreen-ci/evergreen/auth"
+	"github.com/evergreen-ci/evergreen/metrics"
 	"github.com/evergreen-ci/evergreen/plugin"
 	"github.com/evergreen-ci/evergreen/rest"
 	"github.com/evergreen-ci/evergreen/util"
@@ -63,10 +64,14 @@ func (uis *UIServer) NewRouter() (*mux.Router, error) {
 	}
 	r.HandleFunc("/logout", uis.logout)
 
+	// Prometheus metrics
+	r.Handle("/metrics", metrics.Handler()).Methods("GET")
+
 	// Waterfall pages
 	r.HandleFunc("/", uis.loadCtx(uis.waterfallPage))
 	r.HandleFunc("/waterfall", uis.loadCtx(uis.waterfallPage))
 	r.HandleFunc("/waterfall/{project_id}", uis.loadCtx(uis.waterfallPage))
+	r.HandleFunc("/waterfall/{project_id}/{branch}", uis.loadCtx(uis.waterfallPage))
 
 	// Timeline page
 	r.HandleFunc("/timeline/{project_id}", uis.loadCtx(uis.timeline))
@@ -91,6 +96,9 @@ func (uis *UIServer) NewRouter() (*mux.Router, error) {
 	r.HandleFunc("/task/dependencies/{task_id}", uis.loadCtx(uis.taskDependencies))
 	r.HandleFunc("/task/dependencies/{task_id}/{execution}", uis.loadCtx(uis.taskDependencies))
 
+	// Log search
+	r.HandleFunc("/log_search/{project_id}", uis.loadCtx(uis.logSearchPage)).Methods("GET")
+
 	// Test Logs
 	r.HandleFunc("/test_log/{task_id}/{task_execution}/{test_name}", uis.loadCtx(uis.testLog))
 	r.HandleFunc("/test_log/{log_id}", uis.loadCtx(uis.testLog))
@@ -119,6 +127,8 @@ func (uis *UIServer) NewRouter() (*mux.Router, error) {
 	r.HandleFunc("/distros/{distro_id}", uis.requireSuperUser(uis.loadCtx(uis.getDistro))).Methods("GET")
 	r.HandleFunc("/distros/{distro_id}", uis.requireSuperUser(uis.loadCtx(uis.modifyDistro))).Methods("POST")
 	r.HandleFunc("/distros/{distro_id}", uis.requireSuperUser(uis.loadCtx(uis.removeDistro))).Methods("DELETE")
+	r.HandleFunc("/distros/{distro_id}/dry_run", uis.requireSuperUser(uis.loadCtx(uis.dryRunDistro))).Methods("POST")
+	r.HandleFunc("/distros/{distro_id}/dry_run/{run_id}", uis.requireSuperUser(uis.loadCtx(uis.getDistroDryRun))).Methods("GET")
 
 	// Event Logs
 	r.HandleFunc("/event_log/{resource_type}/{resource_id:[\\w_\\-\\:\\.\\@]+}", uis.loadCtx(uis.fullEventLogs))
@@ -138,6 +148,8 @@ func (uis *UIServer) NewRouter() (*mux.Router, error) {
 
 	// Task queues
 	r.HandleFunc("/task_queue", uis.loadCtx(uis.allTaskQueues))
+	r.HandleFunc("/json/task_queue/{distro_id}", uis.loadCtx(uis.taskQueueJSON)).Methods("GET")
+	r.HandleFunc("/json/task_queue/{distro_id}/{task_id}", uis.requireUser(uis.loadCtx(uis.modifyTaskQueue))).Methods("POST")
 
 	// Patch pages
 	r.HandleFunc("/patch/{patch_id}", uis.requireUser(uis.loadCtx(uis.patchPage))).Methods("GET")
@@ -157,11 +169,14 @@ func (uis *UIServer) NewRouter() (*mux.Router, error) {
 	r.HandleFunc("/spawn/hosts", uis.requireUser(uis.loadCtx(uis.getSpawnedHosts))).Methods("GET")
 	r.HandleFunc("/spawn/distros", uis.requireUser(uis.loadCtx(uis.listSpawnableDistros))).Methods("GET")
 	r.HandleFunc("/spawn/keys", uis.requireUser(uis.loadCtx(uis.getUserPublicKeys))).Methods("GET")
+	r.HandleFunc("/spawn/volumes", uis.requireUser(uis.loadCtx(uis.getUserVolumes))).Methods("GET")
 
 	// User settings
 	r.HandleFunc("/settings", uis.requireUser(uis.loadCtx(uis.userSettingsPage))).Methods("GET")
 	r.HandleFunc("/settings", uis.requireUser(uis.loadCtx(uis.userSettingsModify))).Methods("PUT")
 	r.HandleFunc("/settings/newkey", uis.requireUser(uis.loadCtx(uis.newAPIKey))).Methods("POST")
+	r.HandleFunc("/settings/subscriptions", uis.requireUser(uis.loadCtx(uis.addSubscription))).Methods("POST")
+	r.HandleFunc("/settings/subscriptions/{subscription_id}", uis.requireUser(uis.loadCtx(uis.removeSubscription))).Methods("DELETE")
 
 	// Task stats
 	r.HandleFunc("/task_timing", uis.requireUser(uis.loadCtx(uis.taskTimingPage))).Methods("GET")
@@ -181,6 +196,9 @@ func (uis *UIServer) NewRouter() (*mux.Router, error) {
 	for _, restRoute := range restRoutes {
 		restRouter.HandleFunc(restRoute.Path, uis.loadCtx(restRoute.Handler)).Name(restRoute.Name).Methods(restRoute.Method)
 	}
+	// the waterfall and log search are served from here since they're built by the ui
+	restRouter.HandleFunc("/projects/{project_id}/waterfall", uis.loadCtx(uis.waterfallJSON)).Name("waterfall").Methods("GET")
+	restRouter.HandleFunc("/projects/{project_id}/log_search", uis.loadCtx(uis.logSearchJSON)).Name("log_search").Methods("GET")
 
 	// Plugin routes
 	rootPluginRouter := r.PathPrefix("/plugin/").Subrouter()
@@ -210,12 +228,24 @@ func (uis *UIServer) NewRouter() (*mux.Router, error) {
 			)
 		}
 		pluginUIhandler := pl.GetUIHandler()
+		if pluginUIhandler != nil {
+			pluginUIhandler = uis.withPluginContext(pluginUIhandler)
+		}
 		util.MountHandler(rootPluginRouter, fmt.Sprintf("/%v/", pl.Name()), pluginUIhandler)
 	}
 
 	return r, nil
 }
 
+// withPluginContext attaches the settings and the user to the requests to a
+// plugin's UI handler, for it to load with plugin.MustHaveContext.
+func (uis *UIServer) withPluginContext(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		plugin.SetContext(r, plugin.UIContext{Settings: uis.Settings, User: GetUser(r)})
+		h.ServeHTTP(w, r)
+	})
+}
+
 // LoggedError logs the given error and writes an HTTP response with its details formatted
 // as JSON if the request headers indicate that it's acceptable (or plaintext otherwise).
 func (uis *UIServer) LoggedError(w http.ResponseWriter, r *http.Request, code int, err error) {
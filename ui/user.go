@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/evergreen-ci/evergreen"
 	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/subscription"
 	"github.com/evergreen-ci/evergreen/model/user"
 	"github.com/evergreen-ci/evergreen/util"
 	"net/http"
@@ -94,13 +95,33 @@ func (uis *UIServer) userSettingsPage(w http.ResponseWriter, r *http.Request) {
 	}
 	exampleConf := confFile{currentUser.Id, currentUser.APIKey, uis.Settings.ApiUrl + "/api", uis.Settings.Ui.Url}
 
+	subscriptions, err := subscription.Find(subscription.ByOwner(currentUser.Id))
+	if err != nil {
+		uis.LoggedError(w, r, http.StatusInternalServerError, fmt.Errorf("Error finding subscriptions: %v", err))
+		return
+	}
+	projectRefs, err := model.FindAllTrackedProjectRefs()
+	if err != nil {
+		uis.LoggedError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	projects := []string{}
+	for _, projectRef := range projectRefs {
+		projects = append(projects, projectRef.Identifier)
+	}
+
 	uis.WriteHTML(w, http.StatusOK, struct {
-		ProjectData projectContext
-		Data        user.UserSettings
-		User        *user.DBUser
-		Config      confFile
-		Flashes     []interface{}
-	}{projCtx, settingsData, currentUser, exampleConf, flashes}, "base",
+		ProjectData   projectContext
+		Data          user.UserSettings
+		User          *user.DBUser
+		Config        confFile
+		Subscriptions []subscription.Subscription
+		Projects      []string
+		Triggers      []subscriptionTrigger
+		ChatEnabled   bool
+		Flashes       []interface{}
+	}{projCtx, settingsData, currentUser, exampleConf, subscriptions, projects,
+		subscriptionTriggers(), uis.Settings.Chat.WebhookURL != "", flashes}, "base",
 		"settings.html", "base_angular.html", "menu.html")
 }
 
@@ -2,7 +2,10 @@ package ui
 
 import (
 	"fmt"
+	"github.com/10gen-labs/slogger/v1"
+	"github.com/evergreen-ci/evergreen"
 	"github.com/evergreen-ci/evergreen/apimodels"
+	"github.com/evergreen-ci/evergreen/db"
 	"github.com/evergreen-ci/evergreen/model"
 	"github.com/evergreen-ci/evergreen/model/build"
 	"github.com/evergreen-ci/evergreen/model/user"
@@ -89,6 +92,12 @@ type waterfallVersion struct {
 	Errors []waterfallVersionError `json:"errors"`
 }
 
+// waterfallError is the body of an error response from the waterfall's json
+// endpoint, matching the errors of the rest of the REST API.
+type waterfallError struct {
+	Message string `json:"message"`
+}
+
 type waterfallVersionError struct {
 	Messages []string `json:"messages"`
 }
@@ -97,8 +106,10 @@ type waterfallVersionError struct {
 // elements consisting of multiple versions rolled-up into one.
 // The skip value indicates how many versions back in time should be skipped
 // before starting to fetch versions, the project indicates which project the
-// returned versions should be a part of.
-func getVersionsAndVariants(skip int, numVersionElements int, project *model.Project) ([]waterfallVersion, []string, error) {
+// returned versions should be a part of, and the filter narrows down the
+// versions, builds and tasks returned.
+func getVersionsAndVariants(skip int, numVersionElements int, project *model.Project,
+	filter *waterfallFilter) ([]waterfallVersion, []string, error) {
 	// the final array of versions to return
 	finalVersions := []waterfallVersion{}
 
@@ -118,7 +129,7 @@ func getVersionsAndVariants(skip int, numVersionElements int, project *model.Pro
 
 		// fetch the versions and associated builds
 		versionsFromDB, buildsByVersion, err :=
-			fetchVersionsAndAssociatedBuilds(project, skip, numVersionElements)
+			fetchVersionsAndAssociatedBuilds(project, filter, skip, numVersionElements)
 
 		if err != nil {
 			return nil, nil, fmt.Errorf("error fetching versions and builds:"+
@@ -213,7 +224,7 @@ func getVersionsAndVariants(skip int, numVersionElements int, project *model.Pro
 						" (removed)"
 				}
 
-				// add the tasks to the build
+				// add the tasks with a matching status to the build
 				for _, task := range build.Tasks {
 					taskForWaterfall := waterfallTask{
 						Id:            task.Id,
@@ -229,9 +240,18 @@ func getVersionsAndVariants(skip int, numVersionElements int, project *model.Pro
 						taskForWaterfall.Status = InactiveStatus
 					}
 
+					if !filter.matchesStatus(taskForWaterfall.Status) {
+						continue
+					}
+
 					buildForWaterfall.Tasks = append(buildForWaterfall.Tasks, taskForWaterfall)
 				}
 
+				// leave out builds with no tasks matching the filter
+				if len(buildForWaterfall.Tasks) == 0 && len(build.Tasks) != 0 {
+					continue
+				}
+
 				activeVersion.Builds =
 					append(activeVersion.Builds, buildForWaterfall)
 			}
@@ -263,11 +283,13 @@ func getVersionsAndVariants(skip int, numVersionElements int, project *model.Pro
 
 // Helper function to fetch a group of versions and their associated builds.
 // Returns the versions themselves, as well as a map of version id -> the
-// builds that are a part of the version (unsorted).
-func fetchVersionsAndAssociatedBuilds(project *model.Project, skip int, numVersions int) ([]version.Version, map[string][]build.Build, error) {
+// builds that are a part of the version (unsorted). Only the versions and
+// builds matching the filter are returned.
+func fetchVersionsAndAssociatedBuilds(project *model.Project, filter *waterfallFilter,
+	skip int, numVersions int) ([]version.Version, map[string][]build.Build, error) {
 
 	// fetch the versions from the db
-	versionsFromDB, err := version.Find(version.ByProjectId(project.Identifier).
+	versionsFromDB, err := version.Find(db.Query(filter.versionQuery(project.Identifier)).
 		WithFields(
 		version.RevisionKey,
 		version.ErrorsKey,
@@ -295,9 +317,14 @@ func fetchVersionsAndAssociatedBuilds(project *model.Project, skip int, numVersi
 		return nil, nil, fmt.Errorf("error fetching builds from database: %v", err)
 	}
 
-	// sort the builds by version
+	// sort the builds by version, leaving out the variants not matching
+	// the filter
+	buildVariantMappings := project.GetVariantMappings()
 	buildsByVersion := map[string][]build.Build{}
 	for _, build := range buildsFromDb {
+		if !filter.matchesVariant(build.BuildVariant, buildVariantMappings[build.BuildVariant]) {
+			continue
+		}
 		buildsByVersion[build.Version] = append(
 			buildsByVersion[build.Version], build)
 	}
@@ -322,7 +349,7 @@ func anyActiveTasks(builds []build.Build) bool {
 // the starting skip for the current page as well as the number of version
 // elements per page (including elements containing rolled-up versions).
 func countOnPreviousPage(skip int, numVersionElements int,
-	project *model.Project) (int, error) {
+	project *model.Project, filter *waterfallFilter) (int, error) {
 
 	// if there is no previous page
 	if skip == 0 {
@@ -351,7 +378,7 @@ func countOnPreviousPage(skip int, numVersionElements int,
 
 		// fetch the versions and builds
 		versionsFromDB, buildsByVersion, err :=
-			fetchVersionsAndAssociatedBuilds(project, stepBack, toFetch)
+			fetchVersionsAndAssociatedBuilds(project, filter, stepBack, toFetch)
 
 		if err != nil {
 			return 0, fmt.Errorf("error fetching versions and builds: %v", err)
@@ -406,13 +433,13 @@ func countOnPreviousPage(skip int, numVersionElements int,
 	}
 }
 
-// Create and return the waterfall data we need to render the page.
-// Http handler for the waterfall page
-func (uis *UIServer) waterfallPage(w http.ResponseWriter, r *http.Request) {
-	projCtx := MustHaveProjectContext(r)
-	if projCtx.Project == nil {
-		http.Error(w, "Project not found", http.StatusNotFound)
-		return
+// getWaterfallData creates and returns the waterfall data for the project,
+// filtered and paginated as the request's query params say. If the data can't
+// be created, the status code to report the error with is returned as well.
+func getWaterfallData(r *http.Request, project *model.Project) (*waterfallData, int, error) {
+	filter, err := parseWaterfallFilter(r)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
 	}
 
 	skip, err := skipValue(r)
@@ -420,36 +447,92 @@ func (uis *UIServer) waterfallPage(w http.ResponseWriter, r *http.Request) {
 		skip = 0
 	}
 
-	finalData := waterfallData{}
+	// jump to a revision or date, if one is given
+	revision := r.FormValue(RevisionQueryParam)
+	date := r.FormValue(DateQueryParam)
+	if revision != "" || date != "" {
+		var jumpTime time.Time
+		if revision == "" {
+			jumpTime, err = parseWaterfallDate(date)
+			if err != nil {
+				return nil, http.StatusBadRequest, err
+			}
+		}
+		var found bool
+		skip, found, err = filter.jumpSkip(project, revision, jumpTime)
+		if err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+		if !found {
+			return nil, http.StatusNotFound, fmt.Errorf("no version matching revision '%v' or date '%v' found",
+				revision, date)
+		}
+	}
+
+	finalData := &waterfallData{}
 
 	// first, get all of the versions and variants we will need
 	finalData.Versions, finalData.BuildVariants, err = getVersionsAndVariants(skip,
-		VersionItemsToCreate, projCtx.Project)
+		VersionItemsToCreate, project, filter)
 	if err != nil {
-		uis.LoggedError(w, r, http.StatusInternalServerError, err)
-		return
+		return nil, http.StatusInternalServerError, err
 	}
 
 	// compute the total number of versions that exist
-	finalData.TotalVersions, err = version.Count(version.ByProjectId(projCtx.Project.Identifier))
+	finalData.TotalVersions, err = version.Count(db.Query(filter.versionQuery(project.Identifier)))
 	if err != nil {
-		uis.LoggedError(w, r, http.StatusInternalServerError, err)
-		return
+		return nil, http.StatusInternalServerError, err
 	}
 
 	// compute the number of versions on the previous page
-	finalData.PreviousPageCount, err = countOnPreviousPage(skip, VersionItemsToCreate, projCtx.Project)
+	finalData.PreviousPageCount, err = countOnPreviousPage(skip, VersionItemsToCreate, project, filter)
 	if err != nil {
-		uis.LoggedError(w, r, http.StatusInternalServerError, err)
-		return
+		return nil, http.StatusInternalServerError, err
 	}
 
 	// add in the skip value
 	finalData.CurrentSkip = skip
 
+	return finalData, http.StatusOK, nil
+}
+
+// Http handler for the waterfall page
+func (uis *UIServer) waterfallPage(w http.ResponseWriter, r *http.Request) {
+	projCtx := MustHaveProjectContext(r)
+	if projCtx.Project == nil {
+		http.Error(w, "Project not found", http.StatusNotFound)
+		return
+	}
+
+	finalData, status, err := getWaterfallData(r, projCtx.Project)
+	if err != nil {
+		uis.LoggedError(w, r, status, err)
+		return
+	}
+
 	uis.WriteHTML(w, http.StatusOK, struct {
 		ProjectData projectContext
 		User        *user.DBUser
 		Data        waterfallData
-	}{projCtx, GetUser(r), finalData}, "base", "waterfall.html", "base_angular.html", "menu.html")
+	}{projCtx, GetUser(r), *finalData}, "base", "waterfall.html", "base_angular.html", "menu.html")
+}
+
+// Http handler for the waterfall's data, as json
+func (uis *UIServer) waterfallJSON(w http.ResponseWriter, r *http.Request) {
+	projCtx := MustHaveProjectContext(r)
+	if projCtx.Project == nil {
+		uis.WriteJSON(w, http.StatusNotFound, waterfallError{"Project not found"})
+		return
+	}
+
+	finalData, status, err := getWaterfallData(r, projCtx.Project)
+	if err != nil {
+		if status == http.StatusInternalServerError {
+			evergreen.Logger.Logf(slogger.ERROR, "Error creating waterfall of project '%v': %v",
+				projCtx.Project.Identifier, err)
+		}
+		uis.WriteJSON(w, status, waterfallError{err.Error()})
+		return
+	}
+	uis.WriteJSON(w, http.StatusOK, finalData)
 }
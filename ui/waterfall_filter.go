@@ -0,0 +1,132 @@
+package ui
+
+import (
+	"fmt"
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/version"
+	"gopkg.in/mgo.v2/bson"
+	"net/http"
+	"regexp"
+	"time"
+)
+
+const (
+	// Query params for filtering the waterfall.
+	VariantQueryParam  = "variant"
+	StatusQueryParam   = "status"
+	AuthorQueryParam   = "author"
+	MessageQueryParam  = "message"
+	RevisionQueryParam = "revision"
+	DateQueryParam     = "date"
+
+	// the day-only format accepted for the date param, besides RFC 3339
+	waterfallDateFormat = "2006-01-02"
+)
+
+// waterfallFilter narrows down the versions, variants and tasks shown on the
+// waterfall. The zero value filters out nothing.
+type waterfallFilter struct {
+	// Variant matches the name or display name of the variants to show
+	Variant *regexp.Regexp
+	// Status is the status of the tasks to show, which may be "inactive"
+	Status string
+	// Author and Message are case-insensitive substrings of the authors and
+	// commit messages of the versions to show
+	Author  string
+	Message string
+}
+
+// parseWaterfallFilter reads the waterfall filter from the request's
+// query params.
+func parseWaterfallFilter(r *http.Request) (*waterfallFilter, error) {
+	filter := &waterfallFilter{
+		Status:  r.FormValue(StatusQueryParam),
+		Author:  r.FormValue(AuthorQueryParam),
+		Message: r.FormValue(MessageQueryParam),
+	}
+	if variant := r.FormValue(VariantQueryParam); variant != "" {
+		variantRegexp, err := regexp.Compile(variant)
+		if err != nil {
+			return nil, fmt.Errorf("invalid variant regex '%v': %v", variant, err)
+		}
+		filter.Variant = variantRegexp
+	}
+	return filter, nil
+}
+
+// containsRegex matches strings containing the substring, ignoring case.
+func containsRegex(substring string) bson.RegEx {
+	return bson.RegEx{regexp.QuoteMeta(substring), "i"}
+}
+
+// versionQuery returns the query for the mainline versions of the project
+// matching the filter.
+func (f *waterfallFilter) versionQuery(projectId string) bson.M {
+	query := bson.M{
+		version.IdentifierKey: projectId,
+		version.RequesterKey:  evergreen.RepotrackerVersionRequester,
+	}
+	if f.Author != "" {
+		query[version.AuthorKey] = containsRegex(f.Author)
+	}
+	if f.Message != "" {
+		query[version.MessageKey] = containsRegex(f.Message)
+	}
+	return query
+}
+
+// matchesVariant returns true if the filter shows builds of the variant.
+func (f *waterfallFilter) matchesVariant(name, displayName string) bool {
+	return f.Variant == nil || f.Variant.MatchString(name) || f.Variant.MatchString(displayName)
+}
+
+// matchesStatus returns true if the filter shows tasks with the status.
+func (f *waterfallFilter) matchesStatus(status string) bool {
+	return f.Status == "" || f.Status == status
+}
+
+// parseWaterfallDate parses the date to jump to, either as a day or a full
+// RFC 3339 time. A day includes the versions created at any time during it.
+func parseWaterfallDate(date string) (time.Time, error) {
+	if day, err := time.Parse(waterfallDateFormat, date); err == nil {
+		return day.Add(24*time.Hour - time.Nanosecond), nil
+	}
+	t, err := time.Parse(time.RFC3339, date)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date '%v': expected YYYY-MM-DD or RFC 3339", date)
+	}
+	return t, nil
+}
+
+// jumpSkip returns the number of versions matching the filter that come
+// before the version to jump to, which is the one with the given revision
+// (or revision prefix) or, if the revision is empty, the most recent one
+// created by the given time. Returns false if there's no such version.
+func (f *waterfallFilter) jumpSkip(project *model.Project, revision string, date time.Time) (int, bool, error) {
+	query := f.versionQuery(project.Identifier)
+	if revision != "" {
+		query[version.RevisionKey] = bson.RegEx{"^" + regexp.QuoteMeta(revision), ""}
+	} else {
+		query[version.CreateTimeKey] = bson.M{"$lte": date}
+	}
+
+	target, err := version.FindOne(db.Query(query).
+		WithFields(version.RevisionOrderNumberKey).
+		Sort([]string{"-" + version.RevisionOrderNumberKey}))
+	if err != nil {
+		return 0, false, err
+	}
+	if target == nil {
+		return 0, false, nil
+	}
+
+	newerQuery := f.versionQuery(project.Identifier)
+	newerQuery[version.RevisionOrderNumberKey] = bson.M{"$gt": target.RevisionOrderNumber}
+	skip, err := version.Count(db.Query(newerQuery))
+	if err != nil {
+		return 0, false, err
+	}
+	return skip, true, nil
+}
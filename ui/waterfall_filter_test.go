@@ -0,0 +1,61 @@
+package ui
+
+import (
+	"github.com/evergreen-ci/evergreen/model/version"
+	. "github.com/smartystreets/goconvey/convey"
+	"gopkg.in/mgo.v2/bson"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestWaterfallFilter(t *testing.T) {
+	Convey("When parsing a waterfall filter from a request", t, func() {
+
+		Convey("the params should be read into the filter", func() {
+			r, err := http.NewRequest("GET", "/waterfall/p?variant=^linux&status=failed&author=Milkie", nil)
+			So(err, ShouldBeNil)
+			filter, err := parseWaterfallFilter(r)
+			So(err, ShouldBeNil)
+			So(filter.Status, ShouldEqual, "failed")
+			So(filter.matchesVariant("linux-64", "Linux 64-bit"), ShouldBeTrue)
+			So(filter.matchesVariant("windows", "Windows"), ShouldBeFalse)
+			So(filter.matchesStatus("failed"), ShouldBeTrue)
+			So(filter.matchesStatus("success"), ShouldBeFalse)
+
+			query := filter.versionQuery("p")
+			So(query[version.IdentifierKey], ShouldEqual, "p")
+			So(query[version.AuthorKey], ShouldResemble, bson.RegEx{"Milkie", "i"})
+			So(query[version.MessageKey], ShouldBeNil)
+		})
+
+		Convey("an empty filter should match everything", func() {
+			r, err := http.NewRequest("GET", "/waterfall/p", nil)
+			So(err, ShouldBeNil)
+			filter, err := parseWaterfallFilter(r)
+			So(err, ShouldBeNil)
+			So(filter.matchesVariant("windows", "Windows"), ShouldBeTrue)
+			So(filter.matchesStatus(InactiveStatus), ShouldBeTrue)
+		})
+
+		Convey("an invalid variant regex should be an error", func() {
+			r, err := http.NewRequest("GET", "/waterfall/p?variant=(linux", nil)
+			So(err, ShouldBeNil)
+			_, err = parseWaterfallFilter(r)
+			So(err, ShouldNotBeNil)
+		})
+	})
+
+	Convey("Waterfall dates should be parsed as days or full times", t, func() {
+		date, err := parseWaterfallDate("2014-07-22")
+		So(err, ShouldBeNil)
+		So(date.Add(time.Nanosecond), ShouldResemble, time.Date(2014, 7, 23, 0, 0, 0, 0, time.UTC))
+
+		date, err = parseWaterfallDate("2014-07-22T13:02:09Z")
+		So(err, ShouldBeNil)
+		So(date, ShouldResemble, time.Date(2014, 7, 22, 13, 2, 9, 0, time.UTC))
+
+		_, err = parseWaterfallDate("yesterday")
+		So(err, ShouldNotBeNil)
+	})
+}
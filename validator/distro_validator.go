@@ -3,12 +3,14 @@ package validator
 import (
 	"fmt"
 	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/cloud"
 	"github.com/evergreen-ci/evergreen/cloud/providers"
 	"github.com/evergreen-ci/evergreen/cloud/providers/static"
 	"github.com/evergreen-ci/evergreen/model/distro"
 	_ "github.com/evergreen-ci/evergreen/plugin/config"
 	"github.com/evergreen-ci/evergreen/util"
 	"github.com/mitchellh/mapstructure"
+	"strings"
 )
 
 type distroValidator func(*distro.Distro, *evergreen.Settings) []ValidationError
@@ -19,6 +21,7 @@ var distroSyntaxValidators = []distroValidator{
 	ensureHasRequiredFields,
 	ensureValidSSHOptions,
 	ensureValidExpansions,
+	ensureValidUserDataProvisioning,
 }
 
 // CheckDistro checks if the distro configuration syntax is valid. Returns
@@ -140,3 +143,33 @@ func ensureValidSSHOptions(d *distro.Distro, s *evergreen.Settings) []Validation
 	}
 	return nil
 }
+
+// ensureValidUserDataProvisioning checks that distros provisioned with user
+// data use a provider that starts its hosts with user data, and hosts that
+// can run the shell script it's made of.
+func ensureValidUserDataProvisioning(d *distro.Distro, s *evergreen.Settings) []ValidationError {
+	if !d.ProvisionWithUserData {
+		return nil
+	}
+	errs := []ValidationError{}
+	if strings.HasPrefix(d.Arch, "windows") {
+		errs = append(errs, ValidationError{
+			Message: fmt.Sprintf("distro '%v' cannot be set for Windows hosts", distro.ProvisionWithUserDataKey),
+			Level:   Error,
+		})
+	}
+
+	// an unknown provider is reported by ensureHasRequiredFields
+	mgr, err := providers.GetCloudManager(d.Provider, s)
+	if err != nil {
+		return errs
+	}
+	if userDataMgr, ok := mgr.(cloud.UserDataManager); !ok || !userDataMgr.SupportsUserData() {
+		errs = append(errs, ValidationError{
+			Message: fmt.Sprintf("distro '%v' cannot be set for provider '%v'",
+				distro.ProvisionWithUserDataKey, d.Provider),
+			Level: Error,
+		})
+	}
+	return errs
+}
@@ -3,6 +3,7 @@ package validator
 import (
 	"github.com/evergreen-ci/evergreen"
 	"github.com/evergreen-ci/evergreen/cloud/providers/ec2"
+	"github.com/evergreen-ci/evergreen/cloud/providers/static"
 	"github.com/evergreen-ci/evergreen/db"
 	"github.com/evergreen-ci/evergreen/model/distro"
 	_ "github.com/evergreen-ci/evergreen/plugin/config"
@@ -233,3 +234,32 @@ func TestEnsureValidSSHOptions(t *testing.T) {
 		})
 	})
 }
+
+func TestEnsureValidUserDataProvisioning(t *testing.T) {
+	Convey("When validating a distro provisioned with user data...", t, func() {
+		Convey("if its provider starts hosts with user data, no error should be returned", func() {
+			d := &distro.Distro{
+				Arch:                  "linux_amd64",
+				Provider:              ec2.OnDemandProviderName,
+				ProvisionWithUserData: true,
+			}
+			So(ensureValidUserDataProvisioning(d, conf), ShouldResemble, []ValidationError{})
+		})
+		Convey("if its provider can't start hosts with user data, an error should be returned", func() {
+			d := &distro.Distro{
+				Arch:                  "linux_amd64",
+				Provider:              static.ProviderName,
+				ProvisionWithUserData: true,
+			}
+			So(len(ensureValidUserDataProvisioning(d, conf)), ShouldEqual, 1)
+		})
+		Convey("if its hosts run Windows, an error should be returned", func() {
+			d := &distro.Distro{
+				Arch:                  "windows_amd64",
+				Provider:              ec2.OnDemandProviderName,
+				ProvisionWithUserData: true,
+			}
+			So(len(ensureValidUserDataProvisioning(d, conf)), ShouldEqual, 1)
+		})
+	})
+}
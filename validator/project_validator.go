@@ -3,6 +3,7 @@ package validator
 import (
 	"fmt"
 	"github.com/evergreen-ci/evergreen"
+	evgcommand "github.com/evergreen-ci/evergreen/command"
 	"github.com/evergreen-ci/evergreen/model"
 	"github.com/evergreen-ci/evergreen/model/distro"
 	"github.com/evergreen-ci/evergreen/plugin"
@@ -472,6 +473,12 @@ func validateCommands(section string, project *model.Project, registry plugin.Re
 				errs = append(errs, ValidationError{Message: msg})
 			}
 		}
+		if cmd.If != "" {
+			if err := evgcommand.ValidateCondition(cmd.If); err != nil {
+				msg := fmt.Sprintf("%v section in %v: %v", section, command, err)
+				errs = append(errs, ValidationError{Message: msg})
+			}
+		}
 	}
 	return errs
 }
@@ -560,10 +567,21 @@ func validateProjectTaskNames(project *model.Project) []ValidationError {
 	taskNames := map[string]bool{}
 	for _, task := range project.Tasks {
 		if _, ok := taskNames[task.Name]; ok {
+			message := fmt.Sprintf("task '%v' in project '%v' already exists",
+				task.Name, project.Identifier)
+			if len(task.MatrixParameterValues) > 0 {
+				message += fmt.Sprintf(" (generated from matrix parameter "+
+					"values %v)", task.MatrixParameterValues)
+			}
+			errs = append(errs, ValidationError{Message: message})
+		}
+		if model.IsTaskTagSelector(task.Name) {
 			errs = append(errs,
 				ValidationError{
-					Message: fmt.Sprintf("task '%v' in project '%v' "+
-						"already exists", task.Name, project.Identifier),
+					Message: fmt.Sprintf("task '%v' in project '%v' can't "+
+						"start with '%v', which is used to select tasks by "+
+						"tag", task.Name, project.Identifier,
+						model.TaskTagSelectorPrefix),
 				},
 			)
 		}
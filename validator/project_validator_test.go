@@ -669,6 +669,32 @@ func TestValidateProjectTaskNames(t *testing.T) {
 			So(validateProjectTaskNames(project), ShouldNotResemble, []ValidationError{})
 			So(len(validateProjectTaskNames(project)), ShouldEqual, 1)
 		})
+		Convey("ensure duplicate task names generated from matrix parameters"+
+			" throw an error", func() {
+			project := &model.Project{
+				Tasks: []model.ProjectTask{
+					{Name: "jstests_${suite}", MatrixParameters: []model.MatrixParameter{
+						{Name: "suite", Values: []model.MatrixParameterValue{{Value: "core"}}},
+						{Name: "engine", Values: []model.MatrixParameterValue{
+							{Value: "mmapv1"}, {Value: "wiredTiger"}}},
+					}},
+				},
+			}
+			So(model.LoadProjectInto([]byte{}, "proj", project), ShouldBeNil)
+			errs := validateProjectTaskNames(project)
+			So(len(errs), ShouldEqual, 1)
+			So(errs[0].Message, ShouldContainSubstring, "jstests_core")
+			So(errs[0].Message, ShouldContainSubstring, "matrix parameter")
+		})
+		Convey("ensure task names that look like tag selectors throw an"+
+			" error", func() {
+			project := &model.Project{
+				Tasks: []model.ProjectTask{
+					{Name: ".compile"},
+				},
+			}
+			So(len(validateProjectTaskNames(project)), ShouldEqual, 1)
+		})
 		Convey("ensure unique task names do not throw an error", func() {
 			project := &model.Project{
 				Tasks: []model.ProjectTask{